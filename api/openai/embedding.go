@@ -0,0 +1,144 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/aidea-server/pkg/youdao"
+	"github.com/mylxsw/aidea-server/server/auth"
+	"github.com/mylxsw/aidea-server/server/controllers/common"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
+)
+
+// embeddingMaxInputs 单次请求最多允许的文本数量
+const embeddingMaxInputs = 2048
+
+// EmbeddingController OpenAI 兼容的文本向量化接口
+type EmbeddingController struct {
+	translater youdao.Translater    `autowire:"@"`
+	userSrv    *service.UserService `autowire:"@"`
+	quotaRepo  *repo.QuotaRepo      `autowire:"@"`
+	embedding  *embedding.Imp       `autowire:"@"`
+}
+
+func NewEmbeddingController(resolver infra.Resolver) web.Controller {
+	ctl := &EmbeddingController{}
+	resolver.MustAutoWire(ctl)
+	return ctl
+}
+
+func (ctl *EmbeddingController) Register(router web.Router) {
+	router.Group("/embeddings", func(router web.Router) {
+		router.Post("/", ctl.Embeddings)
+	})
+}
+
+// EmbeddingInput 向量化的输入内容，可以是单个字符串，也可以是字符串数组
+type EmbeddingInput []string
+
+func (in *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = EmbeddingInput{text}
+		return nil
+	}
+
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return errors.New("input must be a string or an array of strings")
+	}
+
+	*in = texts
+	return nil
+}
+
+type EmbeddingRequest struct {
+	Input EmbeddingInput `json:"input"`
+	Model string         `json:"model"`
+	// EncodingFormat 向量的返回格式：float 或者 base64
+	EncodingFormat string `json:"encoding_format,omitempty"`
+	User           string `json:"user,omitempty"`
+}
+
+type EmbeddingData struct {
+	Object string `json:"object"`
+	// Embedding 向量，encoding_format 为 base64 时为 base64 编码的字符串
+	Embedding any `json:"embedding"`
+	Index     int `json:"index"`
+}
+
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+type EmbeddingResponse struct {
+	Object string          `json:"object"`
+	Data   []EmbeddingData `json:"data"`
+	Model  string          `json:"model"`
+	Usage  EmbeddingUsage  `json:"usage"`
+}
+
+// Embeddings 文本向量化
+func (ctl *EmbeddingController) Embeddings(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	var req EmbeddingRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if len(req.Input) == 0 || len(req.Input) > embeddingMaxInputs || req.EncodingFormat != "" && req.EncodingFormat != "float" && req.EncodingFormat != "base64" {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if !ctl.embedding.Support(req.Model) {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidModel), http.StatusBadRequest)
+	}
+
+	quota, err := ctl.userSrv.UserQuota(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("查询用户智慧果余量失败: %s", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	// 请求前根据估算的 token 数量检查余额，实际扣费以厂商返回的 token 数量为准
+	if quota.Rest-quota.Freezed < coins.GetEmbeddingCoins(req.Model, int64(embedding.EstimateTokens(req.Model, req.Input))) {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrQuotaNotEnough), http.StatusPaymentRequired)
+	}
+
+	resp, err := ctl.embedding.Embedding(ctx, embedding.Request{
+		Model: req.Model,
+		Input: req.Input,
+		User:  req.User,
+	})
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "model": req.Model}).Errorf("create embeddings failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	if err := ctl.quotaRepo.QuotaConsume(ctx, user.ID, coins.GetEmbeddingCoins(req.Model, int64(resp.InputTokens)), repo.NewQuotaUsedMeta("embedding", req.Model)); err != nil {
+		log.F(log.M{"user_id": user.ID, "model": req.Model}).Errorf("used quota add failed: %s", err)
+	}
+
+	return webCtx.JSON(EmbeddingResponse{
+		Object: "list",
+		Data: array.Map(resp.Embeddings, func(vector []float32, index int) EmbeddingData {
+			var value any = vector
+			if req.EncodingFormat == "base64" {
+				value = embedding.EncodeBase64(vector)
+			}
+
+			return EmbeddingData{Object: "embedding", Embedding: value, Index: index}
+		}),
+		Model: resp.Model,
+		Usage: EmbeddingUsage{PromptTokens: resp.InputTokens, TotalTokens: resp.InputTokens},
+	})
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/internal/coins"
 	"github.com/mylxsw/aidea-server/pkg/ai/chat"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/web"
@@ -12,6 +13,7 @@ import (
 
 type CompatibleController struct {
 	conf *config.Config `autowire:"@"`
+	chat chat.Chat      `autowire:"@"`
 }
 
 func NewOpenAICompatibleController(resolver infra.Resolver) web.Controller {
@@ -32,16 +34,45 @@ type Model struct {
 	Object  string `json:"object"`
 	Created int64  `json:"created"`
 	OwnedBy string `json:"owned_by"`
+
+	// 以下为模型目录中的能力描述，OpenAI 官方接口中没有这些字段
+	Name          string   `json:"name,omitempty"`
+	Aliases       []string `json:"aliases,omitempty"`
+	ContextWindow int      `json:"context_window,omitempty"`
+	MaxOutput     int      `json:"max_output,omitempty"`
+	SupportVision bool     `json:"support_vision,omitempty"`
+	SupportTools  bool     `json:"support_tools,omitempty"`
+	InputPrice    int64    `json:"input_price,omitempty"`
+	OutputPrice   int64    `json:"output_price,omitempty"`
+}
+
+func (ctl *CompatibleController) buildModel(item chat.Model) Model {
+	input, output, ok := item.Price()
+	if !ok {
+		// 未在模型目录中配置价格时，使用价格表中的价格
+		input = coins.GetOpenAITextCoins(item.RealID(), 1000)
+		output = input
+	}
+
+	return Model{
+		ID:            item.RealID(),
+		Object:        "model",
+		Created:       1626777600,
+		OwnedBy:       item.Category,
+		Name:          item.Name,
+		Aliases:       item.Aliases,
+		ContextWindow: chat.ContextWindow(ctl.chat, item),
+		MaxOutput:     item.MaxOutput,
+		SupportVision: item.SupportVision,
+		SupportTools:  item.SupportTools,
+		InputPrice:    input,
+		OutputPrice:   output,
+	}
 }
 
 func (ctl *CompatibleController) Models(ctx context.Context, webCtx web.Context) web.Response {
 	models := array.Map(chat.Models(ctl.conf, false), func(item chat.Model, _ int) Model {
-		return Model{
-			ID:      item.RealID(),
-			Object:  "model",
-			Created: 1626777600,
-			OwnedBy: item.Category,
-		}
+		return ctl.buildModel(item)
 	})
 	return webCtx.JSON(web.M{"data": models, "object": "list"})
 }
@@ -49,17 +80,12 @@ func (ctl *CompatibleController) Models(ctx context.Context, webCtx web.Context)
 func (ctl *CompatibleController) Model(ctx context.Context, webCtx web.Context) web.Response {
 	modelID := webCtx.PathVar("model_id")
 	matched := array.Filter(chat.Models(ctl.conf, true), func(item chat.Model, _ int) bool {
-		return item.RealID() == modelID
+		return item.Match(modelID)
 	})
 
 	if len(matched) == 0 {
 		return webCtx.JSONError("model not found", http.StatusNotFound)
 	}
 
-	return webCtx.JSON(Model{
-		ID:      modelID,
-		Object:  "model",
-		Created: 1626777600,
-		OwnedBy: matched[0].Category,
-	})
+	return webCtx.JSON(ctl.buildModel(matched[0]))
 }
@@ -169,7 +169,9 @@ func routes(resolver infra.Resolver, router web.Router, mw web.RequestMiddleware
 	r.Controllers(
 		"/v1",
 		controllers.NewOpenAIController(resolver, conf, true),
+		controllers.NewMessageController(resolver),
 		openai.NewOpenAICompatibleController(resolver),
+		openai.NewEmbeddingController(resolver),
 	)
 
 	r.Controllers(
@@ -20,11 +20,14 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/ai/chat"
 	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
 	"github.com/mylxsw/aidea-server/pkg/ai/deepai"
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
 	"github.com/mylxsw/aidea-server/pkg/ai/fromston"
 	"github.com/mylxsw/aidea-server/pkg/ai/getimgai"
 	"github.com/mylxsw/aidea-server/pkg/ai/gpt360"
 	"github.com/mylxsw/aidea-server/pkg/ai/leap"
 	"github.com/mylxsw/aidea-server/pkg/ai/lepton"
+	"github.com/mylxsw/aidea-server/pkg/ai/local"
 	"github.com/mylxsw/aidea-server/pkg/ai/oneapi"
 	"github.com/mylxsw/aidea-server/pkg/ai/openai"
 	"github.com/mylxsw/aidea-server/pkg/ai/sensenova"
@@ -119,6 +122,7 @@ func main() {
 		service.Provider{},
 		jobs.Provider{},
 		chat.Provider{},
+		embedding.Provider{},
 		proxy.Provider{},
 		file.Provider{},
 		migrate.Provider{},
@@ -160,6 +164,8 @@ func main() {
 		google.Provider{},
 		openrouter.Provider{},
 		sky.Provider{},
+		local.Provider{},
+		fake.Provider{},
 	)
 
 	app.MustRun(ins)
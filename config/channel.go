@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAIChannel 兼容 OpenAI 接口的聊天渠道，如 DeepSeek、Moonshot 或者内部的模型网关
+type OpenAIChannel struct {
+	// Name 渠道名称，同时作为模型 ID 的前缀，如 deepseek:deepseek-chat
+	Name string `json:"name" yaml:"name"`
+	// Aliases 渠道别名，路由规则、故障转移规则中可以使用别名引用渠道
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// Servers 服务地址，不要忘记在 URL 后面添加 /v1，配置多个时会在多个服务之间平衡负载
+	Servers []string `json:"servers" yaml:"servers"`
+	// Keys API Key，支持 key|权重 的格式，Azure 模式下每个服务地址对应一个 Key
+	Keys []string `json:"-" yaml:"keys"`
+	// Organization OpenAI 组织 ID
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
+	// Azure 是否为 Azure OpenAI 服务
+	Azure bool `json:"azure,omitempty" yaml:"azure,omitempty"`
+	// APIVersion Azure OpenAI 服务的 API 版本
+	APIVersion string `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	// AutoProxy 是否使用 Socks5 代理访问
+	AutoProxy bool `json:"autoproxy,omitempty" yaml:"autoproxy,omitempty"`
+	// Headers 请求时附加的 HTTP 头
+	Headers map[string]string `json:"-" yaml:"headers,omitempty"`
+	// StreamUsage 流式请求时附加 stream_options.include_usage，要求渠道返回 Token 用量，渠道不支持该参数时不要开启
+	StreamUsage bool `json:"stream_usage,omitempty" yaml:"stream_usage,omitempty"`
+	// ContextWindow 模型的上下文窗口大小（Token），模型未单独配置时使用，为 0 时使用 OpenAI 同名模型的上下文长度
+	ContextWindow int `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+	// Models 渠道支持的模型
+	Models []OpenAIChannelModel `json:"models" yaml:"models"`
+}
+
+// OpenAIChannelModel 兼容 OpenAI 接口的渠道支持的模型
+type OpenAIChannelModel struct {
+	// ID 模型名称，完整的模型 ID 为 {渠道名称}:{模型名称}
+	ID string `json:"id" yaml:"id"`
+	// Upstream 请求渠道时使用的模型名称（Azure 中为部署名称），为空时与 ID 相同
+	Upstream string `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	// Name 模型展示名称
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty" yaml:"avatar_url,omitempty"`
+	// SupportVision 是否支持图片输入
+	SupportVision bool `json:"support_vision,omitempty" yaml:"support_vision,omitempty"`
+	// ContextWindow 模型的上下文窗口大小（Token），为 0 时使用渠道的配置
+	ContextWindow int `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+	// Hidden 隐藏的模型不在模型列表中展示，也不参与默认路由，只用于路由规则、故障转移，如为内置模型提供备用渠道
+	Hidden bool `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+}
+
+// UpstreamModel 请求渠道时使用的模型名称
+func (m OpenAIChannelModel) UpstreamModel() string {
+	if m.Upstream != "" {
+		return m.Upstream
+	}
+
+	return m.ID
+}
+
+// LoadOpenAIChannels 读取兼容 OpenAI 接口的渠道配置文件（YAML）
+func LoadOpenAIChannels(path string) ([]OpenAIChannel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOpenAIChannels(data)
+}
+
+// ParseOpenAIChannels 解析兼容 OpenAI 接口的渠道配置
+func ParseOpenAIChannels(data []byte) ([]OpenAIChannel, error) {
+	var file struct {
+		Channels []OpenAIChannel `yaml:"channels"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for i, ch := range file.Channels {
+		name := strings.TrimSpace(ch.Name)
+		if name == "" {
+			return nil, fmt.Errorf("channel #%d: missing name", i+1)
+		}
+
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("channel %s: duplicate name", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if len(ch.Servers) == 0 {
+			return nil, fmt.Errorf("channel %s: missing servers", name)
+		}
+
+		if len(ch.Keys) == 0 {
+			return nil, fmt.Errorf("channel %s: missing keys", name)
+		}
+
+		if ch.Azure && len(ch.Keys) != len(ch.Servers) {
+			return nil, fmt.Errorf("channel %s: azure channel requires one key per server", name)
+		}
+
+		for j, m := range ch.Models {
+			if strings.TrimSpace(m.ID) == "" {
+				return nil, fmt.Errorf("channel %s: model #%d missing id", name, j+1)
+			}
+		}
+
+		file.Channels[i].Name = name
+	}
+
+	return file.Channels, nil
+}
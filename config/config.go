@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/starter/app"
@@ -130,6 +132,122 @@ type Config struct {
 	OpenRouterServer        string   `json:"openrouter_server" yaml:"openrouter_server"`
 	OpenRouterKey           string   `json:"openrouter_key" yaml:"openrouter_key"`
 
+	// OpenAIChannels 兼容 OpenAI 接口的渠道
+	OpenAIChannels []OpenAIChannel `json:"openai_channels" yaml:"openai_channels"`
+
+	// 本地推理服务（Ollama/llama.cpp server）
+	EnableLocal bool   `json:"enable_local" yaml:"enable_local"`
+	LocalAPI    string `json:"local_api" yaml:"local_api"`
+	LocalServer string `json:"local_server" yaml:"local_server"`
+	// LocalContextLength 本地模型的上下文窗口大小
+	LocalContextLength int `json:"local_context_length" yaml:"local_context_length"`
+	// LocalPrice 本地模型每 1K Token 的价格，为 0 时免费
+	LocalPrice int64 `json:"local_price" yaml:"local_price"`
+	// LocalPrices 单个本地模型每 1K Token 的价格，格式为 `模型=价格`，未配置的模型使用 LocalPrice
+	LocalPrices []string `json:"local_prices" yaml:"local_prices"`
+	// LocalRefresh 本地推理服务模型列表的刷新间隔
+	LocalRefresh time.Duration `json:"local_refresh" yaml:"local_refresh"`
+
+	// 模拟的聊天、图片生成服务，用于开发和测试
+	EnableFake bool `json:"enable_fake" yaml:"enable_fake"`
+	// FakeLatency 每次请求的延迟，流式响应中为每个分片的延迟
+	FakeLatency time.Duration `json:"fake_latency" yaml:"fake_latency"`
+	// FakeChunkSize 流式响应每个分片包含的字符数
+	FakeChunkSize int `json:"fake_chunk_size" yaml:"fake_chunk_size"`
+	// FakeError 对所有请求注入的错误：429、500、timeout、content_filter
+	FakeError string `json:"fake_error" yaml:"fake_error"`
+	// FakeTimeout 注入 timeout 错误时，请求挂起的时间
+	FakeTimeout time.Duration `json:"fake_timeout" yaml:"fake_timeout"`
+	// FakeScriptFile 剧本文件路径
+	FakeScriptFile string `json:"fake_script_file" yaml:"fake_script_file"`
+	// FakeImageURL 图片地址模板
+	FakeImageURL string `json:"fake_image_url" yaml:"fake_image_url"`
+
+	// ChatRoutes 聊天模型路由规则，格式为 `模型=渠道`，用于将模型指定到特定的渠道处理
+	// 例如 `qwen-max=dashscope`，模型以 `:*` 结尾表示前缀匹配，如 `deepseek:*=oneapi`
+	ChatRoutes []string `json:"chat_routes" yaml:"chat_routes"`
+	// ChatFailover 聊天模型故障转移规则，格式为 `模型=渠道1,渠道2@模型`，渠道按照顺序依次尝试
+	// 渠道后可以通过 `@` 指定该渠道使用的模型名称，如 `gpt-4=openai,openai-backup,openrouter@openai.gpt-4`
+	ChatFailover []string `json:"chat_failover" yaml:"chat_failover"`
+	// ChatFailoverThreshold 渠道连续失败多少次后触发熔断
+	ChatFailoverThreshold int `json:"chat_failover_threshold" yaml:"chat_failover_threshold"`
+	// ChatFailoverCooldown 渠道熔断后，多长时间后重新尝试
+	ChatFailoverCooldown time.Duration `json:"chat_failover_cooldown" yaml:"chat_failover_cooldown"`
+	// ChatFailoverFirstResponseTimeout 流式响应等待首个响应的超时时间，超时后切换到下一个渠道
+	ChatFailoverFirstResponseTimeout time.Duration `json:"chat_failover_first_response_timeout" yaml:"chat_failover_first_response_timeout"`
+
+	// ChatPolicies 聊天模型路由策略，根据用户等级、上下文长度等信息改写请求的模型或者渠道
+	ChatPolicies []string `json:"chat_policies" yaml:"chat_policies"`
+	// ChatChannelCosts 聊天渠道的相对成本，格式为 `渠道=成本`
+	ChatChannelCosts []string `json:"chat_channel_costs" yaml:"chat_channel_costs"`
+
+	// KeyPoolCooldown API Key 被限流（429）后的隔离时间
+	KeyPoolCooldown time.Duration `json:"keypool_cooldown" yaml:"keypool_cooldown"`
+	// KeyPoolAuthCooldown API Key 认证失败（401/403）后的隔离时间
+	KeyPoolAuthCooldown time.Duration `json:"keypool_auth_cooldown" yaml:"keypool_auth_cooldown"`
+	// KeyPoolMaxConcurrency 单个 API Key 的最大并发请求数，0 表示不限制
+	KeyPoolMaxConcurrency int `json:"keypool_max_concurrency" yaml:"keypool_max_concurrency"`
+	// KeyPoolRPM 单个 API Key 每分钟的最大请求数，0 表示不限制
+	KeyPoolRPM int `json:"keypool_rpm" yaml:"keypool_rpm"`
+
+	// ModelCatalogFile 模型目录文件路径（YAML）
+	ModelCatalogFile string `json:"model_catalog_file" yaml:"model_catalog_file"`
+	// ModelCatalogDB 是否从数据库中加载模型目录
+	ModelCatalogDB bool `json:"model_catalog_db" yaml:"model_catalog_db"`
+	// ModelCatalogRefresh 模型目录的刷新间隔
+	ModelCatalogRefresh time.Duration `json:"model_catalog_refresh" yaml:"model_catalog_refresh"`
+
+	// ChatContextCompression 是否启用上下文压缩，启用后，超出上下文窗口的早期对话会被总结为摘要保留在上下文中
+	ChatContextCompression bool `json:"chat_context_compression" yaml:"chat_context_compression"`
+	// ChatContextCompressionModel 生成上下文摘要使用的模型
+	ChatContextCompressionModel string `json:"chat_context_compression_model" yaml:"chat_context_compression_model"`
+	// ChatContextCompressionMaxTokens 上下文摘要的最大 Token 数量
+	ChatContextCompressionMaxTokens int `json:"chat_context_compression_max_tokens" yaml:"chat_context_compression_max_tokens"`
+	// ChatContextCompressionTTL 上下文摘要的缓存时间
+	ChatContextCompressionTTL time.Duration `json:"chat_context_compression_ttl" yaml:"chat_context_compression_ttl"`
+
+	// ChatSuggestionTitle 是否在新数字人的首次对话后，自动生成数字人的标题
+	ChatSuggestionTitle bool `json:"chat_suggestion_title" yaml:"chat_suggestion_title"`
+	// ChatSuggestionFollowUp 是否在回答结束后生成推荐的追问问题
+	ChatSuggestionFollowUp bool `json:"chat_suggestion_follow_up" yaml:"chat_suggestion_follow_up"`
+	// ChatSuggestionModel 生成标题和推荐问题使用的模型
+	ChatSuggestionModel string `json:"chat_suggestion_model" yaml:"chat_suggestion_model"`
+	// ChatSuggestionBilling 生成标题和推荐问题是否向用户收费
+	ChatSuggestionBilling bool `json:"chat_suggestion_billing" yaml:"chat_suggestion_billing"`
+
+	// ChatCompareMaxModels 多模型对比时，一次最多可以同时对比的模型数量
+	ChatCompareMaxModels int `json:"chat_compare_max_models" yaml:"chat_compare_max_models"`
+
+	// EnableKnowledgeBase 是否启用知识库
+	EnableKnowledgeBase bool `json:"enable_knowledge_base" yaml:"enable_knowledge_base"`
+	// KnowledgeEmbeddingProvider 文本向量化服务提供方：openai、local
+	KnowledgeEmbeddingProvider string `json:"knowledge_embedding_provider" yaml:"knowledge_embedding_provider"`
+	// KnowledgeEmbeddingModel 文本向量化使用的模型
+	KnowledgeEmbeddingModel string `json:"knowledge_embedding_model" yaml:"knowledge_embedding_model"`
+	// KnowledgeChunkSize 文档分块的最大字符数
+	KnowledgeChunkSize int `json:"knowledge_chunk_size" yaml:"knowledge_chunk_size"`
+	// KnowledgeChunkOverlap 相邻文档分块之间重叠的字符数
+	KnowledgeChunkOverlap int `json:"knowledge_chunk_overlap" yaml:"knowledge_chunk_overlap"`
+	// KnowledgeTopK 聊天时检索的文档分块数量
+	KnowledgeTopK int `json:"knowledge_top_k" yaml:"knowledge_top_k"`
+	// KnowledgeMinScore 检索结果的最低相似度（百分比）
+	KnowledgeMinScore int `json:"knowledge_min_score" yaml:"knowledge_min_score"`
+
+	// WebSearchProvider 联网搜索服务提供方：searxng、bing、google、fixture，为空时不启用
+	WebSearchProvider string `json:"web_search_provider" yaml:"web_search_provider"`
+	// WebSearchSearXNGURL SearXNG 服务地址
+	WebSearchSearXNGURL string `json:"web_search_searxng_url" yaml:"web_search_searxng_url"`
+	// WebSearchBingKey Bing Web Search API Key
+	WebSearchBingKey string `json:"-" yaml:"-"`
+	// WebSearchGoogleKey Google Custom Search API Key
+	WebSearchGoogleKey string `json:"-" yaml:"-"`
+	// WebSearchGoogleCX Google Custom Search 搜索引擎 ID
+	WebSearchGoogleCX string `json:"web_search_google_cx" yaml:"web_search_google_cx"`
+	// WebSearchFixtureFile 本地测试搜索结果文件
+	WebSearchFixtureFile string `json:"web_search_fixture_file" yaml:"web_search_fixture_file"`
+	// WebSearchResultCount 加入到上下文中的搜索结果数量
+	WebSearchResultCount int `json:"web_search_result_count" yaml:"web_search_result_count"`
+
 	// Proxy
 	Socks5Proxy string `json:"socks5_proxy" yaml:"socks5_proxy"`
 	// ProxyURL 代理地址，该值会覆盖 Socks5Proxy 配置
@@ -146,6 +264,7 @@ type Config struct {
 	StabilityAIAutoProxy    bool     `json:"stabilityai_auto_proxy" yaml:"stabilityai_auto_proxy"`
 	StabilityAIOrganization string   `json:"stabilityai_organization" yaml:"stabilityai_organization"`
 	StabilityAIKey          string   `json:"stabilityai_key" yaml:"stabilityai_key"`
+	StabilityAIKeys         []string `json:"stabilityai_keys" yaml:"stabilityai_keys"`
 	StabilityAIServer       []string `json:"stabilityai_servers" yaml:"stabilityai_servers"`
 
 	// Leap
@@ -222,6 +341,19 @@ type Config struct {
 	AliyunSMSTemplateID string `json:"aliyun_sms_template_id" yaml:"aliyun_sms_template_id"`
 	AliyunSMSSign       string `json:"aliyun_sms_sign" yaml:"aliyun_sms_sign"`
 
+	// EnableLocalContentDetect 是否启用本地内容安全检测
+	EnableLocalContentDetect bool `json:"enable_local_content_detect" yaml:"enable_local_content_detect"`
+	// LocalContentDetectDir 本地内容安全检测词库目录
+	LocalContentDetectDir string `json:"local_content_detect_dir" yaml:"local_content_detect_dir"`
+	// LocalContentDetectReloadInterval 本地词库变更检查间隔
+	LocalContentDetectReloadInterval time.Duration `json:"local_content_detect_reload_interval" yaml:"local_content_detect_reload_interval"`
+	// EnableChatOutputContentDetect 是否对模型输出的聊天内容进行内容安全检测
+	EnableChatOutputContentDetect bool `json:"enable_chat_output_content_detect" yaml:"enable_chat_output_content_detect"`
+	// ChatOutputContentDetectWindow 聊天输出内容安全检测的窗口大小（字符数）
+	ChatOutputContentDetectWindow int `json:"chat_output_content_detect_window" yaml:"chat_output_content_detect_window"`
+	// ChatOutputContentDetectStep 聊天输出内容每累积多少个字符检测一次
+	ChatOutputContentDetectStep int `json:"chat_output_content_detect_step" yaml:"chat_output_content_detect_step"`
+
 	// Apple 应用内支付
 	EnableApplePay bool `json:"enable_apple_pay" yaml:"enable_apple_pay"`
 
@@ -337,6 +469,28 @@ func Register(ins *app.App) {
 			coins.DebugPrintPriceInfo()
 		}
 
+		// 加载模型目录，数据库中的模型目录在服务启动后加载
+		modelCatalogFile := ctx.String("model-catalog-file")
+		if modelCatalogFile != "" {
+			models, err := catalog.ParseFile(modelCatalogFile)
+			if err != nil {
+				panic(fmt.Errorf("模型目录加载失败: %w", err))
+			}
+
+			catalog.Default().Replace(models)
+		}
+
+		// 加载兼容 OpenAI 接口的渠道
+		var openAIChannels []OpenAIChannel
+		if openAIChannelsFile := ctx.String("openai-channels-file"); openAIChannelsFile != "" {
+			channels, err := LoadOpenAIChannels(openAIChannelsFile)
+			if err != nil {
+				panic(fmt.Errorf("OpenAI 兼容渠道配置加载失败: %w", err))
+			}
+
+			openAIChannels = channels
+		}
+
 		return &Config{
 			Listen:              ctx.String("listen"),
 			DBURI:               ctx.String("db-uri"),
@@ -435,6 +589,70 @@ func Register(ins *app.App) {
 			OpenRouterServer:        ctx.String("openrouter-server"),
 			OpenRouterKey:           ctx.String("openrouter-key"),
 
+			OpenAIChannels: openAIChannels,
+
+			EnableLocal:        ctx.Bool("enable-local"),
+			LocalAPI:           ctx.String("local-api"),
+			LocalServer:        ctx.String("local-server"),
+			LocalContextLength: ctx.Int("local-context-length"),
+			LocalPrice:         int64(ctx.Int("local-price")),
+			LocalPrices:        ctx.StringSlice("local-prices"),
+			LocalRefresh:       ctx.Duration("local-refresh"),
+
+			EnableFake:     ctx.Bool("enable-fake"),
+			FakeLatency:    ctx.Duration("fake-latency"),
+			FakeChunkSize:  ctx.Int("fake-chunk-size"),
+			FakeError:      ctx.String("fake-error"),
+			FakeTimeout:    ctx.Duration("fake-timeout"),
+			FakeScriptFile: ctx.String("fake-script-file"),
+			FakeImageURL:   ctx.String("fake-image-url"),
+
+			ChatRoutes:                       ctx.StringSlice("chat-routes"),
+			ChatFailover:                     ctx.StringSlice("chat-failover"),
+			ChatFailoverThreshold:            ctx.Int("chat-failover-threshold"),
+			ChatFailoverCooldown:             ctx.Duration("chat-failover-cooldown"),
+			ChatFailoverFirstResponseTimeout: ctx.Duration("chat-failover-first-response-timeout"),
+
+			ChatPolicies:     ctx.StringSlice("chat-policies"),
+			ChatChannelCosts: ctx.StringSlice("chat-channel-costs"),
+
+			KeyPoolCooldown:       ctx.Duration("keypool-cooldown"),
+			KeyPoolAuthCooldown:   ctx.Duration("keypool-auth-cooldown"),
+			KeyPoolMaxConcurrency: ctx.Int("keypool-max-concurrency"),
+			KeyPoolRPM:            ctx.Int("keypool-rpm"),
+
+			ModelCatalogFile:    modelCatalogFile,
+			ModelCatalogDB:      ctx.Bool("model-catalog-db"),
+			ModelCatalogRefresh: ctx.Duration("model-catalog-refresh"),
+
+			ChatContextCompression:          ctx.Bool("chat-context-compression"),
+			ChatContextCompressionModel:     ctx.String("chat-context-compression-model"),
+			ChatContextCompressionMaxTokens: ctx.Int("chat-context-compression-max-tokens"),
+			ChatContextCompressionTTL:       ctx.Duration("chat-context-compression-ttl"),
+
+			ChatSuggestionTitle:    ctx.Bool("chat-suggestion-title"),
+			ChatSuggestionFollowUp: ctx.Bool("chat-suggestion-follow-up"),
+			ChatSuggestionModel:    ctx.String("chat-suggestion-model"),
+			ChatSuggestionBilling:  ctx.Bool("chat-suggestion-billing"),
+
+			ChatCompareMaxModels: ctx.Int("chat-compare-max-models"),
+
+			EnableKnowledgeBase:        ctx.Bool("enable-knowledge-base"),
+			KnowledgeEmbeddingProvider: ctx.String("knowledge-embedding-provider"),
+			KnowledgeEmbeddingModel:    ctx.String("knowledge-embedding-model"),
+			KnowledgeChunkSize:         ctx.Int("knowledge-chunk-size"),
+			KnowledgeChunkOverlap:      ctx.Int("knowledge-chunk-overlap"),
+			KnowledgeTopK:              ctx.Int("knowledge-top-k"),
+			KnowledgeMinScore:          ctx.Int("knowledge-min-score"),
+
+			WebSearchProvider:    ctx.String("web-search-provider"),
+			WebSearchSearXNGURL:  ctx.String("web-search-searxng-url"),
+			WebSearchBingKey:     ctx.String("web-search-bing-key"),
+			WebSearchGoogleKey:   ctx.String("web-search-google-key"),
+			WebSearchGoogleCX:    ctx.String("web-search-google-cx"),
+			WebSearchFixtureFile: ctx.String("web-search-fixture-file"),
+			WebSearchResultCount: ctx.Int("web-search-result-count"),
+
 			Socks5Proxy: ctx.String("socks5-proxy"),
 			ProxyURL:    ctx.String("proxy-url"),
 
@@ -446,6 +664,7 @@ func Register(ins *app.App) {
 			EnableStabilityAI:       ctx.Bool("enable-stabilityai"),
 			StabilityAIAutoProxy:    ctx.Bool("stabilityai-autoproxy"),
 			StabilityAIKey:          ctx.String("stabilityai-key"),
+			StabilityAIKeys:         ctx.StringSlice("stabilityai-keys"),
 			StabilityAIOrganization: ctx.String("stabilityai-organization"),
 			StabilityAIServer:       ctx.StringSlice("stabilityai-servers"),
 
@@ -511,6 +730,13 @@ func Register(ins *app.App) {
 			AliyunSMSTemplateID: ctx.String("aliyun-smstemplateid"),
 			AliyunSMSSign:       ctx.String("aliyun-smssign"),
 
+			EnableLocalContentDetect:         ctx.Bool("enable-local-contentdetect"),
+			LocalContentDetectDir:            ctx.String("local-contentdetect-dir"),
+			LocalContentDetectReloadInterval: ctx.Duration("local-contentdetect-reload-interval"),
+			EnableChatOutputContentDetect:    ctx.Bool("enable-chat-output-contentdetect"),
+			ChatOutputContentDetectWindow:    ctx.Int("chat-output-contentdetect-window"),
+			ChatOutputContentDetectStep:      ctx.Int("chat-output-contentdetect-step"),
+
 			EnableApplePay: ctx.Bool("enable-applepay"),
 
 			EnableAlipay:            ctx.Bool("enable-alipay"),
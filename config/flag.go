@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/mylxsw/glacier/starter/app"
 )
@@ -109,11 +110,70 @@ func initCmdFlags(ins *app.App) {
 	ins.AddStringFlag("openrouter-server", "https://openrouter.ai/api/v1", "openrouter server")
 	ins.AddStringFlag("openrouter-key", "", "openrouter key")
 
+	ins.AddStringFlag("openai-channels-file", "", "兼容 OpenAI 接口的渠道配置文件路径（YAML），每个渠道会自动注册为聊天渠道，留空则不启用")
+
+	ins.AddBoolFlag("enable-local", "是否启用本地推理服务（Ollama/llama.cpp server）")
+	ins.AddStringFlag("local-api", "ollama", "本地推理服务的接口类型，可选值：ollama, llamacpp")
+	ins.AddStringFlag("local-server", "http://127.0.0.1:11434", "本地推理服务地址")
+	ins.AddIntFlag("local-context-length", 4096, "本地模型的上下文窗口大小（Token）")
+	ins.AddIntFlag("local-price", 0, "本地模型每 1K Token 的价格（智慧果），为 0 时免费")
+	ins.AddStringSliceFlag("local-prices", []string{}, "单个本地模型每 1K Token 的价格（智慧果），格式为 模型=价格，如 llama2:70b=5，未配置的模型使用 local-price")
+	ins.AddDurationFlag("local-refresh", 1*time.Minute, "本地推理服务模型列表的刷新间隔，为 0 时只在启动时加载一次")
+
+	ins.AddBoolFlag("enable-fake", "是否启用模拟的聊天、图片生成服务（fake），不请求任何外部服务，仅用于开发和测试")
+	ins.AddDurationFlag("fake-latency", 0, "模拟服务每次请求的延迟，流式响应中为每个分片的延迟")
+	ins.AddIntFlag("fake-chunk-size", 4, "模拟服务流式响应每个分片包含的字符数")
+	ins.AddStringFlag("fake-error", "", "模拟服务对所有请求注入的错误，可选值：429, 500, timeout, content_filter，留空则不注入")
+	ins.AddDurationFlag("fake-timeout", 30*time.Second, "模拟服务注入 timeout 错误时，请求挂起的时间")
+	ins.AddStringFlag("fake-script-file", "", "模拟服务的剧本文件路径（YAML），留空时原样返回用户消息")
+	ins.AddStringFlag("fake-image-url", "https://picsum.photos/seed/{hash}/{width}/{height}", "模拟图片生成服务返回的图片地址模板")
+
+	ins.AddStringSliceFlag("chat-routes", []string{}, "聊天模型路由规则，格式为 模型=渠道，例如 qwen-max=dashscope，模型以 :* 结尾表示前缀匹配")
+	ins.AddStringSliceFlag("chat-failover", []string{}, "聊天模型故障转移规则，格式为 模型=渠道1,渠道2@模型，渠道按照顺序依次尝试，@ 后为该渠道使用的模型名称（可选）")
+	ins.AddIntFlag("chat-failover-threshold", 5, "聊天渠道连续失败多少次后触发熔断")
+	ins.AddDurationFlag("chat-failover-cooldown", 60*time.Second, "聊天渠道熔断后，多长时间后重新尝试")
+	ins.AddDurationFlag("chat-failover-first-response-timeout", 20*time.Second, "流式聊天等待首个响应的超时时间，超时后切换到下一个渠道")
+	ins.AddStringSliceFlag("chat-policies", []string{}, "聊天模型路由策略，格式为 模型 [if 条件 && 条件] => [目标模型] [@渠道] [| 提示信息]，条件支持 tier（anonymous/free/paid/internal）和 tokens")
+	ins.AddStringSliceFlag("chat-channel-costs", []string{}, "聊天渠道的相对成本，格式为 渠道=成本，用于路由策略选择成本最低的渠道（@cheapest），未配置的渠道成本为 1")
+
+	ins.AddDurationFlag("keypool-cooldown", 60*time.Second, "API Key 被限流（429）后的隔离时间")
+	ins.AddDurationFlag("keypool-auth-cooldown", 30*time.Minute, "API Key 认证失败（401/403）后的隔离时间")
+	ins.AddIntFlag("keypool-max-concurrency", 0, "单个 API Key 的最大并发请求数，0 表示不限制")
+	ins.AddIntFlag("keypool-rpm", 0, "单个 API Key 每分钟的最大请求数，0 表示不限制")
+	ins.AddBoolFlag("chat-context-compression", "是否启用上下文压缩，启用后，超出上下文窗口的早期对话会被总结为摘要，以系统消息的形式保留在上下文中")
+	ins.AddStringFlag("chat-context-compression-model", "gpt-3.5-turbo", "上下文压缩时生成摘要使用的模型，建议使用价格较低的模型")
+	ins.AddIntFlag("chat-context-compression-max-tokens", 500, "上下文摘要的最大 Token 数量")
+	ins.AddDurationFlag("chat-context-compression-ttl", 7*24*time.Hour, "上下文摘要的缓存时间")
+
+	ins.AddBoolFlag("chat-suggestion-title", "是否在新数字人的首次对话后，自动生成数字人的标题")
+	ins.AddBoolFlag("chat-suggestion-follow-up", "是否在回答结束后，生成 2-3 个推荐的追问问题")
+	ins.AddStringFlag("chat-suggestion-model", "gpt-3.5-turbo", "生成标题和推荐问题使用的模型，建议使用价格较低的模型")
+	ins.AddBoolFlag("chat-suggestion-billing", "生成标题和推荐问题是否向用户收费，不收费时由平台承担费用")
+
+	ins.AddIntFlag("chat-compare-max-models", 4, "多模型对比时，一次最多可以同时对比的模型数量")
+
+	ins.AddBoolFlag("enable-knowledge-base", "是否启用知识库，启用后，用户可以上传文档创建知识库，并关联到数字人，聊天时自动检索相关内容作为上下文")
+	ins.AddStringFlag("knowledge-embedding-provider", "openai", "知识库文本向量化服务提供方，支持 openai、local（本地哈希向量，仅用于测试）")
+	ins.AddStringFlag("knowledge-embedding-model", "text-embedding-ada-002", "知识库文本向量化使用的模型，支持 OpenAI、文心千帆、灵积的向量模型，provider 为 local 时无效")
+	ins.AddIntFlag("knowledge-chunk-size", 500, "知识库文档分块的最大字符数")
+	ins.AddIntFlag("knowledge-chunk-overlap", 50, "知识库相邻文档分块之间重叠的字符数")
+	ins.AddIntFlag("knowledge-top-k", 4, "聊天时从知识库中检索的文档分块数量")
+	ins.AddIntFlag("knowledge-min-score", 70, "知识库检索结果的最低相似度（百分比），低于该值的文档分块会被忽略")
+
+	ins.AddStringFlag("web-search-provider", "", "联网搜索服务提供方，支持 searxng、bing、google、fixture（本地测试数据），为空时不启用联网搜索")
+	ins.AddStringFlag("web-search-searxng-url", "", "SearXNG 服务地址，例如 http://localhost:8080")
+	ins.AddStringFlag("web-search-bing-key", "", "Bing Web Search API Key")
+	ins.AddStringFlag("web-search-google-key", "", "Google Custom Search API Key")
+	ins.AddStringFlag("web-search-google-cx", "", "Google Custom Search 搜索引擎 ID")
+	ins.AddStringFlag("web-search-fixture-file", "", "本地测试搜索结果文件（JSON 格式），仅 fixture 有效")
+	ins.AddIntFlag("web-search-result-count", 5, "联网搜索时加入到上下文中的搜索结果数量")
+
 	ins.AddBoolFlag("enable-stabilityai", "是否启用 StabilityAI 文生图、图生图服务")
 	ins.AddBoolFlag("stabilityai-autoproxy", "使用 socks5 代理访问 StabilityAI 服务")
 	ins.AddStringFlag("stabilityai-organization", "", "stabilityai organization")
 	ins.AddStringSliceFlag("stabilityai-servers", []string{"https://api.stability.ai"}, "stabilityai servers")
 	ins.AddFlags(app.StringEnvFlag("stabilityai-key", "", "stabilityai key", "STABILITYAI_KEY"))
+	ins.AddStringSliceFlag("stabilityai-keys", []string{}, "stabilityai keys，这里所有的 Keys 会和 stabilityai-key 合并到一起，按照权重分摊请求负载")
 
 	ins.AddBoolFlag("enable-leapai", "是否启用 LeapAI 文生图、图生图服务")
 	ins.AddBoolFlag("leapai-autoproxy", "使用 socks5 代理访问 Leap 服务")
@@ -177,6 +237,12 @@ func initCmdFlags(ins *app.App) {
 	ins.AddStringFlag("aliyun-smstemplateid", "", "阿里云短信验证码模板 ID")
 	ins.AddStringFlag("aliyun-smssign", "AIdea", "阿里云短信签名")
 	ins.AddBoolFlag("enable-contentdetect", "是否启用内容安全检测（使用阿里云的内容安全服务）")
+	ins.AddBoolFlag("enable-local-contentdetect", "是否启用本地内容安全检测（基于本地敏感词库、正则表达式规则），可以与阿里云内容安全服务同时使用")
+	ins.AddStringFlag("local-contentdetect-dir", "./data/content-safety", "本地内容安全检测词库目录")
+	ins.AddDurationFlag("local-contentdetect-reload-interval", time.Minute, "本地内容安全检测词库变更检查间隔，为 0 时不自动重新加载")
+	ins.AddBoolFlag("enable-chat-output-contentdetect", "是否对模型输出的聊天内容进行内容安全检测，检测到违规内容时中断输出")
+	ins.AddIntFlag("chat-output-contentdetect-window", 200, "聊天输出内容安全检测的窗口大小（字符数），相邻窗口之间重叠的部分用于避免敏感词被截断")
+	ins.AddIntFlag("chat-output-contentdetect-step", 100, "聊天输出内容每累积多少个字符检测一次，检测通过的内容才会发送给客户端")
 
 	ins.AddBoolFlag("enable-applepay", "启用 Apple 应用内支付")
 
@@ -216,6 +282,9 @@ func initCmdFlags(ins *app.App) {
 	ins.AddStringFlag("virtual-model-beichou-prompt", "", "北丑大模型内置提示语")
 
 	ins.AddStringFlag("price-table-file", "", "价格表文件路径，留空则使用默认价格表")
+	ins.AddStringFlag("model-catalog-file", "", "模型目录文件路径（YAML），用于覆盖或者新增模型的元数据，留空则只使用内置的模型定义")
+	ins.AddBoolFlag("model-catalog-db", "是否从数据库（chat_model 表）中加载模型目录，数据库中的定义优先于模型目录文件")
+	ins.AddDurationFlag("model-catalog-refresh", 1*time.Minute, "模型目录的刷新间隔，为 0 时不刷新")
 
 	ins.AddStringFlag("font-path", "", "字体文件路径")
 	ins.AddStringFlag("service-status-page", "", "服务状态页面，留空则不启用服务状态页面")
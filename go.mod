@@ -17,9 +17,11 @@ require (
 	github.com/gorilla/websocket v1.5.0
 	github.com/hashicorp/go-version v1.6.0
 	github.com/iancoleman/strcase v0.2.0
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/mylxsw/asteria v1.0.1
 	github.com/mylxsw/eloquent v0.0.2-0.20231129035241-c08e054b0632
 	github.com/mylxsw/glacier v1.1.4-0.20231112080120-114e547468b0
+	github.com/pkoukk/tiktoken-go-loader v0.0.2
 	github.com/redis/go-redis/v9 v9.0.3
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/sashabaranov/go-openai v1.17.7
@@ -48,7 +50,7 @@ require (
 	github.com/clbanning/mxj/v2 v2.5.5 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/dlclark/regexp2 v1.8.1 // indirect
+	github.com/dlclark/regexp2 v1.10.0 // indirect
 	github.com/golang-jwt/jwt/v4 v4.5.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
@@ -91,7 +93,7 @@ require (
 	github.com/hibiken/asynq v0.24.1
 	github.com/mylxsw/go-ioc v1.1.0 // indirect
 	github.com/mylxsw/go-utils v1.0.3
-	github.com/pkoukk/tiktoken-go v0.1.2
+	github.com/pkoukk/tiktoken-go v0.1.6
 	github.com/prometheus/client_golang v1.11.1
 	github.com/qiniu/go-sdk/v7 v7.15.0
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/go-utils/array"
 )
 
@@ -47,9 +48,31 @@ type ModelWithName struct {
 	NonCN     bool      `json:"non_cn,omitempty" yaml:"non_cn,omitempty"`
 }
 
+// allFreeModels 返回价格表以及模型目录中配置的免费模型，模型目录中的配置优先
+func allFreeModels() []ModelWithName {
+	overrides := array.Filter(catalog.Default().Models(), func(item catalog.Model, _ int) bool { return item.FreeCount > 0 })
+	if len(overrides) == 0 {
+		return freeModels
+	}
+
+	overridden := make(map[string]bool)
+	models := array.Map(overrides, func(item catalog.Model, _ int) ModelWithName {
+		overridden[item.RealID()] = true
+
+		name := item.Name
+		if name == "" {
+			name = item.RealID()
+		}
+
+		return ModelWithName{Model: item.RealID(), Name: name, FreeCount: item.FreeCount, NonCN: item.IsSensitiveModel()}
+	})
+
+	return append(array.Filter(freeModels, func(item ModelWithName, _ int) bool { return !overridden[item.Model] }), models...)
+}
+
 // FreeModels returns all free models
 func FreeModels() []ModelWithName {
-	models := array.Filter(freeModels, func(item ModelWithName, _ int) bool {
+	models := array.Filter(allFreeModels(), func(item ModelWithName, _ int) bool {
 		if !item.EndAt.IsZero() {
 			return item.FreeCount > 0 && item.EndAt.After(time.Now())
 		}
@@ -68,7 +91,7 @@ func GetFreeModel(modelID string) *ModelWithName {
 	id := segs[len(segs)-1]
 
 	var matched ModelWithName
-	for _, model := range freeModels {
+	for _, model := range allFreeModels() {
 		if model.Model == id {
 			matched = model
 			break
@@ -2,7 +2,10 @@ package coins
 
 import (
 	"math"
+	"sync"
 	"time"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 )
 
 var coinTables = map[string]CoinTable{
@@ -124,6 +127,22 @@ var coinTables = map[string]CoinTable{
 	"upload": {
 		"qiniu": 1,
 	},
+
+	// 文本向量化，按照每 1M Token 计费
+	"embedding": {
+		"default": 200,
+
+		// OpenAI $0.0001/1K tokens
+		"text-embedding-ada-002": 100,
+		// 灵积 ¥0.0007/1K tokens
+		"text-embedding-v1": 100,
+		"text-embedding-v2": 100,
+		// 文心千帆 ¥0.002/1K tokens
+		"embedding-v1": 200,
+		"bge-large-zh": 200,
+		"bge-large-en": 200,
+		"tao-8k":       200,
+	},
 }
 
 func GetCoinsTable() map[string]CoinTable {
@@ -137,8 +156,44 @@ func PriceToCoins(price float64, serviceFeeRate float64) int64 {
 
 // 智慧果计费
 
-func GetOpenAITextCoins(model string, wordCount int64) int64 {
+// textCoinsUnit 文本模型输入、输出每 1K Token 的价格，优先使用模型目录中配置的价格
+func textCoinsUnit(model string) (input int64, output int64, ok bool) {
+	if m, found := catalog.Default().Get(model); found {
+		if input, output, ok := m.Price(); ok {
+			return input, output, true
+		}
+	}
+
+	if unit, ok := textPrices.Load(model); ok {
+		return unit.(int64), unit.(int64), true
+	}
+
 	unit, ok := coinTables["openai"][model]
+	return unit, unit, ok
+}
+
+// textPrices 运行时设置的文本模型价格（每 1K Token），如从本地推理服务中发现的模型
+var textPrices sync.Map
+
+// SetTextPrice 设置文本模型每 1K Token 的价格，价格为 0 时该模型免费，模型目录中配置的价格优先
+func SetTextPrice(model string, unit int64) {
+	textPrices.Store(model, unit)
+}
+
+// HasBuiltinTextPrice 模型是否在内置价格表中，内置价格表中的模型均为云端服务提供的模型
+func HasBuiltinTextPrice(model string) bool {
+	_, ok := coinTables["openai"][model]
+	return ok
+}
+
+// IsZeroPriceModel 文本模型的价格是否为 0
+func IsZeroPriceModel(model string) bool {
+	input, output, ok := textCoinsUnit(model)
+	return ok && input == 0 && output == 0
+}
+
+func GetOpenAITextCoins(model string, wordCount int64) int64 {
+	unit, _, ok := textCoinsUnit(model)
 	if !ok {
 		return 50
 	}
@@ -153,9 +208,19 @@ func GetOpenAITextCoins(model string, wordCount int64) int64 {
 	return int64(math.Ceil(float64(unit) * float64(wordCount) / 1000.0))
 }
 
-func GetOpenAITokensForCoins(model string, coins int64) int64 {
-	unit, ok := coinTables["openai"][model]
+// GetTextCoins 文本模型按照输入、输出 Token 数量分别计费
+func GetTextCoins(model string, inputTokens, outputTokens int64) int64 {
+	input, output, ok := textCoinsUnit(model)
 	if !ok {
+		return 50
+	}
+
+	return int64(math.Ceil(float64(input*inputTokens+output*outputTokens) / 1000.0))
+}
+
+func GetOpenAITokensForCoins(model string, coins int64) int64 {
+	unit, _, ok := textCoinsUnit(model)
+	if !ok || unit <= 0 {
 		return 0
 	}
 
@@ -189,6 +254,21 @@ func GetUploadCoins() int64 {
 	return unit
 }
 
+// GetEmbeddingCoins 文本向量化计费，不足 1 个智慧果按照 1 个计算
+func GetEmbeddingCoins(model string, tokenCount int64) int64 {
+	unit, ok := coinTables["embedding"][model]
+	if !ok {
+		unit = coinTables["embedding"]["default"]
+	}
+
+	coins := int64(math.Ceil(float64(unit) * float64(tokenCount) / 1000000.0))
+	if coins < 1 {
+		return 1
+	}
+
+	return coins
+}
+
 // GetUnifiedImageGenCoins 统一的图片生成计费
 func GetUnifiedImageGenCoins(model string) int {
 	if price, ok := coinTables["image"][model]; ok {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/go-utils/assert"
 	"gopkg.in/yaml.v3"
 )
@@ -102,3 +103,48 @@ func TestLoadCoinsTable(t *testing.T) {
 func TestSpeechCoins(t *testing.T) {
 	fmt.Println(coins.GetTextToVoiceCoins("tts-1", 100))
 }
+
+func TestGetEmbeddingCoins(t *testing.T) {
+	assert.EqualValues(t, 1, coins.GetEmbeddingCoins("text-embedding-ada-002", 10))
+	assert.EqualValues(t, 100, coins.GetEmbeddingCoins("text-embedding-ada-002", 1000000))
+	assert.EqualValues(t, 101, coins.GetEmbeddingCoins("text-embedding-ada-002", 1000001))
+	assert.EqualValues(t, 400, coins.GetEmbeddingCoins("unknown-model", 2000000))
+}
+
+func TestCatalogPrice(t *testing.T) {
+	catalog.Default().Replace([]catalog.Model{
+		{ID: "openai:gpt-4", Category: "openai", InputPrice: 30, OutputPrice: 60, FreeCount: 2},
+		{ID: "ollama:llama2", Aliases: []string{"llama"}, InputPrice: 1},
+	})
+	defer catalog.Default().Replace(nil)
+
+	assert.EqualValues(t, 30, coins.GetOpenAITextCoins("gpt-4", 1000))
+	assert.EqualValues(t, 90, coins.GetTextCoins("gpt-4", 1000, 1000))
+	assert.EqualValues(t, 2, coins.GetTextCoins("llama", 1000, 1000))
+	// 模型目录中未配置价格时，使用价格表中的价格
+	assert.EqualValues(t, 8, coins.GetTextCoins("gpt-3.5-turbo", 1000, 1500))
+	assert.EqualValues(t, 50, coins.GetTextCoins("unknown-model", 1000, 1000))
+
+	free := coins.GetFreeModel("openai:gpt-4")
+	assert.True(t, free != nil)
+	assert.Equal(t, 2, free.FreeCount)
+	assert.True(t, free.NonCN)
+}
+
+func TestSetTextPrice(t *testing.T) {
+	coins.SetTextPrice("llama2:latest", 0)
+	coins.SetTextPrice("mistral:latest", 2)
+	defer catalog.Default().Replace(nil)
+
+	assert.True(t, coins.IsZeroPriceModel("llama2:latest"))
+	assert.EqualValues(t, 0, coins.GetTextCoins("llama2:latest", 1000, 1000))
+	assert.EqualValues(t, 0, coins.GetOpenAITokensForCoins("llama2:latest", 10))
+	assert.False(t, coins.IsZeroPriceModel("mistral:latest"))
+	assert.EqualValues(t, 4, coins.GetTextCoins("mistral:latest", 1000, 1000))
+	assert.False(t, coins.IsZeroPriceModel("unknown-model"))
+
+	// 模型目录中配置的价格优先
+	catalog.Default().Replace([]catalog.Model{{ID: "local:llama2:latest", InputPrice: 1}})
+	assert.False(t, coins.IsZeroPriceModel("llama2:latest"))
+	assert.EqualValues(t, 2, coins.GetTextCoins("llama2:latest", 1000, 1000))
+}
@@ -0,0 +1,104 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mylxsw/aidea-server/pkg/export"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/asteria/log"
+)
+
+type ChatExportPayload struct {
+	ID        string        `json:"id,omitempty"`
+	UserID    int64         `json:"user_id,omitempty"`
+	RoomID    int64         `json:"room_id,omitempty"`
+	Format    export.Format `json:"format,omitempty"`
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+}
+
+func (payload *ChatExportPayload) GetTitle() string {
+	return "聊天记录导出"
+}
+
+func (payload *ChatExportPayload) SetID(id string) {
+	payload.ID = id
+}
+
+func (payload *ChatExportPayload) GetID() string {
+	return payload.ID
+}
+
+func (payload *ChatExportPayload) GetUID() int64 {
+	return payload.UserID
+}
+
+func (payload *ChatExportPayload) GetQuotaID() int64 {
+	return 0
+}
+
+func (payload *ChatExportPayload) GetQuota() int64 {
+	return 0
+}
+
+func NewChatExportTask(payload any) *asynq.Task {
+	data, _ := json.Marshal(payload)
+	return asynq.NewTask(TypeChatExport, data)
+}
+
+func BuildChatExportHandler(exportSrv *service.ExportService, rep *repo2.Repository) TaskHandler {
+	return func(ctx context.Context, task *asynq.Task) (err error) {
+		var payload ChatExportPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return err
+		}
+
+		// 如果任务是 30 分钟前创建的，不再处理
+		if payload.CreatedAt.Add(30 * time.Minute).Before(time.Now()) {
+			return rep.Queue.Update(
+				context.TODO(),
+				payload.GetID(),
+				repo2.QueueTaskStatusFailed,
+				ErrorResult{Errors: []string{"任务处理超时"}},
+			)
+		}
+
+		defer func() {
+			if err2 := recover(); err2 != nil {
+				log.With(task).Errorf("panic: %v", err2)
+				err = fmt.Errorf("panic: %v", err2)
+			}
+
+			if err != nil {
+				if err := rep.Queue.Update(
+					context.TODO(),
+					payload.GetID(),
+					repo2.QueueTaskStatusFailed,
+					ErrorResult{Errors: []string{err.Error()}},
+				); err != nil {
+					log.With(task).Errorf("update queue status failed: %s", err)
+				}
+			}
+		}()
+
+		url, err := exportSrv.Export(ctx, payload.UserID, payload.RoomID, payload.Format)
+		if err != nil {
+			log.With(payload).Errorf("export chat messages failed: %v", err)
+			return err
+		}
+
+		return rep.Queue.Update(
+			context.TODO(),
+			payload.GetID(),
+			repo2.QueueTaskStatusSuccess,
+			CompletionResult{
+				Resources:   []string{url},
+				ValidBefore: time.Now().Add(service.ExportURLTTL),
+			},
+		)
+	}
+}
@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+)
+
+// BuildFakeImageCompletionHandler 模拟的图片生成，返回占位图片地址，不请求任何外部服务，用于开发和测试
+func BuildFakeImageCompletionHandler(client *fake.Fake, rep *repo2.Repository) TaskHandler {
+	return func(ctx context.Context, task *asynq.Task) (err error) {
+		var payload ImageCompletionPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return err
+		}
+
+		if payload.CreatedAt.Add(5 * time.Minute).Before(time.Now()) {
+			rep.Queue.Update(context.TODO(), payload.GetID(), repo2.QueueTaskStatusFailed, ErrorResult{Errors: []string{"任务处理超时"}})
+			log.WithFields(log.Fields{"payload": payload}).Errorf("task expired")
+			return nil
+		}
+
+		defer func() {
+			if err2 := recover(); err2 != nil {
+				log.With(task).Errorf("panic: %v", err2)
+				err = err2.(error)
+
+				// 更新创作岛历史记录
+				if err := rep.Creative.UpdateRecordByTaskID(ctx, payload.GetUID(), payload.GetID(), repo2.CreativeRecordUpdateRequest{
+					Status: repo2.CreativeStatusFailed,
+					Answer: err.Error(),
+				}); err != nil {
+					log.WithFields(log.Fields{"payload": payload}).Errorf("update creative failed: %s", err)
+				}
+			}
+
+			if err != nil {
+				if err := rep.Queue.Update(
+					context.TODO(),
+					payload.GetID(),
+					repo2.QueueTaskStatusFailed,
+					ErrorResult{
+						Errors: []string{err.Error()},
+					},
+				); err != nil {
+					log.With(task).Errorf("update queue status failed: %s", err)
+				}
+			}
+		}()
+
+		resources, err := client.Images(ctx, payload.Prompt, payload.Seed, payload.Width, payload.Height, payload.ImageCount)
+		if err != nil {
+			log.With(payload).Errorf("[Fake] 图片生成失败: %v", err)
+			panic(err)
+		}
+
+		if len(resources) == 0 {
+			panic(errors.New("没有生成任何图片"))
+		}
+
+		retJson, err := json.Marshal(resources)
+		if err != nil {
+			log.WithFields(log.Fields{"payload": payload}).Errorf("update creative failed: %s", err)
+			panic(err)
+		}
+
+		if err := rep.Creative.UpdateRecordByTaskID(ctx, payload.GetUID(), payload.GetID(), repo2.CreativeRecordUpdateRequest{
+			Status:    repo2.CreativeStatusSuccess,
+			Answer:    string(retJson),
+			QuotaUsed: payload.GetQuota(),
+		}); err != nil {
+			log.WithFields(log.Fields{"payload": payload}).Errorf("update creative failed: %s", err)
+			return err
+		}
+
+		if err := rep.Quota.QuotaConsume(
+			ctx,
+			payload.GetUID(),
+			payload.GetQuota(),
+			repo2.NewQuotaUsedMeta("fake", payload.Model),
+		); err != nil {
+			log.Errorf("used quota add failed: %s", err)
+		}
+
+		return rep.Queue.Update(
+			context.TODO(),
+			payload.GetID(),
+			repo2.QueueTaskStatusSuccess,
+			CompletionResult{
+				Resources:   resources,
+				ValidBefore: time.Now().Add(7 * 24 * time.Hour),
+			},
+		)
+	}
+}
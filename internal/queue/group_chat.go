@@ -125,13 +125,21 @@ func BuildGroupChatHandler(conf *config.Config, ct chat.Chat, rep *repo2.Reposit
 			panic(fmt.Errorf("chat failed: %s %s", resp.ErrorCode, resp.Error))
 		}
 
-		tokenConsumed := int64(resp.InputTokens + resp.OutputTokens)
+		// 优先使用服务商上报的 Token 用量，未上报时使用分词器估算，输入、输出分别计费
+		calFeeModel := req.ResolveCalFeeModel(conf)
+		var usage chat.Usage
+		usage.Update(*resp)
+		usage = usage.Fill(req.Messages, chat.Message{Role: "assistant", Content: resp.Text}, calFeeModel)
+		tokenConsumed := int64(usage.Total())
+
 		// 免费请求不计费
 		leftCount, _ := userSrv.FreeChatRequestCounts(ctx, payload.UserID, req.Model)
 		quotaConsumed := ternary.IfLazy(
 			leftCount > 0,
 			func() int64 { return 0 },
-			func() int64 { return coins.GetOpenAITextCoins(req.ResolveCalFeeModel(conf), tokenConsumed) },
+			func() int64 {
+				return coins.GetTextCoins(calFeeModel, int64(usage.InputTokens), int64(usage.OutputTokens))
+			},
 		)
 
 		// 更新消息状态
@@ -152,7 +160,7 @@ func BuildGroupChatHandler(conf *config.Config, ct chat.Chat, rep *repo2.Reposit
 
 		// 扣除智慧果
 		if quotaConsumed > 0 {
-			if err := rep.Quota.QuotaConsume(ctx, payload.UserID, quotaConsumed, repo2.NewQuotaUsedMeta("group_chat", req.Model)); err != nil {
+			if err := rep.Quota.QuotaConsume(ctx, payload.UserID, quotaConsumed, repo2.NewQuotaUsedMeta("group_chat", req.Model).WithUsage(int64(usage.ReportedInputTokens), int64(usage.ReportedOutputTokens), int64(usage.EstimatedInputTokens), int64(usage.EstimatedOutputTokens))); err != nil {
 				log.Errorf("used quota add failed: %s", err)
 			}
 		}
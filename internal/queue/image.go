@@ -7,6 +7,7 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/ai/chat"
 	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
 	"github.com/mylxsw/aidea-server/pkg/ai/deepai"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
 	"github.com/mylxsw/aidea-server/pkg/ai/fromston"
 	"github.com/mylxsw/aidea-server/pkg/ai/getimgai"
 	"github.com/mylxsw/aidea-server/pkg/ai/leap"
@@ -110,6 +111,7 @@ func BuildImageCompletionHandler(
 	rep *repo2.Repository,
 	oai openai2.Client,
 	dalleClient *openai2.DalleImageClient,
+	fakeClient *fake.Fake,
 ) TaskHandler {
 	return func(ctx context.Context, task *asynq.Task) (err error) {
 		var payload ImageCompletionPayload
@@ -147,6 +149,14 @@ func BuildImageCompletionHandler(
 			return BuildDashscopeImageCompletionHandler(dashscopeClient, up, rep, translator, oai)(ctx, task)
 		case "dalle":
 			return BuildDalleCompletionHandler(dalleClient, up, rep)(ctx, task)
+		case "fake":
+			// 模拟服务只在开发和测试环境中启用，未启用时不允许通过模拟服务生成图片
+			if !conf.EnableFake {
+				rep.Queue.Update(context.TODO(), payload.GetID(), repo2.QueueTaskStatusFailed, ErrorResult{Errors: []string{"模拟服务未启用"}})
+				return nil
+			}
+
+			return BuildFakeImageCompletionHandler(fakeClient, rep)(ctx, task)
 		default:
 			return nil
 		}
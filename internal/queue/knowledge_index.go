@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/asteria/log"
+)
+
+type KnowledgeIndexPayload struct {
+	ID         string    `json:"id,omitempty"`
+	UserID     int64     `json:"user_id,omitempty"`
+	DocumentID int64     `json:"document_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
+
+func (payload *KnowledgeIndexPayload) GetTitle() string {
+	return "知识库文档处理"
+}
+
+func (payload *KnowledgeIndexPayload) SetID(id string) {
+	payload.ID = id
+}
+
+func (payload *KnowledgeIndexPayload) GetID() string {
+	return payload.ID
+}
+
+func (payload *KnowledgeIndexPayload) GetUID() int64 {
+	return payload.UserID
+}
+
+func (payload *KnowledgeIndexPayload) GetQuotaID() int64 {
+	return 0
+}
+
+func (payload *KnowledgeIndexPayload) GetQuota() int64 {
+	return 0
+}
+
+func NewKnowledgeIndexTask(payload any) *asynq.Task {
+	data, _ := json.Marshal(payload)
+	return asynq.NewTask(TypeKnowledgeIndex, data)
+}
+
+func BuildKnowledgeIndexHandler(knowledgeSrv *service.KnowledgeService, rep *repo2.Repository) TaskHandler {
+	return func(ctx context.Context, task *asynq.Task) (err error) {
+		var payload KnowledgeIndexPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err2 := recover(); err2 != nil {
+				log.With(task).Errorf("panic: %v", err2)
+				err = fmt.Errorf("panic: %v", err2)
+			}
+
+			if err != nil {
+				if err := rep.Queue.Update(
+					context.TODO(),
+					payload.GetID(),
+					repo2.QueueTaskStatusFailed,
+					ErrorResult{Errors: []string{err.Error()}},
+				); err != nil {
+					log.With(task).Errorf("update queue status failed: %s", err)
+				}
+			}
+		}()
+
+		if err := knowledgeSrv.IndexDocument(ctx, payload.UserID, payload.DocumentID); err != nil {
+			log.With(payload).Errorf("index knowledge document failed: %v", err)
+			return err
+		}
+
+		return rep.Queue.Update(
+			context.TODO(),
+			payload.GetID(),
+			repo2.QueueTaskStatusSuccess,
+			EmptyResult{},
+		)
+	}
+}
@@ -97,6 +97,8 @@ const (
 	TypeGroupChat                = "group_chat"
 	TypeArtisticTextCompletion   = "artistic_text:completion"
 	TypeImageToVideoCompletion   = "image_to_video:completion"
+	TypeChatExport               = "chat:export"
+	TypeKnowledgeIndex           = "knowledge:index"
 )
 
 func ResolveTaskType(category, model string) string {
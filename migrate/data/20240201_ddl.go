@@ -0,0 +1,9 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240201DDL(m *migrate.Manager) {
+	m.Schema("20240201-ddl").Table("rooms", func(builder *migrate.Builder) {
+		builder.Text("sampling").Nullable(true).Comment("采样参数，JSON 格式")
+	})
+}
@@ -0,0 +1,10 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240202DDL(m *migrate.Manager) {
+	m.Schema("20240202-ddl").Table("chat_messages", func(builder *migrate.Builder) {
+		builder.Index("chat_messages_user_room_idx", "user_id", "room_id")
+		builder.Index("chat_messages_user_updated_idx", "user_id", "updated_at")
+	})
+}
@@ -0,0 +1,24 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+// Migrate20240203DDL 聊天记录和创作岛历史记录的全文索引，使用 ngram 分词以支持中文检索
+func Migrate20240203DDL(m *migrate.Manager) {
+	m.Schema("20240203-ddl").Raw("chat_messages", func() []string {
+		return []string{
+			"ALTER TABLE `chat_messages` ADD FULLTEXT INDEX `chat_messages_message_ft` (`message`) WITH PARSER ngram",
+		}
+	})
+
+	m.Schema("20240203-ddl").Raw("chat_group_message", func() []string {
+		return []string{
+			"ALTER TABLE `chat_group_message` ADD FULLTEXT INDEX `chat_group_message_message_ft` (`message`) WITH PARSER ngram",
+		}
+	})
+
+	m.Schema("20240203-ddl").Raw("creative_history", func() []string {
+		return []string{
+			"ALTER TABLE `creative_history` ADD FULLTEXT INDEX `creative_history_prompt_ft` (`prompt`) WITH PARSER ngram",
+		}
+	})
+}
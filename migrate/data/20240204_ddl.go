@@ -0,0 +1,9 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240204DDL(m *migrate.Manager) {
+	m.Schema("20240204-ddl").Table("chat_messages", func(builder *migrate.Builder) {
+		builder.Text("images").Nullable(true).Comment("消息中包含的图片地址，JSON 数组格式")
+	})
+}
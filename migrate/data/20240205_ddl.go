@@ -0,0 +1,50 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240205DDL(m *migrate.Manager) {
+	m.Schema("20240205-ddl").Create("knowledge_base", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("用户 ID")
+		builder.String("name", 100).Nullable(false).Comment("知识库名称")
+		builder.String("description", 255).Nullable(true).Comment("知识库描述")
+		builder.Timestamps(0)
+		builder.Index("idx_user_id", "user_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+
+	m.Schema("20240205-ddl").Create("knowledge_document", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("用户 ID")
+		builder.Integer("knowledge_base_id", false, true).Nullable(false).Comment("知识库 ID")
+		builder.String("name", 255).Nullable(false).Comment("文档名称")
+		builder.Integer("size", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("文档大小（字节）")
+		builder.LongText("content").Nullable(true).Comment("从文档中提取的文本内容")
+		builder.Integer("chunk_count", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("文档分块数量")
+		builder.TinyInteger("status", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("状态：0-等待处理 1-处理成功 2-处理失败")
+		builder.String("error", 255).Nullable(true).Comment("处理失败的原因")
+		builder.Timestamps(0)
+		builder.Index("idx_knowledge_base_id", "knowledge_base_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+
+	m.Schema("20240205-ddl").Create("knowledge_chunk", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Integer("knowledge_base_id", false, true).Nullable(false).Comment("知识库 ID")
+		builder.Integer("document_id", false, true).Nullable(false).Comment("文档 ID")
+		builder.Integer("seq", false, true).Nullable(false).Comment("分块在文档中的序号")
+		builder.Text("content").Nullable(false).Comment("分块内容")
+		builder.MediumText("embedding").Nullable(false).Comment("分块内容的向量，float32 小端序列化后 base64 编码")
+		builder.Timestamps(0)
+		builder.Index("idx_knowledge_base_id", "knowledge_base_id")
+		builder.Index("idx_document_id", "document_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+
+	m.Schema("20240205-ddl").Table("rooms", func(builder *migrate.Builder) {
+		builder.Integer("knowledge_base_id", false, true).Nullable(true).Comment("关联的知识库 ID")
+	})
+}
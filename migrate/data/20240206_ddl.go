@@ -0,0 +1,9 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240206DDL(m *migrate.Manager) {
+	m.Schema("20240206-ddl").Table("rooms", func(builder *migrate.Builder) {
+		builder.TinyInteger("web_search", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("是否开启联网模式：0-关闭 1-开启")
+	})
+}
@@ -0,0 +1,16 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240207DDL(m *migrate.Manager) {
+	m.Schema("20240207-ddl").Create("chat_model", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.String("model_id", 100).Nullable(false).Comment("模型 ID，格式为 {category}:{model}")
+		builder.Text("definition").Nullable(false).Comment("模型定义，JSON 格式，字段参考 pkg/ai/catalog.Model")
+		builder.TinyInteger("status", false, true).Nullable(false).Default(migrate.RawExpr("1")).Comment("状态：0-停用 1-启用")
+		builder.Timestamps(0)
+		builder.Unique("uk_model_id", "model_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+}
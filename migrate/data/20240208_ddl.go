@@ -0,0 +1,37 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240208DDL(m *migrate.Manager) {
+	m.Schema("20240208-ddl").Create("chat_comparison", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("用户 ID")
+		builder.Text("prompt").Nullable(false).Comment("用户的提问（最后一条用户消息）")
+		builder.LongText("messages").Nullable(true).Comment("请求的上下文消息，JSON 格式")
+		builder.String("models", 1024).Nullable(false).Comment("参与对比的模型列表，JSON 格式")
+		builder.Integer("quota_consumed", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("消耗的智慧果总数")
+		builder.Timestamps(0)
+		builder.Index("idx_user_id", "user_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+
+	m.Schema("20240208-ddl").Create("chat_comparison_answer", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Integer("comparison_id", false, true).Nullable(false).Comment("对比记录 ID")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("用户 ID")
+		builder.Integer("seq", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("模型在对比中的序号")
+		builder.String("model", 100).Nullable(false).Comment("模型 ID")
+		builder.LongText("answer").Nullable(true).Comment("模型的回复")
+		builder.TinyInteger("status", false, true).Nullable(false).Default(migrate.RawExpr("1")).Comment("状态：1-成功 2-失败")
+		builder.String("error", 255).Nullable(true).Comment("失败的原因")
+		builder.Integer("input_tokens", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("输入 Token 数")
+		builder.Integer("output_tokens", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("输出 Token 数")
+		builder.Integer("quota_consumed", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("消耗的智慧果")
+		builder.Integer("elapsed_ms", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("响应耗时（毫秒）")
+		builder.Timestamps(0)
+		builder.Index("idx_comparison_id", "comparison_id")
+		builder.Charset("utf8mb4")
+		builder.Collation("utf8mb4_general_ci")
+	})
+}
@@ -22,6 +22,14 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 	data.Migrate20231129DML(m)
 	data.Migrate20240125DML(m)
 	data.Migrate20240131DDL(m)
+	data.Migrate20240201DDL(m)
+	data.Migrate20240202DDL(m)
+	data.Migrate20240203DDL(m)
+	data.Migrate20240204DDL(m)
+	data.Migrate20240205DDL(m)
+	data.Migrate20240206DDL(m)
+	data.Migrate20240207DDL(m)
+	data.Migrate20240208DDL(m)
 
 	return m.Run(ctx)
 }
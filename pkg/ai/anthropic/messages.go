@@ -0,0 +1,266 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	ContentTypeText       = "text"
+	ContentTypeToolUse    = "tool_use"
+	ContentTypeToolResult = "tool_result"
+)
+
+// MessagesRequest is the request of the Messages API, which supports tool use.
+// https://docs.anthropic.com/claude/reference/messages_post
+type MessagesRequest struct {
+	// Model The model that will complete your prompt.
+	Model Model `json:"model"`
+	// Messages Input messages, user and assistant turns must alternate.
+	Messages []MessageParam `json:"messages"`
+	// System System prompt.
+	System string `json:"system,omitempty"`
+	// MaxTokens The maximum number of tokens to generate before stopping.
+	MaxTokens int `json:"max_tokens"`
+	// StopSequences Custom text sequences that will cause the model to stop generating.
+	StopSequences []string `json:"stop_sequences,omitempty"`
+	// Temperature Amount of randomness injected into the response.
+	Temperature float64 `json:"temperature,omitempty"`
+	// TopP Use nucleus sampling.
+	TopP float64 `json:"top_p,omitempty"`
+	// TopK Only sample from the top K options for each subsequent token.
+	TopK int `json:"top_k,omitempty"`
+	// Stream Whether to incrementally stream the response using server-sent events.
+	Stream bool `json:"stream,omitempty"`
+	// Tools Definitions of tools that the model may use.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice How the model should use the provided tools.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+type MessageParam struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock is one of text, tool_use and tool_result content blocks.
+type ContentBlock struct {
+	Type string `json:"type"`
+	// Text The text content, only for text block.
+	Text string `json:"text,omitempty"`
+	// ID The tool use id, only for tool_use block.
+	ID string `json:"id,omitempty"`
+	// Name The tool name, only for tool_use block.
+	Name string `json:"name,omitempty"`
+	// Input The tool input object, only for tool_use block.
+	Input json.RawMessage `json:"input,omitempty"`
+	// ToolUseID The id of the tool use request this is a result for, only for tool_result block.
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	// Content The result of the tool, only for tool_result block.
+	Content string `json:"content,omitempty"`
+}
+
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// InputSchema JSON schema for the tool input.
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type ToolChoice struct {
+	// Type One of auto, any and tool.
+	Type string `json:"type"`
+	// Name The name of the tool to use, only when type is tool.
+	Name string `json:"name,omitempty"`
+}
+
+type MessagesResponse struct {
+	ID      string         `json:"id,omitempty"`
+	Type    string         `json:"type,omitempty"`
+	Role    string         `json:"role,omitempty"`
+	Content []ContentBlock `json:"content,omitempty"`
+	Model   string         `json:"model,omitempty"`
+	// StopReason The reason that we stopped, one of end_turn, max_tokens, stop_sequence and tool_use.
+	StopReason string         `json:"stop_reason,omitempty"`
+	Usage      *Usage         `json:"usage,omitempty"`
+	Error      *ResponseError `json:"error,omitempty"`
+}
+
+// Text returns all text content in the response.
+func (resp *MessagesResponse) Text() string {
+	var text string
+	for _, block := range resp.Content {
+		if block.Type == ContentTypeText {
+			text += block.Text
+		}
+	}
+
+	return text
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+}
+
+// MessagesStreamEvent is the event of the streaming Messages API.
+// Event types: message_start, content_block_start, content_block_delta, content_block_stop,
+// message_delta, message_stop, ping and error.
+type MessagesStreamEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	// Message Only for message_start event.
+	Message *MessagesResponse `json:"message,omitempty"`
+	// ContentBlock Only for content_block_start event.
+	ContentBlock *ContentBlock `json:"content_block,omitempty"`
+	// Delta For content_block_delta and message_delta event.
+	Delta *MessagesStreamDelta `json:"delta,omitempty"`
+	// Usage Only for message_delta event.
+	Usage *Usage         `json:"usage,omitempty"`
+	Error *ResponseError `json:"error,omitempty"`
+}
+
+type MessagesStreamDelta struct {
+	// Type One of text_delta and input_json_delta, empty for message_delta event.
+	Type        string `json:"type,omitempty"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+func (ai *Anthropic) newMessagesRequest(ctx context.Context, req MessagesRequest) (*http.Request, error) {
+	req.Model = ai.resolveModel(req.Model)
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = 4000
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %s", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(ai.serverURL, "/")+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create http request failed: %s", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+	httpReq.Header.Set("x-api-key", ai.apiKey)
+
+	return httpReq, nil
+}
+
+// Messages send a request to the Messages API
+func (ai *Anthropic) Messages(ctx context.Context, req MessagesRequest) (*MessagesResponse, error) {
+	req.Stream = false
+	httpReq, err := ai.newMessagesRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := ai.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("chat failed: %s", err)
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("chat failed [%s]: %s", httpResp.Status, string(data))
+	}
+
+	var chatResp MessagesResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("decode response failed: %s", err)
+	}
+
+	return &chatResp, nil
+}
+
+// MessagesStream send a request to the Messages API and returns the stream events
+func (ai *Anthropic) MessagesStream(ctx context.Context, req MessagesRequest) (<-chan MessagesStreamEvent, error) {
+	req.Stream = true
+	httpReq, err := ai.newMessagesRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+	httpReq.Header.Set("Connection", "keep-alive")
+
+	httpResp, err := ai.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(httpResp.Body)
+		_ = httpResp.Body.Close()
+
+		return nil, fmt.Errorf("chat failed [%s]: %s", httpResp.Status, string(data))
+	}
+
+	res := make(chan MessagesStreamEvent)
+	go func() {
+		defer func() {
+			_ = httpResp.Body.Close()
+			close(res)
+		}()
+
+		reader := bufio.NewReader(httpResp.Body)
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil {
+				if err == io.EOF {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+				case res <- MessagesStreamEvent{Type: "error", Error: &ResponseError{Type: "read_error", Message: fmt.Sprintf("read response failed: %v", err)}}:
+				}
+				return
+			}
+
+			// event: content_block_delta
+			// data: {"type": "content_block_delta","index": 0,"delta": {"type": "text_delta", "text": "Hello"}}
+			dataStr := strings.TrimSpace(string(data))
+			if !strings.HasPrefix(dataStr, "data:") {
+				continue
+			}
+
+			var event MessagesStreamEvent
+			if err := json.Unmarshal([]byte(strings.TrimSpace(dataStr[5:])), &event); err != nil {
+				select {
+				case <-ctx.Done():
+				case res <- MessagesStreamEvent{Type: "error", Error: &ResponseError{Type: "decode_error", Message: fmt.Sprintf("decode response failed: %v", err)}}:
+				}
+				return
+			}
+
+			if event.Type == "ping" {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case res <- event:
+				if event.Type == "message_stop" || event.Type == "error" {
+					return
+				}
+			}
+		}
+	}()
+
+	return res, nil
+}
@@ -19,6 +19,7 @@ import (
 type BaiduAI interface {
 	Chat(ctx context.Context, model Model, req ChatRequest) (*ChatResponse, error)
 	ChatStream(ctx context.Context, model Model, req ChatRequest) (<-chan ChatResponse, error)
+	Embedding(ctx context.Context, model EmbeddingModel, req EmbeddingRequest) (*EmbeddingResponse, error)
 }
 
 type BaiduAIImpl struct {
@@ -108,6 +109,10 @@ type ChatRequest struct {
 	//    （1）值越大表示惩罚越大
 	//    （2）默认1.0，取值范围：[1.0, 2.0]
 	PenaltyScore float64 `json:"penalty_score,omitempty"`
+	// Stop 生成停止标识，当模型生成结果以stop中某个元素结尾时，停止文本生成。说明：
+	//    （1）每个元素长度不超过20字符
+	//    （2）最多4个元素
+	Stop []string `json:"stop,omitempty"`
 	// Stream 是否以流式接口的形式返回数据，默认false
 	Stream bool `json:"stream,omitempty"`
 	// UserID 表示最终用户的唯一标识符，可以监视和检测滥用行为，防止接口恶意调用
@@ -0,0 +1,81 @@
+package baidu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/resty.v1"
+)
+
+type EmbeddingModel string
+
+const (
+	// ModelEmbeddingV1 Embedding-V1 是基于百度文心大模型技术的文本表示模型，向量维度 384
+	// ¥0.002元/千tokens
+	ModelEmbeddingV1 EmbeddingModel = "embedding-v1"
+	// ModelBgeLargeZH bge-large-zh 是由智源研究院研发的中文版文本表示模型，向量维度 1024
+	// ¥0.002元/千tokens
+	ModelBgeLargeZH EmbeddingModel = "bge-large-zh"
+	// ModelBgeLargeEN bge-large-en 是由智源研究院研发的英文版文本表示模型，向量维度 1024
+	// ¥0.002元/千tokens
+	ModelBgeLargeEN EmbeddingModel = "bge-large-en"
+	// ModelTao8K tao-8k 是支持 8K 上下文长度的文本表示模型，向量维度 1024
+	// ¥0.002元/千tokens
+	ModelTao8K EmbeddingModel = "tao-8k"
+)
+
+// EmbeddingModels 支持的所有向量模型
+var EmbeddingModels = []EmbeddingModel{ModelEmbeddingV1, ModelBgeLargeZH, ModelBgeLargeEN, ModelTao8K}
+
+type EmbeddingRequest struct {
+	// Input 输入的文本列表，最多 16 条，每条文本长度不超过 384 tokens（tao-8k 为 8192 tokens）
+	Input []string `json:"input"`
+	// UserID 表示最终用户的唯一标识符
+	UserID string `json:"user_id,omitempty"`
+}
+
+type EmbeddingResponse struct {
+	ErrorCode    int    `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_msg,omitempty"`
+
+	Id      string          `json:"id,omitempty"`
+	Object  string          `json:"object,omitempty"`
+	Created int             `json:"created,omitempty"`
+	Data    []EmbeddingData `json:"data,omitempty"`
+	Usage   Usage           `json:"usage,omitempty"`
+}
+
+type EmbeddingData struct {
+	Object    string    `json:"object,omitempty"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	// Index 向量对应的输入文本在 input 中的下标
+	Index int `json:"index"`
+}
+
+func (ai *BaiduAIImpl) Embedding(ctx context.Context, model EmbeddingModel, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	resp, err := resty.R().SetQueryParam("access_token", ai.getAccessToken()).
+		SetHeader("Content-Type", "application/json").
+		SetBody(req).
+		SetContext(ctx).
+		Post("https://aip.baidubce.com/rpc/2.0/ai_custom/v1/wenxinworkshop/embeddings/" + string(model))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("embedding failed, status code: %d", resp.StatusCode())
+	}
+
+	var embeddingResponse EmbeddingResponse
+	if err := json.Unmarshal(resp.Body(), &embeddingResponse); err != nil {
+		return nil, err
+	}
+
+	if embeddingResponse.ErrorCode != 0 {
+		return nil, fmt.Errorf("embedding failed [%d]: %s", embeddingResponse.ErrorCode, embeddingResponse.ErrorMessage)
+	}
+
+	return &embeddingResponse, nil
+}
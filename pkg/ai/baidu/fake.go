@@ -11,3 +11,7 @@ func (f FakeBaiduAI) Chat(ctx context.Context, model Model, req ChatRequest) (*C
 func (f FakeBaiduAI) ChatStream(ctx context.Context, model Model, req ChatRequest) (<-chan ChatResponse, error) {
 	return nil, nil
 }
+
+func (f FakeBaiduAI) Embedding(ctx context.Context, model EmbeddingModel, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	return nil, nil
+}
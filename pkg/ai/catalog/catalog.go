@@ -0,0 +1,167 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Registry 模型目录，保存配置文件、数据库中定义的模型元数据
+// 内置模型的定义由各个模块维护，注册表中同 ID 的模型会覆盖内置定义，不存在的模型会作为新模型追加
+type Registry struct {
+	lock   sync.RWMutex
+	models []Model
+	// version 模型目录的版本，每次替换模型时递增，用于判断依赖模型目录的缓存是否失效
+	version uint64
+}
+
+// NewRegistry 创建模型目录
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default 全局的模型目录，计费、上下文长度、模型列表等都从这里读取模型元数据
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Replace 替换模型目录中的全部模型，ID 重复时后面的定义覆盖前面的定义
+func (r *Registry) Replace(models []Model) {
+	merged := make([]Model, 0, len(models))
+	index := make(map[string]int)
+	for _, m := range models {
+		m.ID = strings.TrimSpace(m.ID)
+		if m.ID == "" {
+			continue
+		}
+
+		if i, ok := index[m.ID]; ok {
+			merged[i] = merged[i].Merge(m)
+			continue
+		}
+
+		index[m.ID] = len(merged)
+		merged = append(merged, m)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.models = merged
+	r.version++
+}
+
+// Version 返回模型目录的版本，模型目录每次重新加载后版本都会变化
+func (r *Registry) Version() uint64 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.version
+}
+
+// Models 返回模型目录中的全部模型
+func (r *Registry) Models() []Model {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]Model(nil), r.models...)
+}
+
+// Get 查询模型，id 可以是完整的模型 ID、模型名称或者别名
+func (r *Registry) Get(id string) (Model, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	// 完整的模型 ID 优先
+	for _, m := range r.models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+
+	for _, m := range r.models {
+		if m.Match(id) {
+			return m, true
+		}
+	}
+
+	return Model{}, false
+}
+
+// Resolve 将模型别名解析为模型名称，不是别名时原样返回
+func (r *Registry) Resolve(model string) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, m := range r.models {
+		for _, alias := range m.Aliases {
+			if alias == model {
+				return m.RealID()
+			}
+		}
+	}
+
+	return model
+}
+
+// Apply 将模型目录中的定义合并到内置的模型列表中
+func (r *Registry) Apply(builtin []Model) []Model {
+	overrides := r.Models()
+	if len(overrides) == 0 {
+		return builtin
+	}
+
+	index := make(map[string]int, len(builtin))
+	models := make([]Model, 0, len(builtin)+len(overrides))
+	for _, m := range builtin {
+		index[m.ID] = len(models)
+		models = append(models, m)
+	}
+
+	for _, m := range overrides {
+		if i, ok := index[m.ID]; ok {
+			models[i] = models[i].Merge(m)
+			continue
+		}
+
+		models = append(models, m)
+	}
+
+	return models
+}
+
+// File 模型目录配置文件的格式
+type File struct {
+	Models []Model `json:"models" yaml:"models"`
+}
+
+// Parse 解析 YAML 格式的模型目录
+func Parse(data []byte) ([]Model, error) {
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	for i, m := range file.Models {
+		if strings.TrimSpace(m.ID) == "" {
+			return nil, fmt.Errorf("model #%d: missing id", i+1)
+		}
+	}
+
+	return file.Models, nil
+}
+
+// ParseFile 读取并解析 YAML 格式的模型目录文件
+func ParseFile(path string) ([]Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
@@ -0,0 +1,93 @@
+package catalog_test
+
+import (
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+const testCatalog = `
+models:
+  - id: openai:gpt-4
+    aliases: [gpt4]
+    context_window: 8192
+    max_output: 4096
+    support_tools: true
+    input_price: 30
+    output_price: 60
+  - id: openai:gpt-4
+    description: 能力强，更精准
+  - id: ollama:llama2
+    name: Llama 2
+    is_chat: true
+    regions: [cn, global]
+    platforms: [macos, windows]
+    version_min: 1.0.8
+`
+
+func TestRegistry(t *testing.T) {
+	models, err := catalog.Parse([]byte(testCatalog))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(models))
+
+	_, err = catalog.Parse([]byte("models:\n  - name: missing id"))
+	assert.True(t, err != nil)
+
+	registry := catalog.NewRegistry()
+	registry.Replace(models)
+	assert.Equal(t, 2, len(registry.Models()))
+
+	gpt4, ok := registry.Get("gpt4")
+	assert.True(t, ok)
+	assert.Equal(t, "openai:gpt-4", gpt4.ID)
+	assert.Equal(t, "能力强，更精准", gpt4.Description)
+	assert.Equal(t, 8192, gpt4.ContextWindow)
+
+	input, output, ok := gpt4.Price()
+	assert.True(t, ok)
+	assert.EqualValues(t, 30, input)
+	assert.EqualValues(t, 60, output)
+
+	_, ok = registry.Get("llama2")
+	assert.True(t, ok)
+	_, ok = registry.Get("gpt-3.5-turbo")
+	assert.False(t, ok)
+
+	assert.Equal(t, "gpt-4", registry.Resolve("gpt4"))
+	assert.Equal(t, "gpt-4", registry.Resolve("gpt-4"))
+	assert.Equal(t, "unknown", registry.Resolve("unknown"))
+
+	merged := registry.Apply([]catalog.Model{
+		{ID: "openai:gpt-4", Name: "GPT-4", Category: "openai", IsChat: true, AvatarURL: "gpt4.png"},
+		{ID: "openai:gpt-3.5-turbo", Name: "GPT-3.5", Category: "openai", IsChat: true},
+	})
+	assert.Equal(t, 3, len(merged))
+	assert.Equal(t, "GPT-4", merged[0].Name)
+	assert.Equal(t, "gpt4.png", merged[0].AvatarURL)
+	assert.True(t, merged[0].SupportTools)
+	assert.EqualValues(t, []string{"gpt4"}, merged[0].Aliases)
+	assert.Equal(t, "ollama:llama2", merged[2].ID)
+}
+
+func TestModel_AvailableFor(t *testing.T) {
+	gpt4 := catalog.Model{ID: "openai:gpt-4", Category: "openai", VersionMin: "1.0.5"}
+	assert.True(t, gpt4.IsSensitiveModel())
+	assert.True(t, gpt4.AvailableFor(catalog.Client{}))
+	assert.True(t, gpt4.AvailableFor(catalog.Client{Version: "1.0.6", Region: catalog.RegionGlobal}))
+	assert.False(t, gpt4.AvailableFor(catalog.Client{Version: "1.0.4"}))
+	assert.False(t, gpt4.AvailableFor(catalog.Client{Region: catalog.RegionCN}))
+
+	// 配置了可用地区时，以配置为准
+	gpt4.Regions = []string{catalog.RegionCN, catalog.RegionGlobal}
+	assert.True(t, gpt4.AvailableFor(catalog.Client{Region: catalog.RegionCN}))
+
+	llama := catalog.Model{ID: "ollama:llama2", Regions: []string{catalog.RegionCN}, Platforms: []string{"macos"}}
+	assert.False(t, llama.IsSensitiveModel())
+	assert.True(t, llama.AvailableFor(catalog.Client{Platform: "macos", Region: catalog.RegionCN}))
+	assert.False(t, llama.AvailableFor(catalog.Client{Platform: "ios", Region: catalog.RegionCN}))
+	assert.False(t, llama.AvailableFor(catalog.Client{Platform: "macos", Region: catalog.RegionGlobal}))
+
+	llama.Disabled = true
+	assert.False(t, llama.AvailableFor(catalog.Client{}))
+}
@@ -0,0 +1,227 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	"github.com/mylxsw/go-utils/str"
+)
+
+// 模型可用的地区
+const (
+	// RegionCN 国产化模式
+	RegionCN = "cn"
+	// RegionGlobal 非国产化模式
+	RegionGlobal = "global"
+)
+
+// Model 模型的元数据以及能力描述
+type Model struct {
+	// ID 模型 ID，格式为 {category}:{model}，如 openai:gpt-4
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name,omitempty"`
+	ShortName   string `json:"short_name" yaml:"short_name,omitempty"`
+	Description string `json:"description" yaml:"description,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty" yaml:"avatar_url,omitempty"`
+	Category    string `json:"category" yaml:"category,omitempty"`
+	IsImage     bool   `json:"is_image" yaml:"is_image,omitempty"`
+	Disabled    bool   `json:"disabled" yaml:"disabled,omitempty"`
+	VersionMin  string `json:"version_min,omitempty" yaml:"version_min,omitempty"`
+	VersionMax  string `json:"version_max,omitempty" yaml:"version_max,omitempty"`
+	Tag         string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	IsChat        bool `json:"is_chat" yaml:"is_chat,omitempty"`
+	SupportVision bool `json:"support_vision,omitempty" yaml:"support_vision,omitempty"`
+	// SupportTools 是否支持工具调用
+	SupportTools bool `json:"support_tools,omitempty" yaml:"support_tools,omitempty"`
+
+	// Aliases 模型别名，请求中可以使用别名代替模型名称
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// ContextWindow 模型上下文窗口大小（Token），为 0 时使用渠道的默认值
+	ContextWindow int `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+	// MaxOutput 单次最大输出 Token 数量，为 0 时不限制
+	MaxOutput int `json:"max_output,omitempty" yaml:"max_output,omitempty"`
+	// InputPrice 输入每 1K Token 的价格（智慧果），为 0 时使用价格表中的价格
+	InputPrice int64 `json:"input_price,omitempty" yaml:"input_price,omitempty"`
+	// OutputPrice 输出每 1K Token 的价格（智慧果），为 0 时与 InputPrice 相同
+	OutputPrice int64 `json:"output_price,omitempty" yaml:"output_price,omitempty"`
+	// FreeCount 每日免费次数，为 0 时使用价格表中的免费模型配置
+	FreeCount int `json:"free_count,omitempty" yaml:"free_count,omitempty"`
+	// Regions 允许使用的地区（cn/global），为空时按照模型分类判断
+	Regions []string `json:"regions,omitempty" yaml:"regions,omitempty"`
+	// Platforms 允许使用的客户端平台（ios/android/macos/windows/linux/web），为空时不限制
+	Platforms []string `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+
+	// Virtual 虚拟模型的实现，不为空时该模型为虚拟模型，实现细节不对客户端展示
+	Virtual *Virtual `json:"-" yaml:"virtual,omitempty"`
+}
+
+// Virtual 虚拟模型的实现，虚拟模型由其它模型驱动，可以定制系统提示语和采样参数
+type Virtual struct {
+	// Model 驱动虚拟模型的模型
+	Model string `json:"model" yaml:"model,omitempty"`
+	// Channel 驱动虚拟模型的渠道，为空时按照 Model 选择渠道
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// Prompt 虚拟模型内置的系统提示语
+	Prompt string `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	// Parameters 默认的采样参数（temperature/top_p/stop/seed/presence_penalty/frequency_penalty），请求中指定的参数优先
+	Parameters map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// merge 使用 override 中不为空的字段覆盖当前定义
+func (v Virtual) merge(override Virtual) Virtual {
+	mergeString(&v.Model, override.Model)
+	mergeString(&v.Channel, override.Channel)
+	mergeString(&v.Prompt, override.Prompt)
+
+	if len(override.Parameters) > 0 {
+		v.Parameters = override.Parameters
+	}
+
+	return v
+}
+
+// RealID 模型在渠道中的名称（去掉分类前缀）
+func (m Model) RealID() string {
+	segs := strings.SplitN(m.ID, ":", 2)
+	return segs[len(segs)-1]
+}
+
+// IsSensitiveModel 是否为国产化模式下不可用的模型
+func (m Model) IsSensitiveModel() bool {
+	if len(m.Regions) > 0 {
+		return !str.In(RegionCN, m.Regions)
+	}
+
+	return m.Category == "openai" || m.Category == "Anthropic" || m.Category == "google"
+}
+
+func (m Model) IsVirtualModel() bool {
+	return m.Category == "virtual" || m.Virtual != nil
+}
+
+// Match 判断模型 ID、模型名称或者别名是否与 id 一致
+func (m Model) Match(id string) bool {
+	return m.ID == id || m.RealID() == id || str.In(id, m.Aliases)
+}
+
+// Price 返回输入、输出每 1K Token 的价格，未配置价格时 ok 为 false
+func (m Model) Price() (input int64, output int64, ok bool) {
+	if m.InputPrice <= 0 {
+		return 0, 0, false
+	}
+
+	if m.OutputPrice <= 0 {
+		return m.InputPrice, m.InputPrice, true
+	}
+
+	return m.InputPrice, m.OutputPrice, true
+}
+
+// Client 请求模型列表的客户端信息
+type Client struct {
+	// Version 客户端版本，为空时不检查版本
+	Version string
+	// Platform 客户端平台，为空时不检查平台
+	Platform string
+	// Region 客户端所在地区，为 cn 时不返回国产化模式下不可用的模型
+	Region string
+}
+
+// AvailableFor 判断模型对指定客户端是否可用
+func (m Model) AvailableFor(client Client) bool {
+	if m.Disabled {
+		return false
+	}
+
+	if client.Version != "" && m.VersionMin != "" && misc.VersionOlder(client.Version, m.VersionMin) {
+		return false
+	}
+
+	if client.Version != "" && m.VersionMax != "" && misc.VersionNewer(client.Version, m.VersionMax) {
+		return false
+	}
+
+	if client.Platform != "" && len(m.Platforms) > 0 && !str.In(client.Platform, m.Platforms) {
+		return false
+	}
+
+	if client.Region == RegionCN && m.IsSensitiveModel() {
+		return false
+	}
+
+	if client.Region == RegionGlobal && len(m.Regions) > 0 && !str.In(RegionGlobal, m.Regions) {
+		return false
+	}
+
+	return true
+}
+
+// Merge 使用 override 中不为空的字段覆盖当前模型的定义
+// 布尔类型字段只能由 false 覆盖为 true，即配置中只能禁用模型或者开启某项能力
+func (m Model) Merge(override Model) Model {
+	mergeString(&m.Name, override.Name)
+	mergeString(&m.ShortName, override.ShortName)
+	mergeString(&m.Description, override.Description)
+	mergeString(&m.AvatarURL, override.AvatarURL)
+	mergeString(&m.Category, override.Category)
+	mergeString(&m.VersionMin, override.VersionMin)
+	mergeString(&m.VersionMax, override.VersionMax)
+	mergeString(&m.Tag, override.Tag)
+
+	m.IsImage = m.IsImage || override.IsImage
+	m.Disabled = m.Disabled || override.Disabled
+	m.IsChat = m.IsChat || override.IsChat
+	m.SupportVision = m.SupportVision || override.SupportVision
+	m.SupportTools = m.SupportTools || override.SupportTools
+
+	if len(override.Aliases) > 0 {
+		m.Aliases = override.Aliases
+	}
+
+	if len(override.Regions) > 0 {
+		m.Regions = override.Regions
+	}
+
+	if len(override.Platforms) > 0 {
+		m.Platforms = override.Platforms
+	}
+
+	if override.ContextWindow > 0 {
+		m.ContextWindow = override.ContextWindow
+	}
+
+	if override.MaxOutput > 0 {
+		m.MaxOutput = override.MaxOutput
+	}
+
+	if override.InputPrice > 0 {
+		m.InputPrice = override.InputPrice
+	}
+
+	if override.OutputPrice > 0 {
+		m.OutputPrice = override.OutputPrice
+	}
+
+	if override.FreeCount > 0 {
+		m.FreeCount = override.FreeCount
+	}
+
+	if override.Virtual != nil {
+		var virtual Virtual
+		if m.Virtual != nil {
+			virtual = *m.Virtual
+		}
+
+		virtual = virtual.merge(*override.Virtual)
+		m.Virtual = &virtual
+	}
+
+	return m
+}
+
+func mergeString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
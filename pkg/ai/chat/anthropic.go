@@ -2,11 +2,28 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/mylxsw/aidea-server/pkg/ai/anthropic"
+	"github.com/mylxsw/go-utils/array"
 	"strings"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "anthropic",
+		Prefixes: []string{"Anthropic:"},
+		Models:   []string{string(anthropic.ModelClaude2), string(anthropic.ModelClaudeInstant)},
+	}, func(ai *AI) Chat { return ai.Anthropic })
+}
+
+// anthropicSamplingCapability Anthropic 支持的采样参数，temperature 取值范围为 [0, 1]
+var anthropicSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 1},
+	MaxStop:     MaxStopSequences,
+}
+
 type AnthropicChat struct {
 	ai *anthropic.Anthropic
 }
@@ -56,10 +73,255 @@ func (chat *AnthropicChat) initRequest(req Request) anthropic.Request {
 		contextMessages = contextMessages[1:]
 	}
 
-	return anthropic.NewRequest(anthropic.Model(req.Model), contextMessages)
+	anthropicReq := anthropic.NewRequest(anthropic.Model(req.Model), contextMessages)
+
+	sampling := req.Sampling.Clamp(anthropicSamplingCapability)
+	anthropicReq.Temperature = sampling.TemperatureValue()
+	anthropicReq.TopP = sampling.TopPValue()
+	anthropicReq.StopSequences = sampling.Stop
+
+	return anthropicReq
+}
+
+// useMessagesAPI 工具调用只有 Messages API 支持，包含工具调用时使用 Messages API
+func (chat *AnthropicChat) useMessagesAPI(req Request) bool {
+	return len(req.Tools) > 0 || req.Messages.HasToolCalls()
+}
+
+func (chat *AnthropicChat) initMessagesRequest(req Request) anthropic.MessagesRequest {
+	req.Model = strings.TrimPrefix(req.Model, "Anthropic:")
+
+	var systemMessages []string
+	messages := make([]anthropic.MessageParam, 0)
+
+	for _, msg := range req.Messages {
+		role := msg.Role
+		blocks := make([]anthropic.ContentBlock, 0)
+
+		switch msg.Role {
+		case "system":
+			systemMessages = append(systemMessages, msg.Content)
+			continue
+		case RoleTool:
+			// 工具调用结果以 user 消息的形式发送
+			role = "user"
+			blocks = append(blocks, anthropic.ContentBlock{
+				Type:      anthropic.ContentTypeToolResult,
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		default:
+			if strings.TrimSpace(msg.Content) != "" {
+				blocks = append(blocks, anthropic.ContentBlock{Type: anthropic.ContentTypeText, Text: msg.Content})
+			}
+
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+
+				blocks = append(blocks, anthropic.ContentBlock{
+					Type:  anthropic.ContentTypeToolUse,
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: input,
+				})
+			}
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		// user 和 assistant 消息必须交替出现，相同角色的连续消息合并为一条
+		if len(messages) > 0 && messages[len(messages)-1].Role == role {
+			messages[len(messages)-1].Content = append(messages[len(messages)-1].Content, blocks...)
+			continue
+		}
+
+		messages = append(messages, anthropic.MessageParam{Role: role, Content: blocks})
+	}
+
+	// 第一条消息必须是 user 消息
+	for len(messages) > 0 && messages[0].Role != "user" {
+		messages = messages[1:]
+	}
+
+	sampling := req.Sampling.Clamp(anthropicSamplingCapability)
+	anthropicReq := anthropic.MessagesRequest{
+		Model:         anthropic.Model(req.Model),
+		Messages:      messages,
+		System:        strings.Join(systemMessages, "\n\n"),
+		MaxTokens:     req.MaxTokens,
+		Temperature:   sampling.TemperatureValue(),
+		TopP:          sampling.TopPValue(),
+		StopSequences: sampling.Stop,
+	}
+
+	// Anthropic 不支持 none，此时不提供工具定义
+	mode, name := req.ToolChoice()
+	if mode == "none" {
+		return anthropicReq
+	}
+
+	anthropicReq.Tools = array.Map(req.Tools, func(item Tool, _ int) anthropic.Tool {
+		schema := item.Function.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+
+		return anthropic.Tool{Name: item.Function.Name, Description: item.Function.Description, InputSchema: schema}
+	})
+
+	switch mode {
+	case "required":
+		anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: "any"}
+	case "function":
+		anthropicReq.ToolChoice = &anthropic.ToolChoice{Type: "tool", Name: name}
+	}
+
+	return anthropicReq
+}
+
+// anthropicFinishReason 将 Anthropic 的停止原因转换为 OpenAI 的格式
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "end_turn", "stop_sequence":
+		return "stop"
+	}
+
+	return reason
+}
+
+func (chat *AnthropicChat) messagesChat(ctx context.Context, req Request) (*Response, error) {
+	res, err := chat.ai.Messages(ctx, chat.initMessagesRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil && res.Error.Type != "" {
+		return nil, fmt.Errorf("anthropic ai chat error: [%s] %s", res.Error.Type, res.Error.Message)
+	}
+
+	resp := &Response{Text: res.Text(), FinishReason: anthropicFinishReason(res.StopReason)}
+	if res.Usage != nil {
+		resp.InputTokens, resp.OutputTokens = res.Usage.InputTokens, res.Usage.OutputTokens
+	}
+
+	for _, block := range res.Content {
+		if block.Type == anthropic.ContentTypeToolUse {
+			index := len(resp.ToolCalls)
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				Index:    &index,
+				ID:       block.ID,
+				Type:     ToolTypeFunction,
+				Function: FunctionCall{Name: block.Name, Arguments: string(block.Input)},
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+func (chat *AnthropicChat) messagesChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	stream, err := chat.ai.MessagesStream(ctx, chat.initMessagesRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(chan Response)
+	go func() {
+		defer close(res)
+
+		// toolIndexes 内容块序号与工具调用序号的对应关系
+		toolIndexes := make(map[int]int)
+		var inputTokens int
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				var resp Response
+				switch event.Type {
+				case "error":
+					errResp := Response{Error: "unknown error", ErrorCode: "unknown_error"}
+					if event.Error != nil {
+						errResp = Response{Error: event.Error.Message, ErrorCode: event.Error.Type}
+					}
+
+					select {
+					case <-ctx.Done():
+					case res <- errResp:
+					}
+					return
+				case "message_start":
+					if event.Message != nil && event.Message.Usage != nil {
+						inputTokens = event.Message.Usage.InputTokens
+					}
+					continue
+				case "content_block_start":
+					if event.ContentBlock == nil || event.ContentBlock.Type != anthropic.ContentTypeToolUse {
+						continue
+					}
+
+					index := len(toolIndexes)
+					toolIndexes[event.Index] = index
+					resp.ToolCalls = []ToolCall{{
+						Index:    &index,
+						ID:       event.ContentBlock.ID,
+						Type:     ToolTypeFunction,
+						Function: FunctionCall{Name: event.ContentBlock.Name},
+					}}
+				case "content_block_delta":
+					if event.Delta == nil {
+						continue
+					}
+
+					if event.Delta.Type == "input_json_delta" {
+						index := toolIndexes[event.Index]
+						resp.ToolCalls = []ToolCall{{Index: &index, Function: FunctionCall{Arguments: event.Delta.PartialJSON}}}
+					} else {
+						resp.Text = event.Delta.Text
+					}
+				case "message_delta":
+					if event.Delta != nil {
+						resp.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+					}
+
+					if event.Usage != nil {
+						resp.InputTokens, resp.OutputTokens = inputTokens, event.Usage.OutputTokens
+					}
+				default:
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case res <- resp:
+				}
+			}
+		}
+	}()
+
+	return res, nil
 }
 
 func (chat *AnthropicChat) Chat(ctx context.Context, req Request) (*Response, error) {
+	if chat.useMessagesAPI(req) {
+		return chat.messagesChat(ctx, req)
+	}
+
 	res, err := chat.ai.Chat(ctx, chat.initRequest(req))
 	if err != nil {
 		return nil, err
@@ -75,6 +337,10 @@ func (chat *AnthropicChat) Chat(ctx context.Context, req Request) (*Response, er
 }
 
 func (chat *AnthropicChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	if chat.useMessagesAPI(req) {
+		return chat.messagesChatStream(ctx, req)
+	}
+
 	stream, err := chat.ai.ChatStream(ctx, chat.initRequest(req))
 	if err != nil {
 		return nil, err
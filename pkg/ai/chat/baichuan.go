@@ -7,6 +7,21 @@ import (
 	"github.com/mylxsw/go-utils/array"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "baichuan",
+		Aliases:  []string{"百川"},
+		Prefixes: []string{"百川:"},
+		Models:   []string{baichuan.ModelBaichuan2_53B},
+	}, func(ai *AI) Chat { return ai.Baichuan })
+}
+
+// baichuanSamplingCapability 百川支持的采样参数，top_p 取值范围为 [0, 1)
+var baichuanSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 0.99},
+}
+
 type BaichuanAIChat struct {
 	ai *baichuan.BaichuanAI
 }
@@ -25,11 +40,14 @@ func (ai *BaichuanAIChat) initRequest(req Request) baichuan.Request {
 		}
 	})
 
+	sampling := req.Sampling.Clamp(baichuanSamplingCapability)
 	return baichuan.Request{
 		Model:    req.Model,
 		Messages: messages,
 		Parameters: baichuan.Parameters{
 			WithSearchEnhance: true,
+			Temperature:       sampling.TemperatureValue(),
+			TopP:              sampling.TopPValue(),
 		},
 	}
 }
@@ -7,6 +7,32 @@ import (
 	"strings"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "baidu",
+		Aliases:  []string{"文心千帆"},
+		Prefixes: []string{"文心千帆:"},
+		Models: []string{
+			string(baidu.ModelErnieBot),
+			baidu.ModelErnieBotTurbo,
+			baidu.ModelErnieBot4,
+			baidu.ModelAquilaChat7B,
+			baidu.ModelChatGLM2_6B_32K,
+			baidu.ModelBloomz7B,
+			baidu.ModelLlama2_13b,
+			baidu.ModelLlama2_7b_CN,
+			baidu.ModelLlama2_70b,
+		},
+	}, func(ai *AI) Chat { return ai.Baidu })
+}
+
+// baiduSamplingCapability 文心千帆支持的采样参数，temperature 取值范围为 (0, 1]
+var baiduSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 1},
+	MaxStop:     MaxStopSequences,
+}
+
 type BaiduAIChat struct {
 	bai baidu.BaiduAI
 }
@@ -34,7 +60,12 @@ func (chat *BaiduAIChat) initRequest(req Request) baidu.ChatRequest {
 		}
 	}
 
-	res := baidu.ChatRequest{}
+	sampling := req.Sampling.Clamp(baiduSamplingCapability)
+	res := baidu.ChatRequest{
+		Temperature: sampling.TemperatureValue(),
+		TopP:        sampling.TopPValue(),
+		Stop:        sampling.Stop,
+	}
 
 	contextMessages = contextMessages.Fix()
 	if len(systemMessages) > 0 {
@@ -0,0 +1,38 @@
+package chat
+
+import (
+	"github.com/mylxsw/go-utils/array"
+)
+
+// Branch 对话分支参数
+// 聊天记录按照 PID 组织为树形结构：回复的 PID 为对应的提问，提问的 PID 为上一轮的回复
+// 重新生成回复时，新的回复与原回复为同级节点；编辑消息后重新发送时，新的提问与原提问为同级节点
+type Branch struct {
+	// ParentID 父消息 ID，新的提问追加在该回复之后；重新生成回复时，为需要重新回答的提问 ID
+	ParentID int64 `json:"parent_id,omitempty"`
+	// EditID 被编辑的提问 ID，新的提问作为它的同级节点，与 ParentID 同时指定时忽略 ParentID
+	EditID int64 `json:"edit_id,omitempty"`
+	// Regenerate 是否重新生成 ParentID 对应提问的回复
+	Regenerate bool `json:"regenerate,omitempty"`
+}
+
+// IsBranch 是否为分支对话请求
+func (b Branch) IsBranch() bool {
+	return b.ParentID > 0 || b.EditID > 0
+}
+
+// WithHistory 使用服务端保存的分支聊天记录替换客户端提交的上下文
+// 保留客户端提交的 system 消息，非重新生成时，客户端提交的最后一条消息作为新的提问追加在聊天记录之后
+func (req Request) WithHistory(history Messages) Request {
+	messages := array.Filter(req.Messages, func(item Message, _ int) bool { return item.Role == "system" })
+	messages = append(messages, history...)
+
+	if !req.Regenerate && len(req.Messages) > 0 {
+		if last := req.Messages[len(req.Messages)-1]; last.Role == "user" {
+			messages = append(messages, last)
+		}
+	}
+
+	req.Messages = messages
+	return req
+}
@@ -0,0 +1,36 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestRequest_WithHistory(t *testing.T) {
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(`{"model": "gpt-4", "parent_id": 12, "messages": [
+		{"role": "system", "content": "prompt"},
+		{"role": "user", "content": "client history"},
+		{"role": "assistant", "content": "client answer"},
+		{"role": "user", "content": "new question"}
+	]}`), &req))
+	assert.True(t, req.IsBranch())
+	assert.Equal(t, int64(12), req.ParentID)
+
+	history := Messages{{Role: "user", Content: "question"}, {Role: "assistant", Content: "answer"}}
+
+	res := req.WithHistory(history)
+	assert.Equal(t, 4, len(res.Messages))
+	assert.Equal(t, "prompt", res.Messages[0].Content)
+	assert.Equal(t, "question", res.Messages[1].Content)
+	assert.Equal(t, "new question", res.Messages[3].Content)
+
+	// 重新生成回复时，聊天记录以需要重新回答的提问结尾，忽略客户端提交的消息
+	req.Regenerate = true
+	res = req.WithHistory(history[:1])
+	assert.Equal(t, 2, len(res.Messages))
+	assert.Equal(t, "question", res.Messages[1].Content)
+
+	assert.False(t, Request{}.IsBranch())
+}
@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 关闭状态，请求正常通过
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 打开状态，请求直接被拒绝
+	BreakerOpen
+	// BreakerHalfOpen 半开状态，允许一个探测请求通过，根据探测结果决定关闭或者再次打开
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker 渠道熔断器，连续失败次数达到阈值后打开，冷却时间过后进入半开状态进行探测
+type CircuitBreaker struct {
+	lock sync.Mutex
+
+	// threshold 连续失败多少次后打开熔断器
+	threshold int
+	// cooldown 熔断器打开后，多长时间后进入半开状态
+	cooldown time.Duration
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probing 半开状态下是否已经有探测请求在进行中
+	probing bool
+
+	now func() time.Time
+}
+
+// NewCircuitBreaker 创建一个熔断器
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// State 返回熔断器当前状态
+func (b *CircuitBreaker) State() BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Allow 判断是否允许请求通过，半开状态下只允许一个探测请求通过
+func (b *CircuitBreaker) Allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+
+		b.probing = true
+		return true
+	}
+
+	return true
+}
+
+// Success 记录一次成功请求，熔断器恢复到关闭状态
+func (b *CircuitBreaker) Success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败请求，返回熔断器是否因此次失败而打开
+func (b *CircuitBreaker) Failure() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		opened := b.state != BreakerOpen
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		return opened
+	}
+
+	return false
+}
+
+// Abort 放弃本次请求（如请求被取消），不影响熔断器状态，半开状态下允许新的探测请求通过
+func (b *CircuitBreaker) Abort() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+}
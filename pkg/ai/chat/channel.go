@@ -0,0 +1,103 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mylxsw/aidea-server/config"
+	openai2 "github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/ternary"
+)
+
+// OpenAIChannelChat 配置文件中定义的兼容 OpenAI 接口的渠道，支持模型重命名
+type OpenAIChannelChat struct {
+	channel config.OpenAIChannel
+	imp     *OpenAIChat
+}
+
+func NewOpenAIChannelChat(client openai2.Client, ch config.OpenAIChannel) *OpenAIChannelChat {
+	chat := &OpenAIChannelChat{channel: ch}
+	chat.imp = &OpenAIChat{oai: client, contextSize: chat.upstreamContextSize}
+
+	return chat
+}
+
+// Channel 返回渠道的路由定义
+func (chat *OpenAIChannelChat) Channel() Channel {
+	return Channel{
+		Name:     chat.channel.Name,
+		Aliases:  chat.channel.Aliases,
+		Prefixes: []string{chat.channel.Name + ":"},
+		// 隐藏的模型不参与默认路由，只能通过路由规则、故障转移规则使用
+		Models: array.Map(
+			array.Filter(chat.channel.Models, func(item config.OpenAIChannelModel, _ int) bool { return !item.Hidden }),
+			func(item config.OpenAIChannelModel, _ int) string { return item.ID },
+		),
+	}
+}
+
+// prepare 去掉模型 ID 中的渠道前缀，并替换为渠道中的模型名称
+func (chat *OpenAIChannelChat) prepare(req Request) Request {
+	req.Model = strings.TrimPrefix(req.Model, chat.channel.Name+":")
+	for _, m := range chat.channel.Models {
+		if m.ID == req.Model {
+			req.Model = m.UpstreamModel()
+			break
+		}
+	}
+
+	return req
+}
+
+func (chat *OpenAIChannelChat) Chat(ctx context.Context, req Request) (*Response, error) {
+	return chat.imp.Chat(ctx, chat.prepare(req))
+}
+
+func (chat *OpenAIChannelChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	return chat.imp.ChatStream(ctx, chat.prepare(req))
+}
+
+func (chat *OpenAIChannelChat) MaxContextLength(model string) int {
+	return chat.upstreamContextSize(chat.prepare(Request{Model: model}).Model)
+}
+
+// upstreamContextSize 渠道中模型的上下文长度，依次使用模型、渠道的配置，都未配置时使用 OpenAI 模型的上下文长度
+func (chat *OpenAIChannelChat) upstreamContextSize(upstream string) int {
+	for _, m := range chat.channel.Models {
+		if m.UpstreamModel() == upstream && m.ContextWindow > 0 {
+			return m.ContextWindow
+		}
+	}
+
+	if chat.channel.ContextWindow > 0 {
+		return chat.channel.ContextWindow
+	}
+
+	return openai2.ModelMaxContextSize(upstream)
+}
+
+// openAIChannelModels 配置文件中定义的兼容 OpenAI 接口的渠道提供的模型
+func openAIChannelModels(conf *config.Config) []Model {
+	models := make([]Model, 0)
+	for _, ch := range conf.OpenAIChannels {
+		for _, m := range ch.Models {
+			if m.Hidden {
+				continue
+			}
+
+			models = append(models, Model{
+				ID:            ch.Name + ":" + m.ID,
+				Name:          ternary.If(m.Name != "", m.Name, m.ID),
+				Description:   m.Description,
+				AvatarURL:     m.AvatarURL,
+				Category:      ch.Name,
+				IsChat:        true,
+				SupportVision: m.SupportVision,
+				ContextWindow: ternary.If(m.ContextWindow > 0, m.ContextWindow, ch.ContextWindow),
+			})
+		}
+	}
+
+	return models
+}
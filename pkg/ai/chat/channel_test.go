@@ -0,0 +1,118 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/config"
+	openai2 "github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+const testOpenAIChannels = `
+channels:
+  - name: deepseek
+    aliases: [深度求索]
+    servers: [ "%s/v1" ]
+    keys: [ "sk-test" ]
+    context_window: 32000
+    headers:
+      X-Team: aidea
+    models:
+      - id: deepseek-chat
+        name: DeepSeek Chat
+      - id: kimi
+        upstream: moonshot-v1-8k
+        context_window: 8000
+      - id: gpt-4
+        hidden: true
+`
+
+func TestOpenAIChannelChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		assert.Equal(t, "aidea", r.Header.Get("X-Team"))
+
+		var req struct {
+			Model string `json:"model"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"1","object":"chat.completion","model":"%s","choices":[{"index":0,"message":{"role":"assistant","content":"%s"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":1}}`, req.Model, req.Model)
+	}))
+	defer server.Close()
+
+	channels, err := config.ParseOpenAIChannels([]byte(fmt.Sprintf(testOpenAIChannels, server.URL)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(channels))
+
+	_, err = config.ParseOpenAIChannels([]byte("channels:\n  - name: deepseek\n    keys: [sk]"))
+	assert.True(t, err != nil)
+	_, err = config.ParseOpenAIChannels([]byte("channels:\n  - name: a\n    servers: [s]\n    keys: [k]\n  - name: A\n    servers: [s]\n    keys: [k]"))
+	assert.True(t, err != nil)
+
+	conf := &config.Config{OpenAIChannels: channels}
+	clients := openai2.NewChannels(conf, nil)
+	ch := NewOpenAIChannelChat(clients.Get("deepseek"), channels[0])
+
+	// 隐藏的模型不参与默认路由，也不在模型列表中展示
+	registry := NewRegistry("openai")
+	registry.Register(Channel{Name: "openai", Prefixes: []string{"openai:"}}, &failoverTestClient{name: "openai"})
+	registry.Register(ch.Channel(), ch)
+	assert.Equal(t, "deepseek", registry.Resolve("deepseek-chat"))
+	assert.Equal(t, "deepseek", registry.Resolve("deepseek:gpt-4"))
+	assert.Equal(t, "openai", registry.Resolve("gpt-4"))
+	assert.True(t, registry.Channel("深度求索") == Chat(ch))
+
+	models := openAIChannelModels(conf)
+	assert.Equal(t, 2, len(models))
+	assert.Equal(t, "deepseek:deepseek-chat", models[0].ID)
+	assert.Equal(t, "DeepSeek Chat", models[0].Name)
+	assert.Equal(t, 32000, models[0].ContextWindow)
+	assert.Equal(t, "kimi", models[1].Name)
+	assert.Equal(t, 8000, models[1].ContextWindow)
+
+	// 请求时替换为渠道中的模型名称
+	resp, err := ch.Chat(context.TODO(), Request{Model: "kimi", Messages: Messages{{Role: "user", Content: "hi"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "\nmoonshot-v1-8k", resp.Text)
+	assert.Equal(t, 5, resp.InputTokens)
+
+	resp, err = ch.Chat(context.TODO(), Request{Model: "deepseek:deepseek-chat", Messages: Messages{{Role: "user", Content: "hi"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "\ndeepseek-chat", resp.Text)
+
+	assert.Equal(t, 8000, ch.MaxContextLength("kimi"))
+	assert.Equal(t, 32000, ch.MaxContextLength("deepseek-chat"))
+}
+
+func TestOpenAIChannelChat_ClaimedModels(t *testing.T) {
+	channels, err := config.ParseOpenAIChannels([]byte(`
+channels:
+  - name: deepseek
+    servers: [ "http://localhost/v1" ]
+    keys: [ "sk-test" ]
+    models:
+      - id: deepseek-chat
+      - id: gpt-4
+      - id: qwen-max
+`))
+	assert.NoError(t, err)
+
+	conf := &config.Config{OpenAIChannels: channels}
+	clients := openai2.NewChannels(conf, nil)
+	imp := NewChat(conf, &AI{Channels: []*OpenAIChannelChat{NewOpenAIChannelChat(clients.Get("deepseek"), channels[0])}}).(*Imp)
+
+	// 已经被内置渠道占用的模型不会被配置的渠道接管，只能通过渠道前缀使用
+	assert.Equal(t, "deepseek", imp.registry.Resolve("deepseek-chat"))
+	assert.Equal(t, "openai", imp.registry.Resolve("gpt-4"))
+	assert.Equal(t, "dashscope", imp.registry.Resolve("qwen-max"))
+	assert.Equal(t, "deepseek", imp.registry.Resolve("deepseek:gpt-4"))
+	assert.Equal(t, "deepseek", imp.registry.Resolve("deepseek:qwen-max"))
+}
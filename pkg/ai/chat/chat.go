@@ -4,20 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/mylxsw/aidea-server/pkg/ai/anthropic"
-	"github.com/mylxsw/aidea-server/pkg/ai/baichuan"
-	"github.com/mylxsw/aidea-server/pkg/ai/baidu"
-	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/aidea-server/pkg/ai/google"
-	"github.com/mylxsw/aidea-server/pkg/ai/gpt360"
-	"github.com/mylxsw/aidea-server/pkg/ai/openrouter"
-	"github.com/mylxsw/aidea-server/pkg/ai/sensenova"
-	"github.com/mylxsw/aidea-server/pkg/ai/sky"
-	"github.com/mylxsw/aidea-server/pkg/ai/tencentai"
-	"github.com/mylxsw/aidea-server/pkg/ai/xfyun"
 	"strings"
 
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/go-utils/array"
 )
 
@@ -30,6 +23,12 @@ type Message struct {
 	Role              string              `json:"role"`
 	Content           string              `json:"content"`
 	MultipartContents []*MultipartContent `json:"multipart_content,omitempty"`
+	// Name 消息发送者名称，工具调用结果消息中为函数名称
+	Name string `json:"name,omitempty"`
+	// ToolCalls 模型发起的工具调用，仅 assistant 消息
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID 工具调用结果对应的调用 ID，仅 tool 消息
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
 type MultipartContent struct {
@@ -71,6 +70,11 @@ func (ms Messages) HasImage() bool {
 }
 
 func (ms Messages) Fix() Messages {
+	// 工具调用消息需要保持原有的顺序和结构，不做处理
+	if ms.HasToolCalls() {
+		return ms
+	}
+
 	msgs := ms
 	// 如果最后一条消息不是用户消息，则补充一条用户消息
 	last := msgs[len(msgs)-1]
@@ -114,10 +118,22 @@ type Request struct {
 	Messages  Messages `json:"messages"`
 	MaxTokens int      `json:"max_tokens,omitempty"`
 	N         int      `json:"n,omitempty"` // 复用作为 room_id
+	// Tools 模型可以调用的工具列表
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoiceRaw 工具调用控制，可以是 none/auto/required，或者指定函数 {"type": "function", "function": {"name": "xxx"}}
+	ToolChoiceRaw any `json:"tool_choice,omitempty"`
+	// Sampling 采样参数（temperature/top_p/stop/seed/penalty）
+	Sampling
+	// Branch 对话分支，指定后基于服务端保存的聊天记录构建上下文
+	Branch
+	// WebSearch 是否开启联网模式，开启后先搜索与问题相关的内容加入到上下文中
+	WebSearch bool `json:"web_search,omitempty"`
 
 	// 业务定制字段
 	RoomID    int64 `json:"-"`
 	WebSocket bool  `json:"-"`
+	// Channel 路由策略指定优先使用的渠道，为空时按照默认规则选择渠道
+	Channel string `json:"-"`
 }
 
 func (req Request) assembleMessage() string {
@@ -146,8 +162,10 @@ func (req Request) Init() Request {
 		req.N = 0
 	}
 
-	// 过滤掉内容为空的 message
-	req.Messages = array.Filter(req.Messages, func(item Message, _ int) bool { return strings.TrimSpace(item.Content) != "" })
+	// 过滤掉内容为空的 message，工具调用消息的内容可以为空
+	req.Messages = array.Filter(req.Messages, func(item Message, _ int) bool {
+		return strings.TrimSpace(item.Content) != "" || len(item.ToolCalls) > 0 || item.Role == RoleTool
+	})
 
 	// TODO 临时方案，对于 Google Gemini Pro Vision 模型，有以下特性:
 	// 1. 不支持多轮对话
@@ -200,16 +218,39 @@ func (req Request) Fix(chat Chat, maxContextLength int64, maxTokenCount int) (*R
 	return &req, int64(inputTokens), nil
 }
 
+// AppendSystemPrompt 将内容追加到请求的系统提示中，有 system 消息时追加到第一条 system 消息中（部分厂商只支持一条 system 消息）
+func (req Request) AppendSystemPrompt(content string) Request {
+	messages := make(Messages, 0, len(req.Messages)+1)
+	var injected bool
+	for _, msg := range req.Messages {
+		if msg.Role == "system" && !injected {
+			msg.Content = msg.Content + "\n\n" + content
+			injected = true
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if !injected {
+		messages = append(Messages{{Role: "system", Content: content}}, messages...)
+	}
+
+	req.Messages = messages
+	return req
+}
+
+// ResolveCalFeeModel 返回计费使用的模型，虚拟模型在模型目录中配置了价格时按照虚拟模型计费，否则按照驱动模型计费
 func (req Request) ResolveCalFeeModel(conf *config.Config) string {
-	if req.Model == "nanxian" {
-		return conf.VirtualModel.NanxianRel
+	vm, ok := LookupVirtualModel(conf, req.Model)
+	if !ok {
+		return localFeeModel(req.Model)
 	}
 
-	if req.Model == "beichou" {
-		return conf.VirtualModel.BeichouRel
+	if _, _, ok := vm.Price(); ok {
+		return req.Model
 	}
 
-	return req.Model
+	return localFeeModel(vm.Virtual.Model)
 }
 
 type Response struct {
@@ -219,6 +260,8 @@ type Response struct {
 	FinishReason string `json:"finish_reason,omitempty"`
 	InputTokens  int    `json:"input_tokens,omitempty"`
 	OutputTokens int    `json:"output_tokens,omitempty"`
+	// ToolCalls 模型发起的工具调用，流式响应中为增量数据，需要使用 MergeToolCalls 合并
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
 
 type Chat interface {
@@ -231,164 +274,83 @@ type Chat interface {
 }
 
 type Imp struct {
-	ai      *AI
-	virtual *VirtualChat
+	registry *Registry
+	failover *Failover
+	policy   *Policy
 }
 
-func NewChat(conf *config.Config, ai *AI) Chat {
-	var virtualImpl Chat
-	impLowercase := strings.ToLower(conf.VirtualModel.Implementation)
-	switch impLowercase {
-	case "openai":
-		virtualImpl = ai.OpenAI
-	case "baidu", "文心千帆":
-		virtualImpl = ai.Baidu
-	case "dashscope", "灵积":
-		virtualImpl = ai.DashScope
-	case "xfyun", "讯飞星火":
-		virtualImpl = ai.Xfyun
-	case "sense_nova", "商汤日日新":
-		virtualImpl = ai.SenseNova
-	case "tencent", "腾讯":
-		virtualImpl = ai.Tencent
-	case "anthropic":
-		virtualImpl = ai.Anthropic
-	case "baichuan", "百川":
-		virtualImpl = ai.Baichuan
-	case "gpt360", "360智脑":
-		virtualImpl = ai.GPT360
-	case "chatglm_turbo", "chatglm_pro", "chatglm_lite", "chatglm_std", "PaLM-2":
-		virtualImpl = ai.OneAPI
-	case "google":
-		virtualImpl = ai.Google
-	case "sky":
-		virtualImpl = ai.Sky
-	default:
-		if openrouter.SupportModel(impLowercase) {
-			virtualImpl = ai.Openrouter
-		} else {
-			virtualImpl = ai.OpenAI
+// withoutClaimedModels 去掉渠道中已经被其它渠道（包括内置的 OpenAI 模型）占用的模型 ID，避免配置的渠道接管这些模型的默认路由
+// 被去掉的模型仍然可以通过渠道前缀（如 `deepseek:gpt-4`）或者路由规则使用
+func withoutClaimedModels(registry *Registry, ch Channel) Channel {
+	ch.Models = array.Filter(ch.Models, func(model string, _ int) bool {
+		if owner := registry.Resolve(model); owner != registry.fallback || coins.HasBuiltinTextPrice(model) {
+			log.Warningf("model %s of openai channel %s conflicts with channel %s, use %s:%s instead", model, ch.Name, owner, ch.Name, model)
+			return false
 		}
-	}
-
-	return &Imp{
-		ai:      ai,
-		virtual: NewVirtualChat(virtualImpl, conf.VirtualModel),
-	}
-}
-
-func (ai *Imp) selectImp(model string) Chat {
-	if strings.HasPrefix(model, "灵积:") {
-		return ai.ai.DashScope
-	}
-
-	if strings.HasPrefix(model, "文心千帆:") {
-		return ai.ai.Baidu
-	}
 
-	if strings.HasPrefix(model, "讯飞星火:") {
-		return ai.ai.Xfyun
-	}
+		return true
+	})
 
-	if strings.HasPrefix(model, "商汤日日新:") {
-		return ai.ai.SenseNova
-	}
+	return ch
+}
 
-	if strings.HasPrefix(model, "腾讯:") {
-		return ai.ai.Tencent
+func NewChat(conf *config.Config, ai *AI) Chat {
+	registry := NewRegistry("openai")
+	for _, ch := range builtinChannels {
+		// 渠道未启用时（例如未配置备用 OpenAI 服务），resolve 返回 nil，不注册该渠道
+		if imp := ch.resolve(ai); imp != nil {
+			registry.Register(ch.channel, imp)
+		}
 	}
 
-	if strings.HasPrefix(model, "Anthropic:") {
-		return ai.ai.Anthropic
+	if conf.EnableFake {
+		registry.Register(fakeChannel, ai.Fake)
 	}
 
-	if strings.HasPrefix(model, "百川:") {
-		return ai.ai.Baichuan
-	}
+	// 配置文件中定义的兼容 OpenAI 接口的渠道，不允许覆盖内置渠道
+	for _, ch := range ai.Channels {
+		if registry.Channel(ch.Channel().Name) != nil {
+			log.Warningf("openai channel %s conflicts with builtin channel, ignored", ch.Channel().Name)
+			continue
+		}
 
-	if strings.HasPrefix(model, "virtual:") {
-		return ai.virtual
+		registry.Register(withoutClaimedModels(registry, ch.Channel()), ch)
 	}
 
-	if strings.HasPrefix(model, "360智脑:") {
-		return ai.ai.GPT360
-	}
+	// 虚拟模型由模型目录定义，每个虚拟模型可以指定驱动模型、渠道、提示语和采样参数
+	registry.Register(Channel{
+		Name:     "virtual",
+		Prefixes: []string{"virtual:"},
+		Models:   []string{ModelNanXian, ModelBeiChou},
+		Match: func(model string) bool {
+			_, ok := LookupVirtualModel(conf, model)
+			return ok
+		},
+	}, NewVirtualChat(registry, conf))
 
-	if strings.HasPrefix(model, "oneapi:") {
-		return ai.ai.OneAPI
-	}
+	// 配置文件中的路由规则优先级最高
+	registry.LoadRoutes(conf.ChatRoutes)
 
-	if strings.HasPrefix(model, "google:") {
-		return ai.ai.Google
-	}
+	failover := NewFailover(registry, conf.ChatFailoverThreshold, conf.ChatFailoverCooldown, conf.ChatFailoverFirstResponseTimeout)
+	failover.LoadRules(conf.ChatFailover)
 
-	if strings.HasPrefix(model, "openrouter:") {
-		return ai.ai.Openrouter
-	}
+	policy := NewPolicy(failover, conf.ChatChannelCosts)
+	policy.LoadRules(conf.ChatPolicies)
 
-	if strings.HasPrefix(model, "sky:") {
-		return ai.ai.Sky
-	}
+	return &Imp{registry: registry, failover: failover, policy: policy}
+}
 
-	// TODO 根据模型名称判断使用哪个 AI
-	switch model {
-	case string(baidu.ModelErnieBot),
-		baidu.ModelErnieBotTurbo,
-		baidu.ModelErnieBot4,
-		baidu.ModelAquilaChat7B,
-		baidu.ModelChatGLM2_6B_32K,
-		baidu.ModelBloomz7B,
-		baidu.ModelLlama2_13b,
-		baidu.ModelLlama2_7b_CN,
-		baidu.ModelLlama2_70b:
-		// 百度文心千帆
-		return ai.ai.Baidu
-	case dashscope.ModelQWenV1, dashscope.ModelQWenPlusV1,
-		dashscope.ModelQWen7BV1, dashscope.ModelQWen7BChatV1,
-		dashscope.ModelQWenMax, dashscope.ModelQWenMaxLongContext, dashscope.ModelQWenVLPlus,
-		dashscope.ModelQWenTurbo, dashscope.ModelQWenPlus, dashscope.ModelBaiChuan7BChatV1,
-		dashscope.ModelQWen7BChat, dashscope.ModelQWen14BChat:
-		// 阿里灵积平台
-		return ai.ai.DashScope
-	case string(xfyun.ModelGeneralV1_5), string(xfyun.ModelGeneralV2), string(xfyun.ModelGeneralV3):
-		// 讯飞星火
-		return ai.ai.Xfyun
-	case string(sensenova.ModelNovaPtcXLV1), string(sensenova.ModelNovaPtcXSV1):
-		// 商汤日日新
-		return ai.ai.SenseNova
-	case tencentai.ModelHyllm:
-		// 腾讯混元大模型
-		return ai.ai.Tencent
-	case string(anthropic.ModelClaude2), string(anthropic.ModelClaudeInstant):
-		// Anthropic
-		return ai.ai.Anthropic
-	case baichuan.ModelBaichuan2_53B:
-		// 百川
-		return ai.ai.Baichuan
-	case gpt360.Model360GPT_S2_V9:
-		// 360智脑
-		return ai.ai.GPT360
-	case ModelNanXian, ModelBeiChou:
-		// 虚拟模型
-		return ai.virtual
-	case "chatglm_turbo", "chatglm_pro", "chatglm_lite", "chatglm_std", "PaLM-2":
-		// oneapi
-		return ai.ai.OneAPI
-	case google.ModelGeminiPro, google.ModelGeminiProVision:
-		return ai.ai.Google
-	case sky.ModelSkyChatMegaVerse:
-		return ai.ai.Sky
-	default:
-		if openrouter.SupportModel(model) {
-			return ai.ai.Openrouter
-		}
-	}
+// Policy 返回模型路由策略
+func (ai *Imp) Policy() *Policy {
+	return ai.policy
+}
 
-	return ai.ai.OpenAI
+func (ai *Imp) selectImp(model string) Chat {
+	return ai.registry.Imp(model)
 }
 
 func (ai *Imp) Chat(ctx context.Context, req Request) (*Response, error) {
-	return ai.selectImp(req.Model).Chat(ctx, req)
+	return ai.failover.Chat(ctx, ai.applyCatalog(req))
 }
 
 func (ai *Imp) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
@@ -403,9 +365,24 @@ func (ai *Imp) ChatStream(ctx context.Context, req Request) (<-chan Response, er
 		return item
 	})
 
-	return ai.selectImp(req.Model).ChatStream(ctx, req)
+	return ai.failover.ChatStream(ctx, ai.applyCatalog(req))
+}
+
+// applyCatalog 根据模型目录中的定义修正请求：解析模型别名，限制最大输出 Token 数量
+func (ai *Imp) applyCatalog(req Request) Request {
+	req.Model = catalog.Default().Resolve(req.Model)
+	if m, ok := catalog.Default().Get(req.Model); ok && m.MaxOutput > 0 && (req.MaxTokens <= 0 || req.MaxTokens > m.MaxOutput) {
+		req.MaxTokens = m.MaxOutput
+	}
+
+	return req
 }
 
+// MaxContextLength 模型最大上下文长度，优先使用模型目录中的定义
 func (ai *Imp) MaxContextLength(model string) int {
+	if m, ok := catalog.Default().Get(model); ok && m.ContextWindow > 0 {
+		return m.ContextWindow
+	}
+
 	return ai.selectImp(model).MaxContextLength(model)
 }
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CompareChunk 多模型对比时，单个模型的流式响应，Index 为模型在请求中的序号
+type CompareChunk struct {
+	Index int
+	Model string
+	Response
+	// Done 该模型的响应已经结束
+	Done bool
+}
+
+// FanOut 将请求并发发送给多个模型，所有模型的流式响应合并到同一个 channel 中
+// 每个模型的响应按照原有顺序到达，最后一个为 Done 响应；所有模型的响应都结束后 channel 关闭
+func FanOut(ctx context.Context, c Chat, reqs []Request) <-chan CompareChunk {
+	res := make(chan CompareChunk)
+
+	send := func(chunk CompareChunk) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case res <- chunk:
+			return true
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(index int, req Request) {
+			defer wg.Done()
+			defer send(CompareChunk{Index: index, Model: req.Model, Done: true})
+
+			stream, err := c.ChatStream(ctx, req)
+			if err != nil {
+				code := "CHAT_ERROR"
+				if errors.Is(err, ErrContentFilter) {
+					code = "CONTENT_FILTER"
+				}
+
+				send(CompareChunk{Index: index, Model: req.Model, Response: Response{Error: err.Error(), ErrorCode: code}})
+				return
+			}
+
+			for data := range stream {
+				if !send(CompareChunk{Index: index, Model: req.Model, Response: data}) {
+					// 请求已取消，继续读取直到渠道关闭 channel，避免渠道协程阻塞
+					for range stream {
+					}
+					return
+				}
+			}
+		}(i, req)
+	}
+
+	go func() {
+		wg.Wait()
+		close(res)
+	}()
+
+	return res
+}
@@ -0,0 +1,45 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestFanOut(t *testing.T) {
+	fc := NewFakeChat(fake.New(fake.Options{ChunkSize: 2}, []fake.Rule{
+		{Model: "first", Reply: "first reply"},
+		{Model: "second", Reply: "second reply"},
+	}))
+
+	messages := Messages{{Role: "user", Content: "hello"}}
+	reqs := []Request{
+		{Model: "first", Messages: messages},
+		{Model: "second", Messages: messages},
+		{Model: "broken", Messages: Messages{{Role: "user", Content: "[fake-error:content_filter]"}}},
+	}
+
+	texts := make([]string, len(reqs))
+	errs := make([]string, len(reqs))
+	done := make([]int, len(reqs))
+	for chunk := range FanOut(context.TODO(), fc, reqs) {
+		assert.Equal(t, reqs[chunk.Index].Model, chunk.Model)
+		if chunk.Done {
+			done[chunk.Index]++
+			continue
+		}
+
+		// Done 之后不应该再有该模型的响应
+		assert.Equal(t, 0, done[chunk.Index])
+		texts[chunk.Index] += chunk.Text
+		if chunk.ErrorCode != "" {
+			errs[chunk.Index] = chunk.ErrorCode
+		}
+	}
+
+	assert.EqualValues(t, []string{"first reply", "second reply", ""}, texts)
+	assert.EqualValues(t, []string{"", "", "CONTENT_FILTER"}, errs)
+	assert.EqualValues(t, []int{1, 1, 1}, done)
+}
@@ -0,0 +1,192 @@
+package chat
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/go-utils/array"
+)
+
+// SummaryStore 上下文摘要存储，repo.CacheRepo 实现了该接口
+type SummaryStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+}
+
+// Summary 上下文摘要
+type Summary struct {
+	// Text 摘要内容
+	Text string
+	// Model 生成摘要使用的模型
+	Model string
+	// Usage 生成摘要消耗的 Token 数量，摘要来自缓存时为 0
+	Usage
+	// Cached 摘要是否来自缓存
+	Cached bool
+}
+
+// Tokens 生成摘要消耗的 Token 总数
+func (s Summary) Tokens() int {
+	return s.InputTokens + s.OutputTokens
+}
+
+// cachedSummary 缓存的摘要，记录已经总结过的每条消息的摘要值，用于增量总结
+type cachedSummary struct {
+	Digests []string `json:"digests"`
+	Summary string   `json:"summary"`
+}
+
+// summaryMaxMessageLength 生成摘要时，单条消息的最大长度，超出部分会被截断
+const summaryMaxMessageLength = 2000
+
+const summarySystemPrompt = `你是一个对话摘要助手。请将用户提供的对话内容总结为简洁的摘要，保留对后续对话有帮助的关键信息，例如用户的身份、需求、偏好、已确定的结论以及重要的事实和数据。
+如果提供了已有摘要，请将已有摘要与新的对话内容合并为一份完整的摘要。
+直接输出摘要内容，不要添加任何解释。`
+
+// Compressor 上下文压缩，将超出上下文窗口而被丢弃的早期对话总结为摘要
+type Compressor struct {
+	chat      Chat
+	store     SummaryStore
+	model     string
+	maxTokens int
+	ttl       time.Duration
+}
+
+// NewCompressor 创建上下文压缩实例，model 为生成摘要使用的模型
+func NewCompressor(chat Chat, store SummaryStore, model string, maxTokens int, ttl time.Duration) *Compressor {
+	if maxTokens <= 0 {
+		maxTokens = 500
+	}
+
+	return &Compressor{chat: chat, store: store, model: model, maxTokens: maxTokens, ttl: ttl}
+}
+
+// Summarize 对被丢弃的消息生成摘要，key 为摘要的缓存 key（通常每个数字人一个）
+// 只对尚未总结过的消息进行增量总结，并合并到已有的摘要中，没有需要总结的消息时返回 nil
+// 客户端发送的上下文窗口每轮都会向后滑动，被丢弃的消息并不总是以上一次的消息为前缀，因此按照单条消息判断是否已经总结过
+func (c *Compressor) Summarize(ctx context.Context, key string, dropped Messages) (*Summary, error) {
+	dropped = array.Filter(dropped, func(item Message, _ int) bool {
+		return item.Role != "system" && strings.TrimSpace(item.Content) != ""
+	})
+	if len(dropped) == 0 {
+		return nil, nil
+	}
+
+	var previous cachedSummary
+	if data, err := c.store.Get(ctx, key); err == nil {
+		if err := json.Unmarshal([]byte(data), &previous); err != nil {
+			log.F(log.M{"key": key}).Warningf("unmarshal cached context summary failed: %v", err)
+		}
+	}
+
+	// 缓存格式不正确（或者是旧版本的缓存）时，重新生成
+	if len(previous.Digests) == 0 {
+		previous = cachedSummary{}
+	}
+
+	summarized := array.ToMap(previous.Digests, func(item string, _ int) string { return item })
+	digests := array.Map(dropped, func(item Message, _ int) string { return messageDigest(item) })
+	messages := array.Filter(dropped, func(_ Message, i int) bool {
+		_, ok := summarized[digests[i]]
+		return !ok
+	})
+
+	if len(messages) == 0 && previous.Summary != "" {
+		return &Summary{Text: previous.Summary, Model: c.model, Cached: true}, nil
+	}
+
+	req := Request{
+		Model: c.model,
+		Messages: Messages{
+			{Role: "system", Content: summarySystemPrompt},
+			{Role: "user", Content: buildSummaryPrompt(previous.Summary, messages)},
+		},
+		MaxTokens: c.maxTokens,
+	}
+
+	resp, err := c.chat.Chat(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("summarize context failed: %w", err)
+	}
+
+	if resp.ErrorCode != "" {
+		return nil, fmt.Errorf("summarize context failed: [%s] %s", resp.ErrorCode, resp.Error)
+	}
+
+	text := strings.TrimSpace(resp.Text)
+	if text == "" {
+		return nil, errors.New("summarize context failed: empty summary")
+	}
+
+	summary := Summary{
+		Text:  text,
+		Model: c.model,
+		Usage: Usage{InputTokens: resp.InputTokens, OutputTokens: resp.OutputTokens}.Fill(req.Messages, Message{Role: "assistant", Content: text}, c.model),
+	}
+
+	// 只记录当前被丢弃消息的摘要值，更早的消息已经不在客户端的上下文窗口中，其内容保留在摘要里
+	data, _ := json.Marshal(cachedSummary{Digests: digests, Summary: text})
+	if err := c.store.Set(ctx, key, string(data), c.ttl); err != nil {
+		log.F(log.M{"key": key}).Errorf("save context summary failed: %v", err)
+	}
+
+	return &summary, nil
+}
+
+// buildSummaryPrompt 构建生成摘要的提示语
+func buildSummaryPrompt(previous string, messages Messages) string {
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("已有摘要：\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\n新的对话内容：\n")
+	} else {
+		sb.WriteString("对话内容：\n")
+	}
+
+	for _, msg := range messages {
+		content := []rune(strings.TrimSpace(msg.Content))
+		if len(content) > summaryMaxMessageLength {
+			content = append(content[:summaryMaxMessageLength], []rune("...")...)
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s\n", msg.Role, string(content)))
+	}
+
+	return sb.String()
+}
+
+// messageDigest 计算单条消息的摘要值
+func messageDigest(msg Message) string {
+	h := sha1.New()
+	h.Write([]byte(msg.Role))
+	h.Write([]byte{0})
+	h.Write([]byte(msg.Content))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DroppedMessages 返回上下文缩减时被丢弃的消息（不包含 system 消息），上下文缩减总是丢弃最早的消息
+func DroppedMessages(original, reduced Messages) Messages {
+	isContext := func(item Message, _ int) bool { return item.Role != "system" }
+
+	originalContext := array.Filter(original, isContext)
+	reducedContext := array.Filter(reduced, isContext)
+	if len(reducedContext) >= len(originalContext) {
+		return nil
+	}
+
+	return originalContext[:len(originalContext)-len(reducedContext)]
+}
+
+// InjectSummary 将上下文摘要注入到请求中
+func (req Request) InjectSummary(summary string) Request {
+	return req.AppendSystemPrompt("以下是之前对话内容的摘要，请在回答时参考：\n" + summary)
+}
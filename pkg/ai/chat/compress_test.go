@@ -0,0 +1,122 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type summaryTestStore struct {
+	data map[string]string
+}
+
+func (s *summaryTestStore) Get(ctx context.Context, key string) (string, error) {
+	if v, ok := s.data[key]; ok {
+		return v, nil
+	}
+
+	return "", errors.New("not found")
+}
+
+func (s *summaryTestStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	s.data[key] = value
+	return nil
+}
+
+type summaryTestClient struct {
+	ChatTestClient
+	prompts []string
+}
+
+func (c *summaryTestClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	c.prompts = append(c.prompts, prompt)
+
+	return &Response{Text: "summary#" + string(rune('0'+len(c.prompts))), InputTokens: 100, OutputTokens: 20}, nil
+}
+
+func TestCompressor_Summarize(t *testing.T) {
+	client := &summaryTestClient{}
+	store := &summaryTestStore{data: make(map[string]string)}
+	compressor := NewCompressor(client, store, "gpt-3.5-turbo", 200, time.Hour)
+
+	dropped := Messages{
+		{Role: "system", Content: "system"},
+		{Role: "user", Content: "我叫小明"},
+		{Role: "assistant", Content: "你好，小明"},
+	}
+
+	summary, err := compressor.Summarize(context.TODO(), "room", dropped)
+	assert.NoError(t, err)
+	assert.Equal(t, "summary#1", summary.Text)
+	assert.Equal(t, 120, summary.Tokens())
+	assert.False(t, summary.Cached)
+	assert.True(t, strings.Contains(client.prompts[0], "user: 我叫小明"))
+	assert.False(t, strings.Contains(client.prompts[0], "system"))
+
+	// 被丢弃的消息没有变化，使用缓存
+	summary, err = compressor.Summarize(context.TODO(), "room", dropped)
+	assert.NoError(t, err)
+	assert.Equal(t, "summary#1", summary.Text)
+	assert.True(t, summary.Cached)
+	assert.Equal(t, 0, summary.Tokens())
+	assert.Equal(t, 1, len(client.prompts))
+
+	// 新增被丢弃的消息，增量总结
+	dropped = append(dropped, Message{Role: "user", Content: "我喜欢蓝色"}, Message{Role: "assistant", Content: "好的"})
+	summary, err = compressor.Summarize(context.TODO(), "room", dropped)
+	assert.NoError(t, err)
+	assert.Equal(t, "summary#2", summary.Text)
+	assert.True(t, strings.Contains(client.prompts[1], "已有摘要：\nsummary#1"))
+	assert.True(t, strings.Contains(client.prompts[1], "user: 我喜欢蓝色"))
+	assert.False(t, strings.Contains(client.prompts[1], "我叫小明"))
+
+	// 上下文窗口向后滑动，最早的消息不再出现在被丢弃的消息中，只总结新增的消息并合并到已有摘要
+	dropped = Messages{dropped[3], dropped[4], {Role: "user", Content: "我住在北京"}, {Role: "assistant", Content: "明白"}}
+	summary, err = compressor.Summarize(context.TODO(), "room", dropped)
+	assert.NoError(t, err)
+	assert.Equal(t, "summary#3", summary.Text)
+	assert.True(t, strings.Contains(client.prompts[2], "已有摘要：\nsummary#2"))
+	assert.True(t, strings.Contains(client.prompts[2], "user: 我住在北京"))
+	assert.False(t, strings.Contains(client.prompts[2], "我喜欢蓝色"))
+
+	summary, err = compressor.Summarize(context.TODO(), "room", dropped[2:])
+	assert.NoError(t, err)
+	assert.Equal(t, "summary#3", summary.Text)
+	assert.True(t, summary.Cached)
+
+	summary, err = compressor.Summarize(context.TODO(), "room", Messages{{Role: "system", Content: "system"}})
+	assert.NoError(t, err)
+	assert.True(t, summary == nil)
+}
+
+func TestDroppedMessages(t *testing.T) {
+	original := Messages{
+		{Role: "system", Content: "system"},
+		{Role: "user", Content: "user #1"},
+		{Role: "assistant", Content: "assistant #1"},
+		{Role: "user", Content: "user #2"},
+	}
+
+	dropped := DroppedMessages(original, Messages{original[0], original[3]})
+	assert.Equal(t, 2, len(dropped))
+	assert.Equal(t, "user #1", dropped[0].Content)
+	assert.Equal(t, "assistant #1", dropped[1].Content)
+
+	assert.Equal(t, 0, len(DroppedMessages(original, original)))
+}
+
+func TestRequest_InjectSummary(t *testing.T) {
+	req := Request{Messages: Messages{{Role: "system", Content: "prompt"}, {Role: "user", Content: "hello"}}}.InjectSummary("summary")
+	assert.Equal(t, 2, len(req.Messages))
+	assert.True(t, strings.HasPrefix(req.Messages[0].Content, "prompt\n\n"))
+	assert.True(t, strings.HasSuffix(req.Messages[0].Content, "summary"))
+
+	req = Request{Messages: Messages{{Role: "user", Content: "hello"}}}.InjectSummary("summary")
+	assert.Equal(t, 2, len(req.Messages))
+	assert.Equal(t, "system", req.Messages[0].Role)
+}
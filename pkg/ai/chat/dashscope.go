@@ -8,12 +8,36 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/go-utils/str"
+	"github.com/mylxsw/go-utils/ternary"
 	"strings"
 	"time"
 
 	"github.com/mylxsw/go-utils/array"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "dashscope",
+		Aliases:  []string{"灵积"},
+		Prefixes: []string{"灵积:"},
+		Models: []string{
+			dashscope.ModelQWenV1, dashscope.ModelQWenPlusV1,
+			dashscope.ModelQWen7BV1, dashscope.ModelQWen7BChatV1,
+			dashscope.ModelQWenMax, dashscope.ModelQWenMaxLongContext, dashscope.ModelQWenVLPlus,
+			dashscope.ModelQWenTurbo, dashscope.ModelQWenPlus, dashscope.ModelBaiChuan7BChatV1,
+			dashscope.ModelQWen7BChat, dashscope.ModelQWen14BChat,
+		},
+	}, func(ai *AI) Chat { return ai.DashScope })
+}
+
+// dashscopeSamplingCapability 通义千问支持的采样参数，temperature 取值范围为 [0, 2)，top_p 取值范围为 (0, 1)
+var dashscopeSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1.99},
+	TopP:        &Range{Min: 0.01, Max: 0.99},
+	MaxStop:     MaxStopSequences,
+	Seed:        true,
+}
+
 type DashScopeChat struct {
 	dashscope *dashscope.DashScope
 	file      *file.File
@@ -142,8 +166,85 @@ func (ds *DashScopeChat) initRequest(req Request) dashscope.ChatRequest {
 	}
 }
 
+// initToolRequest 工具调用只支持 messages 格式，同时返回结果必须为 message 格式
+func (ds *DashScopeChat) initToolRequest(req Request) dashscope.ChatRequest {
+	messages := array.Map(req.Messages, func(msg Message, _ int) dashscope.Message {
+		return dashscope.Message{
+			Role:       msg.Role,
+			Text:       msg.Content,
+			Name:       ternary.If(msg.Role == RoleTool && msg.Name == "", req.Messages.toolCallName(msg.ToolCallID), msg.Name),
+			ToolCallID: msg.ToolCallID,
+			ToolCalls: array.Map(msg.ToolCalls, func(call ToolCall, _ int) dashscope.ToolCall {
+				return dashscope.ToolCall{
+					ID:       call.ID,
+					Type:     ToolTypeFunction,
+					Function: dashscope.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments},
+				}
+			}),
+		}
+	})
+
+	chatReq := dashscope.ChatRequest{
+		Model: strings.TrimPrefix(req.Model, "灵积:"),
+		Input: dashscope.ChatInput{Messages: messages},
+		Parameters: dashscope.ChatParameters{
+			ResultFormat: dashscope.ResultFormatMessage,
+		},
+	}
+
+	// 通义千问不支持 tool_choice 参数，none 时不提供工具定义
+	if mode, _ := req.ToolChoice(); mode != "none" {
+		chatReq.Parameters.Tools = array.Map(req.Tools, func(item Tool, _ int) dashscope.Tool {
+			return dashscope.Tool{
+				Type: ToolTypeFunction,
+				Function: dashscope.FunctionDefinition{
+					Name:        item.Function.Name,
+					Description: item.Function.Description,
+					Parameters:  item.Function.Parameters,
+				},
+			}
+		})
+	}
+
+	return chatReq
+}
+
+func (ds *DashScopeChat) buildRequest(req Request) dashscope.ChatRequest {
+	var chatReq dashscope.ChatRequest
+	if req.Model != dashscope.ModelQWenVLPlus && (len(req.Tools) > 0 || req.Messages.HasToolCalls()) {
+		chatReq = ds.initToolRequest(req)
+	} else {
+		chatReq = ds.initRequest(req)
+	}
+
+	sampling := req.Sampling.Clamp(dashscopeSamplingCapability)
+	chatReq.Parameters.Temperature = sampling.TemperatureValue()
+	chatReq.Parameters.TopP = sampling.TopPValue()
+	chatReq.Parameters.Stop = sampling.Stop
+	chatReq.Parameters.Seed = sampling.SeedValue()
+
+	return chatReq
+}
+
+// dashscopeToolCalls 返回响应中的工具调用
+func dashscopeToolCalls(resp dashscope.ChatResponse) []ToolCall {
+	if len(resp.Output.Choices) == 0 {
+		return nil
+	}
+
+	return array.Map(resp.Output.Choices[0].Message.ToolCalls, func(call dashscope.ToolCall, i int) ToolCall {
+		index := i
+		return ToolCall{
+			Index:    &index,
+			ID:       call.ID,
+			Type:     ToolTypeFunction,
+			Function: FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments},
+		}
+	})
+}
+
 func (ds *DashScopeChat) Chat(ctx context.Context, req Request) (*Response, error) {
-	chatReq := ds.initRequest(req)
+	chatReq := ds.buildRequest(req)
 	resp, err := ds.dashscope.Chat(ctx, chatReq)
 	if err != nil {
 		return nil, err
@@ -153,15 +254,22 @@ func (ds *DashScopeChat) Chat(ctx context.Context, req Request) (*Response, erro
 		return nil, fmt.Errorf("dashscope chat error: [%s] %s", resp.Code, resp.Message)
 	}
 
+	text, finishReason := resp.Output.Text, resp.Output.FinishReason
+	if len(resp.Output.Choices) > 0 {
+		text, finishReason = resp.Output.Choices[0].Message.Text, resp.Output.Choices[0].FinishReason
+	}
+
 	return &Response{
-		Text:         resp.Output.Text,
+		Text:         text,
+		FinishReason: finishReason,
+		ToolCalls:    dashscopeToolCalls(*resp),
 		InputTokens:  resp.Usage.InputTokens,
 		OutputTokens: resp.Usage.OutputTokens,
 	}, nil
 }
 
 func (ds *DashScopeChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
-	stream, err := ds.dashscope.ChatStream(ctx, ds.initRequest(req))
+	stream, err := ds.dashscope.ChatStream(ctx, ds.buildRequest(req))
 	if err != nil {
 		return nil, err
 	}
@@ -188,14 +296,22 @@ func (ds *DashScopeChat) ChatStream(ctx context.Context, req Request) (<-chan Re
 					return
 				}
 
-				select {
-				case <-ctx.Done():
-					return
-				case res <- Response{
+				resp := Response{
 					Text:         strings.TrimPrefix(data.Output.Text, lastMessage),
 					InputTokens:  data.Usage.InputTokens,
 					OutputTokens: data.Usage.OutputTokens,
-				}:
+				}
+
+				// 工具调用在流式响应中为全量数据，只在生成结束时返回
+				if data.Output.FinishReason == "tool_calls" {
+					resp.FinishReason = data.Output.FinishReason
+					resp.ToolCalls = dashscopeToolCalls(data)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case res <- resp:
 				}
 
 				lastMessage = data.Output.Text
@@ -0,0 +1,343 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mylxsw/asteria/log"
+)
+
+// failoverTarget 故障转移目标渠道
+type failoverTarget struct {
+	channel string
+	// model 该渠道使用的模型名称，为空时使用请求中的模型
+	model string
+	imp   Chat
+}
+
+func (t failoverTarget) request(req Request) Request {
+	if t.model != "" {
+		req.Model = t.model
+	}
+
+	return req
+}
+
+// Failover 聊天渠道故障转移，按照顺序依次尝试模型对应的渠道，每个渠道都有独立的熔断器
+type Failover struct {
+	registry *Registry
+	rules    map[string][]failoverTarget
+
+	threshold            int
+	cooldown             time.Duration
+	firstResponseTimeout time.Duration
+
+	lock     sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewFailover 创建故障转移实例
+// threshold 为渠道连续失败多少次后熔断，cooldown 为熔断持续时间，firstResponseTimeout 为流式响应等待首个响应的超时时间
+func NewFailover(registry *Registry, threshold int, cooldown, firstResponseTimeout time.Duration) *Failover {
+	return &Failover{
+		registry:             registry,
+		rules:                make(map[string][]failoverTarget),
+		threshold:            threshold,
+		cooldown:             cooldown,
+		firstResponseTimeout: firstResponseTimeout,
+		breakers:             make(map[string]*CircuitBreaker),
+	}
+}
+
+// LoadRules 加载故障转移规则，格式为 `模型=渠道1,渠道2@模型`，例如 `gpt-4=openai,openrouter@openai.gpt-4`
+func (f *Failover) LoadRules(rules []string) {
+	for _, rule := range rules {
+		segs := strings.SplitN(rule, "=", 2)
+		if len(segs) != 2 || strings.TrimSpace(segs[0]) == "" || strings.TrimSpace(segs[1]) == "" {
+			log.Warningf("invalid chat failover rule: %s", rule)
+			continue
+		}
+
+		targets := make([]failoverTarget, 0)
+		for _, item := range strings.Split(segs[1], ",") {
+			channelSegs := strings.SplitN(strings.TrimSpace(item), "@", 2)
+			target := failoverTarget{channel: strings.TrimSpace(channelSegs[0])}
+			if len(channelSegs) > 1 {
+				target.model = strings.TrimSpace(channelSegs[1])
+			}
+
+			target.imp = f.registry.Channel(target.channel)
+			if target.imp == nil {
+				log.Warningf("invalid chat failover rule %s: channel %s not found", rule, target.channel)
+				continue
+			}
+
+			targets = append(targets, target)
+		}
+
+		if len(targets) > 0 {
+			f.rules[strings.TrimSpace(segs[0])] = targets
+		}
+	}
+}
+
+func (f *Failover) breaker(channel string) *CircuitBreaker {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if b, ok := f.breakers[channel]; ok {
+		return b
+	}
+
+	b := NewCircuitBreaker(f.threshold, f.cooldown)
+	f.breakers[channel] = b
+	return b
+}
+
+// candidates 返回本次请求可以尝试的渠道，所有渠道都被熔断时，仍然尝试第一个渠道
+// preferred 为路由策略指定优先使用的渠道，该渠道可用时排在第一位
+func (f *Failover) candidates(model string, preferred string) []failoverTarget {
+	targets := f.rules[model]
+
+	candidates := make([]failoverTarget, 0, len(targets))
+	for _, t := range targets {
+		if f.breaker(t.channel).State() == BreakerOpen {
+			continue
+		}
+
+		if t.channel == preferred {
+			candidates = append([]failoverTarget{t}, candidates...)
+		} else {
+			candidates = append(candidates, t)
+		}
+	}
+
+	if len(candidates) == 0 {
+		log.Warningf("all chat channels for model %s are unavailable, try %s", model, targets[0].channel)
+		return targets[:1]
+	}
+
+	return candidates
+}
+
+// HealthyChannels 返回模型配置的故障转移渠道中未被熔断的渠道
+func (f *Failover) HealthyChannels(model string) []string {
+	channels := make([]string, 0)
+	for _, t := range f.rules[model] {
+		if f.breaker(t.channel).State() != BreakerOpen {
+			channels = append(channels, t.channel)
+		}
+	}
+
+	return channels
+}
+
+func (f *Failover) success(t failoverTarget) {
+	f.breaker(t.channel).Success()
+}
+
+// release 请求未能反映渠道可用性时（请求被取消），不改变熔断器状态，否则视为渠道可用
+func (f *Failover) release(ctx context.Context, t failoverTarget) {
+	if ctx.Err() != nil {
+		f.breaker(t.channel).Abort()
+		return
+	}
+
+	f.success(t)
+}
+
+func (f *Failover) failure(t failoverTarget, model string, err error) {
+	log.F(log.M{"channel": t.channel, "model": model}).Warningf("chat channel failed: %v", err)
+
+	if f.breaker(t.channel).Failure() {
+		log.F(log.M{"channel": t.channel}).Errorf("chat channel circuit breaker opened")
+	}
+}
+
+// shouldFailover 判断发生错误后是否需要切换到下一个渠道，内容安全、上下文超限以及请求被取消时无需切换
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	return !errors.Is(err, ErrContentFilter) && !errors.Is(err, ErrContextExceedLimit) && !errors.Is(err, context.Canceled)
+}
+
+// Chat 以请求-响应的方式进行对话，失败时自动切换到下一个渠道
+func (f *Failover) Chat(ctx context.Context, req Request) (*Response, error) {
+	if len(f.rules[req.Model]) <= 1 {
+		return f.imp(req).Chat(ctx, req)
+	}
+
+	var lastErr error
+	for _, t := range f.candidates(req.Model, req.Channel) {
+		// 半开状态的熔断器只允许一个探测请求通过，已经有探测请求时跳过该渠道
+		if !f.breaker(t.channel).Allow() && lastErr != nil {
+			continue
+		}
+
+		resp, err := t.imp.Chat(ctx, t.request(req))
+		if err == nil && resp.ErrorCode != "" {
+			err = fmt.Errorf("[%s] %s", resp.ErrorCode, resp.Error)
+		}
+
+		if err == nil {
+			f.success(t)
+			return resp, nil
+		}
+
+		if !shouldFailover(ctx, err) {
+			f.release(ctx, t)
+			return resp, err
+		}
+
+		f.failure(t, req.Model, err)
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// ChatStream 以流的方式进行对话
+// 在收到首个有效响应之前（建立连接失败、首个响应为错误、首个响应超时），自动切换到下一个渠道
+func (f *Failover) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	if len(f.rules[req.Model]) <= 1 {
+		return f.imp(req).ChatStream(ctx, req)
+	}
+
+	candidates := f.candidates(req.Model, req.Channel)
+
+	var lastErr error
+	for i, t := range candidates {
+		if !f.breaker(t.channel).Allow() && lastErr != nil {
+			continue
+		}
+
+		last := i == len(candidates)-1
+
+		attemptCtx, cancel := context.WithCancel(ctx)
+		stream, err := t.imp.ChatStream(attemptCtx, t.request(req))
+		if err != nil {
+			cancel()
+
+			if !shouldFailover(ctx, err) {
+				f.release(ctx, t)
+				return nil, err
+			}
+
+			f.failure(t, req.Model, err)
+			lastErr = err
+			continue
+		}
+
+		first, ok, err := f.waitFirstResponse(ctx, stream, last)
+		if err == nil && ok && first.ErrorCode == "" {
+			f.success(t)
+			return forwardStream(ctx, cancel, &first, stream), nil
+		}
+
+		if ctx.Err() != nil {
+			f.breaker(t.channel).Abort()
+			cancel()
+			drainStream(stream)
+			return nil, ctx.Err()
+		}
+
+		if err == nil {
+			if ok {
+				err = fmt.Errorf("[%s] %s", first.ErrorCode, first.Error)
+			} else {
+				err = errors.New("chat stream closed without response")
+			}
+		}
+
+		f.failure(t, req.Model, err)
+		lastErr = err
+
+		// 最后一个渠道也失败时，将其响应原样返回给调用方
+		if last {
+			if ok {
+				return forwardStream(ctx, cancel, &first, stream), nil
+			}
+
+			return forwardStream(ctx, cancel, nil, stream), nil
+		}
+
+		cancel()
+		drainStream(stream)
+	}
+
+	return nil, lastErr
+}
+
+// waitFirstResponse 等待流式响应的首个响应，最后一个渠道不设置超时时间
+func (f *Failover) waitFirstResponse(ctx context.Context, stream <-chan Response, last bool) (Response, bool, error) {
+	var timeout <-chan time.Time
+	if !last && f.firstResponseTimeout > 0 {
+		timer := time.NewTimer(f.firstResponseTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return Response{}, false, ctx.Err()
+	case <-timeout:
+		return Response{}, false, errors.New("wait for first response timeout")
+	case res, ok := <-stream:
+		return res, ok, nil
+	}
+}
+
+// imp 没有配置故障转移规则时，优先使用请求指定的渠道，否则按照模型选择渠道
+func (f *Failover) imp(req Request) Chat {
+	if req.Channel != "" {
+		if imp := f.registry.Channel(req.Channel); imp != nil {
+			return imp
+		}
+	}
+
+	return f.registry.Imp(req.Model)
+}
+
+// forwardStream 将已经读取的首个响应与剩余的流式响应合并转发
+func forwardStream(ctx context.Context, cancel context.CancelFunc, first *Response, stream <-chan Response) <-chan Response {
+	res := make(chan Response)
+	go func() {
+		defer close(res)
+		defer cancel()
+
+		if first != nil {
+			select {
+			case <-ctx.Done():
+				drainStream(stream)
+				return
+			case res <- *first:
+			}
+		}
+
+		for data := range stream {
+			select {
+			case <-ctx.Done():
+				drainStream(stream)
+				return
+			case res <- data:
+			}
+		}
+	}()
+
+	return res
+}
+
+// drainStream 丢弃被放弃的流式响应中的剩余数据，避免写入方阻塞
+func drainStream(stream <-chan Response) {
+	go func() {
+		for range stream {
+		}
+	}()
+}
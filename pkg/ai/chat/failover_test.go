@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type failoverTestClient struct {
+	ChatTestClient
+	name   string
+	err    error
+	stream []Response
+	// hang 为 true 时，流式响应不返回任何数据，直到请求被取消
+	hang  bool
+	calls int
+}
+
+func (c *failoverTestClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &Response{Text: c.name + ":" + req.Model}, nil
+}
+
+func (c *failoverTestClient) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	res := make(chan Response)
+	go func() {
+		defer close(res)
+
+		if c.hang {
+			<-ctx.Done()
+			return
+		}
+
+		for _, item := range c.stream {
+			select {
+			case <-ctx.Done():
+				return
+			case res <- item:
+			}
+		}
+	}()
+
+	return res, nil
+}
+
+func newFailoverTestRegistry(clients ...*failoverTestClient) *Registry {
+	registry := NewRegistry(clients[0].name)
+	for _, c := range clients {
+		registry.Register(Channel{Name: c.name}, c)
+	}
+
+	return registry
+}
+
+func readStream(stream <-chan Response) string {
+	var text string
+	for item := range stream {
+		text += item.Text + item.ErrorCode
+	}
+
+	return text
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Failure())
+	assert.True(t, breaker.Failure())
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+
+	// 冷却时间过后进入半开状态，只允许一个探测请求通过
+	now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	// 探测失败，重新打开
+	assert.True(t, breaker.Failure())
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, BreakerClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+	assert.True(t, breaker.Allow())
+}
+
+func TestFailover_Chat(t *testing.T) {
+	primary := &failoverTestClient{name: "azure", err: errors.New("service unavailable")}
+	secondary := &failoverTestClient{name: "openrouter"}
+
+	failover := NewFailover(newFailoverTestRegistry(primary, secondary), 2, time.Minute, time.Second)
+	failover.LoadRules([]string{"gpt-4=azure,not-exist,openrouter@openai.gpt-4"})
+
+	for i := 0; i < 3; i++ {
+		resp, err := failover.Chat(context.TODO(), Request{Model: "gpt-4"})
+		assert.NoError(t, err)
+		assert.Equal(t, "openrouter:openai.gpt-4", resp.Text)
+	}
+
+	// 主渠道熔断后，不再请求主渠道
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, BreakerOpen, failover.breaker("azure").State())
+
+	// 内容安全错误不触发故障转移
+	secondary.err = ErrContentFilter
+	_, err := failover.Chat(context.TODO(), Request{Model: "gpt-4"})
+	assert.True(t, errors.Is(err, ErrContentFilter))
+	assert.Equal(t, BreakerClosed, failover.breaker("openrouter").State())
+
+	// 没有配置故障转移规则的模型直接使用路由结果
+	secondary.err = nil
+	resp, err := failover.Chat(context.TODO(), Request{Model: "gpt-3.5-turbo"})
+	assert.True(t, err != nil)
+	assert.True(t, resp == nil)
+}
+
+func TestFailover_ChatStream(t *testing.T) {
+	broken := &failoverTestClient{name: "broken", stream: []Response{{ErrorCode: "rate_limit"}}}
+	slow := &failoverTestClient{name: "slow", hang: true}
+	empty := &failoverTestClient{name: "empty"}
+	healthy := &failoverTestClient{name: "healthy", stream: []Response{{Text: "hello"}, {Text: " world"}}}
+
+	failover := NewFailover(newFailoverTestRegistry(broken, slow, empty, healthy), 1, time.Minute, 50*time.Millisecond)
+	failover.LoadRules([]string{
+		"gpt-4=broken,slow,empty,healthy",
+		"gpt-3.5-turbo=empty,broken",
+	})
+
+	stream, err := failover.ChatStream(context.TODO(), Request{Model: "gpt-4"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", readStream(stream))
+
+	for _, name := range []string{"broken", "slow", "empty"} {
+		assert.Equal(t, BreakerOpen, failover.breaker(name).State())
+	}
+
+	// 所有渠道都失败时，返回最后一个渠道的响应
+	failover.breaker("empty").Success()
+	failover.breaker("broken").Success()
+	stream, err = failover.ChatStream(context.TODO(), Request{Model: "gpt-3.5-turbo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "rate_limit", readStream(stream))
+}
@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	"github.com/mylxsw/aidea-server/pkg/ai/tokenizer"
+)
+
+const (
+	fakeChannelName = "fake"
+	// ModelFakeEcho 模拟的聊天模型，默认原样返回用户消息
+	ModelFakeEcho = "fake:echo"
+)
+
+// fakeChannel 模拟渠道，只在启用模拟服务（enable-fake）时注册，避免生产环境中可以访问到错误注入等调试功能
+var fakeChannel = Channel{
+	Name:     fakeChannelName,
+	Prefixes: []string{"fake:"},
+}
+
+// FakeChat 模拟的聊天渠道，不请求任何外部服务，用于开发和测试
+type FakeChat struct {
+	fake *fake.Fake
+}
+
+func NewFakeChat(f *fake.Fake) *FakeChat {
+	return &FakeChat{fake: f}
+}
+
+// reply 根据最后一条用户消息生成回复，未指定 Token 用量时使用模型对应的分词器计算
+func (fc *FakeChat) reply(req Request) fake.Reply {
+	req.Model = strings.TrimPrefix(req.Model, fakeChannelName+":")
+
+	var prompt string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role != "user" {
+			continue
+		}
+
+		prompt = req.Messages[i].Content
+		for _, part := range req.Messages[i].MultipartContents {
+			if part.Text != "" {
+				prompt = part.Text
+			}
+		}
+		break
+	}
+
+	reply := fc.fake.Reply(req.Model, prompt)
+	if reply.InputTokens == 0 {
+		reply.InputTokens, _ = MessageTokenCount(req.Messages, req.Model)
+	}
+
+	if reply.OutputTokens == 0 {
+		reply.OutputTokens = tokenizer.Count(req.Model, reply.Text)
+	}
+
+	return reply
+}
+
+// err 将模拟服务的错误转换为聊天渠道的错误
+func (fc *FakeChat) err(ctx context.Context, code string) error {
+	err := fc.fake.Err(ctx, code)
+	if errors.Is(err, fake.ErrContentFilter) {
+		return ErrContentFilter
+	}
+
+	return err
+}
+
+func (fc *FakeChat) Chat(ctx context.Context, req Request) (*Response, error) {
+	if err := fc.fake.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	reply := fc.reply(req)
+	if err := fc.err(ctx, reply.Error); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Text:         reply.Text,
+		FinishReason: "stop",
+		InputTokens:  reply.InputTokens,
+		OutputTokens: reply.OutputTokens,
+	}, nil
+}
+
+func (fc *FakeChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	reply := fc.reply(req)
+
+	// 超时错误在流式响应开始后挂起，与上游服务迟迟没有返回首个响应的行为一致
+	if reply.Error != "" && reply.Error != fake.ErrorTimeout {
+		if err := fc.err(ctx, reply.Error); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make(chan Response)
+	go func() {
+		defer close(res)
+
+		if reply.Error == fake.ErrorTimeout {
+			if err := fc.err(ctx, reply.Error); err != nil && ctx.Err() == nil {
+				select {
+				case <-ctx.Done():
+				case res <- Response{Error: err.Error(), ErrorCode: "FAKE_TIMEOUT"}:
+				}
+			}
+			return
+		}
+
+		chunks := fc.fake.Chunks(reply.Text)
+		if len(chunks) == 0 {
+			chunks = []string{""}
+		}
+
+		for i, chunk := range chunks {
+			if err := fc.fake.Wait(ctx); err != nil {
+				return
+			}
+
+			data := Response{Text: chunk}
+			if i == len(chunks)-1 {
+				data.FinishReason = "stop"
+				data.InputTokens = reply.InputTokens
+				data.OutputTokens = reply.OutputTokens
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case res <- data:
+			}
+		}
+	}()
+
+	return res, nil
+}
+
+func (fc *FakeChat) MaxContextLength(model string) int {
+	return 8000
+}
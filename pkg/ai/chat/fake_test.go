@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestFakeChat(t *testing.T) {
+	fc := NewFakeChat(fake.New(fake.Options{ChunkSize: 2, Timeout: 10 * time.Millisecond}, nil))
+
+	// 只有启用模拟服务时才注册模拟渠道
+	imp := NewChat(&config.Config{EnableFake: true}, &AI{Fake: fc}).(*Imp)
+	assert.Equal(t, fakeChannelName, imp.registry.Resolve(ModelFakeEcho))
+
+	imp = NewChat(&config.Config{}, &AI{Fake: fc}).(*Imp)
+	assert.True(t, imp.registry.Resolve(ModelFakeEcho) != fakeChannelName)
+	assert.True(t, imp.registry.Channel(fakeChannelName) == nil)
+	assert.Equal(t, 1, len(fakeModels(&config.Config{EnableFake: true})))
+	assert.Equal(t, 0, len(fakeModels(&config.Config{})))
+
+	req := Request{Model: "echo", Messages: Messages{{Role: "system", Content: "be nice"}, {Role: "user", Content: "hello"}}}
+	resp, err := fc.Chat(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", resp.Text)
+	assert.True(t, resp.InputTokens > 0)
+	assert.Equal(t, 1, resp.OutputTokens)
+
+	stream, err := fc.ChatStream(context.TODO(), req)
+	assert.NoError(t, err)
+
+	var text string
+	var last Response
+	for data := range stream {
+		text += data.Text
+		last = data
+	}
+	assert.Equal(t, "hello", text)
+	assert.Equal(t, "stop", last.FinishReason)
+	assert.Equal(t, resp.InputTokens, last.InputTokens)
+
+	// 错误注入
+	_, err = fc.Chat(context.TODO(), Request{Model: "echo", Messages: Messages{{Role: "user", Content: "[fake-error:content_filter]"}}})
+	assert.True(t, errors.Is(err, ErrContentFilter))
+
+	_, err = fc.ChatStream(context.TODO(), Request{Model: "echo", Messages: Messages{{Role: "user", Content: "[fake-error:429]"}}})
+	assert.True(t, errors.Is(err, fake.ErrRateLimit))
+
+	stream, err = fc.ChatStream(context.TODO(), Request{Model: "echo", Messages: Messages{{Role: "user", Content: "[fake-error:timeout]"}}})
+	assert.NoError(t, err)
+	last = <-stream
+	assert.Equal(t, "FAKE_TIMEOUT", last.ErrorCode)
+}
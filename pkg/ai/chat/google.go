@@ -3,6 +3,8 @@ package chat
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"github.com/mylxsw/aidea-server/pkg/ai/google"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
@@ -12,6 +14,21 @@ import (
 	"strings"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "google",
+		Prefixes: []string{"google:"},
+		Models:   []string{google.ModelGeminiPro, google.ModelGeminiProVision},
+	}, func(ai *AI) Chat { return ai.Google })
+}
+
+// googleSamplingCapability Gemini 支持的采样参数
+var googleSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 1},
+	MaxStop:     MaxStopSequences,
+}
+
 type GoogleChat struct {
 	gai *google.GoogleAI
 }
@@ -31,6 +48,8 @@ func (chat *GoogleChat) initRequest(req Request) (*google.Request, error) {
 			Role:              msg.Role,
 			Content:           msg.Content,
 			MultipartContents: msg.MultipartContents,
+			ToolCalls:         msg.ToolCalls,
+			ToolCallID:        msg.ToolCallID,
 		}
 
 		if msg.Role == "system" {
@@ -62,7 +81,35 @@ func (chat *GoogleChat) initRequest(req Request) (*google.Request, error) {
 
 	googleReq.Contents = array.Map(contextMessages, func(msg Message, _ int) google.Message {
 		contents := make([]google.MessagePart, 0)
-		if len(msg.MultipartContents) == 0 {
+		if msg.Role == RoleTool {
+			// Gemini 不支持工具调用 ID，通过调用 ID 查询对应的函数名称
+			return google.Message{
+				Role: google.RoleFunction,
+				Parts: []google.MessagePart{{
+					FunctionResponse: &google.FunctionResponse{
+						Name:     req.Messages.toolCallName(msg.ToolCallID),
+						Response: googleFunctionResponse(msg.Content),
+					},
+				}},
+			}
+		}
+
+		if len(msg.ToolCalls) > 0 {
+			if strings.TrimSpace(msg.Content) != "" {
+				contents = append(contents, google.MessagePart{Text: msg.Content})
+			}
+
+			for _, call := range msg.ToolCalls {
+				args := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(args) {
+					args = json.RawMessage("{}")
+				}
+
+				contents = append(contents, google.MessagePart{
+					FunctionCall: &google.FunctionCall{Name: call.Function.Name, Args: args},
+				})
+			}
+		} else if len(msg.MultipartContents) == 0 {
 			contents = append(contents, google.MessagePart{
 				Text: msg.Content,
 			})
@@ -106,9 +153,75 @@ func (chat *GoogleChat) initRequest(req Request) (*google.Request, error) {
 		}
 	})
 
+	// 连续的工具调用结果需要合并为一条包含多个 functionResponse 的消息
+	googleReq.Contents = array.Reduce(googleReq.Contents, func(carry []google.Message, item google.Message) []google.Message {
+		if n := len(carry); n > 0 && item.Role == google.RoleFunction && carry[n-1].Role == google.RoleFunction {
+			carry[n-1].Parts = append(carry[n-1].Parts, item.Parts...)
+			return carry
+		}
+
+		return append(carry, item)
+	}, make([]google.Message, 0, len(googleReq.Contents)))
+
+	if len(req.Tools) > 0 {
+		googleReq.Tools = []google.Tool{{
+			FunctionDeclarations: array.Map(req.Tools, func(item Tool, _ int) google.FunctionDeclaration {
+				return google.FunctionDeclaration{
+					Name:        item.Function.Name,
+					Description: item.Function.Description,
+					Parameters:  item.Function.Parameters,
+				}
+			}),
+		}}
+
+		switch mode, name := req.ToolChoice(); mode {
+		case "none":
+			googleReq.ToolConfig = &google.ToolConfig{FunctionCallingConfig: &google.FunctionCallingConfig{Mode: "NONE"}}
+		case "required":
+			googleReq.ToolConfig = &google.ToolConfig{FunctionCallingConfig: &google.FunctionCallingConfig{Mode: "ANY"}}
+		case "function":
+			googleReq.ToolConfig = &google.ToolConfig{FunctionCallingConfig: &google.FunctionCallingConfig{
+				Mode:                 "ANY",
+				AllowedFunctionNames: []string{name},
+			}}
+		}
+	}
+
+	if sampling := req.Sampling.Clamp(googleSamplingCapability); !sampling.IsEmpty() {
+		googleReq.GenerationConfig = &google.GenerationConfig{
+			StopSequences: sampling.Stop,
+			Temperature:   sampling.TemperatureValue(),
+			TopP:          sampling.TopPValue(),
+		}
+	}
+
 	return &googleReq, nil
 }
 
+// googleFunctionResponse 函数调用结果必须为 JSON 对象，非 JSON 对象时包装为 {"content": "..."}
+func googleFunctionResponse(content string) json.RawMessage {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(content), &obj); err == nil {
+		return json.RawMessage(content)
+	}
+
+	data, _ := json.Marshal(map[string]string{"content": content})
+	return data
+}
+
+// googleToolCalls 将 Gemini 的函数调用转换为工具调用，Gemini 不支持调用 ID，这里自动生成
+func googleToolCalls(calls []google.FunctionCall, offset int) []ToolCall {
+	return array.Map(calls, func(call google.FunctionCall, i int) ToolCall {
+		index := offset + i
+		return ToolCall{
+			Index:    &index,
+			ID:       fmt.Sprintf("call_%s_%d", call.Name, index),
+			Type:     ToolTypeFunction,
+			Function: FunctionCall{Name: call.Name, Arguments: string(call.Args)},
+		}
+	})
+}
+
 func (chat *GoogleChat) Chat(ctx context.Context, req Request) (*Response, error) {
 	googleReq, err := chat.initRequest(req)
 	if err != nil {
@@ -130,7 +243,17 @@ func (chat *GoogleChat) Chat(ctx context.Context, req Request) (*Response, error
 		resText += "\n\n> 注意：当前模型不支持多轮对话，对话结束"
 	}
 
-	return &Response{Text: resText}, nil
+	resp := &Response{Text: resText}
+	if res.UsageMetadata != nil {
+		resp.InputTokens, resp.OutputTokens = res.UsageMetadata.PromptTokenCount, res.UsageMetadata.CandidatesTokenCount
+	}
+
+	if calls := res.FunctionCalls(); len(calls) > 0 {
+		resp.ToolCalls = googleToolCalls(calls, 0)
+		resp.FinishReason = "tool_calls"
+	}
+
+	return resp, nil
 }
 
 func (chat *GoogleChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
@@ -170,6 +293,7 @@ func (chat *GoogleChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 			close(res)
 		}()
 
+		var toolCallCount int
 		for {
 			select {
 			case <-ctx.Done():
@@ -187,9 +311,20 @@ func (chat *GoogleChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 					return
 				}
 
+				resp := Response{Text: data.String()}
+				if data.UsageMetadata != nil {
+					resp.InputTokens, resp.OutputTokens = data.UsageMetadata.PromptTokenCount, data.UsageMetadata.CandidatesTokenCount
+				}
+
+				if calls := data.FunctionCalls(); len(calls) > 0 {
+					resp.ToolCalls = googleToolCalls(calls, toolCallCount)
+					resp.FinishReason = "tool_calls"
+					toolCallCount += len(calls)
+				}
+
 				select {
 				case <-ctx.Done():
-				case res <- Response{Text: data.String()}:
+				case res <- resp:
 				}
 			}
 		}
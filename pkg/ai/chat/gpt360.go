@@ -9,6 +9,21 @@ import (
 	"github.com/mylxsw/go-utils/array"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "gpt360",
+		Aliases:  []string{"360智脑"},
+		Prefixes: []string{"360智脑:"},
+		Models:   []string{gpt360.Model360GPT_S2_V9},
+	}, func(ai *AI) Chat { return ai.GPT360 })
+}
+
+// gpt360SamplingCapability 360 智脑支持的采样参数
+var gpt360SamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 1},
+}
+
 type GPT360Chat struct {
 	g360 *gpt360.GPT360
 }
@@ -27,9 +42,12 @@ func (ds *GPT360Chat) initRequest(req Request) gpt360.ChatRequest {
 		}
 	})
 
+	sampling := req.Sampling.Clamp(gpt360SamplingCapability)
 	return gpt360.ChatRequest{
-		Model:    strings.TrimPrefix(req.Model, "360智脑:"),
-		Messages: messages,
+		Model:       strings.TrimPrefix(req.Model, "360智脑:"),
+		Messages:    messages,
+		Temperature: sampling.TemperatureValue(),
+		TopP:        sampling.TopPValue(),
 	}
 }
 
@@ -0,0 +1,271 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/local"
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	"github.com/mylxsw/aidea-server/pkg/uploader"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/go-utils/array"
+)
+
+const localChannelName = "local"
+
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     localChannelName,
+		Aliases:  []string{local.APIOllama, local.APILlamaCpp, "本地模型"},
+		Prefixes: []string{"local:"},
+		Match:    isLocalModel,
+	}, func(ai *AI) Chat { return ai.Local })
+}
+
+// isLocalModel 不带前缀的模型名称是否由本地渠道处理
+// 与内置价格表中的云端模型同名时（如 llama.cpp 中常见的 gpt-3.5-turbo 别名），不带前缀的名称仍然由云端渠道处理，本地模型需要使用 local: 前缀访问
+func isLocalModel(model string) bool {
+	if coins.HasBuiltinTextPrice(model) {
+		return false
+	}
+
+	return array.In(model, array.Map(DiscoveredModels(localChannelName), func(item Model, _ int) string {
+		return item.RealID()
+	}))
+}
+
+// localFeeModel 本地模型使用带渠道前缀的模型 ID 计费，避免本地模型的价格影响同名的云端模型
+func localFeeModel(model string) string {
+	if isLocalModel(model) {
+		return localChannelName + ":" + model
+	}
+
+	return model
+}
+
+// LocalChat 本地推理服务（Ollama/llama.cpp server），模型列表从推理服务中自动发现
+type LocalChat struct {
+	ai   *local.LocalAI
+	conf *config.Config
+	// prices 单独配置了价格的模型，key 为模型名称（不含 local: 前缀）
+	prices map[string]int64
+}
+
+func NewLocalChat(ai *local.LocalAI, conf *config.Config) *LocalChat {
+	prices := make(map[string]int64)
+	for _, item := range conf.LocalPrices {
+		// 模型名称中可能包含 =，使用最后一个 = 分割
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 {
+			log.Warningf("invalid local model price: %s", item)
+			continue
+		}
+
+		price, err := strconv.ParseInt(strings.TrimSpace(item[idx+1:]), 10, 64)
+		if err != nil || price < 0 {
+			log.Warningf("invalid local model price: %s", item)
+			continue
+		}
+
+		prices[strings.TrimPrefix(strings.TrimSpace(item[:idx]), localChannelName+":")] = price
+	}
+
+	return &LocalChat{ai: ai, conf: conf, prices: prices}
+}
+
+// price 本地模型每 1K Token 的价格，未单独配置时使用 local-price
+func (lc *LocalChat) price(model string) int64 {
+	if price, ok := lc.prices[model]; ok {
+		return price
+	}
+
+	return lc.conf.LocalPrice
+}
+
+// Discover 从本地推理服务中加载模型列表，并设置模型的价格
+func (lc *LocalChat) Discover(ctx context.Context) error {
+	models, err := lc.ai.Models(ctx)
+	if err != nil {
+		return fmt.Errorf("discover local models failed: %w", err)
+	}
+
+	SetDiscoveredModels(localChannelName, array.Map(models, func(item local.ModelInfo, _ int) Model {
+		coins.SetTextPrice(localChannelName+":"+item.Name, lc.price(item.Name))
+
+		return Model{
+			ID:            localChannelName + ":" + item.Name,
+			Name:          item.Name,
+			ShortName:     item.Name,
+			Description:   "部署在本地推理服务中的模型",
+			Category:      localChannelName,
+			IsChat:        true,
+			SupportVision: item.Vision && lc.ai.SupportVision(),
+			ContextWindow: lc.conf.LocalContextLength,
+		}
+	}))
+
+	return nil
+}
+
+// Watch 定时刷新本地推理服务的模型列表，interval 为 0 时只加载一次
+func (lc *LocalChat) Watch(ctx context.Context, interval time.Duration) {
+	if err := lc.Discover(ctx); err != nil {
+		log.Errorf("%v", err)
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := lc.Discover(ctx); err != nil {
+				log.Errorf("%v", err)
+			}
+		}
+	}
+}
+
+func (lc *LocalChat) initRequest(ctx context.Context, req Request) local.ChatRequest {
+	req.Model = strings.TrimPrefix(req.Model, localChannelName+":")
+	req.Messages = req.Messages.Fix()
+
+	messages := array.Map(req.Messages, func(item Message, _ int) local.Message {
+		msg := local.Message{Role: item.Role, Content: item.Content}
+		if len(item.MultipartContents) == 0 {
+			return msg
+		}
+
+		var texts []string
+		for _, ct := range item.MultipartContents {
+			if ct.Text != "" {
+				texts = append(texts, ct.Text)
+			} else if ct.ImageURL != nil && lc.ai.SupportVision() {
+				if image, err := lc.encodeImage(ctx, ct.ImageURL.URL); err != nil {
+					log.With(err).Errorf("load image failed: %s", ct.ImageURL.URL)
+				} else {
+					msg.Images = append(msg.Images, image)
+				}
+			}
+		}
+
+		if len(texts) > 0 {
+			msg.Content = strings.Join(texts, "\n")
+		}
+
+		return msg
+	})
+
+	sampling := req.Sampling.Clamp(openAISamplingCapability)
+	return local.ChatRequest{
+		Model:    req.Model,
+		Messages: messages,
+		Options: local.Options{
+			Temperature:      sampling.Temperature,
+			TopP:             sampling.TopP,
+			Stop:             sampling.Stop,
+			Seed:             sampling.Seed,
+			PresencePenalty:  sampling.PresencePenalty,
+			FrequencyPenalty: sampling.FrequencyPenalty,
+			NumPredict:       req.MaxTokens,
+			NumCtx:           lc.conf.LocalContextLength,
+		},
+	}
+}
+
+// encodeImage 将图片地址或者 base64 编码的图片转换为不含 data URL 前缀的 base64 编码
+func (lc *LocalChat) encodeImage(ctx context.Context, imageURL string) (string, error) {
+	if strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		encoded, _, err := uploader.DownloadRemoteFileAsBase64Raw(ctx, imageURL, true)
+		return encoded, err
+	}
+
+	data, _, err := misc.DecodeBase64ImageWithMime(imageURL)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (lc *LocalChat) Chat(ctx context.Context, req Request) (*Response, error) {
+	resp, err := lc.ai.Chat(ctx, lc.initRequest(ctx, req))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("local chat error: %s", resp.Error)
+	}
+
+	return &Response{
+		Text:         resp.Message.Content,
+		FinishReason: resp.DoneReason,
+		InputTokens:  resp.PromptEvalCount,
+		OutputTokens: resp.EvalCount,
+	}, nil
+}
+
+func (lc *LocalChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	stream, err := lc.ai.ChatStream(ctx, lc.initRequest(ctx, req))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(chan Response)
+	go func() {
+		defer close(res)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				if data.Error != "" {
+					select {
+					case <-ctx.Done():
+					case res <- Response{Error: data.Error, ErrorCode: "LOCAL_ERROR"}:
+					}
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case res <- Response{
+					Text:         data.Message.Content,
+					FinishReason: data.DoneReason,
+					InputTokens:  data.PromptEvalCount,
+					OutputTokens: data.EvalCount,
+				}:
+				}
+			}
+		}
+	}()
+
+	return res, nil
+}
+
+func (lc *LocalChat) MaxContextLength(model string) int {
+	if lc.conf.LocalContextLength > 0 {
+		return lc.conf.LocalContextLength
+	}
+
+	return 4096
+}
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/local"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestLocalChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = fmt.Fprint(w, `{"models":[{"name":"llama2:latest","details":{"families":["llama"]}},{"name":"llava:latest","details":{"families":["llama","clip"]}},{"name":"gpt-3.5-turbo","details":{"families":["llama"]}}]}`)
+		case "/api/chat":
+			_, _ = fmt.Fprint(w, `{"message":{"role":"assistant","content":"Hello"},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":2}`)
+		}
+	}))
+	defer server.Close()
+	defer SetDiscoveredModels(localChannelName, nil)
+
+	conf := &config.Config{EnableLocal: true, LocalContextLength: 8192, LocalPrices: []string{"llava:latest=5", "invalid"}}
+	lc := NewLocalChat(local.New(local.APIOllama, server.URL), conf)
+	assert.NoError(t, lc.Discover(context.TODO()))
+
+	models := localModels(conf)
+	assert.Equal(t, 3, len(models))
+	assert.Equal(t, "local:llama2:latest", models[0].ID)
+	assert.False(t, models[0].SupportVision)
+	assert.True(t, models[1].SupportVision)
+	assert.Equal(t, 8192, models[1].ContextWindow)
+	assert.Equal(t, 0, len(localModels(&config.Config{})))
+
+	// 本地模型的价格使用带前缀的模型 ID，不影响同名的云端模型
+	assert.True(t, coins.IsZeroPriceModel("local:llama2:latest"))
+	assert.True(t, coins.IsZeroPriceModel("local:gpt-3.5-turbo"))
+	assert.False(t, coins.IsZeroPriceModel("gpt-3.5-turbo"))
+	assert.EqualValues(t, 5, coins.GetTextCoins("local:llava:latest", 1000, 0))
+	assert.Equal(t, "local:llama2:latest", Request{Model: "llama2:latest"}.ResolveCalFeeModel(conf))
+	assert.Equal(t, "gpt-3.5-turbo", Request{Model: "gpt-3.5-turbo"}.ResolveCalFeeModel(conf))
+
+	// 发现的模型路由到本地渠道
+	registry := NewRegistry("openai")
+	for _, ch := range builtinChannels {
+		if ch.channel.Name == localChannelName {
+			registry.Register(ch.channel, lc)
+		}
+	}
+	assert.Equal(t, localChannelName, registry.Resolve("llava:latest"))
+	assert.Equal(t, localChannelName, registry.Resolve("local:mistral:latest"))
+	assert.Equal(t, "openai", registry.Resolve("gpt-4"))
+	assert.Equal(t, "openai", registry.Resolve("gpt-3.5-turbo"))
+	assert.Equal(t, localChannelName, registry.Resolve("local:gpt-3.5-turbo"))
+
+	resp, err := lc.Chat(context.TODO(), Request{Model: "llama2:latest", Messages: Messages{{Role: "user", Content: "hi"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Text)
+	assert.Equal(t, 10, resp.InputTokens)
+	assert.Equal(t, 2, resp.OutputTokens)
+	assert.Equal(t, 8192, lc.MaxContextLength("llama2:latest"))
+}
@@ -1,9 +1,12 @@
 package chat
 
 import (
+	"sync"
+
 	"github.com/mylxsw/aidea-server/pkg/ai/anthropic"
 	"github.com/mylxsw/aidea-server/pkg/ai/baichuan"
 	"github.com/mylxsw/aidea-server/pkg/ai/baidu"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
 	"github.com/mylxsw/aidea-server/pkg/ai/google"
 	"github.com/mylxsw/aidea-server/pkg/ai/gpt360"
@@ -11,42 +14,15 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/ai/tencentai"
 	"github.com/mylxsw/aidea-server/pkg/ai/xfyun"
 	"github.com/mylxsw/go-utils/str"
-	"strings"
 
 	"github.com/mylxsw/aidea-server/config"
 	"github.com/mylxsw/go-utils/array"
 )
 
-type Model struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	ShortName   string `json:"short_name"`
-	Description string `json:"description"`
-	AvatarURL   string `json:"avatar_url,omitempty"`
-	Category    string `json:"category"`
-	IsImage     bool   `json:"is_image"`
-	Disabled    bool   `json:"disabled"`
-	VersionMin  string `json:"version_min,omitempty"`
-	VersionMax  string `json:"version_max,omitempty"`
-	Tag         string `json:"tag,omitempty"`
-
-	IsChat        bool `json:"is_chat"`
-	SupportVision bool `json:"support_vision,omitempty"`
-}
-
-func (m Model) RealID() string {
-	segs := strings.SplitN(m.ID, ":", 2)
-	return segs[1]
-}
-
-func (m Model) IsSensitiveModel() bool {
-	return m.Category == "openai" || m.Category == "Anthropic" || m.Category == "google"
-}
-
-func (m Model) IsVirtualModel() bool {
-	return m.Category == "virtual"
-}
+// Model 模型的元数据以及能力描述
+type Model = catalog.Model
 
+// Models 返回所有模型，内置的模型定义会被模型目录中的定义覆盖
 func Models(conf *config.Config, returnAll bool) []Model {
 	var models []Model
 	models = append(models, openAIModels(conf)...)
@@ -54,6 +30,10 @@ func Models(conf *config.Config, returnAll bool) []Model {
 	models = append(models, googleModels(conf)...)
 	models = append(models, chinaModels(conf)...)
 	models = append(models, aideaModels(conf)...)
+	models = append(models, openAIChannelModels(conf)...)
+	models = append(models, localModels(conf)...)
+	models = append(models, fakeModels(conf)...)
+	models = catalog.Default().Apply(models)
 
 	return array.Filter(
 		array.Map(models, func(item Model, _ int) Model {
@@ -577,6 +557,7 @@ func aideaModels(conf *config.Config) []Model {
 			Disabled:    !conf.EnableVirtualModel,
 			VersionMin:  "1.0.5",
 			AvatarURL:   "https://ssl.aicode.cc/ai-server/assets/avatar/nanxian.png",
+			Virtual:     virtualModelOf(conf, ModelNanXian),
 		},
 		{
 			ID:          "virtual:beichou",
@@ -588,6 +569,68 @@ func aideaModels(conf *config.Config) []Model {
 			Disabled:    !conf.EnableVirtualModel,
 			VersionMin:  "1.0.5",
 			AvatarURL:   "https://ssl.aicode.cc/ai-server/assets/avatar/nanxian.png",
+			Virtual:     virtualModelOf(conf, ModelBeiChou),
+		},
+	}
+}
+
+// localModels 从本地推理服务中发现的模型
+func localModels(conf *config.Config) []Model {
+	if !conf.EnableLocal {
+		return nil
+	}
+
+	return DiscoveredModels(localChannelName)
+}
+
+// fakeModels 模拟的聊天模型，用于开发和测试
+func fakeModels(conf *config.Config) []Model {
+	if !conf.EnableFake {
+		return nil
+	}
+
+	return []Model{
+		{
+			ID:          ModelFakeEcho,
+			Name:        "Fake Echo",
+			Description: "模拟的聊天模型，不请求任何外部服务，用于开发和测试",
+			Category:    fakeChannelName,
+			IsChat:      true,
 		},
 	}
 }
+
+// discoveredModels 从渠道服务端发现的模型，key 为渠道名称
+var discoveredModels = struct {
+	sync.RWMutex
+	channels map[string][]Model
+}{channels: make(map[string][]Model)}
+
+// SetDiscoveredModels 设置从渠道服务端发现的模型列表，同一渠道重复设置时覆盖之前的结果
+func SetDiscoveredModels(channel string, models []Model) {
+	discoveredModels.Lock()
+	defer discoveredModels.Unlock()
+
+	discoveredModels.channels[channel] = models
+}
+
+// DiscoveredModels 返回从指定渠道服务端发现的模型列表
+func DiscoveredModels(channel string) []Model {
+	discoveredModels.RLock()
+	defer discoveredModels.RUnlock()
+
+	return append([]Model(nil), discoveredModels.channels[channel]...)
+}
+
+// ContextWindow 返回模型的上下文窗口大小，模型目录中未配置时使用渠道的默认值，模型没有可用的渠道时返回 0
+func ContextWindow(c Chat, model Model) int {
+	if model.ContextWindow > 0 {
+		return model.ContextWindow
+	}
+
+	if imp, ok := c.(*Imp); ok && imp.selectImp(model.RealID()) == nil {
+		return 0
+	}
+
+	return c.MaxContextLength(model.RealID())
+}
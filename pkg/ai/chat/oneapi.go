@@ -11,6 +11,14 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "oneapi",
+		Prefixes: []string{"oneapi:"},
+		Models:   []string{"chatglm_turbo", "chatglm_pro", "chatglm_lite", "chatglm_std", "PaLM-2"},
+	}, func(ai *AI) Chat { return ai.OneAPI })
+}
+
 type OneAPIChat struct {
 	oai *oneapi.OneAPI
 }
@@ -50,11 +58,14 @@ func (chat *OneAPIChat) initRequest(req Request) (*openai.ChatCompletionRequest,
 	messages := append(systemMessages, msgs...)
 	req.Model = oai.SelectBestModel(req.Model, tokenCount)
 
-	return &openai.ChatCompletionRequest{
+	openaiReq := &openai.ChatCompletionRequest{
 		Model:     req.Model,
 		Messages:  messages,
 		MaxTokens: req.MaxTokens,
-	}, nil
+	}
+
+	applyOpenAISampling(openaiReq, req.Sampling)
+	return openaiReq, nil
 }
 
 func (chat *OneAPIChat) Chat(ctx context.Context, req Request) (*Response, error) {
@@ -130,6 +141,12 @@ func (chat *OneAPIChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 					return
 				}
 
+				// 流的最后一个响应中返回服务商上报的用量
+				if data.Usage != nil {
+					res <- Response{InputTokens: data.Usage.PromptTokens, OutputTokens: data.Usage.CompletionTokens}
+					continue
+				}
+
 				res <- Response{
 					Text: array.Reduce(
 						data.ChatResponse.Choices,
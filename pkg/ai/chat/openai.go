@@ -2,17 +2,52 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/mylxsw/aidea-server/pkg/ai/control"
 	openai2 "github.com/mylxsw/aidea-server/pkg/ai/openai"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
+	"math"
 	"strings"
 
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/ternary"
 	"github.com/sashabaranov/go-openai"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "openai",
+		Prefixes: []string{"openai:"},
+	}, func(ai *AI) Chat { return ai.OpenAI })
+
+	// 备用 OpenAI 服务，默认不处理任何模型，用于故障转移规则中，未配置备用服务时不注册
+	registerBuiltinChannel(Channel{
+		Name: "openai-backup",
+	}, func(ai *AI) Chat { return ai.OpenAIBackup })
+}
+
+// openAIBackupChat 优先使用备用 OpenAI 服务进行对话
+type openAIBackupChat struct {
+	imp Chat
+}
+
+func (chat *openAIBackupChat) Chat(ctx context.Context, req Request) (*Response, error) {
+	return chat.imp.Chat(control.NewContext(ctx, &control.Control{PreferBackup: true}), req)
+}
+
+func (chat *openAIBackupChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
+	return chat.imp.ChatStream(control.NewContext(ctx, &control.Control{PreferBackup: true}), req)
+}
+
+func (chat *openAIBackupChat) MaxContextLength(model string) int {
+	return chat.imp.MaxContextLength(model)
+}
+
 type OpenAIChat struct {
 	oai openai2.Client
+	// contextSize 模型的最大上下文长度，为空时使用 OpenAI 模型的上下文长度
+	contextSize func(model string) int
 }
 
 func NewOpenAIChat(oai openai2.Client) *OpenAIChat {
@@ -27,8 +62,11 @@ func (chat *OpenAIChat) initRequest(req Request) (*openai.ChatCompletionRequest,
 
 	for _, msg := range req.Messages {
 		m := openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+			Role:       msg.Role,
+			Content:    msg.Content,
+			Name:       msg.Name,
+			ToolCallID: msg.ToolCallID,
+			ToolCalls:  array.Map(msg.ToolCalls, func(item ToolCall, _ int) openai.ToolCall { return toOpenAIToolCall(item) }),
 		}
 
 		if len(msg.MultipartContents) > 0 {
@@ -74,7 +112,7 @@ func (chat *OpenAIChat) initRequest(req Request) (*openai.ChatCompletionRequest,
 	msgs, tokenCount, err := openai2.ReduceChatCompletionMessages(
 		contextMessages,
 		req.Model,
-		openai2.ModelMaxContextSize(req.Model),
+		chat.MaxContextLength(req.Model),
 	)
 	if err != nil {
 		return nil, err
@@ -83,11 +121,85 @@ func (chat *OpenAIChat) initRequest(req Request) (*openai.ChatCompletionRequest,
 	messages := append(systemMessages, msgs...)
 	req.Model = openai2.SelectBestModel(req.Model, tokenCount)
 
-	return &openai.ChatCompletionRequest{
-		Model:     req.Model,
-		Messages:  messages,
-		MaxTokens: req.MaxTokens,
-	}, nil
+	openaiReq := &openai.ChatCompletionRequest{
+		Model:      req.Model,
+		Messages:   messages,
+		MaxTokens:  req.MaxTokens,
+		Tools:      toOpenAITools(req.Tools),
+		ToolChoice: req.ToolChoiceRaw,
+	}
+
+	applyOpenAISampling(openaiReq, req.Sampling)
+	return openaiReq, nil
+}
+
+// applyOpenAISampling 设置 OpenAI 请求的采样参数
+func applyOpenAISampling(openaiReq *openai.ChatCompletionRequest, sampling Sampling) {
+	sampling = sampling.Clamp(openAISamplingCapability)
+
+	openaiReq.Temperature = openAIFloat(sampling.Temperature)
+	openaiReq.TopP = openAIFloat(sampling.TopP)
+	openaiReq.Stop = sampling.Stop
+	openaiReq.Seed = sampling.Seed
+	openaiReq.PresencePenalty = openAIFloat(sampling.PresencePenalty)
+	openaiReq.FrequencyPenalty = openAIFloat(sampling.FrequencyPenalty)
+}
+
+// openAIFloat 转换为 OpenAI 请求参数，请求结构体使用了 omitempty，值为 0 时使用最小的非零值代替，避免参数被忽略
+func openAIFloat(v *float64) float32 {
+	if v == nil {
+		return 0
+	}
+
+	if *v == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+
+	return float32(*v)
+}
+
+func toOpenAITools(tools []Tool) []openai.Tool {
+	return array.Map(tools, func(item Tool, _ int) openai.Tool {
+		tool := openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionDefinition{
+				Name:        item.Function.Name,
+				Description: item.Function.Description,
+			},
+		}
+
+		if len(item.Function.Parameters) > 0 {
+			tool.Function.Parameters = item.Function.Parameters
+		} else {
+			tool.Function.Parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+
+		return tool
+	})
+}
+
+func toOpenAIToolCall(call ToolCall) openai.ToolCall {
+	return openai.ToolCall{
+		Index: call.Index,
+		ID:    call.ID,
+		Type:  openai.ToolType(ternary.If(call.Type == "", ToolTypeFunction, call.Type)),
+		Function: openai.FunctionCall{
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		},
+	}
+}
+
+func fromOpenAIToolCall(call openai.ToolCall, _ int) ToolCall {
+	return ToolCall{
+		Index: call.Index,
+		ID:    call.ID,
+		Type:  string(call.Type),
+		Function: FunctionCall{
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		},
+	}
 }
 
 func (chat *OpenAIChat) Chat(ctx context.Context, req Request) (*Response, error) {
@@ -106,7 +218,7 @@ func (chat *OpenAIChat) Chat(ctx context.Context, req Request) (*Response, error
 		return nil, err
 	}
 
-	return &Response{
+	resp := &Response{
 		Text: array.Reduce(
 			res.Choices,
 			func(carry string, item openai.ChatCompletionChoice) string {
@@ -116,7 +228,14 @@ func (chat *OpenAIChat) Chat(ctx context.Context, req Request) (*Response, error
 		),
 		InputTokens:  res.Usage.PromptTokens,
 		OutputTokens: res.Usage.CompletionTokens,
-	}, nil
+	}
+
+	if len(res.Choices) > 0 {
+		resp.FinishReason = string(res.Choices[0].FinishReason)
+		resp.ToolCalls = array.Map(res.Choices[0].Message.ToolCalls, fromOpenAIToolCall)
+	}
+
+	return resp, nil
 }
 
 func (chat *OpenAIChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
@@ -163,7 +282,13 @@ func (chat *OpenAIChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 					return
 				}
 
-				res <- Response{
+				// 流的最后一个响应中返回服务商上报的用量
+				if data.Usage != nil {
+					res <- Response{InputTokens: data.Usage.PromptTokens, OutputTokens: data.Usage.CompletionTokens}
+					continue
+				}
+
+				resp := Response{
 					Text: array.Reduce(
 						data.ChatResponse.Choices,
 						func(carry string, item openai.ChatCompletionStreamChoice) string {
@@ -172,6 +297,13 @@ func (chat *OpenAIChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 						"",
 					),
 				}
+
+				if len(data.ChatResponse.Choices) > 0 {
+					resp.FinishReason = string(data.ChatResponse.Choices[0].FinishReason)
+					resp.ToolCalls = array.Map(data.ChatResponse.Choices[0].Delta.ToolCalls, fromOpenAIToolCall)
+				}
+
+				res <- resp
 			}
 		}
 
@@ -181,5 +313,9 @@ func (chat *OpenAIChat) ChatStream(ctx context.Context, req Request) (<-chan Res
 }
 
 func (chat *OpenAIChat) MaxContextLength(model string) int {
+	if chat.contextSize != nil {
+		return chat.contextSize(model)
+	}
+
 	return openai2.ModelMaxContextSize(model)
 }
@@ -10,6 +10,14 @@ import (
 	"strings"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "openrouter",
+		Prefixes: []string{"openrouter:"},
+		Match:    openrouter.SupportModel,
+	}, func(ai *AI) Chat { return ai.Openrouter })
+}
+
 type OpenRouterChat struct {
 	oai *openrouter.OpenRouter
 }
@@ -49,11 +57,14 @@ func (chat *OpenRouterChat) initRequest(req Request) (*openai.ChatCompletionRequ
 	messages := append(systemMessages, msgs...)
 	req.Model = oai.SelectBestModel(req.Model, tokenCount)
 
-	return &openai.ChatCompletionRequest{
+	openaiReq := &openai.ChatCompletionRequest{
 		Model:     req.Model,
 		Messages:  messages,
 		MaxTokens: req.MaxTokens,
-	}, nil
+	}
+
+	applyOpenAISampling(openaiReq, req.Sampling)
+	return openaiReq, nil
 }
 
 func (chat *OpenRouterChat) Chat(ctx context.Context, req Request) (*Response, error) {
@@ -129,6 +140,12 @@ func (chat *OpenRouterChat) ChatStream(ctx context.Context, req Request) (<-chan
 					return
 				}
 
+				// 流的最后一个响应中返回服务商上报的用量
+				if data.Usage != nil {
+					res <- Response{InputTokens: data.Usage.PromptTokens, OutputTokens: data.Usage.CompletionTokens}
+					continue
+				}
+
 				res <- Response{
 					Text: array.Reduce(
 						data.ChatResponse.Choices,
@@ -0,0 +1,304 @@
+package chat
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mylxsw/asteria/log"
+)
+
+// 路由策略中的用户等级
+const (
+	PolicyTierAnonymous = "anonymous"
+	PolicyTierFree      = "free"
+	PolicyTierPaid      = "paid"
+	PolicyTierInternal  = "internal"
+)
+
+// policyCheapestChannel 路由到模型可用渠道中成本最低的渠道
+const policyCheapestChannel = "cheapest"
+
+// PolicyInput 路由策略的判断依据
+type PolicyInput struct {
+	// Model 请求的模型
+	Model string
+	// Tier 用户等级：anonymous/free/paid/internal
+	Tier string
+	// Tokens 请求上下文的 Token 数量
+	Tokens int
+}
+
+// PolicyDecision 路由策略的执行结果
+type PolicyDecision struct {
+	// Rule 命中的规则
+	Rule string `json:"rule"`
+	// OriginalModel 请求的原始模型
+	OriginalModel string `json:"original_model"`
+	// Model 路由后的模型
+	Model string `json:"model"`
+	// Channel 优先使用的渠道，为空时按照默认规则选择渠道
+	Channel string `json:"channel,omitempty"`
+	// Notice 展示给用户的提示信息
+	Notice string `json:"notice,omitempty"`
+}
+
+type policyCondition struct {
+	field string
+	op    string
+	value string
+}
+
+func (c policyCondition) match(input PolicyInput) bool {
+	switch c.field {
+	case "tier":
+		if c.op == "!=" {
+			return input.Tier != c.value
+		}
+
+		return input.Tier == c.value
+	case "tokens":
+		value, _ := strconv.Atoi(c.value)
+		switch c.op {
+		case ">":
+			return input.Tokens > value
+		case ">=":
+			return input.Tokens >= value
+		case "<":
+			return input.Tokens < value
+		case "<=":
+			return input.Tokens <= value
+		case "!=":
+			return input.Tokens != value
+		default:
+			return input.Tokens == value
+		}
+	}
+
+	return false
+}
+
+type policyRule struct {
+	raw        string
+	model      string
+	conditions []policyCondition
+	// target 路由后的模型，为空时不改变模型
+	target string
+	// channel 优先使用的渠道，cheapest 表示成本最低的可用渠道
+	channel string
+	notice  string
+}
+
+// matchModel 模型匹配，支持 * 结尾的前缀匹配
+func (r policyRule) matchModel(model string) bool {
+	if strings.HasSuffix(r.model, "*") {
+		return strings.HasPrefix(model, strings.TrimSuffix(r.model, "*"))
+	}
+
+	return r.model == model
+}
+
+// Policy 模型路由策略，在选择渠道之前根据用户等级、上下文长度等信息改写请求的模型或者渠道
+type Policy struct {
+	rules    []policyRule
+	failover *Failover
+	// costs 渠道的相对成本，未配置的渠道成本为 1
+	costs map[string]float64
+}
+
+// NewPolicy 创建路由策略，failover 用于查询模型的可用渠道，costs 为渠道的相对成本，格式为 `渠道=成本`
+func NewPolicy(failover *Failover, costs []string) *Policy {
+	p := &Policy{failover: failover, costs: make(map[string]float64)}
+	for _, item := range costs {
+		segs := strings.SplitN(item, "=", 2)
+		if len(segs) != 2 {
+			log.Warningf("invalid chat channel cost: %s", item)
+			continue
+		}
+
+		cost, err := strconv.ParseFloat(strings.TrimSpace(segs[1]), 64)
+		if err != nil {
+			log.Warningf("invalid chat channel cost %s: %v", item, err)
+			continue
+		}
+
+		p.costs[strings.TrimSpace(segs[0])] = cost
+	}
+
+	return p
+}
+
+// LoadRules 加载路由规则，格式为 `模型 [if 条件 && 条件] => [目标模型] [@渠道] [| 提示信息]`，规则按照顺序匹配，只有第一个命中的规则生效
+//
+//	gpt-4 if tier=free => gpt-3.5-turbo | 免费用户暂不支持 GPT-4，已为您切换到 GPT-3.5
+//	gpt-3.5-turbo if tokens>3000 => gpt-3.5-turbo-16k
+//	qwen-max => @cheapest
+func (p *Policy) LoadRules(rules []string) {
+	for _, raw := range rules {
+		rule, err := parsePolicyRule(raw)
+		if err != nil {
+			log.Warningf("invalid chat policy %s: %v", raw, err)
+			continue
+		}
+
+		p.rules = append(p.rules, rule)
+	}
+}
+
+func parsePolicyRule(raw string) (policyRule, error) {
+	rule := policyRule{raw: strings.TrimSpace(raw)}
+
+	segs := strings.SplitN(raw, "=>", 2)
+	if len(segs) != 2 {
+		return rule, fmt.Errorf("missing =>")
+	}
+
+	matcher := strings.SplitN(strings.TrimSpace(segs[0]), " if ", 2)
+	rule.model = strings.TrimSpace(matcher[0])
+	if rule.model == "" {
+		return rule, fmt.Errorf("missing model")
+	}
+
+	if len(matcher) > 1 {
+		for _, item := range strings.Split(matcher[1], "&&") {
+			cond, err := parsePolicyCondition(strings.TrimSpace(item))
+			if err != nil {
+				return rule, err
+			}
+
+			rule.conditions = append(rule.conditions, cond)
+		}
+	}
+
+	action := strings.SplitN(segs[1], "|", 2)
+	if len(action) > 1 {
+		rule.notice = strings.TrimSpace(action[1])
+	}
+
+	for _, item := range strings.Fields(action[0]) {
+		if strings.HasPrefix(item, "@") {
+			rule.channel = strings.TrimPrefix(item, "@")
+		} else {
+			rule.target = item
+		}
+	}
+
+	if rule.target == "" && rule.channel == "" {
+		return rule, fmt.Errorf("missing target model or channel")
+	}
+
+	return rule, nil
+}
+
+func parsePolicyCondition(expr string) (policyCondition, error) {
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if idx := strings.Index(expr, op); idx > 0 {
+			cond := policyCondition{
+				field: strings.TrimSpace(expr[:idx]),
+				op:    op,
+				value: strings.TrimSpace(expr[idx+len(op):]),
+			}
+
+			switch cond.field {
+			case "tier":
+				if op != "=" && op != "!=" {
+					return cond, fmt.Errorf("unsupported operator %s for tier", op)
+				}
+			case "tokens":
+				if _, err := strconv.Atoi(cond.value); err != nil {
+					return cond, fmt.Errorf("invalid tokens value: %s", cond.value)
+				}
+			default:
+				return cond, fmt.Errorf("unsupported condition: %s", cond.field)
+			}
+
+			return cond, nil
+		}
+	}
+
+	return policyCondition{}, fmt.Errorf("invalid condition: %s", expr)
+}
+
+// UsesTier 是否有规则依赖用户等级，用户等级需要查询数据库，没有规则依赖时可以不查询
+func (p *Policy) UsesTier() bool {
+	for _, rule := range p.rules {
+		for _, cond := range rule.conditions {
+			if cond.field == "tier" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Evaluate 执行路由策略，没有命中的规则（或者命中的规则没有改变请求）时返回 nil
+func (p *Policy) Evaluate(input PolicyInput) *PolicyDecision {
+	for _, rule := range p.rules {
+		if !rule.matchModel(input.Model) || !matchConditions(rule.conditions, input) {
+			continue
+		}
+
+		decision := PolicyDecision{Rule: rule.raw, OriginalModel: input.Model, Model: input.Model, Notice: rule.notice}
+		if rule.target != "" {
+			decision.Model = rule.target
+		}
+
+		decision.Channel = rule.channel
+		if rule.channel == policyCheapestChannel {
+			decision.Channel = p.cheapest(decision.Model)
+		}
+
+		if decision.Model == input.Model && decision.Channel == "" {
+			continue
+		}
+
+		if decision.Notice == "" && decision.Model != input.Model {
+			decision.Notice = fmt.Sprintf("本次请求已由 %s 模型回答。", decision.Model)
+		}
+
+		return &decision
+	}
+
+	return nil
+}
+
+func matchConditions(conditions []policyCondition, input PolicyInput) bool {
+	for _, cond := range conditions {
+		if !cond.match(input) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cheapest 返回模型可用渠道中成本最低的渠道，没有可选渠道时返回空
+func (p *Policy) cheapest(model string) string {
+	if p.failover == nil {
+		return ""
+	}
+
+	var selected string
+	lowest := math.MaxFloat64
+	for _, channel := range p.failover.HealthyChannels(model) {
+		cost, ok := p.costs[channel]
+		if !ok {
+			cost = 1
+		}
+
+		if cost < lowest {
+			selected, lowest = channel, cost
+		}
+	}
+
+	return selected
+}
+
+// Apply 将路由结果应用到请求中
+func (d *PolicyDecision) Apply(req Request) Request {
+	req.Model = d.Model
+	req.Channel = d.Channel
+	return req
+}
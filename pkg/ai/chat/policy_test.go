@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy := NewPolicy(nil, nil)
+	policy.LoadRules([]string{
+		"gpt-4 if tier=free => gpt-3.5-turbo | 免费用户暂不支持 GPT-4",
+		"gpt-4 if tier=anonymous => gpt-3.5-turbo",
+		"gpt-3.5-turbo if tokens>3000 && tier!=anonymous => gpt-3.5-turbo-16k",
+		"claude-* if tokens>=100000 => claude-2.1",
+		"invalid rule",
+		"gpt-4 if level=1 => gpt-3.5-turbo",
+		"gpt-4 if tier>1 => gpt-3.5-turbo",
+		"gpt-4 =>",
+	})
+
+	assert.Equal(t, 4, len(policy.rules))
+	assert.True(t, policy.UsesTier())
+
+	decision := policy.Evaluate(PolicyInput{Model: "gpt-4", Tier: PolicyTierFree})
+	assert.Equal(t, "gpt-3.5-turbo", decision.Model)
+	assert.Equal(t, "gpt-4", decision.OriginalModel)
+	assert.Equal(t, "免费用户暂不支持 GPT-4", decision.Notice)
+
+	decision = policy.Evaluate(PolicyInput{Model: "gpt-4", Tier: PolicyTierAnonymous})
+	assert.Equal(t, "gpt-3.5-turbo", decision.Model)
+	assert.Equal(t, "本次请求已由 gpt-3.5-turbo 模型回答。", decision.Notice)
+
+	assert.True(t, policy.Evaluate(PolicyInput{Model: "gpt-4", Tier: PolicyTierPaid}) == nil)
+
+	decision = policy.Evaluate(PolicyInput{Model: "gpt-3.5-turbo", Tier: PolicyTierFree, Tokens: 3001})
+	assert.Equal(t, "gpt-3.5-turbo-16k", decision.Model)
+	assert.True(t, policy.Evaluate(PolicyInput{Model: "gpt-3.5-turbo", Tier: PolicyTierFree, Tokens: 3000}) == nil)
+	assert.True(t, policy.Evaluate(PolicyInput{Model: "gpt-3.5-turbo", Tier: PolicyTierAnonymous, Tokens: 5000}) == nil)
+
+	decision = policy.Evaluate(PolicyInput{Model: "claude-instant-1", Tokens: 100000})
+	assert.Equal(t, "claude-2.1", decision.Model)
+
+	// 目标模型与请求模型相同时，视为未命中
+	assert.True(t, policy.Evaluate(PolicyInput{Model: "claude-2.1", Tokens: 200000}) == nil)
+
+	decision = policy.Evaluate(PolicyInput{Model: "gpt-4", Tier: PolicyTierFree})
+	req := decision.Apply(Request{Model: "gpt-4", Channel: "azure"})
+	assert.Equal(t, "gpt-3.5-turbo", req.Model)
+	assert.Equal(t, "", req.Channel)
+
+	policy = NewPolicy(nil, nil)
+	policy.LoadRules([]string{"gpt-3.5-turbo if tokens>3000 => gpt-3.5-turbo-16k"})
+	assert.False(t, policy.UsesTier())
+}
+
+func TestPolicy_Cheapest(t *testing.T) {
+	azure := &failoverTestClient{name: "azure"}
+	openrouter := &failoverTestClient{name: "openrouter"}
+	dashscope := &failoverTestClient{name: "dashscope"}
+
+	failover := NewFailover(newFailoverTestRegistry(azure, openrouter, dashscope), 1, time.Minute, time.Second)
+	failover.LoadRules([]string{"qwen-max=azure,openrouter,dashscope"})
+
+	policy := NewPolicy(failover, []string{"azure=3", "openrouter=0.5", "invalid", "dashscope=abc"})
+	policy.LoadRules([]string{"qwen-max => @cheapest", "gpt-4 => @cheapest"})
+	assert.False(t, policy.UsesTier())
+
+	decision := policy.Evaluate(PolicyInput{Model: "qwen-max"})
+	assert.Equal(t, "qwen-max", decision.Model)
+	assert.Equal(t, "openrouter", decision.Channel)
+	assert.Equal(t, "", decision.Notice)
+
+	// 路由策略指定的渠道优先使用
+	resp, err := failover.Chat(context.TODO(), decision.Apply(Request{Model: "qwen-max"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "openrouter:qwen-max", resp.Text)
+
+	// 成本最低的渠道被熔断后，选择剩余渠道中成本最低的渠道（未配置成本的渠道成本为 1）
+	failover.breaker("openrouter").Failure()
+	decision = policy.Evaluate(PolicyInput{Model: "qwen-max"})
+	assert.Equal(t, "dashscope", decision.Channel)
+
+	// 没有配置渠道的模型，路由策略不生效
+	assert.True(t, policy.Evaluate(PolicyInput{Model: "gpt-4"}) == nil)
+}
@@ -1,13 +1,17 @@
 package chat
 
 import (
+	"context"
+
 	"github.com/mylxsw/aidea-server/config"
 	"github.com/mylxsw/aidea-server/pkg/ai/anthropic"
 	"github.com/mylxsw/aidea-server/pkg/ai/baichuan"
 	"github.com/mylxsw/aidea-server/pkg/ai/baidu"
 	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
 	"github.com/mylxsw/aidea-server/pkg/ai/google"
 	"github.com/mylxsw/aidea-server/pkg/ai/gpt360"
+	"github.com/mylxsw/aidea-server/pkg/ai/local"
 	"github.com/mylxsw/aidea-server/pkg/ai/oneapi"
 	"github.com/mylxsw/aidea-server/pkg/ai/openai"
 	"github.com/mylxsw/aidea-server/pkg/ai/openrouter"
@@ -17,6 +21,7 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/ai/xfyun"
 	"github.com/mylxsw/aidea-server/pkg/file"
 	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/go-utils/array"
 )
 
 type Provider struct{}
@@ -32,6 +37,21 @@ func (Provider) Register(binder infra.Binder) {
 	binder.MustSingleton(func(conf *config.Config, ai *AI) Chat {
 		return NewChat(conf, ai)
 	})
+	binder.MustSingleton(func(conf *config.Config, c Chat) *Policy {
+		if imp, ok := c.(*Imp); ok {
+			return imp.Policy()
+		}
+
+		return NewPolicy(nil, conf.ChatChannelCosts)
+	})
+}
+
+func (Provider) Daemon(ctx context.Context, resolver infra.Resolver) {
+	resolver.MustResolve(func(conf *config.Config, ai *AI) {
+		if conf.EnableLocal {
+			ai.Local.Watch(ctx, conf.LocalRefresh)
+		}
+	})
 }
 
 type AIProvider struct {
@@ -48,6 +68,9 @@ type AIProvider struct {
 	Google     *google.GoogleAI       `autowire:"@"`
 	OpenRouter *openrouter.OpenRouter `autowire:"@"`
 	Sky        *sky.Sky               `autowire:"@"`
+	Local      *local.LocalAI         `autowire:"@"`
+	Fake       *fake.Fake             `autowire:"@"`
+	Channels   *openai.Channels       `autowire:"@"`
 }
 
 type AI struct {
@@ -64,13 +87,20 @@ type AI struct {
 	Google     *GoogleChat
 	Openrouter *OpenRouterChat
 	Sky        *SkyChat
+	Local      *LocalChat
+	Fake       *FakeChat
+	// Channels 配置文件中定义的兼容 OpenAI 接口的渠道，按照配置顺序排列
+	Channels []*OpenAIChannelChat
+	// OpenAIBackup 优先使用备用 OpenAI 服务的渠道，未配置备用服务时为 nil
+	OpenAIBackup Chat
 }
 
 func NewAI(
+	conf *config.Config,
 	file *file.File,
 	aiProvider *AIProvider,
 ) *AI {
-	return &AI{
+	ai := &AI{
 		OpenAI:     NewOpenAIChat(aiProvider.OpenAI),
 		Baidu:      NewBaiduAIChat(aiProvider.Baidu),
 		DashScope:  NewDashScopeChat(aiProvider.Dashscope, file),
@@ -84,5 +114,16 @@ func NewAI(
 		Google:     NewGoogleChat(aiProvider.Google),
 		Openrouter: NewOpenRouterChat(aiProvider.OpenRouter),
 		Sky:        NewSkyChat(aiProvider.Sky),
+		Local:      NewLocalChat(aiProvider.Local, conf),
+		Fake:       NewFakeChat(aiProvider.Fake),
+		Channels: array.Map(conf.OpenAIChannels, func(ch config.OpenAIChannel, _ int) *OpenAIChannelChat {
+			return NewOpenAIChannelChat(aiProvider.Channels.Get(ch.Name), ch)
+		}),
+	}
+
+	if conf.EnableFallbackOpenAI {
+		ai.OpenAIBackup = &openAIBackupChat{imp: ai.OpenAI}
 	}
+
+	return ai
 }
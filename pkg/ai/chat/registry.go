@@ -0,0 +1,193 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mylxsw/asteria/log"
+)
+
+// Channel 聊天渠道定义，描述一个 Chat 实现负责处理哪些模型
+type Channel struct {
+	// Name 渠道名称，配置文件中通过该名称引用渠道
+	Name string
+	// Aliases 渠道别名，兼容历史配置中使用的中文名称
+	Aliases []string
+	// Prefixes 模型 ID 前缀，如 `灵积:`，以该前缀开头的模型都由该渠道处理
+	Prefixes []string
+	// Models 渠道支持的模型 ID（不含前缀）
+	Models []string
+	// Match 动态判断是否支持指定模型，用于支持模型列表由配置决定的渠道
+	Match func(model string) bool
+}
+
+// builtinChannel 内置渠道，在 AI 实例创建后解析出对应的 Chat 实现
+type builtinChannel struct {
+	channel Channel
+	resolve func(ai *AI) Chat
+}
+
+var builtinChannels []builtinChannel
+
+// registerBuiltinChannel 注册内置渠道，由各厂商的 Chat 实现在 init 中调用
+func registerBuiltinChannel(ch Channel, resolve func(ai *AI) Chat) {
+	builtinChannels = append(builtinChannels, builtinChannel{channel: ch, resolve: resolve})
+}
+
+type registeredChannel struct {
+	Channel
+	imp Chat
+}
+
+// Registry 聊天渠道注册表，负责将模型路由到对应的 Chat 实现
+type Registry struct {
+	channels map[string]*registeredChannel
+	aliases  map[string]string
+	prefixes map[string]string
+	models   map[string]string
+	// routePrefixes, routeModels 配置文件中的路由规则，优先于渠道注册时声明的路由规则
+	routePrefixes map[string]string
+	routeModels   map[string]string
+	// order 渠道注册顺序，Match 按照该顺序进行匹配
+	order []string
+	// fallback 无法匹配时使用的默认渠道
+	fallback string
+}
+
+// NewRegistry 创建一个渠道注册表，fallback 为无法匹配到渠道时使用的默认渠道
+func NewRegistry(fallback string) *Registry {
+	return &Registry{
+		channels: make(map[string]*registeredChannel),
+		aliases:  make(map[string]string),
+		prefixes: make(map[string]string),
+		models:   make(map[string]string),
+		fallback: fallback,
+
+		routePrefixes: make(map[string]string),
+		routeModels:   make(map[string]string),
+	}
+}
+
+// Register 注册渠道，同名渠道重复注册时，后注册的会覆盖之前的实现
+func (r *Registry) Register(ch Channel, imp Chat) {
+	if _, ok := r.channels[ch.Name]; !ok {
+		r.order = append(r.order, ch.Name)
+	}
+
+	r.channels[ch.Name] = &registeredChannel{Channel: ch, imp: imp}
+
+	r.aliases[strings.ToLower(ch.Name)] = ch.Name
+	for _, alias := range ch.Aliases {
+		r.aliases[strings.ToLower(alias)] = ch.Name
+	}
+
+	for _, prefix := range ch.Prefixes {
+		r.prefixes[prefix] = ch.Name
+	}
+
+	for _, model := range ch.Models {
+		r.models[model] = ch.Name
+	}
+}
+
+// Route 将模型路由到指定渠道，会覆盖渠道注册时声明的路由规则
+// model 以 `:*` 结尾时表示前缀路由，例如 `deepseek:*` 表示所有以 `deepseek:` 开头的模型
+func (r *Registry) Route(model string, channel string) error {
+	name, ok := r.aliases[strings.ToLower(channel)]
+	if !ok {
+		return fmt.Errorf("channel %s not found", channel)
+	}
+
+	if strings.HasSuffix(model, ":*") {
+		r.routePrefixes[strings.TrimSuffix(model, "*")] = name
+		return nil
+	}
+
+	r.routeModels[model] = name
+	return nil
+}
+
+// LoadRoutes 加载配置文件中的路由规则，格式为 `模型=渠道`，例如 `qwen-max=dashscope`
+func (r *Registry) LoadRoutes(routes []string) {
+	for _, route := range routes {
+		segs := strings.SplitN(route, "=", 2)
+		if len(segs) != 2 || strings.TrimSpace(segs[0]) == "" || strings.TrimSpace(segs[1]) == "" {
+			log.Warningf("invalid chat route: %s", route)
+			continue
+		}
+
+		if err := r.Route(strings.TrimSpace(segs[0]), strings.TrimSpace(segs[1])); err != nil {
+			log.Warningf("invalid chat route %s: %v", route, err)
+		}
+	}
+}
+
+// Channel 根据渠道名称或者别名查询渠道实现，渠道不存在时返回 nil
+func (r *Registry) Channel(name string) Chat {
+	if n, ok := r.aliases[strings.ToLower(name)]; ok {
+		return r.channels[n].imp
+	}
+
+	return nil
+}
+
+// Channels 返回所有已注册的渠道名称
+func (r *Registry) Channels() []string {
+	names := make([]string, 0, len(r.channels))
+	for name := range r.channels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Resolve 查询模型对应的渠道名称
+// 匹配顺序：路由规则（精确匹配、前缀匹配），渠道声明的前缀、模型，渠道的 Match 函数，默认渠道
+func (r *Registry) Resolve(model string) string {
+	if name, ok := r.routeModels[model]; ok {
+		return name
+	}
+
+	if name := matchPrefix(r.routePrefixes, model); name != "" {
+		return name
+	}
+
+	if name := matchPrefix(r.prefixes, model); name != "" {
+		return name
+	}
+
+	if name, ok := r.models[model]; ok {
+		return name
+	}
+
+	for _, name := range r.order {
+		if ch := r.channels[name]; ch.Match != nil && ch.Match(model) {
+			return name
+		}
+	}
+
+	return r.fallback
+}
+
+// matchPrefix 查询模型匹配的前缀对应的渠道名称，多个前缀同时匹配时，使用最长的前缀
+func matchPrefix(prefixes map[string]string, model string) string {
+	var matchedPrefix, matchedName string
+	for prefix, name := range prefixes {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(matchedPrefix) {
+			matchedPrefix, matchedName = prefix, name
+		}
+	}
+
+	return matchedName
+}
+
+// Imp 查询模型对应的 Chat 实现
+func (r *Registry) Imp(model string) Chat {
+	if ch, ok := r.channels[r.Resolve(model)]; ok {
+		return ch.imp
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type namedTestClient struct {
+	ChatTestClient
+	name string
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	registry := NewRegistry("openai")
+	registry.Register(Channel{Name: "openai", Prefixes: []string{"openai:"}}, &namedTestClient{name: "openai"})
+	registry.Register(Channel{
+		Name:     "dashscope",
+		Aliases:  []string{"灵积"},
+		Prefixes: []string{"灵积:"},
+		Models:   []string{"qwen-max"},
+	}, &namedTestClient{name: "dashscope"})
+	registry.Register(Channel{
+		Name:  "openrouter",
+		Match: func(model string) bool { return model == "01-ai.yi-34b-chat" },
+	}, &namedTestClient{name: "openrouter"})
+
+	assert.Equal(t, "dashscope", registry.Resolve("灵积:qwen-turbo"))
+	assert.Equal(t, "dashscope", registry.Resolve("qwen-max"))
+	assert.Equal(t, "openrouter", registry.Resolve("01-ai.yi-34b-chat"))
+	assert.Equal(t, "openai", registry.Resolve("gpt-4"))
+	assert.Equal(t, "openai", registry.Resolve("unknown-model"))
+
+	assert.Equal(t, "dashscope", registry.Imp("qwen-max").(*namedTestClient).name)
+	assert.Equal(t, "dashscope", registry.Channel("灵积").(*namedTestClient).name)
+	assert.True(t, registry.Channel("not-exist") == nil)
+
+	registry.LoadRoutes([]string{"gpt-4=openrouter", "deepseek:*=灵积", "qwen-max=not-exist", "invalid"})
+	assert.Equal(t, "openrouter", registry.Resolve("gpt-4"))
+	assert.Equal(t, "dashscope", registry.Resolve("deepseek:deepseek-chat"))
+	assert.Equal(t, "dashscope", registry.Resolve("qwen-max"))
+
+	// 路由规则优先于渠道声明的前缀，精确匹配优先于前缀匹配
+	registry.LoadRoutes([]string{"openai:gpt-4=openrouter", "openai:gpt-3.5-turbo:*=灵积"})
+	assert.Equal(t, "openrouter", registry.Resolve("openai:gpt-4"))
+	assert.Equal(t, "openai", registry.Resolve("openai:gpt-4-turbo"))
+	assert.Equal(t, "dashscope", registry.Resolve("openai:gpt-3.5-turbo:0613"))
+
+	// 路由规则加载之后注册的渠道，不会覆盖路由规则
+	registry.Register(Channel{Name: "dashscope", Models: []string{"qwen-max", "gpt-4"}}, &namedTestClient{name: "dashscope"})
+	assert.Equal(t, "openrouter", registry.Resolve("gpt-4"))
+
+	assert.Equal(t, []string{"dashscope", "openai", "openrouter"}, registry.Channels())
+}
+
+func TestBuiltinChannels(t *testing.T) {
+	registry := NewRegistry("openai")
+	for _, ch := range builtinChannels {
+		registry.Register(ch.channel, &namedTestClient{name: ch.channel.Name})
+	}
+
+	testcases := map[string]string{
+		"文心千帆:model_ernie_bot_turbo": "baidu",
+		"model_ernie_bot_4":          "baidu",
+		"qwen-max":                   "dashscope",
+		"generalv3":                  "xfyun",
+		"nova-ptc-xl-v1":             "sense_nova",
+		"hyllm":                      "tencent",
+		"claude-2":                   "anthropic",
+		"Baichuan2-53B":              "baichuan",
+		"360GPT_S2_V9":               "gpt360",
+		"chatglm_turbo":              "oneapi",
+		"gemini-pro":                 "google",
+		"SkyChat-MegaVerse":          "sky",
+		"gpt-3.5-turbo":              "openai",
+	}
+
+	for model, expect := range testcases {
+		assert.Equal(t, expect, registry.Resolve(model))
+	}
+}
+
+func TestNewChat_OpenAIBackup(t *testing.T) {
+	// 未配置备用 OpenAI 服务时，不注册备用渠道，避免故障转移时重复请求同一个服务
+	imp := NewChat(&config.Config{}, &AI{}).(*Imp)
+	assert.True(t, imp.registry.Channel("openai-backup") == nil)
+
+	imp = NewChat(&config.Config{}, &AI{OpenAIBackup: &openAIBackupChat{imp: &namedTestClient{name: "openai"}}}).(*Imp)
+	assert.True(t, imp.registry.Channel("openai-backup") != nil)
+}
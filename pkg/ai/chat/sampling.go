@@ -0,0 +1,237 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sampling 采样参数，未设置的参数使用模型的默认值
+type Sampling struct {
+	// Temperature 采样温度，取值越大，输出越随机
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP 核采样概率阈值
+	TopP *float64 `json:"top_p,omitempty"`
+	// Stop 停止序列，模型生成到这些内容时停止输出
+	Stop StopSequences `json:"stop,omitempty"`
+	// Seed 随机数种子，相同的种子尽可能输出相同的结果
+	Seed *int `json:"seed,omitempty"`
+	// PresencePenalty 存在惩罚，取值越大，越倾向于谈论新的话题
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
+	// FrequencyPenalty 频率惩罚，取值越大，越不容易逐字重复
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+}
+
+// StopSequences 停止序列，兼容 OpenAI 接口中字符串和字符串数组两种格式
+type StopSequences []string
+
+func (s *StopSequences) UnmarshalJSON(data []byte) error {
+	var stop string
+	if err := json.Unmarshal(data, &stop); err == nil {
+		if stop != "" {
+			*s = StopSequences{stop}
+		}
+
+		return nil
+	}
+
+	var stops []string
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return errors.New("stop must be a string or an array of strings")
+	}
+
+	*s = stops
+	return nil
+}
+
+// ParseSampling 解析 JSON 格式的采样参数，内容为空时返回空的采样参数
+func ParseSampling(data string) (Sampling, error) {
+	var sampling Sampling
+	if strings.TrimSpace(data) == "" {
+		return sampling, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &sampling); err != nil {
+		return sampling, fmt.Errorf("invalid sampling parameters: %w", err)
+	}
+
+	return sampling, nil
+}
+
+// IsEmpty 是否没有设置任何采样参数
+func (s Sampling) IsEmpty() bool {
+	return s.Temperature == nil && s.TopP == nil && len(s.Stop) == 0 &&
+		s.Seed == nil && s.PresencePenalty == nil && s.FrequencyPenalty == nil
+}
+
+// JSON 返回采样参数的 JSON 表示，没有设置任何参数时返回空字符串
+func (s Sampling) JSON() string {
+	if s.IsEmpty() {
+		return ""
+	}
+
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// Validate 检查采样参数是否在 OpenAI 接口允许的取值范围内
+func (s Sampling) Validate() error {
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return errors.New("temperature must be between 0 and 2")
+	}
+
+	if s.TopP != nil && (*s.TopP < 0 || *s.TopP > 1) {
+		return errors.New("top_p must be between 0 and 1")
+	}
+
+	if len(s.Stop) > MaxStopSequences {
+		return fmt.Errorf("stop supports up to %d sequences", MaxStopSequences)
+	}
+
+	for _, stop := range s.Stop {
+		if stop == "" {
+			return errors.New("stop sequence must not be empty")
+		}
+	}
+
+	if s.PresencePenalty != nil && (*s.PresencePenalty < -2 || *s.PresencePenalty > 2) {
+		return errors.New("presence_penalty must be between -2 and 2")
+	}
+
+	if s.FrequencyPenalty != nil && (*s.FrequencyPenalty < -2 || *s.FrequencyPenalty > 2) {
+		return errors.New("frequency_penalty must be between -2 and 2")
+	}
+
+	return nil
+}
+
+// Merge 合并采样参数，override 中已设置的参数覆盖当前参数
+func (s Sampling) Merge(override Sampling) Sampling {
+	if override.Temperature != nil {
+		s.Temperature = override.Temperature
+	}
+
+	if override.TopP != nil {
+		s.TopP = override.TopP
+	}
+
+	if len(override.Stop) > 0 {
+		s.Stop = override.Stop
+	}
+
+	if override.Seed != nil {
+		s.Seed = override.Seed
+	}
+
+	if override.PresencePenalty != nil {
+		s.PresencePenalty = override.PresencePenalty
+	}
+
+	if override.FrequencyPenalty != nil {
+		s.FrequencyPenalty = override.FrequencyPenalty
+	}
+
+	return s
+}
+
+// MaxStopSequences 最多允许的停止序列数量
+const MaxStopSequences = 4
+
+// Range 参数取值范围（闭区间）
+type Range struct {
+	Min float64
+	Max float64
+}
+
+func (r *Range) clamp(v *float64) *float64 {
+	if v == nil || r == nil {
+		return nil
+	}
+
+	val := *v
+	if val < r.Min {
+		val = r.Min
+	}
+
+	if val > r.Max {
+		val = r.Max
+	}
+
+	return &val
+}
+
+// SamplingCapability 模型支持的采样参数以及取值范围
+// 大多数厂商的请求结构体使用 omitempty，取值为 0 时会被忽略，因此对应参数的最小值不为 0
+type SamplingCapability struct {
+	// Temperature 温度取值范围，为空时不支持
+	Temperature *Range
+	// TopP 核采样概率阈值取值范围，为空时不支持
+	TopP *Range
+	// MaxStop 最多支持的停止序列数量，为 0 时不支持
+	MaxStop int
+	// Seed 是否支持随机数种子
+	Seed bool
+	// Penalty 存在惩罚和频率惩罚的取值范围，为空时不支持
+	Penalty *Range
+}
+
+// openAISamplingCapability OpenAI 及兼容 OpenAI 接口的模型支持的采样参数
+var openAISamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0, Max: 2},
+	TopP:        &Range{Min: 0, Max: 1},
+	MaxStop:     MaxStopSequences,
+	Seed:        true,
+	Penalty:     &Range{Min: -2, Max: 2},
+}
+
+// Clamp 根据模型支持的采样参数，去掉不支持的参数，并将参数值限制在允许的范围内
+func (s Sampling) Clamp(capability SamplingCapability) Sampling {
+	res := Sampling{
+		Temperature:      capability.Temperature.clamp(s.Temperature),
+		TopP:             capability.TopP.clamp(s.TopP),
+		PresencePenalty:  capability.Penalty.clamp(s.PresencePenalty),
+		FrequencyPenalty: capability.Penalty.clamp(s.FrequencyPenalty),
+	}
+
+	if capability.MaxStop > 0 && len(s.Stop) > 0 {
+		res.Stop = s.Stop
+		if len(res.Stop) > capability.MaxStop {
+			res.Stop = res.Stop[:capability.MaxStop]
+		}
+	}
+
+	if capability.Seed && s.Seed != nil {
+		seed := *s.Seed
+		res.Seed = &seed
+	}
+
+	return res
+}
+
+// TemperatureValue 返回采样温度，未设置时返回 0
+func (s Sampling) TemperatureValue() float64 {
+	if s.Temperature == nil {
+		return 0
+	}
+
+	return *s.Temperature
+}
+
+// TopPValue 返回核采样概率阈值，未设置时返回 0
+func (s Sampling) TopPValue() float64 {
+	if s.TopP == nil {
+		return 0
+	}
+
+	return *s.TopP
+}
+
+// SeedValue 返回随机数种子，未设置时返回 0
+func (s Sampling) SeedValue() int {
+	if s.Seed == nil {
+		return 0
+	}
+
+	return *s.Seed
+}
@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/mylxsw/go-utils/assert"
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestParseSampling(t *testing.T) {
+	sampling, err := ParseSampling(`{"temperature": 0, "top_p": 0.9, "stop": "\n\n", "seed": 42}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, *sampling.Temperature)
+	assert.Equal(t, 0.9, *sampling.TopP)
+	assert.Equal(t, StopSequences{"\n\n"}, sampling.Stop)
+	assert.Equal(t, 42, *sampling.Seed)
+	assert.True(t, sampling.PresencePenalty == nil)
+
+	sampling, err = ParseSampling(`{"stop": ["a", "b"]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, StopSequences{"a", "b"}, sampling.Stop)
+
+	sampling, err = ParseSampling("")
+	assert.NoError(t, err)
+	assert.True(t, sampling.IsEmpty())
+	assert.Equal(t, "", sampling.JSON())
+
+	_, err = ParseSampling(`{"stop": 1}`)
+	assert.True(t, err != nil)
+
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(`{"model": "gpt-4", "temperature": 1.2, "stop": "END"}`), &req))
+	assert.Equal(t, 1.2, req.TemperatureValue())
+	assert.Equal(t, StopSequences{"END"}, req.Stop)
+}
+
+func TestSampling_Validate(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	assert.NoError(t, Sampling{}.Validate())
+	assert.NoError(t, Sampling{Temperature: f(2), TopP: f(0), PresencePenalty: f(-2), Stop: StopSequences{"a", "b", "c", "d"}}.Validate())
+
+	assert.True(t, Sampling{Temperature: f(2.1)}.Validate() != nil)
+	assert.True(t, Sampling{TopP: f(1.1)}.Validate() != nil)
+	assert.True(t, Sampling{FrequencyPenalty: f(-3)}.Validate() != nil)
+	assert.True(t, Sampling{Stop: StopSequences{"a", "b", "c", "d", "e"}}.Validate() != nil)
+	assert.True(t, Sampling{Stop: StopSequences{""}}.Validate() != nil)
+}
+
+func TestSampling_MergeAndClamp(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	seed := 7
+
+	room := Sampling{Temperature: f(0.2), Stop: StopSequences{"room"}, Seed: &seed}
+	merged := room.Merge(Sampling{Temperature: f(1.5), TopP: f(0.5)})
+	assert.Equal(t, 1.5, *merged.Temperature)
+	assert.Equal(t, 0.5, *merged.TopP)
+	assert.Equal(t, StopSequences{"room"}, merged.Stop)
+	assert.Equal(t, 7, *merged.Seed)
+
+	clamped := merged.Clamp(anthropicSamplingCapability)
+	assert.Equal(t, 1.0, *clamped.Temperature)
+	assert.Equal(t, 0.5, *clamped.TopP)
+	assert.True(t, clamped.Seed == nil)
+
+	clamped = Sampling{Temperature: f(0), Stop: StopSequences{"a", "b"}, PresencePenalty: f(1)}.Clamp(xfyunSamplingCapability)
+	assert.Equal(t, 0.01, *clamped.Temperature)
+	assert.Equal(t, 0, len(clamped.Stop))
+	assert.True(t, clamped.PresencePenalty == nil)
+
+	clamped = Sampling{Stop: StopSequences{"a", "b", "c"}}.Clamp(SamplingCapability{MaxStop: 2})
+	assert.Equal(t, StopSequences{"a", "b"}, clamped.Stop)
+
+	// 原始参数不应该被修改
+	assert.Equal(t, 1.5, *merged.Temperature)
+}
+
+func TestApplyOpenAISampling(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	seed := 1
+
+	req := openai.ChatCompletionRequest{}
+	applyOpenAISampling(&req, Sampling{Temperature: f(0), TopP: f(0.8), Seed: &seed, Stop: StopSequences{"END"}})
+	assert.Equal(t, float32(math.SmallestNonzeroFloat32), req.Temperature)
+	assert.Equal(t, float32(0.8), req.TopP)
+	assert.Equal(t, 1, *req.Seed)
+	assert.Equal(t, []string{"END"}, req.Stop)
+	assert.Equal(t, float32(0), req.PresencePenalty)
+}
+
+func TestSkyChat_Sampling(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	messages := Messages{{Role: "user", Content: "hello"}}
+
+	chatReq := (&SkyChat{}).initRequest(Request{Model: "SkyChat-MegaVerse", Messages: messages, Sampling: Sampling{Temperature: f(1.5), Stop: StopSequences{"END"}}})
+	assert.True(t, chatReq.ParamConfig != nil)
+	assert.Equal(t, 1.0, chatReq.ParamConfig.Temperature)
+	assert.Equal(t, 0.0, chatReq.ParamConfig.TopP)
+
+	chatReq = (&SkyChat{}).initRequest(Request{Model: "SkyChat-MegaVerse", Messages: messages})
+	assert.True(t, chatReq.ParamConfig == nil)
+}
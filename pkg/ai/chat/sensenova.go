@@ -10,6 +10,21 @@ import (
 	"github.com/mylxsw/go-utils/array"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "sense_nova",
+		Aliases:  []string{"商汤日日新"},
+		Prefixes: []string{"商汤日日新:"},
+		Models:   []string{string(sensenova.ModelNovaPtcXLV1), string(sensenova.ModelNovaPtcXSV1)},
+	}, func(ai *AI) Chat { return ai.SenseNova })
+}
+
+// senseNovaSamplingCapability 商汤日日新支持的采样参数，temperature 取值范围为 (0, 2]，top_p 取值范围为 (0, 1)
+var senseNovaSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 2},
+	TopP:        &Range{Min: 0.01, Max: 0.99},
+}
+
 type SenseNovaChat struct {
 	sensenova *sensenova.SenseNova
 }
@@ -28,9 +43,12 @@ func (ds *SenseNovaChat) initRequest(req Request) sensenova.Request {
 		}
 	})
 
+	sampling := req.Sampling.Clamp(senseNovaSamplingCapability)
 	return sensenova.Request{
-		Model:    sensenova.Model(strings.TrimPrefix(req.Model, "商汤日日新:")),
-		Messages: messages,
+		Model:       sensenova.Model(strings.TrimPrefix(req.Model, "商汤日日新:")),
+		Messages:    messages,
+		Temperature: sampling.TemperatureValue(),
+		TopP:        sampling.TopPValue(),
 	}
 }
 
@@ -7,6 +7,20 @@ import (
 	"github.com/mylxsw/go-utils/array"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "sky",
+		Prefixes: []string{"sky:"},
+		Models:   []string{sky.ModelSkyChatMegaVerse},
+	}, func(ai *AI) Chat { return ai.Sky })
+}
+
+// skySamplingCapability 天工支持的采样参数，接口不支持停止序列
+var skySamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+	TopP:        &Range{Min: 0.01, Max: 1},
+}
+
 type SkyChat struct {
 	ai *sky.Sky
 }
@@ -29,10 +43,20 @@ func (ai *SkyChat) initRequest(req Request) sky.Request {
 		}
 	})
 
-	return sky.Request{
+	chatReq := sky.Request{
 		Model:    req.Model,
 		Messages: messages,
 	}
+
+	sampling := req.Sampling.Clamp(skySamplingCapability)
+	if sampling.Temperature != nil || sampling.TopP != nil {
+		chatReq.ParamConfig = &sky.ParamConfig{
+			Temperature: sampling.TemperatureValue(),
+			TopP:        sampling.TopPValue(),
+		}
+	}
+
+	return chatReq
 }
 
 func (ai *SkyChat) Chat(ctx context.Context, req Request) (*Response, error) {
@@ -46,9 +70,12 @@ func (ai *SkyChat) Chat(ctx context.Context, req Request) (*Response, error) {
 		return nil, fmt.Errorf("sky chat error: [%d] %s", resp.Code, resp.CodeMsg)
 	}
 
-	return &Response{
-		Text: resp.RespData.Reply,
-	}, nil
+	ret := &Response{Text: resp.RespData.Reply}
+	if usage := resp.RespData.Usage; usage != nil {
+		ret.InputTokens, ret.OutputTokens = usage.PromptTokens, usage.CompletionTokens
+	}
+
+	return ret, nil
 }
 
 func (ai *SkyChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
@@ -79,12 +106,15 @@ func (ai *SkyChat) ChatStream(ctx context.Context, req Request) (<-chan Response
 					return
 				}
 
+				resp := Response{Text: data.RespData.Reply}
+				if usage := data.RespData.Usage; usage != nil {
+					resp.InputTokens, resp.OutputTokens = usage.PromptTokens, usage.CompletionTokens
+				}
+
 				select {
 				case <-ctx.Done():
 					return
-				case res <- Response{
-					Text: data.RespData.Reply,
-				}:
+				case res <- resp:
 				}
 			}
 		}
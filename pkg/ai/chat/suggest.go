@@ -0,0 +1,186 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// suggestionMaxTitleLength 自动生成的标题最大长度
+	suggestionMaxTitleLength = 20
+	// suggestionMaxQuestionLength 推荐问题的最大长度
+	suggestionMaxQuestionLength = 50
+	// suggestionMaxQuestions 推荐问题的最大数量
+	suggestionMaxQuestions = 3
+	// suggestionMaxMessageLength 生成标题和推荐问题时，单条消息的最大长度，超出部分会被截断
+	suggestionMaxMessageLength = 1000
+)
+
+const titleSystemPrompt = `你是一个对话标题生成助手。请根据用户提供的对话内容，生成一个简短的标题概括对话的主题，不超过 15 个字。
+使用与对话相同的语言，直接输出标题，不要添加引号、标点或者任何解释。`
+
+const followUpSystemPrompt = `你是一个追问推荐助手。请根据用户提供的对话内容，站在提问者的角度，推荐 3 个提问者接下来最可能追问的问题。
+每个问题不超过 30 个字，使用与对话相同的语言，每行一个问题，直接输出问题，不要添加序号或者任何解释。`
+
+// Suggestion 自动生成的标题或推荐问题
+type Suggestion struct {
+	// Title 对话标题
+	Title string
+	// Questions 推荐的追问问题
+	Questions []string
+	// Model 生成使用的模型
+	Model string
+	// InputTokens/OutputTokens 生成消耗的 Token 数量
+	InputTokens  int
+	OutputTokens int
+}
+
+// Tokens 生成消耗的 Token 总数
+func (s Suggestion) Tokens() int {
+	return s.InputTokens + s.OutputTokens
+}
+
+// Suggester 使用（价格较低的）模型为对话生成标题和推荐的追问问题
+type Suggester struct {
+	chat  Chat
+	model string
+}
+
+// NewSuggester 创建 Suggester，model 为生成使用的模型
+func NewSuggester(chat Chat, model string) *Suggester {
+	return &Suggester{chat: chat, model: model}
+}
+
+// Title 根据首轮对话生成对话标题
+func (s *Suggester) Title(ctx context.Context, question, answer string) (*Suggestion, error) {
+	suggestion, text, err := s.complete(ctx, titleSystemPrompt, Messages{
+		{Role: "user", Content: question},
+		{Role: "assistant", Content: answer},
+	}, 50)
+	if err != nil {
+		return nil, fmt.Errorf("generate title failed: %w", err)
+	}
+
+	suggestion.Title = cleanTitle(text)
+	if suggestion.Title == "" {
+		return nil, errors.New("generate title failed: empty title")
+	}
+
+	return suggestion, nil
+}
+
+// FollowUps 根据对话上下文以及最后的回答生成推荐的追问问题
+func (s *Suggester) FollowUps(ctx context.Context, messages Messages, answer string) (*Suggestion, error) {
+	contextMessages := make(Messages, 0, len(messages)+1)
+	for _, msg := range messages {
+		if msg.Role != "system" && strings.TrimSpace(msg.Content) != "" {
+			contextMessages = append(contextMessages, msg)
+		}
+	}
+
+	// 只保留最近的一轮对话，足以推测用户接下来的问题
+	if len(contextMessages) > 1 {
+		contextMessages = contextMessages[len(contextMessages)-1:]
+	}
+
+	suggestion, text, err := s.complete(ctx, followUpSystemPrompt, append(contextMessages, Message{Role: "assistant", Content: answer}), 200)
+	if err != nil {
+		return nil, fmt.Errorf("generate follow-up questions failed: %w", err)
+	}
+
+	suggestion.Questions = parseQuestions(text)
+	if len(suggestion.Questions) == 0 {
+		return nil, errors.New("generate follow-up questions failed: no questions")
+	}
+
+	return suggestion, nil
+}
+
+// complete 将对话内容作为用户消息发起请求，返回模型的输出内容
+func (s *Suggester) complete(ctx context.Context, systemPrompt string, messages Messages, maxTokens int) (*Suggestion, string, error) {
+	var sb strings.Builder
+	sb.WriteString("对话内容：\n")
+	for _, msg := range messages {
+		content := []rune(strings.TrimSpace(msg.Content))
+		if len(content) > suggestionMaxMessageLength {
+			content = append(content[:suggestionMaxMessageLength], []rune("...")...)
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s\n", msg.Role, string(content)))
+	}
+
+	req := Request{
+		Model: s.model,
+		Messages: Messages{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: sb.String()},
+		},
+		MaxTokens: maxTokens,
+	}
+
+	resp, err := s.chat.Chat(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.ErrorCode != "" {
+		return nil, "", fmt.Errorf("[%s] %s", resp.ErrorCode, resp.Error)
+	}
+
+	text := strings.TrimSpace(resp.Text)
+	suggestion := Suggestion{Model: s.model, InputTokens: resp.InputTokens, OutputTokens: resp.OutputTokens}
+	if suggestion.InputTokens <= 0 {
+		suggestion.InputTokens, _ = MessageTokenCount(req.Messages, s.model)
+	}
+
+	if suggestion.OutputTokens <= 0 {
+		suggestion.OutputTokens, _ = MessageTokenCount(Messages{{Role: "assistant", Content: text}}, s.model)
+	}
+
+	return &suggestion, text, nil
+}
+
+// questionPrefixPattern 推荐问题前的序号或者列表符号
+var questionPrefixPattern = regexp.MustCompile(`^\s*(\d+[.、)）:：]|[-*•])\s*`)
+
+// parseQuestions 从模型输出中解析推荐问题，每行一个，最多返回 suggestionMaxQuestions 个
+func parseQuestions(text string) []string {
+	questions := make([]string, 0, suggestionMaxQuestions)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		question := strings.TrimSpace(questionPrefixPattern.ReplaceAllString(line, ""))
+		question = strings.Trim(question, "\"'“”")
+		if question == "" || seen[question] || len([]rune(question)) > suggestionMaxQuestionLength {
+			continue
+		}
+
+		seen[question] = true
+		questions = append(questions, question)
+		if len(questions) >= suggestionMaxQuestions {
+			break
+		}
+	}
+
+	return questions
+}
+
+// cleanTitle 清理模型生成的标题：只保留第一行，去掉前缀、引号以及结尾的标点，超出长度时截断
+func cleanTitle(text string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	for _, prefix := range []string{"标题：", "标题:", "Title:"} {
+		title = strings.TrimPrefix(title, prefix)
+	}
+
+	title = strings.Trim(strings.TrimSpace(title), "\"'“”《》「」#*")
+	title = strings.TrimRight(title, "。.！!？?，,")
+
+	runes := []rune(strings.TrimSpace(title))
+	if len(runes) > suggestionMaxTitleLength {
+		runes = runes[:suggestionMaxTitleLength]
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type suggestTestClient struct {
+	ChatTestClient
+	reply   string
+	prompts []string
+}
+
+func (c *suggestTestClient) Chat(ctx context.Context, req Request) (*Response, error) {
+	c.prompts = append(c.prompts, req.Messages[len(req.Messages)-1].Content)
+	return &Response{Text: c.reply, InputTokens: 80, OutputTokens: 10}, nil
+}
+
+func TestSuggester_Title(t *testing.T) {
+	client := &suggestTestClient{reply: "标题：“Go 语言并发编程入门。”\n多余的内容"}
+	suggester := NewSuggester(client, "gpt-3.5-turbo")
+
+	suggestion, err := suggester.Title(context.TODO(), "如何学习 Go 并发？", "可以从 goroutine 和 channel 开始")
+	assert.NoError(t, err)
+	assert.Equal(t, "Go 语言并发编程入门", suggestion.Title)
+	assert.Equal(t, 90, suggestion.Tokens())
+	assert.True(t, strings.Contains(client.prompts[0], "user: 如何学习 Go 并发？"))
+
+	client.reply = "  "
+	_, err = suggester.Title(context.TODO(), "hello", "hi")
+	assert.True(t, err != nil)
+}
+
+func TestSuggester_FollowUps(t *testing.T) {
+	client := &suggestTestClient{reply: "1. goroutine 和线程有什么区别？\n2、channel 会阻塞吗？\n- channel 会阻塞吗？\n\n* 如何避免数据竞争？\n4. 什么是 select？"}
+	suggester := NewSuggester(client, "gpt-3.5-turbo")
+
+	suggestion, err := suggester.FollowUps(context.TODO(), Messages{
+		{Role: "system", Content: "你是一个编程助手"},
+		{Role: "user", Content: "之前的问题"},
+		{Role: "assistant", Content: "之前的回答"},
+		{Role: "user", Content: "如何学习 Go 并发？"},
+	}, "可以从 goroutine 和 channel 开始")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"goroutine 和线程有什么区别？", "channel 会阻塞吗？", "如何避免数据竞争？"}, suggestion.Questions)
+	assert.False(t, strings.Contains(client.prompts[0], "之前的问题"))
+	assert.False(t, strings.Contains(client.prompts[0], "编程助手"))
+	assert.True(t, strings.Contains(client.prompts[0], "assistant: 可以从 goroutine 和 channel 开始"))
+
+	client.reply = ""
+	_, err = suggester.FollowUps(context.TODO(), Messages{{Role: "user", Content: "hello"}}, "hi")
+	assert.True(t, err != nil)
+}
@@ -7,6 +7,21 @@ import (
 	"strings"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "tencent",
+		Aliases:  []string{"腾讯"},
+		Prefixes: []string{"腾讯:"},
+		Models:   []string{tencentai.ModelHyllm},
+	}, func(ai *AI) Chat { return ai.Tencent })
+}
+
+// tencentSamplingCapability 腾讯混元支持的采样参数
+var tencentSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0, Max: 2},
+	TopP:        &Range{Min: 0, Max: 1},
+}
+
 type TencentAIChat struct {
 	ai *tencentai.TencentAI
 }
@@ -52,7 +67,19 @@ func (chat *TencentAIChat) initRequest(req Request) tencentai.Request {
 		contextMessages = append(finalSystemMessages, contextMessages...)
 	}
 
-	return tencentai.NewRequest(contextMessages)
+	tencentReq := tencentai.NewRequest(contextMessages)
+
+	// 腾讯混元请求参数没有使用 omitempty，只有设置了参数时才覆盖默认值
+	sampling := req.Sampling.Clamp(tencentSamplingCapability)
+	if sampling.Temperature != nil {
+		tencentReq.Temperature = *sampling.Temperature
+	}
+
+	if sampling.TopP != nil {
+		tencentReq.TopP = *sampling.TopP
+	}
+
+	return tencentReq
 }
 
 func (chat *TencentAIChat) Chat(ctx context.Context, req Request) (*Response, error) {
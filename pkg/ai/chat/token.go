@@ -3,9 +3,7 @@ package chat
 import (
 	"errors"
 	"fmt"
-	"github.com/mylxsw/go-utils/array"
-	"github.com/pkoukk/tiktoken-go"
-	"strings"
+	"github.com/mylxsw/aidea-server/pkg/ai/tokenizer"
 )
 
 // ReduceMessageContextUpToContextWindow 减少对话上下文到指定的上下文窗口大小
@@ -28,8 +26,9 @@ func ReduceMessageContext(messages Messages, model string, maxTokens int) (reduc
 
 	if num <= maxTokens {
 		// 第一个消息应该是 user 消息
-		if len(messages) > 1 && messages[0].Role == "assistant" {
-			return messages[1:], num, nil
+		// 工具调用结果消息需要与工具调用消息一起出现，调用消息被丢弃时，结果也一并丢弃
+		if len(messages) > 1 && (messages[0].Role == "assistant" || messages[0].Role == RoleTool) {
+			return ReduceMessageContext(messages[1:], model, maxTokens)
 		}
 
 		return messages, num, nil
@@ -42,27 +41,10 @@ func ReduceMessageContext(messages Messages, model string, maxTokens int) (reduc
 	return ReduceMessageContext(messages[1:], model, maxTokens)
 }
 
-// MessageTokenCount 计算对话上下文的 token 数量
-// TODO 不通厂商模型的 Token 计算方式可能不同，需要根据厂商模型进行区分
+// MessageTokenCount 计算对话上下文的 token 数量，不同厂商的模型使用各自的分词器进行计算
 func MessageTokenCount(messages Messages, model string) (numTokens int, err error) {
-	// 所有非 gpt-3.5-turbo/gpt-4 的模型，都按照 gpt-3.5 的方式处理
-	if !array.In(model, []string{"gpt-3.5-turbo", "gpt-4"}) {
-		model = "gpt-3.5-turbo"
-	}
-
-	tkm, err := tiktoken.EncodingForModel(model)
-	if err != nil {
-		return 0, fmt.Errorf("EncodingForModel: %v", err)
-	}
-
-	var tokensPerMessage int
-	if strings.HasPrefix(model, "gpt-3.5-turbo") {
-		tokensPerMessage = 4
-	} else if strings.HasPrefix(model, "gpt-4") {
-		tokensPerMessage = 3
-	} else {
-		tokensPerMessage = 3
-	}
+	tk := tokenizer.ForModel(model)
+	tokensPerMessage := tk.MessageOverhead()
 
 	for _, message := range messages {
 		numTokens += tokensPerMessage
@@ -81,14 +63,25 @@ func MessageTokenCount(messages Messages, model string) (numTokens int, err erro
 						numTokens += 129 * 16
 					}
 				} else {
-					numTokens += len(tkm.Encode(content.Text, nil, nil))
+					numTokens += tk.Count(content.Text)
 				}
 			}
 		} else {
-			numTokens += len(tkm.Encode(message.Content, nil, nil))
+			numTokens += tk.Count(message.Content)
+		}
+
+		for _, call := range message.ToolCalls {
+			numTokens += tk.Count(call.Function.Name) + tk.Count(call.Function.Arguments)
+		}
+		if tokensPerMessage > 0 {
+			numTokens += tk.Count(message.Role)
 		}
-		numTokens += len(tkm.Encode(message.Role, nil, nil))
 	}
-	numTokens += 3
+
+	// 每次回复都以 <|start|>assistant<|message|> 开头
+	if tokensPerMessage > 0 {
+		numTokens += 3
+	}
+
 	return numTokens, nil
 }
@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"encoding/json"
+)
+
+const (
+	// RoleTool 工具调用结果消息的角色
+	RoleTool = "tool"
+	// ToolTypeFunction 函数类型的工具，目前只支持该类型
+	ToolTypeFunction = "function"
+)
+
+// Tool 模型可以调用的工具定义
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition 函数定义
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parameters 函数参数定义，格式为 JSON Schema
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall 模型发起的工具调用
+type ToolCall struct {
+	// Index 工具调用的序号，流式响应中用于标识增量数据属于哪一个工具调用
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 函数调用
+type FunctionCall struct {
+	Name string `json:"name,omitempty"`
+	// Arguments 函数调用参数，JSON 格式，流式响应中为增量数据
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// HasToolCalls 判断对话上下文中是否包含工具调用
+func (ms Messages) HasToolCalls() bool {
+	for _, msg := range ms {
+		if msg.Role == RoleTool || len(msg.ToolCalls) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toolCallName 根据工具调用 ID 查询调用的函数名称，用于不支持工具调用 ID 的厂商
+func (ms Messages) toolCallName(id string) string {
+	for i := len(ms) - 1; i >= 0; i-- {
+		for _, call := range ms[i].ToolCalls {
+			if call.ID == id {
+				return call.Function.Name
+			}
+		}
+	}
+
+	return ""
+}
+
+// ToolChoice 解析请求中的 tool_choice 参数
+// 返回值 mode 取值为 auto/none/required/function，mode 为 function 时，name 为指定调用的函数名称
+func (req Request) ToolChoice() (mode string, name string) {
+	switch choice := req.ToolChoiceRaw.(type) {
+	case string:
+		if choice == "none" || choice == "required" {
+			return choice, ""
+		}
+	case map[string]any:
+		if fn, ok := choice["function"].(map[string]any); ok {
+			if n, ok := fn["name"].(string); ok && n != "" {
+				return "function", n
+			}
+		}
+	}
+
+	return "auto", ""
+}
+
+// MergeToolCalls 将流式响应中的工具调用增量数据合并到已有的工具调用中
+func MergeToolCalls(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, delta := range deltas {
+		index := len(calls)
+		// 缺少或非法的索引（负数或者跳过了尚未出现的工具调用）按新的工具调用追加，避免异常的索引导致分配大量内存
+		if delta.Index != nil && *delta.Index >= 0 && *delta.Index <= len(calls) {
+			index = *delta.Index
+		}
+
+		if index == len(calls) {
+			calls = append(calls, ToolCall{Index: &index})
+		}
+
+		call := &calls[index]
+		if delta.ID != "" {
+			call.ID = delta.ID
+		}
+
+		if delta.Type != "" {
+			call.Type = delta.Type
+		}
+
+		if delta.Function.Name != "" {
+			call.Function.Name = delta.Function.Name
+		}
+
+		call.Function.Arguments += delta.Function.Arguments
+	}
+
+	return calls
+}
@@ -0,0 +1,112 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestMergeToolCalls(t *testing.T) {
+	zero, one := 0, 1
+
+	var calls []ToolCall
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &zero, ID: "call_1", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather"}}})
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &zero, Function: FunctionCall{Arguments: `{"city":`}}})
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &one, ID: "call_2", Function: FunctionCall{Name: "get_time", Arguments: "{}"}}})
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &zero, Function: FunctionCall{Arguments: `"北京"}`}}})
+
+	assert.Equal(t, 2, len(calls))
+	assert.Equal(t, "call_1", calls[0].ID)
+	assert.Equal(t, "get_weather", calls[0].Function.Name)
+	assert.Equal(t, `{"city":"北京"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "get_time", calls[1].Function.Name)
+
+	// 非法的索引按新的工具调用追加
+	negative := -1
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &negative, ID: "call_3", Function: FunctionCall{Name: "search"}}})
+	assert.Equal(t, 3, len(calls))
+	assert.Equal(t, "call_3", calls[2].ID)
+	assert.Equal(t, 2, *calls[2].Index)
+
+	huge := 1 << 30
+	calls = MergeToolCalls(calls, []ToolCall{{Index: &huge, ID: "call_4", Function: FunctionCall{Name: "search"}}})
+	assert.Equal(t, 4, len(calls))
+	assert.Equal(t, "call_4", calls[3].ID)
+	assert.Equal(t, 3, *calls[3].Index)
+}
+
+func TestRequest_ToolChoice(t *testing.T) {
+	var req Request
+	assert.NoError(t, json.Unmarshal([]byte(`{"tool_choice": {"type": "function", "function": {"name": "get_weather"}}}`), &req))
+
+	mode, name := req.ToolChoice()
+	assert.Equal(t, "function", mode)
+	assert.Equal(t, "get_weather", name)
+
+	mode, _ = Request{ToolChoiceRaw: "none"}.ToolChoice()
+	assert.Equal(t, "none", mode)
+
+	mode, _ = Request{}.ToolChoice()
+	assert.Equal(t, "auto", mode)
+}
+
+func TestAnthropicChat_initMessagesRequest(t *testing.T) {
+	req := Request{
+		Model: "claude-3-opus-20240229",
+		Messages: Messages{
+			{Role: "system", Content: "你是一个助手"},
+			{Role: "user", Content: "北京和上海的天气怎么样"},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "call_1", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"北京"}`}},
+				{ID: "call_2", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"上海"}`}},
+			}},
+			{Role: RoleTool, ToolCallID: "call_1", Content: "晴"},
+			{Role: RoleTool, ToolCallID: "call_2", Content: "多云"},
+		},
+		Tools:         []Tool{{Type: ToolTypeFunction, Function: FunctionDefinition{Name: "get_weather"}}},
+		ToolChoiceRaw: "required",
+	}
+
+	anthropicReq := (&AnthropicChat{}).initMessagesRequest(req)
+	assert.Equal(t, "你是一个助手", anthropicReq.System)
+	assert.Equal(t, 3, len(anthropicReq.Messages))
+	assert.Equal(t, 2, len(anthropicReq.Messages[1].Content))
+	assert.Equal(t, "tool_use", anthropicReq.Messages[1].Content[0].Type)
+
+	// 连续的工具调用结果合并为一条 user 消息
+	assert.Equal(t, "user", anthropicReq.Messages[2].Role)
+	assert.Equal(t, 2, len(anthropicReq.Messages[2].Content))
+	assert.Equal(t, "call_2", anthropicReq.Messages[2].Content[1].ToolUseID)
+
+	assert.Equal(t, "any", anthropicReq.ToolChoice.Type)
+	assert.Equal(t, `{"type":"object","properties":{}}`, string(anthropicReq.Tools[0].InputSchema))
+}
+
+func TestGoogleFunctionResponse(t *testing.T) {
+	assert.Equal(t, `{"temperature":20}`, string(googleFunctionResponse(`{"temperature":20}`)))
+	assert.Equal(t, `{"content":"晴"}`, string(googleFunctionResponse("晴")))
+}
+
+func TestGoogleChat_initRequest_ToolResults(t *testing.T) {
+	googleReq, err := (&GoogleChat{}).initRequest(Request{
+		Model: "gemini-pro",
+		Messages: Messages{
+			{Role: "user", Content: "北京和上海的天气怎么样"},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "call_1", Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"北京"}`}},
+				{ID: "call_2", Function: FunctionCall{Name: "get_time", Arguments: `{"city":"上海"}`}},
+			}},
+			{Role: RoleTool, ToolCallID: "call_1", Content: "晴"},
+			{Role: RoleTool, ToolCallID: "call_2", Content: "多云"},
+		},
+	})
+	assert.NoError(t, err)
+
+	// 连续的工具调用结果合并为一条 function 消息
+	assert.Equal(t, 3, len(googleReq.Contents))
+	assert.Equal(t, 2, len(googleReq.Contents[1].Parts))
+	assert.Equal(t, 2, len(googleReq.Contents[2].Parts))
+	assert.Equal(t, "get_weather", googleReq.Contents[2].Parts[0].FunctionResponse.Name)
+	assert.Equal(t, "get_time", googleReq.Contents[2].Parts[1].FunctionResponse.Name)
+}
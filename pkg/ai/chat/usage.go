@@ -0,0 +1,53 @@
+package chat
+
+// Usage 一次聊天请求的 Token 用量
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	// Estimated 服务商未上报用量（或者只上报了一部分），使用分词器估算
+	Estimated bool `json:"estimated,omitempty"`
+	// ReportedInputTokens/ReportedOutputTokens 服务商上报的用量，未上报时为 0
+	ReportedInputTokens  int `json:"reported_input_tokens,omitempty"`
+	ReportedOutputTokens int `json:"reported_output_tokens,omitempty"`
+	// EstimatedInputTokens/EstimatedOutputTokens 分词器估算的用量，用于与服务商上报的用量对账
+	EstimatedInputTokens  int `json:"estimated_input_tokens,omitempty"`
+	EstimatedOutputTokens int `json:"estimated_output_tokens,omitempty"`
+}
+
+// Update 使用响应中服务商上报的用量更新，流式响应中服务商一般上报累计值，部分服务商只在最后一个事件中上报，因此取最大值
+func (u *Usage) Update(resp Response) {
+	if resp.InputTokens > u.InputTokens {
+		u.InputTokens = resp.InputTokens
+	}
+
+	if resp.OutputTokens > u.OutputTokens {
+		u.OutputTokens = resp.OutputTokens
+	}
+}
+
+// Total 输入、输出 Token 总数
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Fill 同时记录服务商上报的用量以及分词器估算的用量，服务商未上报的用量使用估算值，messages 为请求的上下文，reply 为模型的回复
+func (u Usage) Fill(messages Messages, reply Message, model string) Usage {
+	u.ReportedInputTokens, u.ReportedOutputTokens = u.InputTokens, u.OutputTokens
+
+	u.EstimatedInputTokens, _ = MessageTokenCount(messages, model)
+	if reply.Content != "" || len(reply.ToolCalls) > 0 {
+		u.EstimatedOutputTokens, _ = MessageTokenCount(Messages{reply}, model)
+	}
+
+	if u.InputTokens <= 0 {
+		u.InputTokens = u.EstimatedInputTokens
+		u.Estimated = true
+	}
+
+	if u.OutputTokens <= 0 && u.EstimatedOutputTokens > 0 {
+		u.OutputTokens = u.EstimatedOutputTokens
+		u.Estimated = true
+	}
+
+	return u
+}
@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestUsage(t *testing.T) {
+	messages := Messages{{Role: "user", Content: "hello"}}
+	reply := Message{Role: "assistant", Content: "hello world"}
+
+	// 流式响应中只在最后一个事件上报用量
+	fc := NewFakeChat(fake.New(fake.Options{}, []fake.Rule{{Reply: "hello world", InputTokens: 120, OutputTokens: 30}}))
+	stream, err := fc.ChatStream(context.TODO(), Request{Model: "echo", Messages: messages})
+	assert.NoError(t, err)
+
+	var usage Usage
+	for data := range stream {
+		usage.Update(data)
+	}
+
+	usage = usage.Fill(messages, reply, "gpt-3.5-turbo")
+	assert.Equal(t, 120, usage.InputTokens)
+	assert.Equal(t, 30, usage.OutputTokens)
+	assert.Equal(t, 150, usage.Total())
+	assert.False(t, usage.Estimated)
+	assert.Equal(t, 120, usage.ReportedInputTokens)
+	assert.Equal(t, 30, usage.ReportedOutputTokens)
+	// 服务商上报用量时同样记录估算值，用于对账
+	assert.True(t, usage.EstimatedInputTokens > 0)
+	assert.True(t, usage.EstimatedOutputTokens > 0)
+
+	// 累计值取最大
+	usage = Usage{}
+	usage.Update(Response{InputTokens: 10, OutputTokens: 1})
+	usage.Update(Response{InputTokens: 10, OutputTokens: 5})
+	usage.Update(Response{})
+	assert.Equal(t, 10, usage.InputTokens)
+	assert.Equal(t, 5, usage.OutputTokens)
+
+	// 未上报用量时使用分词器估算
+	inputTokens, _ := MessageTokenCount(messages, "gpt-3.5-turbo")
+	outputTokens, _ := MessageTokenCount(Messages{reply}, "gpt-3.5-turbo")
+	usage = Usage{}.Fill(messages, reply, "gpt-3.5-turbo")
+	assert.Equal(t, inputTokens, usage.InputTokens)
+	assert.Equal(t, outputTokens, usage.OutputTokens)
+	assert.True(t, usage.Estimated)
+	assert.Equal(t, 0, usage.ReportedInputTokens)
+	assert.Equal(t, inputTokens, usage.EstimatedInputTokens)
+	assert.Equal(t, outputTokens, usage.EstimatedOutputTokens)
+
+	usage = Usage{InputTokens: 100}.Fill(messages, Message{Role: "assistant"}, "gpt-3.5-turbo")
+	assert.Equal(t, 0, usage.OutputTokens)
+	assert.False(t, usage.Estimated)
+
+	// 输入、输出分别计费
+	coins.SetTextPrice("usage-test-model", 10)
+	assert.EqualValues(t, 2, coins.GetTextCoins("usage-test-model", 120, 30))
+}
@@ -2,11 +2,14 @@ package chat
 
 import (
 	"context"
-	"github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"encoding/json"
+	"fmt"
+	"sync"
 
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/go-utils/array"
-	openailib "github.com/sashabaranov/go-openai"
 )
 
 const (
@@ -14,87 +17,192 @@ const (
 	ModelBeiChou = "beichou"
 )
 
+// VirtualModels 返回所有虚拟模型，包括内置的南贤、北丑以及模型目录中定义的虚拟模型
+func VirtualModels(conf *config.Config) []Model {
+	return array.Filter(Models(conf, true), func(item Model, _ int) bool {
+		return item.Virtual != nil && item.Virtual.Model != ""
+	})
+}
+
+// virtualModelIndex 虚拟模型索引，模型 ID、模型名称以及别名到虚拟模型的映射
+// 路由时每个请求都需要查询虚拟模型，因此缓存索引，配置或者模型目录变化后重新构建
+type virtualModelIndex struct {
+	conf    *config.Config
+	version uint64
+	models  map[string]Model
+}
+
+var (
+	virtualIndexLock sync.RWMutex
+	virtualIndex     *virtualModelIndex
+)
+
+// loadVirtualModelIndex 返回虚拟模型索引，缓存的索引已经失效时重新构建
+func loadVirtualModelIndex(conf *config.Config) *virtualModelIndex {
+	version := catalog.Default().Version()
+
+	virtualIndexLock.RLock()
+	index := virtualIndex
+	virtualIndexLock.RUnlock()
+
+	if index != nil && index.conf == conf && index.version == version {
+		return index
+	}
+
+	index = &virtualModelIndex{conf: conf, version: version, models: make(map[string]Model)}
+	for _, item := range VirtualModels(conf) {
+		// 多个虚拟模型匹配同一个名称时，使用排在前面的模型
+		for _, key := range append([]string{item.ID, item.RealID()}, item.Aliases...) {
+			if _, ok := index.models[key]; !ok {
+				index.models[key] = item
+			}
+		}
+	}
+
+	virtualIndexLock.Lock()
+	virtualIndex = index
+	virtualIndexLock.Unlock()
+
+	return index
+}
+
+// LookupVirtualModel 查询虚拟模型，model 可以是模型 ID、模型名称或者别名
+func LookupVirtualModel(conf *config.Config, model string) (Model, bool) {
+	item, ok := loadVirtualModelIndex(conf).models[model]
+	return item, ok
+}
+
+// VirtualChat 虚拟模型，将请求转换为驱动虚拟模型的模型请求，每个虚拟模型可以使用不同的渠道
 type VirtualChat struct {
-	imp  Chat
-	conf config.VirtualModel
+	registry *Registry
+	conf     *config.Config
+}
+
+func NewVirtualChat(registry *Registry, conf *config.Config) *VirtualChat {
+	return &VirtualChat{registry: registry, conf: conf}
 }
 
-func NewVirtualChat(imp Chat, conf config.VirtualModel) *VirtualChat {
-	return &VirtualChat{imp: imp, conf: conf}
+// IsVirtualModel 是否为虚拟模型
+func (chat *VirtualChat) IsVirtualModel(model string) bool {
+	_, ok := LookupVirtualModel(chat.conf, model)
+	return ok
 }
 
 func (chat *VirtualChat) Chat(ctx context.Context, req Request) (*Response, error) {
-	return chat.imp.Chat(ctx, chat.prepare(req))
+	imp, req, err := chat.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return imp.Chat(ctx, req)
 }
 
 func (chat *VirtualChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
-	return chat.imp.ChatStream(ctx, chat.prepare(req))
+	imp, req, err := chat.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return imp.ChatStream(ctx, req)
 }
 
 func (chat *VirtualChat) MaxContextLength(model string) int {
-	var prompt string
-	if model == ModelNanXian {
-		model = chat.conf.NanxianRel
-		prompt = chat.conf.NanxianPrompt
+	vm, ok := LookupVirtualModel(chat.conf, model)
+	if !ok {
+		return 4000
 	}
 
-	if model == ModelBeiChou {
-		model = chat.conf.BeichouRel
-		prompt = chat.conf.BeichouPrompt
+	imp, err := chat.backend(vm)
+	if err != nil {
+		return 4000
 	}
 
 	var promptTokens int
-	if prompt != "" {
-		promptTokens, _ = openai.NumTokensFromMessages([]openailib.ChatCompletionMessage{{Role: "system", Content: prompt}}, model)
+	if vm.Virtual.Prompt != "" {
+		promptTokens, _ = MessageTokenCount(Messages{{Role: "system", Content: vm.Virtual.Prompt}}, vm.Virtual.Model)
 	}
 
-	return chat.imp.MaxContextLength(model) - promptTokens
+	return imp.MaxContextLength(vm.Virtual.Model) - promptTokens
 }
 
-func (chat *VirtualChat) prepare(req Request) Request {
-	if req.Model == ModelNanXian {
-		req.Model = chat.conf.NanxianRel
-		if chat.conf.NanxianPrompt != "" {
-			var hasSystemMessage bool
-			req.Messages = array.Map(req.Messages, func(m Message, _ int) Message {
-				if m.Role == "system" {
-					hasSystemMessage = true
-					m.Content = chat.conf.NanxianPrompt + "\n" + m.Content
-				}
-
-				return m
-			})
-
-			if !hasSystemMessage {
-				req.Messages = append(
-					Messages{{Role: "system", Content: chat.conf.NanxianPrompt}},
-					req.Messages...,
-				)
-			}
+// backend 返回驱动虚拟模型的 Chat 实现
+// 指定了渠道时使用该渠道（兼容历史配置，渠道不存在时按照模型名称查找），否则按照驱动模型选择渠道
+func (chat *VirtualChat) backend(vm Model) (Chat, error) {
+	var imp Chat
+	if vm.Virtual.Channel != "" {
+		imp = chat.registry.Channel(vm.Virtual.Channel)
+		if imp == nil {
+			imp = chat.registry.Imp(vm.Virtual.Channel)
 		}
+	} else {
+		imp = chat.registry.Imp(vm.Virtual.Model)
+	}
+
+	if imp == nil {
+		return nil, fmt.Errorf("virtual model %s: no channel available for %s", vm.ID, vm.Virtual.Model)
+	}
+
+	// 虚拟模型不能由虚拟模型驱动，避免循环调用
+	if _, ok := imp.(*VirtualChat); ok {
+		return nil, fmt.Errorf("virtual model %s: backed by another virtual model", vm.ID)
+	}
+
+	return imp, nil
+}
+
+// prepare 将虚拟模型请求转换为驱动模型的请求：替换模型名称，注入系统提示语，补充默认的采样参数
+func (chat *VirtualChat) prepare(req Request) (Chat, Request, error) {
+	vm, ok := LookupVirtualModel(chat.conf, req.Model)
+	if !ok {
+		return nil, req, fmt.Errorf("virtual model %s not found", req.Model)
 	}
 
-	if req.Model == ModelBeiChou {
-		req.Model = chat.conf.BeichouRel
-		if chat.conf.BeichouPrompt != "" {
-			var hasSystemMessage bool
-			req.Messages = array.Map(req.Messages, func(m Message, _ int) Message {
-				if m.Role == "system" {
-					hasSystemMessage = true
-					m.Content = chat.conf.BeichouPrompt + "\n" + m.Content
-				}
-
-				return m
-			})
-
-			if !hasSystemMessage {
-				req.Messages = append(
-					Messages{{Role: "system", Content: chat.conf.BeichouPrompt}},
-					req.Messages...,
-				)
+	imp, err := chat.backend(vm)
+	if err != nil {
+		return nil, req, err
+	}
+
+	req.Model = vm.Virtual.Model
+	if vm.Virtual.Prompt != "" {
+		var hasSystemMessage bool
+		req.Messages = array.Map(req.Messages, func(m Message, _ int) Message {
+			if m.Role == "system" {
+				hasSystemMessage = true
+				m.Content = vm.Virtual.Prompt + "\n" + m.Content
 			}
+
+			return m
+		})
+
+		if !hasSystemMessage {
+			req.Messages = append(
+				Messages{{Role: "system", Content: vm.Virtual.Prompt}},
+				req.Messages...,
+			)
 		}
 	}
 
-	return req
+	if len(vm.Virtual.Parameters) > 0 {
+		data, _ := json.Marshal(vm.Virtual.Parameters)
+		sampling, err := ParseSampling(string(data))
+		if err != nil {
+			log.F(log.M{"model": vm.ID}).Warningf("invalid virtual model parameters: %v", err)
+		} else {
+			req.Sampling = sampling.Merge(req.Sampling)
+		}
+	}
+
+	return imp, req, nil
+}
+
+// virtualModelOf 内置虚拟模型的实现
+func virtualModelOf(conf *config.Config, model string) *catalog.Virtual {
+	switch model {
+	case ModelNanXian:
+		return &catalog.Virtual{Model: conf.VirtualModel.NanxianRel, Channel: conf.VirtualModel.Implementation, Prompt: conf.VirtualModel.NanxianPrompt}
+	case ModelBeiChou:
+		return &catalog.Virtual{Model: conf.VirtualModel.BeichouRel, Channel: conf.VirtualModel.Implementation, Prompt: conf.VirtualModel.BeichouPrompt}
+	}
+
+	return nil
 }
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestVirtualChat(t *testing.T) {
+	conf := &config.Config{
+		EnableVirtualModel: true,
+		VirtualModel: config.VirtualModel{
+			Implementation: "openai",
+			NanxianRel:     "gpt-3.5-turbo",
+			NanxianPrompt:  "你是南贤",
+			BeichouRel:     "gpt-4",
+		},
+	}
+
+	catalog.Default().Replace([]catalog.Model{
+		{
+			ID:       "virtual:translator",
+			Category: "virtual",
+			Virtual: &catalog.Virtual{
+				Model:      "qwen-max",
+				Prompt:     "你是一名翻译",
+				Parameters: map[string]interface{}{"temperature": 0.2, "top_p": 0.5},
+			},
+			InputPrice: 2,
+		},
+		{ID: "virtual:beichou", Virtual: &catalog.Virtual{Channel: "dashscope"}},
+	})
+	defer catalog.Default().Replace(nil)
+
+	openai := &failoverTestClient{name: "openai"}
+	dashscope := &failoverTestClient{name: "dashscope"}
+	registry := newFailoverTestRegistry(openai, dashscope)
+	registry.Register(Channel{Name: "dashscope", Models: []string{"qwen-max"}}, dashscope)
+
+	virtual := NewVirtualChat(registry, conf)
+	assert.True(t, virtual.IsVirtualModel(ModelNanXian))
+	assert.True(t, virtual.IsVirtualModel("translator"))
+	assert.False(t, virtual.IsVirtualModel("gpt-4"))
+
+	// 内置虚拟模型，没有 system 消息时注入系统提示语
+	imp, req, err := virtual.prepare(Request{Model: ModelNanXian, Messages: Messages{{Role: "user", Content: "你好"}}})
+	assert.NoError(t, err)
+	assert.True(t, imp == Chat(openai))
+	assert.Equal(t, "gpt-3.5-turbo", req.Model)
+	assert.Equal(t, 2, len(req.Messages))
+	assert.Equal(t, "你是南贤", req.Messages[0].Content)
+
+	// 模型目录中覆盖了内置虚拟模型的渠道
+	imp, req, err = virtual.prepare(Request{Model: ModelBeiChou})
+	assert.NoError(t, err)
+	assert.True(t, imp == Chat(dashscope))
+	assert.Equal(t, "gpt-4", req.Model)
+
+	// 模型目录中定义的虚拟模型，请求中的采样参数优先
+	temperature := 0.8
+	imp, req, err = virtual.prepare(Request{
+		Model:    "translator",
+		Messages: Messages{{Role: "system", Content: "输出英文"}, {Role: "user", Content: "你好"}},
+		Sampling: Sampling{Temperature: &temperature},
+	})
+	assert.NoError(t, err)
+	assert.True(t, imp == Chat(dashscope))
+	assert.Equal(t, "qwen-max", req.Model)
+	assert.Equal(t, 2, len(req.Messages))
+	assert.Equal(t, "你是一名翻译\n输出英文", req.Messages[0].Content)
+	assert.EqualValues(t, 0.8, req.TemperatureValue())
+	assert.EqualValues(t, 0.5, req.TopPValue())
+
+	resp, err := virtual.Chat(context.TODO(), Request{Model: "translator"})
+	assert.NoError(t, err)
+	assert.Equal(t, "dashscope:qwen-max", resp.Text)
+
+	_, err = virtual.Chat(context.TODO(), Request{Model: "unknown"})
+	assert.True(t, err != nil)
+
+	// 虚拟模型配置了价格时按照虚拟模型计费，否则按照驱动模型计费
+	assert.Equal(t, "translator", Request{Model: "translator"}.ResolveCalFeeModel(conf))
+	assert.Equal(t, "gpt-3.5-turbo", Request{Model: ModelNanXian}.ResolveCalFeeModel(conf))
+	assert.Equal(t, "gpt-4", Request{Model: "gpt-4"}.ResolveCalFeeModel(conf))
+}
+
+func TestLookupVirtualModel_Reload(t *testing.T) {
+	conf := &config.Config{}
+	defer catalog.Default().Replace(nil)
+
+	catalog.Default().Replace([]catalog.Model{{ID: "virtual:writer", Virtual: &catalog.Virtual{Model: "gpt-4"}}})
+	vm, ok := LookupVirtualModel(conf, "writer")
+	assert.True(t, ok)
+	assert.Equal(t, "gpt-4", vm.Virtual.Model)
+
+	// 模型目录重新加载后，缓存的虚拟模型索引失效
+	catalog.Default().Replace([]catalog.Model{{ID: "virtual:writer", Aliases: []string{"作家"}, Virtual: &catalog.Virtual{Model: "qwen-max"}}})
+	vm, ok = LookupVirtualModel(conf, "作家")
+	assert.True(t, ok)
+	assert.Equal(t, "qwen-max", vm.Virtual.Model)
+
+	catalog.Default().Replace(nil)
+	_, ok = LookupVirtualModel(conf, "writer")
+	assert.False(t, ok)
+}
@@ -10,6 +10,20 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+func init() {
+	registerBuiltinChannel(Channel{
+		Name:     "xfyun",
+		Aliases:  []string{"讯飞星火"},
+		Prefixes: []string{"讯飞星火:"},
+		Models:   []string{string(xfyun.ModelGeneralV1_5), string(xfyun.ModelGeneralV2), string(xfyun.ModelGeneralV3)},
+	}, func(ai *AI) Chat { return ai.Xfyun })
+}
+
+// xfyunSamplingCapability 讯飞星火支持的采样参数，temperature 取值范围为 (0, 1]
+var xfyunSamplingCapability = SamplingCapability{
+	Temperature: &Range{Min: 0.01, Max: 1},
+}
+
 type XFYunChat struct {
 	client *xfyun.XFYunAI
 }
@@ -68,15 +82,17 @@ func (chat *XFYunChat) Chat(ctx context.Context, req Request) (*Response, error)
 	}
 
 	var content string
+	var usage Usage
 	for msg := range stream {
 		if msg.ErrorCode != "" {
 			return nil, fmt.Errorf("%s %s", msg.ErrorCode, msg.Error)
 		}
 
 		content += msg.Text
+		usage.Update(msg)
 	}
 
-	return &Response{Text: content}, nil
+	return &Response{Text: content, InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens}, nil
 }
 
 func (chat *XFYunChat) ChatStream(ctx context.Context, req Request) (<-chan Response, error) {
@@ -85,7 +101,10 @@ func (chat *XFYunChat) ChatStream(ctx context.Context, req Request) (<-chan Resp
 		return nil, err
 	}
 
-	stream, err := chat.client.ChatStream(ctx, xfyun.Model(model), messages)
+	sampling := req.Sampling.Clamp(xfyunSamplingCapability)
+	stream, err := chat.client.ChatStream(ctx, xfyun.Model(model), messages, xfyun.ChatParams{
+		Temperature: sampling.TemperatureValue(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -6,21 +6,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/go-utils/ternary"
 	"io"
-	"math/rand"
 	"net/http"
 	"strings"
 )
 
 type DashScope struct {
-	apiKeys    []string
+	keys       *keypool.Pool[string]
 	serviceURL string
 }
 
 func New(apiKeys ...string) *DashScope {
+	return NewWithOptions(keypool.Options{}, apiKeys...)
+}
+
+// NewWithOptions 创建 DashScope 客户端，opts 为密钥池配置
+func NewWithOptions(opts keypool.Options, apiKeys ...string) *DashScope {
 	return &DashScope{
-		apiKeys:    apiKeys,
+		keys:       keypool.New("dashscope", keypool.Keys(apiKeys), opts),
 		serviceURL: "https://dashscope.aliyuncs.com",
 	}
 }
@@ -83,6 +88,74 @@ type ChatInput struct {
 type Message struct {
 	Role    string           `json:"role,omitempty"`
 	Content []MessageContent `json:"content,omitempty"`
+	// Text 纯文本消息内容，文本生成模型使用 messages 格式（工具调用）时，content 为字符串
+	Text string `json:"-"`
+	// Name 工具调用结果消息中为函数名称
+	Name string `json:"name,omitempty"`
+	// ToolCalls 模型发起的工具调用
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID 工具调用结果对应的调用 ID
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	type alias Message
+	if len(m.Content) > 0 {
+		return json.Marshal(alias(m))
+	}
+
+	return json.Marshal(struct {
+		alias
+		Content string `json:"content"`
+	}{alias: alias(m), Content: m.Text})
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Message
+	var msg struct {
+		alias
+		Content json.RawMessage `json:"content,omitempty"`
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	*m = Message(msg.alias)
+	if len(msg.Content) == 0 || string(msg.Content) == "null" {
+		return nil
+	}
+
+	// 文本生成模型的 content 为字符串，多模态模型的 content 为数组
+	if msg.Content[0] == '"' {
+		return json.Unmarshal(msg.Content, &m.Text)
+	}
+
+	return json.Unmarshal(msg.Content, &m.Content)
+}
+
+// ToolCall 模型发起的工具调用
+type ToolCall struct {
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// Tool 模型可以调用的工具
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
 }
 
 type MessageContent struct {
@@ -91,6 +164,8 @@ type MessageContent struct {
 }
 
 type ChatParameters struct {
+	// Temperature 用于控制随机性和多样性的程度。取值范围为 [0, 2)，取值越大，生成的随机性越高
+	Temperature float64 `json:"temperature,omitempty"`
 	// TopP 生成时，核采样方法的概率阈值。例如，取值为0.8时，仅保留累计概率之和大于等于0.8的概率分布中的token，
 	// 作为随机采样的候选集。取值范围为(0,1.0)，取值越大，生成的随机性越高；取值越低，生成的随机性越低。
 	// 默认值 0.8。注意，取值不要大于等于1
@@ -101,9 +176,15 @@ type ChatParameters struct {
 	// Seed 生成时，随机数的种子，用于控制模型生成的随机性。如果使用相同的种子，每次运行生成的结果都将相同；
 	// 当需要复现模型的生成结果时，可以使用相同的种子。seed参数支持无符号64位整数类型。默认值 1234
 	Seed int `json:"seed,omitempty"`
+	// Stop 生成时，遇到这些字符串时停止生成，生成的内容中不包含停止字符串
+	Stop []string `json:"stop,omitempty"`
 	// EnableSearch 生成时，是否参考夸克搜索的结果。注意：打开搜索并不意味着一定会使用搜索结果；
 	// 如果打开搜索，模型会将搜索结果作为prompt，进而“自行判断”是否生成结合搜索结果的文本，默认为false
 	EnableSearch bool `json:"enable_search,omitempty"`
+	// ResultFormat 返回结果的格式，可选值为 text/message，使用工具调用时必须为 message
+	ResultFormat string `json:"result_format,omitempty"`
+	// Tools 模型可以调用的工具列表
+	Tools []Tool `json:"tools,omitempty"`
 }
 
 type ChatHistory struct {
@@ -138,9 +219,12 @@ type ChatOutput struct {
 }
 
 type Choice struct {
-	Message Message `json:"message,omitempty"`
+	Message      Message `json:"message,omitempty"`
+	FinishReason string  `json:"finish_reason,omitempty"`
 }
 
+const ResultFormatMessage = "message"
+
 const (
 	FinishReasonStop   = "stop"
 	FinishReasonLength = "length"
@@ -165,11 +249,10 @@ func (ds *DashScope) Chat(ctx context.Context, req ChatRequest) (*ChatResponse,
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-DashScope-DataInspection", "enable")
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +288,6 @@ func (ds *DashScope) ChatStream(ctx context.Context, req ChatRequest) (<-chan Ch
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("Cache-Control", "no-cache")
@@ -213,7 +295,7 @@ func (ds *DashScope) ChatStream(ctx context.Context, req ChatRequest) (<-chan Ch
 	httpReq.Header.Set("X-DashScope-SSE", "enable")
 	httpReq.Header.Set("X-DashScope-DataInspection", "enable")
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -267,8 +349,15 @@ func (ds *DashScope) ChatStream(ctx context.Context, req ChatRequest) (<-chan Ch
 			}
 
 			if len(chatResponse.Output.Choices) > 0 && chatResponse.Output.Text == "" {
-				if len(chatResponse.Output.Choices[0].Message.Content) > 0 {
-					chatResponse.Output.Text = chatResponse.Output.Choices[0].Message.Content[0].Text
+				choice := chatResponse.Output.Choices[0]
+				if len(choice.Message.Content) > 0 {
+					chatResponse.Output.Text = choice.Message.Content[0].Text
+				} else {
+					chatResponse.Output.Text = choice.Message.Text
+				}
+
+				if chatResponse.Output.FinishReason == "" {
+					chatResponse.Output.FinishReason = choice.FinishReason
 				}
 			}
 
@@ -335,9 +424,7 @@ func (ds *DashScope) ImageTaskStatus(ctx context.Context, taskID string) (*Image
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
-
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -357,6 +444,21 @@ func (ds *DashScope) ImageTaskStatus(ctx context.Context, taskID string) (*Image
 	return &chatResp, nil
 }
 
-func (ds *DashScope) apiKeyLoadBalanced() string {
-	return ds.apiKeys[rand.Intn(len(ds.apiKeys))]
+// do 从密钥池中选择密钥发起请求，密钥在响应体关闭时归还
+func (ds *DashScope) do(httpReq *http.Request) (*http.Response, error) {
+	lease, err := ds.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+lease.Value)
+
+	httpResp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		lease.Done(0)
+		return nil, err
+	}
+
+	lease.DoneOnClose(httpResp)
+	return httpResp, nil
 }
@@ -2,6 +2,7 @@ package dashscope_test
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
 	"os"
 	"testing"
@@ -55,3 +56,20 @@ func TestImageTaskStatus(t *testing.T) {
 
 	log.With(resp).Debug("resp")
 }
+
+func TestMessage_JSON(t *testing.T) {
+	data, err := json.Marshal(dashscope.Message{Role: "user", Text: "你好"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"role":"user","content":"你好"}`, string(data))
+
+	data, err = json.Marshal(dashscope.Message{Role: "user", Content: []dashscope.MessageContent{{Text: "你好"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"role":"user","content":[{"text":"你好"}]}`, string(data))
+
+	var msg dashscope.Message
+	assert.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{}"}}]}`), &msg))
+	assert.Equal(t, "get_weather", msg.ToolCalls[0].Function.Name)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":[{"text":"你好"}]}`), &msg))
+	assert.Equal(t, "你好", msg.Content[0].Text)
+}
@@ -0,0 +1,82 @@
+package dashscope
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+)
+
+const (
+	// ModelTextEmbeddingV1 通用文本向量 v1，向量维度 1536
+	ModelTextEmbeddingV1 = "text-embedding-v1"
+	// ModelTextEmbeddingV2 通用文本向量 v2，向量维度 1536
+	ModelTextEmbeddingV2 = "text-embedding-v2"
+)
+
+type TextEmbeddingRequest struct {
+	// Model 指明需要调用的模型，可选 text-embedding-v1 和 text-embedding-v2
+	Model string             `json:"model,omitempty"`
+	Input TextEmbeddingInput `json:"input,omitempty"`
+}
+
+type TextEmbeddingInput struct {
+	// Texts 待处理的文本列表，单次最多 25 条，每条最长 2048 tokens
+	Texts []string `json:"texts,omitempty"`
+}
+
+type TextEmbeddingResponse struct {
+	// RequestID 本次请求的系统唯一码
+	RequestID string              `json:"request_id,omitempty"`
+	Output    TextEmbeddingOutput `json:"output,omitempty"`
+	Usage     TextEmbeddingUsage  `json:"usage,omitempty"`
+}
+
+type TextEmbeddingOutput struct {
+	Embeddings []TextEmbedding `json:"embeddings,omitempty"`
+}
+
+type TextEmbedding struct {
+	// TextIndex 向量对应的输入文本在 texts 中的下标
+	TextIndex int       `json:"text_index"`
+	Embedding []float32 `json:"embedding,omitempty"`
+}
+
+type TextEmbeddingUsage struct {
+	// TotalTokens 本次请求输入内容的 token 数目
+	TotalTokens int `json:"total_tokens,omitempty"`
+}
+
+// TextEmbedding 通用文本向量 API
+// https://help.aliyun.com/zh/dashscope/developer-reference/text-embedding-api-details
+func (ds *DashScope) TextEmbedding(ctx context.Context, req TextEmbeddingRequest) (*TextEmbeddingResponse, error) {
+	lease, err := ds.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := misc.RestyClient(2).R().
+		SetHeader("Authorization", "Bearer "+lease.Value).
+		SetHeader("Content-Type", "application/json").
+		SetContext(ctx).
+		SetBody(req).
+		Post(ds.serviceURL + "/api/v1/services/embeddings/text-embedding/text-embedding")
+	if err != nil {
+		lease.Done(0)
+		return nil, fmt.Errorf("failed to request: %v", err)
+	}
+
+	lease.Done(resp.StatusCode())
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("request failed: %s", string(resp.Body()))
+	}
+
+	var ret TextEmbeddingResponse
+	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %v", err)
+	}
+
+	return &ret, nil
+}
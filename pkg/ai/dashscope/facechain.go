@@ -33,16 +33,24 @@ type FaceChainPersonDetectOutput struct {
 // FaceChainDetect 人物图像检测 API
 // https://help.aliyun.com/zh/dashscope/developer-reference/facechain-face-detection?spm=a2c4g.11186623.0.0.659466b5S9Xqng
 func (ds *DashScope) FaceChainDetect(ctx context.Context, req FaceChainPersonDetectInput) (*FaceChainPersonDetectResponse, error) {
+	lease, err := ds.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := misc.RestyClient(2).R().
-		SetHeader("Authorization", "Bearer "+ds.apiKeyLoadBalanced()).
+		SetHeader("Authorization", "Bearer "+lease.Value).
 		SetHeader("Content-Type", "application/json").
 		SetContext(ctx).
 		SetBody(FaceChainPersonDetectRequest{Input: req, Model: "facechain-facedetect"}).
 		Post(ds.serviceURL + "/api/v1/services/vision/facedetection/detect")
 	if err != nil {
+		lease.Done(0)
 		return nil, fmt.Errorf("failed to request: %v", err)
 	}
 
+	lease.Done(resp.StatusCode())
+
 	if resp.IsError() {
 		return nil, fmt.Errorf("request failed: %s", string(resp.Body()))
 	}
@@ -2,6 +2,7 @@ package dashscope
 
 import (
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/go-utils/array"
 	"strings"
@@ -16,6 +17,6 @@ func (Provider) Register(binder infra.Binder) {
 			return strings.TrimSpace(key) != ""
 		})
 
-		return New(array.Distinct(keys)...)
+		return NewWithOptions(keypool.OptionsFromConfig(conf), array.Distinct(keys)...)
 	})
 }
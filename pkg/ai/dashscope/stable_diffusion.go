@@ -57,11 +57,10 @@ func (ds *DashScope) StableDiffusion(ctx context.Context, req StableDiffusionReq
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-DashScope-Async", "enable")
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
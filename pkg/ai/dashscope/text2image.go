@@ -89,11 +89,10 @@ func (ds *DashScope) Text2Image(ctx context.Context, req Text2ImageRequest) (*Im
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-DashScope-Async", "enable")
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
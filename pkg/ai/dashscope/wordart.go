@@ -108,11 +108,10 @@ func (ds *DashScope) WordArtTexture(ctx context.Context, req WordArtTextureReque
 		return nil, err
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+ds.apiKeyLoadBalanced())
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-DashScope-Async", "enable")
 
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := ds.do(httpReq)
 	if err != nil {
 		return nil, err
 	}
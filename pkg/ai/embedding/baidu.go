@@ -0,0 +1,52 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/baidu"
+	"github.com/mylxsw/go-utils/array"
+)
+
+// baiduBatchSize 文心千帆单次请求的最大文本数量
+const baiduBatchSize = 16
+
+type BaiduEmbedding struct {
+	ai baidu.BaiduAI
+}
+
+func NewBaiduEmbedding(ai baidu.BaiduAI) *BaiduEmbedding {
+	return &BaiduEmbedding{ai: ai}
+}
+
+func (e *BaiduEmbedding) Models() []string {
+	return array.Map(baidu.EmbeddingModels, func(model baidu.EmbeddingModel, _ int) string { return string(model) })
+}
+
+func (e *BaiduEmbedding) Embedding(ctx context.Context, req Request) (*Response, error) {
+	result := &Response{Model: req.Model, Embeddings: make([][]float32, len(req.Input))}
+	for i, batch := range batches(req.Input, baiduBatchSize) {
+		resp, err := e.ai.Embedding(ctx, baidu.EmbeddingModel(req.Model), baidu.EmbeddingRequest{
+			Input:  batch,
+			UserID: req.User,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("baidu embedding failed: %w", err)
+		}
+
+		if resp == nil {
+			return nil, errors.New("baidu embedding is not available")
+		}
+
+		for _, item := range resp.Data {
+			if err := place(result.Embeddings, i*baiduBatchSize, item.Index, item.Embedding); err != nil {
+				return nil, err
+			}
+		}
+
+		result.InputTokens += resp.Usage.TotalTokens
+	}
+
+	return result, nil
+}
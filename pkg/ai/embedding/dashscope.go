@@ -0,0 +1,46 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
+)
+
+// dashscopeBatchSize 灵积单次请求的最大文本数量
+const dashscopeBatchSize = 25
+
+type DashScopeEmbedding struct {
+	ds *dashscope.DashScope
+}
+
+func NewDashScopeEmbedding(ds *dashscope.DashScope) *DashScopeEmbedding {
+	return &DashScopeEmbedding{ds: ds}
+}
+
+func (e *DashScopeEmbedding) Models() []string {
+	return []string{dashscope.ModelTextEmbeddingV1, dashscope.ModelTextEmbeddingV2}
+}
+
+func (e *DashScopeEmbedding) Embedding(ctx context.Context, req Request) (*Response, error) {
+	result := &Response{Model: req.Model, Embeddings: make([][]float32, len(req.Input))}
+	for i, batch := range batches(req.Input, dashscopeBatchSize) {
+		resp, err := e.ds.TextEmbedding(ctx, dashscope.TextEmbeddingRequest{
+			Model: req.Model,
+			Input: dashscope.TextEmbeddingInput{Texts: batch},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("dashscope embedding failed: %w", err)
+		}
+
+		for _, item := range resp.Output.Embeddings {
+			if err := place(result.Embeddings, i*dashscopeBatchSize, item.TextIndex, item.Embedding); err != nil {
+				return nil, err
+			}
+		}
+
+		result.InputTokens += resp.Usage.TotalTokens
+	}
+
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package embedding
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/tokenizer"
+)
+
+var (
+	ErrModelNotSupported = errors.New("embedding model not supported")
+	ErrEmptyInput        = errors.New("embedding input is empty")
+)
+
+// Request 文本向量化请求
+type Request struct {
+	// Model 向量模型
+	Model string `json:"model"`
+	// Input 待向量化的文本列表
+	Input []string `json:"input"`
+	// User 最终用户标识，部分厂商用于滥用检测
+	User string `json:"user,omitempty"`
+}
+
+// Response 文本向量化响应
+type Response struct {
+	Model string `json:"model"`
+	// Embeddings 向量列表，与请求中的 Input 一一对应
+	Embeddings [][]float32 `json:"embeddings"`
+	// InputTokens 厂商计费的 token 数量，厂商未返回时根据输入内容估算
+	InputTokens int `json:"input_tokens"`
+}
+
+// Embedding 文本向量化接口
+type Embedding interface {
+	// Embedding 将文本列表转换为向量
+	Embedding(ctx context.Context, req Request) (*Response, error)
+	// Models 支持的模型列表
+	Models() []string
+}
+
+// Imp 文本向量化服务，根据请求的模型选择对应厂商的实现
+type Imp struct {
+	models map[string]Embedding
+}
+
+func New(imps ...Embedding) *Imp {
+	ai := &Imp{models: make(map[string]Embedding)}
+	for _, imp := range imps {
+		ai.Register(imp)
+	}
+
+	return ai
+}
+
+// Register 注册厂商实现，模型重复时后注册的优先
+func (ai *Imp) Register(imp Embedding) {
+	for _, model := range imp.Models() {
+		ai.models[model] = imp
+	}
+}
+
+// Support 是否支持指定的模型
+func (ai *Imp) Support(model string) bool {
+	_, ok := ai.models[model]
+	return ok
+}
+
+// Models 所有可用的模型，按照名称排序
+func (ai *Imp) Models() []string {
+	models := make([]string, 0, len(ai.models))
+	for model := range ai.models {
+		models = append(models, model)
+	}
+
+	sort.Strings(models)
+	return models
+}
+
+func (ai *Imp) Embedding(ctx context.Context, req Request) (*Response, error) {
+	imp, ok := ai.models[req.Model]
+	if !ok {
+		return nil, ErrModelNotSupported
+	}
+
+	if len(req.Input) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	resp, err := imp.Embedding(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Model == "" {
+		resp.Model = req.Model
+	}
+
+	if resp.InputTokens <= 0 {
+		resp.InputTokens = EstimateTokens(req.Model, req.Input)
+	}
+
+	return resp, nil
+}
+
+// EstimateTokens 估算输入文本的 token 数量
+func EstimateTokens(model string, input []string) int {
+	tk := tokenizer.ForModel(model)
+
+	var count int
+	for _, text := range input {
+		count += tk.Count(text)
+	}
+
+	return count
+}
+
+// batches 按照厂商单次请求的最大文本数量对输入分批
+func batches(input []string, size int) [][]string {
+	result := make([][]string, 0, (len(input)+size-1)/size)
+	for start := 0; start < len(input); start += size {
+		end := start + size
+		if end > len(input) {
+			end = len(input)
+		}
+
+		result = append(result, input[start:end])
+	}
+
+	return result
+}
+
+// place 将一批向量按照下标放入结果中，offset 为该批次在整体输入中的起始位置
+func place(result [][]float32, offset, index int, vector []float32) error {
+	if index < 0 || offset+index >= len(result) {
+		return errors.New("invalid embedding index")
+	}
+
+	result[offset+index] = vector
+	return nil
+}
+
+// EncodeBase64 将向量编码为 base64 字符串（float32 小端序），与 OpenAI Embeddings API 的 base64 格式一致，也用于向量的存储
+func EncodeBase64(vector []float32) string {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeBase64 解码 EncodeBase64 编码的向量
+func DecodeBase64(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode vector failed: %w", err)
+	}
+
+	if len(data)%4 != 0 {
+		return nil, errors.New("invalid vector length")
+	}
+
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	return vector, nil
+}
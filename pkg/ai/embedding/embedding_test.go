@@ -0,0 +1,68 @@
+package embedding_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type fakeEmbedding struct {
+	models []string
+	tokens int
+}
+
+func (f fakeEmbedding) Models() []string {
+	return f.models
+}
+
+func (f fakeEmbedding) Embedding(ctx context.Context, req embedding.Request) (*embedding.Response, error) {
+	vectors := make([][]float32, len(req.Input))
+	for i := range req.Input {
+		vectors[i] = []float32{float32(i), float32(len(f.models))}
+	}
+
+	return &embedding.Response{Embeddings: vectors, InputTokens: f.tokens}, nil
+}
+
+func TestImp_Embedding(t *testing.T) {
+	imp := embedding.New(
+		fakeEmbedding{models: []string{"model-a"}, tokens: 42},
+		fakeEmbedding{models: []string{"model-b", "model-c"}},
+	)
+
+	assert.Equal(t, []string{"model-a", "model-b", "model-c"}, imp.Models())
+	assert.True(t, imp.Support("model-b"))
+	assert.False(t, imp.Support("model-d"))
+
+	resp, err := imp.Embedding(context.TODO(), embedding.Request{Model: "model-a", Input: []string{"hello", "world"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "model-a", resp.Model)
+	assert.Equal(t, 42, resp.InputTokens)
+	assert.Equal(t, 2, len(resp.Embeddings))
+	assert.EqualValues(t, 1, resp.Embeddings[0][1])
+
+	// 厂商未返回 token 数量时使用估算值
+	resp, err = imp.Embedding(context.TODO(), embedding.Request{Model: "model-c", Input: []string{"hello world"}})
+	assert.NoError(t, err)
+	assert.True(t, resp.InputTokens > 0)
+	assert.EqualValues(t, 2, resp.Embeddings[0][1])
+
+	_, err = imp.Embedding(context.TODO(), embedding.Request{Model: "model-d", Input: []string{"hello"}})
+	assert.True(t, errors.Is(err, embedding.ErrModelNotSupported))
+
+	_, err = imp.Embedding(context.TODO(), embedding.Request{Model: "model-a"})
+	assert.True(t, errors.Is(err, embedding.ErrEmptyInput))
+}
+
+func TestVectorCodec(t *testing.T) {
+	vector := []float32{0.1, -0.5, 3.25, 0}
+	decoded, err := embedding.DecodeBase64(embedding.EncodeBase64(vector))
+	assert.NoError(t, err)
+	assert.Equal(t, vector, decoded)
+
+	_, err = embedding.DecodeBase64("AAA=")
+	assert.True(t, err != nil)
+}
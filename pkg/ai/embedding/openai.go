@@ -0,0 +1,53 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	openaiHelper "github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"github.com/sashabaranov/go-openai"
+)
+
+// openaiBatchSize OpenAI 单次请求的最大文本数量
+const openaiBatchSize = 2048
+
+type OpenAIEmbedding struct {
+	client openaiHelper.Client
+}
+
+func NewOpenAIEmbedding(client openaiHelper.Client) *OpenAIEmbedding {
+	return &OpenAIEmbedding{client: client}
+}
+
+func (e *OpenAIEmbedding) Models() []string {
+	return []string{openai.AdaEmbeddingV2.String()}
+}
+
+func (e *OpenAIEmbedding) Embedding(ctx context.Context, req Request) (*Response, error) {
+	var model openai.EmbeddingModel
+	if err := model.UnmarshalText([]byte(req.Model)); err != nil || model == openai.Unknown {
+		return nil, ErrModelNotSupported
+	}
+
+	result := &Response{Model: req.Model, Embeddings: make([][]float32, len(req.Input))}
+	for i, batch := range batches(req.Input, openaiBatchSize) {
+		resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: batch,
+			Model: model,
+			User:  req.User,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("openai embedding failed: %w", err)
+		}
+
+		for _, item := range resp.Data {
+			if err := place(result.Embeddings, i*openaiBatchSize, item.Index, item.Embedding); err != nil {
+				return nil, err
+			}
+		}
+
+		result.InputTokens += resp.Usage.PromptTokens
+	}
+
+	return result, nil
+}
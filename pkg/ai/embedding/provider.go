@@ -0,0 +1,30 @@
+package embedding
+
+import (
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/baidu"
+	"github.com/mylxsw/aidea-server/pkg/ai/dashscope"
+	"github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func(conf *config.Config, client openai.Client, baiduAI baidu.BaiduAI, ds *dashscope.DashScope) *Imp {
+		imp := New()
+		if conf.EnableOpenAI {
+			imp.Register(NewOpenAIEmbedding(client))
+		}
+
+		if conf.EnableBaiduWXAI {
+			imp.Register(NewBaiduEmbedding(baiduAI))
+		}
+
+		if conf.EnableDashScopeAI {
+			imp.Register(NewDashScopeEmbedding(ds))
+		}
+
+		return imp
+	})
+}
@@ -0,0 +1,231 @@
+package fake
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 可注入的错误类型
+const (
+	ErrorRateLimit     = "429"
+	ErrorServer        = "500"
+	ErrorTimeout       = "timeout"
+	ErrorContentFilter = "content_filter"
+)
+
+var (
+	ErrRateLimit     = errors.New("fake: too many requests [429]")
+	ErrServer        = errors.New("fake: internal server error [500]")
+	ErrContentFilter = errors.New("fake: content filtered")
+	ErrTimeout       = fmt.Errorf("fake: %w", context.DeadlineExceeded)
+)
+
+// DefaultImageURL 默认的图片地址模板
+const DefaultImageURL = "https://picsum.photos/seed/{hash}/{width}/{height}"
+
+var errorMarkers = []string{ErrorRateLimit, ErrorServer, ErrorTimeout, ErrorContentFilter}
+
+// Options 模拟服务的行为配置
+type Options struct {
+	// Latency 每次请求的延迟，流式响应中为每个分片的延迟
+	Latency time.Duration
+	// ChunkSize 流式响应每个分片包含的字符数
+	ChunkSize int
+	// Error 对所有请求注入的错误，可选值：429、500、timeout、content_filter
+	Error string
+	// Timeout 注入 timeout 错误时，请求挂起的时间
+	Timeout time.Duration
+	// ImageURL 图片地址模板，支持 {hash}、{seed}、{width}、{height}、{index} 占位符
+	ImageURL string
+}
+
+// Rule 剧本规则，按照顺序匹配，第一条匹配的规则生效
+type Rule struct {
+	// Match 用户消息包含该内容时匹配，为空时匹配所有请求
+	Match string `yaml:"match,omitempty"`
+	// Model 只对指定的模型生效，为空时对所有模型生效
+	Model string `yaml:"model,omitempty"`
+	// Reply 回复内容
+	Reply string `yaml:"reply,omitempty"`
+	// Error 注入的错误
+	Error string `yaml:"error,omitempty"`
+	// InputTokens/OutputTokens 上报的 Token 用量，为 0 时根据内容计算
+	InputTokens  int `yaml:"input_tokens,omitempty"`
+	OutputTokens int `yaml:"output_tokens,omitempty"`
+}
+
+// Reply 模拟服务的回复
+type Reply struct {
+	Text         string
+	Error        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Fake 模拟的 AI 服务，不会请求任何外部服务，相同的输入总是得到相同的输出
+type Fake struct {
+	opts  Options
+	rules []Rule
+}
+
+func New(opts Options, rules []Rule) *Fake {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 4
+	}
+
+	if opts.ImageURL == "" {
+		opts.ImageURL = DefaultImageURL
+	}
+
+	return &Fake{opts: opts, rules: rules}
+}
+
+// LoadScript 读取剧本文件（YAML）
+func LoadScript(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseScript(data)
+}
+
+// ParseScript 解析剧本
+func ParseScript(data []byte) ([]Rule, error) {
+	var script struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range script.Rules {
+		if rule.Error != "" && !isErrorType(rule.Error) {
+			return nil, fmt.Errorf("rule #%d: invalid error %s", i+1, rule.Error)
+		}
+	}
+
+	return script.Rules, nil
+}
+
+func isErrorType(code string) bool {
+	for _, m := range errorMarkers {
+		if m == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reply 生成回复：依次使用内容中的错误标记（如 [fake-error:429]）、剧本规则、全局错误配置，都未命中时原样返回用户消息
+func (f *Fake) Reply(model, prompt string) Reply {
+	for _, code := range errorMarkers {
+		if strings.Contains(prompt, "[fake-error:"+code+"]") {
+			return Reply{Error: code}
+		}
+	}
+
+	for _, rule := range f.rules {
+		if rule.Model != "" && rule.Model != model {
+			continue
+		}
+
+		if rule.Match != "" && !strings.Contains(prompt, rule.Match) {
+			continue
+		}
+
+		return Reply{Text: rule.Reply, Error: rule.Error, InputTokens: rule.InputTokens, OutputTokens: rule.OutputTokens}
+	}
+
+	if f.opts.Error != "" {
+		return Reply{Error: f.opts.Error}
+	}
+
+	return Reply{Text: prompt}
+}
+
+// Wait 模拟请求延迟
+func (f *Fake) Wait(ctx context.Context) error {
+	if f.opts.Latency <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(f.opts.Latency):
+		return nil
+	}
+}
+
+// Err 将错误类型转换为错误，timeout 会挂起直到超时或者请求被取消
+func (f *Fake) Err(ctx context.Context, code string) error {
+	switch code {
+	case "":
+		return nil
+	case ErrorRateLimit:
+		return ErrRateLimit
+	case ErrorContentFilter:
+		return ErrContentFilter
+	case ErrorTimeout:
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.opts.Timeout):
+			return ErrTimeout
+		}
+	default:
+		return ErrServer
+	}
+}
+
+// Chunks 将回复内容按照字符切分为流式响应的分片
+func (f *Fake) Chunks(text string) []string {
+	runes := []rune(text)
+	chunks := make([]string, 0, len(runes)/f.opts.ChunkSize+1)
+	for i := 0; i < len(runes); i += f.opts.ChunkSize {
+		end := i + f.opts.ChunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+
+		chunks = append(chunks, string(runes[i:end]))
+	}
+
+	return chunks
+}
+
+// Images 生成占位图片地址，相同的提示语、种子、尺寸生成的地址相同
+func (f *Fake) Images(ctx context.Context, prompt string, seed, width, height, count int64) ([]string, error) {
+	if err := f.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := f.Err(ctx, f.Reply("", prompt).Error); err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, count)
+	for i := int64(0); i < count; i++ {
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%dx%d|%d", prompt, seed, width, height, i)))
+		urls = append(urls, strings.NewReplacer(
+			"{hash}", hex.EncodeToString(sum[:])[:16],
+			"{seed}", strconv.FormatInt(seed, 10),
+			"{width}", strconv.FormatInt(width, 10),
+			"{height}", strconv.FormatInt(height, 10),
+			"{index}", strconv.FormatInt(i, 10),
+		).Replace(f.opts.ImageURL))
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,67 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/fake"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+const testScript = `
+rules:
+  - match: 你好
+    reply: 你好，我是模拟的助手
+    output_tokens: 8
+  - match: 敏感
+    error: content_filter
+  - model: gpt-4
+    reply: gpt-4 reply
+`
+
+func TestFake_Reply(t *testing.T) {
+	rules, err := fake.ParseScript([]byte(testScript))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rules))
+
+	_, err = fake.ParseScript([]byte("rules:\n  - error: 404"))
+	assert.True(t, err != nil)
+
+	f := fake.New(fake.Options{ChunkSize: 3}, rules)
+	assert.Equal(t, "你好，我是模拟的助手", f.Reply("echo", "你好呀").Text)
+	assert.Equal(t, 8, f.Reply("echo", "你好呀").OutputTokens)
+	assert.Equal(t, fake.ErrorContentFilter, f.Reply("echo", "敏感内容").Error)
+	assert.Equal(t, "gpt-4 reply", f.Reply("gpt-4", "hello").Text)
+	assert.Equal(t, "hello", f.Reply("echo", "hello").Text)
+	assert.Equal(t, fake.ErrorRateLimit, f.Reply("echo", "你好 [fake-error:429]").Error)
+
+	assert.EqualValues(t, []string{"hel", "lo ", "世界"}, f.Chunks("hello 世界"))
+	assert.Equal(t, 0, len(f.Chunks("")))
+
+	// 全局错误注入
+	f = fake.New(fake.Options{Error: fake.ErrorTimeout, Timeout: 10 * time.Millisecond}, nil)
+	assert.Equal(t, fake.ErrorTimeout, f.Reply("echo", "hello").Error)
+	assert.True(t, errors.Is(f.Err(context.TODO(), fake.ErrorTimeout), context.DeadlineExceeded))
+	assert.True(t, errors.Is(f.Err(context.TODO(), fake.ErrorRateLimit), fake.ErrRateLimit))
+	assert.NoError(t, f.Err(context.TODO(), ""))
+}
+
+func TestFake_Images(t *testing.T) {
+	f := fake.New(fake.Options{ImageURL: "https://example.com/{seed}/{width}x{height}/{index}.png"}, nil)
+
+	urls, err := f.Images(context.TODO(), "a cat", 42, 512, 768, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"https://example.com/42/512x768/0.png", "https://example.com/42/512x768/1.png"}, urls)
+
+	// 相同的输入得到相同的输出
+	f = fake.New(fake.Options{}, nil)
+	first, err := f.Images(context.TODO(), "a cat", 42, 512, 512, 1)
+	assert.NoError(t, err)
+	second, _ := f.Images(context.TODO(), "a cat", 42, 512, 512, 1)
+	assert.EqualValues(t, first, second)
+
+	_, err = f.Images(context.TODO(), "a cat [fake-error:500]", 42, 512, 512, 1)
+	assert.True(t, errors.Is(err, fake.ErrServer))
+}
@@ -0,0 +1,30 @@
+package fake
+
+import (
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func(conf *config.Config) (*Fake, error) {
+		var rules []Rule
+		if conf.FakeScriptFile != "" {
+			loaded, err := LoadScript(conf.FakeScriptFile)
+			if err != nil {
+				return nil, err
+			}
+
+			rules = loaded
+		}
+
+		return New(Options{
+			Latency:   conf.FakeLatency,
+			ChunkSize: conf.FakeChunkSize,
+			Error:     conf.FakeError,
+			Timeout:   conf.FakeTimeout,
+			ImageURL:  conf.FakeImageURL,
+		}, rules), nil
+	})
+}
@@ -19,8 +19,9 @@ import (
 )
 
 const (
-	RoleUser  = "user"
-	RoleModel = "model"
+	RoleFunction = "function"
+	RoleUser     = "user"
+	RoleModel    = "model"
 )
 
 const (
@@ -73,6 +74,30 @@ type Request struct {
 	Contents         []Message         `json:"contents,omitempty"`
 	SafetySettings   []SafetySetting   `json:"safetySettings,omitempty"`
 	GenerationConfig *GenerationConfig `json:"generationConfig,omitempty"`
+	Tools            []Tool            `json:"tools,omitempty"`
+	ToolConfig       *ToolConfig       `json:"toolConfig,omitempty"`
+}
+
+// Tool 模型可以调用的工具，https://ai.google.dev/api/rest/v1beta/Tool
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations,omitempty"`
+}
+
+type FunctionDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parameters 函数参数定义，OpenAPI Schema 格式
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+type ToolConfig struct {
+	FunctionCallingConfig *FunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+type FunctionCallingConfig struct {
+	// Mode 可选值为 AUTO/ANY/NONE
+	Mode                 string   `json:"mode,omitempty"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
 }
 
 func (req *Request) HasImage() bool {
@@ -106,8 +131,22 @@ type Message struct {
 }
 
 type MessagePart struct {
-	Text       string                 `json:"text,omitempty"`
-	InlineData *MessagePartInlineData `json:"inlineData,omitempty"`
+	Text             string                 `json:"text,omitempty"`
+	InlineData       *MessagePartInlineData `json:"inlineData,omitempty"`
+	FunctionCall     *FunctionCall          `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse      `json:"functionResponse,omitempty"`
+}
+
+// FunctionCall 模型发起的函数调用
+type FunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// FunctionResponse 函数调用结果
+type FunctionResponse struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
 }
 
 type MessagePartInlineData struct {
@@ -119,6 +158,14 @@ type Response struct {
 	Candidates     []Candidate     `json:"candidates,omitempty"`
 	PromptFeedback *PromptFeedback `json:"promptFeedback,omitempty"`
 	Error          *ErrorResponse  `json:"error,omitempty"`
+	// UsageMetadata Token 用量，流式响应中为累计值
+	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount,omitempty"`
+	CandidatesTokenCount int `json:"candidatesTokenCount,omitempty"`
+	TotalTokenCount      int `json:"totalTokenCount,omitempty"`
 }
 
 func (resp *Response) String() string {
@@ -131,6 +178,20 @@ func (resp *Response) String() string {
 	}, "")
 }
 
+// FunctionCalls 返回响应中的所有函数调用
+func (resp *Response) FunctionCalls() []FunctionCall {
+	calls := make([]FunctionCall, 0)
+	for _, candidate := range resp.Candidates {
+		for _, part := range candidate.Content.Parts {
+			if part.FunctionCall != nil {
+				calls = append(calls, *part.FunctionCall)
+			}
+		}
+	}
+
+	return calls
+}
+
 type Candidate struct {
 	Content       Message        `json:"content,omitempty"`
 	FinishReason  string         `json:"finishReason,omitempty"`
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-uuid"
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/proxy"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
@@ -20,7 +21,7 @@ import (
 )
 
 type Lepton struct {
-	apiKeys      []string
+	keys         *keypool.Pool[string]
 	qrServerURLs []string
 	resty        *resty.Client
 }
@@ -29,7 +30,7 @@ func Default(conf *config.Config) *Lepton {
 	restyClient := misc.RestyClient(2).SetTimeout(180 * time.Second)
 
 	return &Lepton{
-		apiKeys:      conf.LeptonAIKeys,
+		keys:         keypool.New("lepton", keypool.Keys(conf.LeptonAIKeys), keypool.OptionsFromConfig(conf)),
 		qrServerURLs: conf.LeptonAIQRServers,
 		resty:        restyClient,
 	}
@@ -45,14 +46,20 @@ func New(resolver infra.Resolver, conf *config.Config) *Lepton {
 	}
 
 	return &Lepton{
-		apiKeys:      conf.LeptonAIKeys,
+		keys:         keypool.New("lepton", keypool.Keys(conf.LeptonAIKeys), keypool.OptionsFromConfig(conf)),
 		qrServerURLs: conf.LeptonAIQRServers,
 		resty:        restyClient,
 	}
 }
 
-func (ai *Lepton) client() (serverURL, key string) {
-	return ai.qrServerURLs[rand.Intn(len(ai.qrServerURLs))], ai.apiKeys[rand.Intn(len(ai.apiKeys))]
+// client 随机选择一个服务地址，并从密钥池中选择一个密钥，密钥使用完毕后需要归还
+func (ai *Lepton) client() (string, *keypool.Lease[string], error) {
+	lease, err := ai.keys.Acquire()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ai.qrServerURLs[rand.Intn(len(ai.qrServerURLs))], lease, nil
 }
 
 type QRImageRequest struct {
@@ -111,17 +118,24 @@ func (resp ImageResponse) SaveToLocalFiles(ctx context.Context, savePath string)
 }
 
 func (ai *Lepton) ImageGenerate(ctx context.Context, req QRImageRequest) (*ImageResponse, error) {
-	server, key := ai.client()
+	server, lease, err := ai.client()
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := ai.resty.R().
-		SetHeader("Authorization", "Bearer "+key).
+		SetHeader("Authorization", "Bearer "+lease.Value).
 		SetHeader("Content-Type", "application/json").
 		SetContext(ctx).
 		SetBody(req).
 		Post(server + "/generate")
 	if err != nil {
+		lease.Done(0)
 		return nil, err
 	}
 
+	lease.Done(resp.StatusCode())
+
 	if resp.IsError() {
 		return nil, fmt.Errorf("generate image failed: [%d] %s", resp.StatusCode(), string(resp.Body()))
 	}
@@ -0,0 +1,408 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// 支持的本地推理服务接口类型
+const (
+	// APIOllama Ollama 接口，https://github.com/ollama/ollama/blob/main/docs/api.md
+	APIOllama = "ollama"
+	// APILlamaCpp llama.cpp server 的 OpenAI 兼容接口，https://github.com/ggerganov/llama.cpp/tree/master/examples/server
+	APILlamaCpp = "llamacpp"
+)
+
+// LocalAI 本地推理服务（Ollama/llama.cpp server）客户端
+type LocalAI struct {
+	api    string
+	server string
+	client *http.Client
+}
+
+// New 创建本地推理服务客户端，api 为接口类型（ollama/llamacpp），server 为服务地址，如 http://127.0.0.1:11434
+func New(api, server string) *LocalAI {
+	if api == "" {
+		api = APIOllama
+	}
+
+	return &LocalAI{api: api, server: strings.TrimSuffix(server, "/"), client: http.DefaultClient}
+}
+
+// API 返回接口类型
+func (ai *LocalAI) API() string {
+	return ai.api
+}
+
+// SupportVision 接口是否支持图片输入，llama.cpp server 的 OpenAI 兼容接口不支持图片
+func (ai *LocalAI) SupportVision() bool {
+	return ai.api == APIOllama
+}
+
+type Message struct {
+	// Role 取值有 system, assistant, user
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images base64 编码的图片（不含 data URL 前缀），仅 Ollama 支持
+	Images []string `json:"images,omitempty"`
+}
+
+// Options 采样参数，未设置的参数使用模型的默认值
+type Options struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	// NumPredict 最大输出 Token 数量
+	NumPredict int `json:"num_predict,omitempty"`
+	// NumCtx 上下文窗口大小，仅 Ollama 支持，llama.cpp 在服务启动时指定
+	NumCtx int `json:"num_ctx,omitempty"`
+}
+
+type ChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  Options   `json:"options"`
+}
+
+type ChatResponse struct {
+	Model      string  `json:"model,omitempty"`
+	Message    Message `json:"message"`
+	Done       bool    `json:"done"`
+	DoneReason string  `json:"done_reason,omitempty"`
+	// PromptEvalCount 输入 Token 数量
+	PromptEvalCount int `json:"prompt_eval_count,omitempty"`
+	// EvalCount 输出 Token 数量
+	EvalCount int    `json:"eval_count,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ModelInfo 本地推理服务中可用的模型
+type ModelInfo struct {
+	Name string `json:"name"`
+	// Vision 是否支持图片输入
+	Vision bool `json:"vision"`
+}
+
+func (ai *LocalAI) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	req.Stream = false
+
+	httpResp, err := ai.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if ai.api == APILlamaCpp {
+		var resp llamaCppResponse
+		if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("decode response failed: %w", err)
+		}
+
+		return resp.toChatResponse(), nil
+	}
+
+	var resp ChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+
+	return &resp, nil
+}
+
+func (ai *LocalAI) ChatStream(ctx context.Context, req ChatRequest) (<-chan ChatResponse, error) {
+	req.Stream = true
+
+	httpResp, err := ai.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(chan ChatResponse)
+	go func() {
+		defer func() {
+			_ = httpResp.Body.Close()
+			close(res)
+		}()
+
+		reader := bufio.NewReader(httpResp.Body)
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil {
+				if err == io.EOF {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+				case res <- ChatResponse{Error: fmt.Sprintf("read response failed: %v", err)}:
+				}
+				return
+			}
+
+			resp, ok, err := ai.decodeStreamLine(data)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case res <- ChatResponse{Error: fmt.Sprintf("decode response failed: %v", err)}:
+				}
+				return
+			}
+
+			if !ok {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case res <- *resp:
+				if resp.Done || resp.Error != "" {
+					return
+				}
+			}
+		}
+	}()
+
+	return res, nil
+}
+
+// decodeStreamLine 解析流式响应中的一行数据，Ollama 为 NDJSON 格式，llama.cpp 为 SSE 格式
+func (ai *LocalAI) decodeStreamLine(line []byte) (*ChatResponse, bool, error) {
+	data := bytes.TrimSpace(line)
+	if len(data) == 0 {
+		return nil, false, nil
+	}
+
+	if ai.api == APILlamaCpp {
+		if !bytes.HasPrefix(data, []byte("data:")) {
+			return nil, false, nil
+		}
+
+		data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("data:")))
+		if string(data) == "[DONE]" {
+			return &ChatResponse{Done: true}, true, nil
+		}
+
+		var resp llamaCppResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, false, err
+		}
+
+		return resp.toChatResponse(), true, nil
+	}
+
+	var resp ChatResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, err
+	}
+
+	return &resp, true, nil
+}
+
+// Models 返回本地推理服务中可用的模型列表
+func (ai *LocalAI) Models(ctx context.Context) ([]ModelInfo, error) {
+	path := "/api/tags"
+	if ai.api == APILlamaCpp {
+		path = "/v1/models"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, ai.server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := ai.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, responseError(httpResp)
+	}
+
+	if ai.api == APILlamaCpp {
+		var resp struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("decode response failed: %w", err)
+		}
+
+		models := make([]ModelInfo, 0, len(resp.Data))
+		for _, m := range resp.Data {
+			models = append(models, ModelInfo{Name: m.ID})
+		}
+
+		return models, nil
+	}
+
+	var resp struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Details struct {
+				Families []string `json:"families"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		var vision bool
+		for _, family := range m.Details.Families {
+			// llava 等多模态模型包含 clip 视觉编码器
+			if family == "clip" || family == "mllama" {
+				vision = true
+			}
+		}
+
+		models = append(models, ModelInfo{Name: m.Name, Vision: vision})
+	}
+
+	return models, nil
+}
+
+func (ai *LocalAI) post(ctx context.Context, req ChatRequest) (*http.Response, error) {
+	path := "/api/chat"
+	var payload any = req
+	if ai.api == APILlamaCpp {
+		path = "/v1/chat/completions"
+		payload = newLlamaCppRequest(req)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, ai.server+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := ai.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusBadRequest {
+		defer httpResp.Body.Close()
+		return nil, responseError(httpResp)
+	}
+
+	return httpResp, nil
+}
+
+// responseError 解析错误响应，Ollama 的错误格式为 {"error": "xxx"}，llama.cpp 为 {"error": {"message": "xxx"}}
+func responseError(httpResp *http.Response) error {
+	data, _ := io.ReadAll(httpResp.Body)
+
+	var ollamaErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &ollamaErr); err == nil && ollamaErr.Error != "" {
+		return fmt.Errorf("local chat failed [%s]: %s", httpResp.Status, ollamaErr.Error)
+	}
+
+	var llamaCppErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &llamaCppErr); err == nil && llamaCppErr.Error.Message != "" {
+		return fmt.Errorf("local chat failed [%s]: %s", httpResp.Status, llamaCppErr.Error.Message)
+	}
+
+	return fmt.Errorf("local chat failed [%s]: %s", httpResp.Status, string(data))
+}
+
+type llamaCppMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type llamaCppRequest struct {
+	Model            string            `json:"model"`
+	Messages         []llamaCppMessage `json:"messages"`
+	Stream           bool              `json:"stream"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	TopP             *float64          `json:"top_p,omitempty"`
+	Stop             []string          `json:"stop,omitempty"`
+	Seed             *int              `json:"seed,omitempty"`
+	PresencePenalty  *float64          `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64          `json:"frequency_penalty,omitempty"`
+	MaxTokens        int               `json:"max_tokens,omitempty"`
+}
+
+func newLlamaCppRequest(req ChatRequest) llamaCppRequest {
+	messages := make([]llamaCppMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, llamaCppMessage{Role: m.Role, Content: m.Content})
+	}
+
+	return llamaCppRequest{
+		Model:            req.Model,
+		Messages:         messages,
+		Stream:           req.Stream,
+		Temperature:      req.Options.Temperature,
+		TopP:             req.Options.TopP,
+		Stop:             req.Options.Stop,
+		Seed:             req.Options.Seed,
+		PresencePenalty:  req.Options.PresencePenalty,
+		FrequencyPenalty: req.Options.FrequencyPenalty,
+		MaxTokens:        req.Options.NumPredict,
+	}
+}
+
+type llamaCppResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (resp llamaCppResponse) toChatResponse() *ChatResponse {
+	res := ChatResponse{
+		Model:           resp.Model,
+		Message:         Message{Role: "assistant"},
+		PromptEvalCount: resp.Usage.PromptTokens,
+		EvalCount:       resp.Usage.CompletionTokens,
+	}
+
+	for _, c := range resp.Choices {
+		res.Message.Content += c.Message.Content + c.Delta.Content
+		if c.FinishReason != nil && *c.FinishReason != "" {
+			res.Done = true
+			res.DoneReason = *c.FinishReason
+		}
+	}
+
+	return &res
+}
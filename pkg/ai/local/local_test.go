@@ -0,0 +1,142 @@
+package local_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/local"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func newOllamaServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = fmt.Fprint(w, `{"models":[{"name":"llama2:latest","details":{"families":["llama"]}},{"name":"llava:latest","details":{"families":["llama","clip"]}}]}`)
+		case "/api/chat":
+			var req local.ChatRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			if req.Model == "unknown" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"error":"model 'unknown' not found"}`)
+				return
+			}
+
+			if !req.Stream {
+				_, _ = fmt.Fprintf(w, `{"model":"%s","message":{"role":"assistant","content":"%d images"},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":2}`, req.Model, len(req.Messages[0].Images))
+				return
+			}
+
+			_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":"Hello"},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":" world"},"done":false}`)
+			_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":2}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newLlamaCppServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = fmt.Fprint(w, `{"object":"list","data":[{"id":"models/mistral-7b.gguf"}]}`)
+		case "/v1/chat/completions":
+			var req map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.EqualValues(t, 128, req["max_tokens"])
+
+			if req["stream"] != true {
+				_, _ = fmt.Fprint(w, `{"model":"mistral","choices":[{"message":{"role":"assistant","content":"Hello world"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":2}}`)
+				return
+			}
+
+			_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"},\"finish_reason\":null}]}\n\n")
+			_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\" world\"},\"finish_reason\":null}]}\n\n")
+			_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func readStream(stream <-chan local.ChatResponse) (text string, done bool) {
+	for item := range stream {
+		text += item.Message.Content + item.Error
+		done = done || item.Done
+	}
+
+	return text, done
+}
+
+func TestLocalAI_Ollama(t *testing.T) {
+	server := newOllamaServer(t)
+	defer server.Close()
+
+	client := local.New(local.APIOllama, server.URL+"/")
+	assert.True(t, client.SupportVision())
+
+	models, err := client.Models(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(models))
+	assert.Equal(t, "llama2:latest", models[0].Name)
+	assert.False(t, models[0].Vision)
+	assert.True(t, models[1].Vision)
+
+	resp, err := client.Chat(context.TODO(), local.ChatRequest{
+		Model:    "llava:latest",
+		Messages: []local.Message{{Role: "user", Content: "What is in this picture?", Images: []string{"aGVsbG8="}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1 images", resp.Message.Content)
+	assert.Equal(t, "stop", resp.DoneReason)
+	assert.Equal(t, 10, resp.PromptEvalCount)
+	assert.Equal(t, 2, resp.EvalCount)
+
+	stream, err := client.ChatStream(context.TODO(), local.ChatRequest{Model: "llama2:latest", Messages: []local.Message{{Role: "user", Content: "hi"}}})
+	assert.NoError(t, err)
+	text, done := readStream(stream)
+	assert.Equal(t, "Hello world", text)
+	assert.True(t, done)
+
+	_, err = client.Chat(context.TODO(), local.ChatRequest{Model: "unknown"})
+	assert.True(t, err != nil)
+	assert.Equal(t, "local chat failed [404 Not Found]: model 'unknown' not found", err.Error())
+}
+
+func TestLocalAI_LlamaCpp(t *testing.T) {
+	server := newLlamaCppServer(t)
+	defer server.Close()
+
+	client := local.New(local.APILlamaCpp, server.URL)
+	assert.False(t, client.SupportVision())
+
+	models, err := client.Models(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(models))
+	assert.Equal(t, "models/mistral-7b.gguf", models[0].Name)
+
+	req := local.ChatRequest{
+		Model:    "mistral",
+		Messages: []local.Message{{Role: "user", Content: "hi"}},
+		Options:  local.Options{NumPredict: 128},
+	}
+
+	resp, err := client.Chat(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", resp.Message.Content)
+	assert.True(t, resp.Done)
+	assert.Equal(t, 10, resp.PromptEvalCount)
+	assert.Equal(t, 2, resp.EvalCount)
+
+	stream, err := client.ChatStream(context.TODO(), req)
+	assert.NoError(t, err)
+	text, done := readStream(stream)
+	assert.Equal(t, "Hello world", text)
+	assert.True(t, done)
+}
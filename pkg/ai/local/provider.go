@@ -0,0 +1,14 @@
+package local
+
+import (
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func(conf *config.Config) *LocalAI {
+		return New(conf.LocalAPI, conf.LocalServer)
+	})
+}
@@ -15,6 +15,7 @@ type Client interface {
 	CreateImage(ctx context.Context, request openai.ImageRequest) (response openai.ImageResponse, err error)
 	CreateTranscription(ctx context.Context, request openai.AudioRequest) (response openai.AudioResponse, err error)
 	CreateSpeech(ctx context.Context, request openai.CreateSpeechRequest) (response io.ReadCloser, err error)
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequestConverter) (response openai.EmbeddingResponse, err error)
 	QuickAsk(ctx context.Context, prompt string, question string, maxTokenCount int) (string, error)
 }
 
@@ -149,6 +150,23 @@ func (proxy *ClientImpl) CreateSpeech(ctx context.Context, request openai.Create
 	panic("no openai client available")
 }
 
+func (proxy *ClientImpl) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequestConverter) (response openai.EmbeddingResponse, err error) {
+	ctl := control.FromContext(ctx)
+	if ctl.PreferBackup && proxy.backup != nil {
+		return proxy.backup.CreateEmbeddings(ctx, request)
+	}
+
+	if proxy.main != nil {
+		return proxy.main.CreateEmbeddings(ctx, request)
+	}
+
+	if proxy.backup != nil {
+		return proxy.backup.CreateEmbeddings(ctx, request)
+	}
+
+	panic("no openai client available")
+}
+
 func (proxy *ClientImpl) QuickAsk(ctx context.Context, prompt string, question string, maxTokenCount int) (string, error) {
 	var res string
 	var err error
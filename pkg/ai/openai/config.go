@@ -1,8 +1,13 @@
 package openai
 
-import "github.com/mylxsw/aidea-server/config"
+import (
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
+)
 
 type Config struct {
+	// Name 渠道名称，用于区分不同渠道的密钥池
+	Name               string
 	Enable             bool
 	OpenAIAzure        bool
 	OpenAIAPIVersion   string
@@ -10,6 +15,14 @@ type Config struct {
 	OpenAIServers      []string
 	OpenAIKeys         []string
 	AutoProxy          bool
+	// Headers 请求时附加的 HTTP 头
+	Headers map[string]string
+	// AzureDeploymentAsModel Azure 模式下直接使用模型名称作为部署名称
+	AzureDeploymentAsModel bool
+	// KeyPool 密钥池配置
+	KeyPool keypool.Options
+	// StreamUsage 流式请求时附加 stream_options.include_usage，要求服务商返回 Token 用量
+	StreamUsage bool
 }
 
 func parseMainConfig(conf *config.Config) *Config {
@@ -21,6 +34,9 @@ func parseMainConfig(conf *config.Config) *Config {
 		OpenAIServers:      conf.OpenAIServers,
 		OpenAIKeys:         conf.OpenAIKeys,
 		AutoProxy:          conf.OpenAIAutoProxy,
+		KeyPool:            keypool.OptionsFromConfig(conf),
+		// Azure OpenAI 较早的 API 版本不支持 stream_options 参数
+		StreamUsage: !conf.OpenAIAzure,
 	}
 }
 
@@ -33,6 +49,8 @@ func parseBackupConfig(conf *config.Config) *Config {
 		OpenAIServers:      conf.FallbackOpenAIServers,
 		OpenAIKeys:         conf.FallbackOpenAIKeys,
 		AutoProxy:          conf.FallbackOpenAIAutoProxy,
+		KeyPool:            keypool.OptionsFromConfig(conf),
+		StreamUsage:        !conf.FallbackOpenAIAzure,
 	}
 }
 
@@ -46,6 +64,7 @@ func parseDalleConfig(conf *config.Config) *Config {
 			OpenAIServers:      conf.OpenAIServers,
 			OpenAIKeys:         conf.OpenAIKeys,
 			AutoProxy:          conf.OpenAIAutoProxy,
+			KeyPool:            keypool.OptionsFromConfig(conf),
 		}
 	}
 
@@ -57,5 +76,24 @@ func parseDalleConfig(conf *config.Config) *Config {
 		OpenAIServers:      conf.OpenAIDalleServers,
 		OpenAIKeys:         conf.OpenAIDalleKeys,
 		AutoProxy:          conf.OpenAIDalleAutoProxy,
+		KeyPool:            keypool.OptionsFromConfig(conf),
+	}
+}
+
+// parseChannelConfig 兼容 OpenAI 接口的渠道配置
+func parseChannelConfig(conf *config.Config, ch config.OpenAIChannel) *Config {
+	return &Config{
+		Name:                   ch.Name,
+		Enable:                 true,
+		OpenAIAzure:            ch.Azure,
+		OpenAIAPIVersion:       ch.APIVersion,
+		OpenAIOrganization:     ch.Organization,
+		OpenAIServers:          ch.Servers,
+		OpenAIKeys:             ch.Keys,
+		AutoProxy:              ch.AutoProxy,
+		Headers:                ch.Headers,
+		AzureDeploymentAsModel: true,
+		KeyPool:                keypool.OptionsFromConfig(conf),
+		StreamUsage:            ch.StreamUsage,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/proxy"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
@@ -16,6 +17,7 @@ import (
 type DalleImageClient struct {
 	conf *Config
 	http *resty.Client
+	keys *keypool.Pool[string]
 }
 
 func NewDalleImageClient(conf *Config, pp *proxy.Proxy) *DalleImageClient {
@@ -24,7 +26,7 @@ func NewDalleImageClient(conf *Config, pp *proxy.Proxy) *DalleImageClient {
 		restyClient.SetTransport(pp.BuildTransport())
 	}
 
-	return &DalleImageClient{conf: conf, http: restyClient}
+	return &DalleImageClient{conf: conf, http: restyClient, keys: keypool.New("openai-dalle", keypool.Keys(conf.OpenAIKeys), conf.KeyPool)}
 }
 
 type ImageRequest struct {
@@ -97,24 +99,28 @@ type ErrorResponseInner struct {
 	Type    string `json:"type,omitempty"`
 }
 
-func (client *DalleImageClient) pickAPIKey() string {
-	return client.conf.OpenAIKeys[rand.Intn(len(client.conf.OpenAIKeys))]
-}
-
 func (client *DalleImageClient) pickServer() string {
 	return client.conf.OpenAIServers[rand.Intn(len(client.conf.OpenAIServers))]
 }
 
 func (client *DalleImageClient) CreateImage(ctx context.Context, request ImageRequest) (*ImageResponse, error) {
+	lease, err := client.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := client.http.R().
 		SetContext(ctx).
-		SetHeader("Authorization", "Bearer "+client.pickAPIKey()).
+		SetHeader("Authorization", "Bearer "+lease.Value).
 		SetBody(request).
 		Post(fmt.Sprintf("%s/images/generations", client.pickServer()))
 	if err != nil {
+		lease.Done(0)
 		return nil, err
 	}
 
+	lease.Done(resp.StatusCode())
+
 	var ret ImageResponse
 	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
 		return nil, err
@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/mylxsw/aidea-server/pkg/ai/tokenizer"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"io"
-	"math/rand"
+	"net/http"
 	"strings"
 
 	"github.com/mylxsw/go-utils/array"
-	"github.com/pkoukk/tiktoken-go"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -88,10 +89,7 @@ func NumTokensFromMessages(messages []openai.ChatCompletionMessage, model string
 		model = "gpt-3.5-turbo"
 	}
 
-	tkm, err := tiktoken.EncodingForModel(model)
-	if err != nil {
-		return 0, fmt.Errorf("EncodingForModel: %v", err)
-	}
+	tk := tokenizer.ForModel(model)
 
 	var tokensPerMessage int
 	var tokensPerName int
@@ -108,9 +106,9 @@ func NumTokensFromMessages(messages []openai.ChatCompletionMessage, model string
 
 	for _, message := range messages {
 		numTokens += tokensPerMessage
-		numTokens += len(tkm.Encode(message.Content, nil, nil))
-		numTokens += len(tkm.Encode(message.Role, nil, nil))
-		numTokens += len(tkm.Encode(message.Name, nil, nil))
+		numTokens += tk.Count(message.Content)
+		numTokens += tk.Count(message.Role)
+		numTokens += tk.Count(message.Name)
 		if message.Name != "" {
 			numTokens += tokensPerName
 		}
@@ -120,17 +118,55 @@ func NumTokensFromMessages(messages []openai.ChatCompletionMessage, model string
 }
 
 type realClientImpl struct {
-	conf    *Config
-	clients []*openai.Client
+	conf *Config
+	pool *keypool.Pool[*openai.Client]
 }
 
 func New(conf *Config, clients []*openai.Client) Client {
-	return &realClientImpl{clients: clients, conf: conf}
+	entries := make([]keypool.Entry[*openai.Client], 0, len(clients))
+	for i, c := range clients {
+		entries = append(entries, keypool.Entry[*openai.Client]{ID: fmt.Sprintf("client-%d", i), Value: c, Weight: 1})
+	}
+
+	return NewWithPool(conf, keypool.New("openai", entries, keypool.Options{}))
 }
 
-// client 随机返回一个 OpenAI Client
-func (client *realClientImpl) client(model string) *openai.Client {
-	return client.clients[rand.Intn(len(client.clients))]
+// NewWithPool 使用密钥池创建 OpenAI Client，池中的每一项为使用不同服务地址和密钥创建的 Client
+func NewWithPool(conf *Config, pool *keypool.Pool[*openai.Client]) Client {
+	return &realClientImpl{pool: pool, conf: conf}
+}
+
+// statusCodeOf 从 OpenAI 请求的错误中提取 HTTP 状态码，请求成功时返回 200，未得到响应时返回 0
+func statusCodeOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+
+	return 0
+}
+
+// do 从密钥池中选择一个 OpenAI Client 执行请求，请求结束后根据结果更新密钥的状态
+func do[R any](client *realClientImpl, fn func(c *openai.Client) (R, error)) (R, error) {
+	lease, err := client.pool.Acquire()
+	if err != nil {
+		var empty R
+		return empty, err
+	}
+
+	res, err := fn(lease.Value)
+	lease.Done(statusCodeOf(err))
+
+	return res, err
 }
 
 func (client *realClientImpl) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (response openai.ChatCompletionResponse, err error) {
@@ -139,22 +175,48 @@ func (client *realClientImpl) CreateChatCompletion(ctx context.Context, request
 		request.MaxTokens = 4096
 	}
 
-	return client.client(request.Model).CreateChatCompletion(ctx, request)
+	return do(client, func(c *openai.Client) (openai.ChatCompletionResponse, error) {
+		return c.CreateChatCompletion(ctx, request)
+	})
 }
 
 func (client *realClientImpl) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (stream *openai.ChatCompletionStream, err error) {
+	stream, lease, err := client.createChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	lease.Done(http.StatusOK)
+	return stream, nil
+}
+
+// createChatCompletionStream 创建流式聊天请求，请求成功时，返回的密钥需要在流读取结束后归还
+func (client *realClientImpl) createChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, *keypool.Lease[*openai.Client], error) {
 	// TODO: 临时解决方案，后续需要优化
 	if request.Model == "gpt-4-vision-preview" && request.MaxTokens == 0 {
 		request.MaxTokens = 4096
 	}
 
-	return client.client(request.Model).CreateChatCompletionStream(ctx, request)
+	lease, err := client.pool.Acquire()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stream, err := lease.Value.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		lease.Done(statusCodeOf(err))
+		return nil, nil, err
+	}
+
+	return stream, lease, nil
 }
 
 type ChatStreamResponse struct {
 	Code         string `json:"code,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
 	ChatResponse *openai.ChatCompletionStreamResponse
+	// Usage 服务商上报的 Token 用量，只在流的最后一个响应中返回
+	Usage *openai.Usage `json:"usage,omitempty"`
 }
 
 func (client *realClientImpl) ChatStream(ctx context.Context, request openai.ChatCompletionRequest) (<-chan ChatStreamResponse, error) {
@@ -163,7 +225,8 @@ func (client *realClientImpl) ChatStream(ctx context.Context, request openai.Cha
 		request.MaxTokens = 4096
 	}
 
-	stream, err := client.CreateChatCompletionStream(ctx, request)
+	ctx, usage := withStreamUsage(ctx)
+	stream, lease, err := client.createChatCompletionStream(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -174,11 +237,19 @@ func (client *realClientImpl) ChatStream(ctx context.Context, request openai.Cha
 		defer func() {
 			close(res)
 			stream.Close()
+			lease.Done(http.StatusOK)
 		}()
 
 		for {
 			response, err := stream.Recv()
 			if errors.Is(err, io.EOF) {
+				if u := usage.get(); u != nil {
+					select {
+					case <-ctx.Done():
+					case res <- ChatStreamResponse{Usage: u}:
+					}
+				}
+
 				return
 			}
 
@@ -202,15 +273,27 @@ func (client *realClientImpl) ChatStream(ctx context.Context, request openai.Cha
 }
 
 func (client *realClientImpl) CreateImage(ctx context.Context, request openai.ImageRequest) (response openai.ImageResponse, err error) {
-	return client.client("dall-e").CreateImage(ctx, request)
+	return do(client, func(c *openai.Client) (openai.ImageResponse, error) {
+		return c.CreateImage(ctx, request)
+	})
 }
 
 func (client *realClientImpl) CreateTranscription(ctx context.Context, request openai.AudioRequest) (response openai.AudioResponse, err error) {
-	return client.client("audio").CreateTranscription(ctx, request)
+	return do(client, func(c *openai.Client) (openai.AudioResponse, error) {
+		return c.CreateTranscription(ctx, request)
+	})
 }
 
 func (client *realClientImpl) CreateSpeech(ctx context.Context, request openai.CreateSpeechRequest) (response io.ReadCloser, err error) {
-	return client.client("audio").CreateSpeech(ctx, request)
+	return do(client, func(c *openai.Client) (io.ReadCloser, error) {
+		return c.CreateSpeech(ctx, request)
+	})
+}
+
+func (client *realClientImpl) CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequestConverter) (response openai.EmbeddingResponse, err error) {
+	return do(client, func(c *openai.Client) (openai.EmbeddingResponse, error) {
+		return c.CreateEmbeddings(ctx, request)
+	})
 }
 
 func (client *realClientImpl) QuickAsk(ctx context.Context, prompt string, question string, maxTokenCount int) (string, error) {
@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/aidea-server/pkg/proxy"
 	"github.com/mylxsw/go-utils/ternary"
 	"net"
@@ -46,46 +47,72 @@ func (Provider) Register(binder infra.Binder) {
 
 		return NewOpenAIProxy(mainClient, backupClient)
 	})
+
+	binder.MustSingleton(func(conf *config.Config, resolver infra.Resolver) *Channels {
+		var proxyDialer *proxy.Proxy
+		if conf.SupportProxy() {
+			resolver.MustResolve(func(pp *proxy.Proxy) {
+				proxyDialer = pp
+			})
+		}
+
+		return NewChannels(conf, proxyDialer)
+	})
+}
+
+// Channels 配置文件中定义的兼容 OpenAI 接口的渠道客户端
+type Channels struct {
+	clients map[string]Client
+}
+
+// NewChannels 为每个兼容 OpenAI 接口的渠道创建客户端
+func NewChannels(conf *config.Config, pp *proxy.Proxy) *Channels {
+	clients := make(map[string]Client, len(conf.OpenAIChannels))
+	for _, ch := range conf.OpenAIChannels {
+		clients[ch.Name] = NewOpenAIClient(parseChannelConfig(conf, ch), pp)
+	}
+
+	return &Channels{clients: clients}
+}
+
+// Get 查询渠道客户端，渠道不存在时返回 nil
+func (c *Channels) Get(name string) Client {
+	return c.clients[name]
 }
 
 func NewOpenAIClient(conf *Config, pp *proxy.Proxy) Client {
-	clients := make([]*openai.Client, 0)
+	entries := make([]keypool.Entry[*openai.Client], 0)
 
 	// 如果是 Azure API，则每一个 Server 对应一个 Key
 	// 否则 Servers 和 Keys 取笛卡尔积
 	if conf.OpenAIAzure {
 		for i, server := range conf.OpenAIServers {
-			clients = append(clients, createOpenAIClient(
-				true,
-				conf.OpenAIAPIVersion,
-				server,
-				"",
-				conf.OpenAIKeys[i],
-				ternary.If(conf.AutoProxy, pp, nil),
-			))
+			key, weight := keypool.ParseKey(conf.OpenAIKeys[i])
+			entries = append(entries, keypool.Entry[*openai.Client]{
+				ID:     server + "#" + keypool.MaskKey(key),
+				Weight: weight,
+				Value:  createOpenAIClient(conf, server, key, ternary.If(conf.AutoProxy, pp, nil)),
+			})
 		}
 	} else {
 		for _, server := range conf.OpenAIServers {
-			for _, key := range conf.OpenAIKeys {
-				clients = append(clients, createOpenAIClient(
-					false,
-					"",
-					server,
-					conf.OpenAIOrganization,
-					key,
-					ternary.If(conf.AutoProxy, pp, nil),
-				))
+			for _, item := range conf.OpenAIKeys {
+				key, weight := keypool.ParseKey(item)
+				entries = append(entries, keypool.Entry[*openai.Client]{
+					ID:     server + "#" + keypool.MaskKey(key),
+					Weight: weight,
+					Value:  createOpenAIClient(conf, server, key, ternary.If(conf.AutoProxy, pp, nil)),
+				})
 			}
 		}
 	}
 
-	return New(conf, clients)
+	return NewWithPool(conf, keypool.New(ternary.If(conf.Name != "", conf.Name, "openai"), entries, conf.KeyPool))
 }
 
-func createOpenAIClient(isAzure bool, apiVersion string, server, organization, key string, pp *proxy.Proxy) *openai.Client {
+func createOpenAIClient(conf *Config, server, key string, pp *proxy.Proxy) *openai.Client {
 	openaiConf := openai.DefaultConfig(key)
 	openaiConf.BaseURL = server
-	openaiConf.OrgID = organization
 	openaiConf.HTTPClient.Timeout = 180 * time.Second
 	if pp != nil {
 		openaiConf.HTTPClient.Transport = pp.BuildTransport()
@@ -97,10 +124,21 @@ func createOpenAIClient(isAzure bool, apiVersion string, server, organization, k
 		}
 	}
 
-	if isAzure {
+	if len(conf.Headers) > 0 {
+		openaiConf.HTTPClient.Transport = &headerTransport{base: openaiConf.HTTPClient.Transport, headers: conf.Headers}
+	}
+
+	openaiConf.HTTPClient.Transport = &streamUsageTransport{base: openaiConf.HTTPClient.Transport, includeUsage: conf.StreamUsage}
+
+	if conf.OpenAIAzure {
 		openaiConf.APIType = openai.APITypeAzure
-		openaiConf.APIVersion = apiVersion
+		openaiConf.APIVersion = conf.OpenAIAPIVersion
 		openaiConf.AzureModelMapperFunc = func(model string) string {
+			// 渠道配置中已经指定了部署名称
+			if conf.AzureDeploymentAsModel {
+				return model
+			}
+
 			// TODO 应该使用配置文件配置，注意，这里返回的应该是 Azure 部署名称
 			switch model {
 			case "gpt-3.5-turbo", "gpt-3.5-turbo-0613":
@@ -115,7 +153,24 @@ func createOpenAIClient(isAzure bool, apiVersion string, server, organization, k
 
 			return regexp.MustCompile(`[.:]`).ReplaceAllString(model, "")
 		}
+	} else {
+		openaiConf.OrgID = conf.OpenAIOrganization
 	}
 
 	return openai.NewClientWithConfig(openaiConf)
 }
+
+// headerTransport 为每个请求附加自定义的 HTTP 头
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,143 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// streamUsageKey 流式请求的 Token 用量在 context 中的 key
+type streamUsageKey struct{}
+
+// streamUsage 流式响应中服务商上报的 Token 用量，由 streamUsageTransport 在读取响应时写入
+type streamUsage struct {
+	lock  sync.Mutex
+	usage *openai.Usage
+}
+
+func (u *streamUsage) set(usage openai.Usage) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.usage = &usage
+}
+
+func (u *streamUsage) get() *openai.Usage {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	return u.usage
+}
+
+// withStreamUsage 在 context 中记录流式请求的 Token 用量
+func withStreamUsage(ctx context.Context) (context.Context, *streamUsage) {
+	usage := &streamUsage{}
+	return context.WithValue(ctx, streamUsageKey{}, usage), usage
+}
+
+// streamUsageTransport 读取流式聊天响应中服务商上报的 Token 用量
+// go-openai 的流式响应中不包含用量字段，因此在 HTTP 层解析响应中的 usage 事件
+// includeUsage 为 true 时，请求中附加 stream_options.include_usage，要求服务商在最后一个事件中返回用量
+type streamUsageTransport struct {
+	base         http.RoundTripper
+	includeUsage bool
+}
+
+func (t *streamUsageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	usage, ok := req.Context().Value(streamUsageKey{}).(*streamUsage)
+	if !ok || req.Body == nil || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return t.base.RoundTrip(req)
+	}
+
+	if t.includeUsage {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		body = includeStreamUsage(body)
+
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	resp.Body = &usageReader{ReadCloser: resp.Body, usage: usage}
+	return resp, nil
+}
+
+// includeStreamUsage 在流式请求中附加 stream_options.include_usage，非流式请求保持不变
+func includeStreamUsage(body []byte) []byte {
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(body, &req); err != nil {
+		return body
+	}
+
+	if string(req["stream"]) != "true" {
+		return body
+	}
+
+	req["stream_options"] = json.RawMessage(`{"include_usage":true}`)
+	data, err := json.Marshal(req)
+	if err != nil {
+		return body
+	}
+
+	return data
+}
+
+// usageReader 读取 SSE 响应的同时，解析其中包含 usage 的事件
+type usageReader struct {
+	io.ReadCloser
+	usage *streamUsage
+	line  []byte
+}
+
+func (r *usageReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.line = append(r.line, p[:n]...)
+	for {
+		i := bytes.IndexByte(r.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		r.parse(r.line[:i])
+		r.line = r.line[i+1:]
+	}
+
+	return n, err
+}
+
+func (r *usageReader) parse(line []byte) {
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte("data:")) || !bytes.Contains(line, []byte(`"usage"`)) {
+		return
+	}
+
+	var event struct {
+		Usage *openai.Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:"))), &event); err != nil {
+		return
+	}
+
+	if event.Usage != nil && (event.Usage.PromptTokens > 0 || event.Usage.CompletionTokens > 0) {
+		r.usage.set(*event.Usage)
+	}
+}
@@ -0,0 +1,73 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/openai"
+	"github.com/mylxsw/go-utils/assert"
+	openailib "github.com/sashabaranov/go-openai"
+)
+
+func TestChatStreamUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hello"}}]}`+"\n\n")
+
+		// 只有请求中包含 stream_options.include_usage 时才返回用量
+		if opts, ok := req["stream_options"].(map[string]interface{}); ok && opts["include_usage"] == true {
+			_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`+"\n\n")
+		}
+
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	chatStream := func(streamUsage bool) (string, *openailib.Usage) {
+		client := openai.NewOpenAIClient(&openai.Config{
+			Enable:        true,
+			OpenAIServers: []string{server.URL + "/v1"},
+			OpenAIKeys:    []string{"sk-test"},
+			StreamUsage:   streamUsage,
+		}, nil)
+
+		stream, err := client.ChatStream(context.TODO(), openailib.ChatCompletionRequest{
+			Model:    "gpt-3.5-turbo",
+			Messages: []openailib.ChatCompletionMessage{{Role: "user", Content: "hi"}},
+			Stream:   true,
+		})
+		assert.NoError(t, err)
+
+		var text string
+		var usage *openailib.Usage
+		for res := range stream {
+			if res.Usage != nil {
+				usage = res.Usage
+				continue
+			}
+
+			for _, choice := range res.ChatResponse.Choices {
+				text += choice.Delta.Content
+			}
+		}
+
+		return text, usage
+	}
+
+	text, usage := chatStream(true)
+	assert.Equal(t, "hello", text)
+	assert.True(t, usage != nil)
+	assert.Equal(t, 12, usage.PromptTokens)
+	assert.Equal(t, 3, usage.CompletionTokens)
+
+	text, usage = chatStream(false)
+	assert.Equal(t, "hello", text)
+	assert.True(t, usage == nil)
+}
@@ -52,6 +52,10 @@ type Request struct {
 	Model Model `json:"model,omitempty"`
 	// MaxNewTokens 期望模型生成的最大token数 [1,2048], 默认为 1024
 	MaxNewTokens int `json:"max_new_tokens,omitempty"`
+	// Temperature 温度采样参数，取值范围 (0,2]，默认为 0.8
+	Temperature float64 `json:"temperature,omitempty"`
+	// TopP 核采样参数，取值范围 (0,1)，默认为 0.7
+	TopP float64 `json:"top_p,omitempty"`
 	// Messages 输入给模型的对话上下文，数组中的每个对象为聊天的上下文信息
 	Messages []Message `json:"messages,omitempty"`
 	// Stream 是否使用流式传输，如果开启，数据将按照data-only SSE（server-sent events）返回中间结果，并以 data: [DONE] 结束
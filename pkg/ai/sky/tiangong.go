@@ -35,6 +35,15 @@ type Message struct {
 type Request struct {
 	Messages []Message `json:"messages"`
 	Model    string    `json:"model"`
+	// ParamConfig 生成参数，未设置时使用服务端默认值
+	ParamConfig *ParamConfig `json:"param_config,omitempty"`
+}
+
+type ParamConfig struct {
+	// Temperature 取值范围: (0, 1]，越高生成的内容越随机
+	Temperature float64 `json:"temperature,omitempty"`
+	// TopP 取值范围: (0, 1]，核采样概率阈值
+	TopP float64 `json:"top_p,omitempty"`
 }
 
 type Response struct {
@@ -48,6 +57,15 @@ type RespData struct {
 	Status       int    `json:"status"`
 	Reply        string `json:"reply"`
 	FinishReason int    `json:"finish_reason"` // 1正常结束，2：token限制
+	// Usage Token 用量，流式响应中只在最后一个响应中返回
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Usage Token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 func (r RespData) IsSensitive() bool {
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mylxsw/aidea-server/pkg/keypool"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/proxy"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
@@ -16,12 +17,14 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-uuid"
 	"github.com/mylxsw/aidea-server/config"
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/go-utils/array"
 	"github.com/mylxsw/go-utils/must"
 )
 
@@ -33,6 +36,9 @@ const (
 type StabilityAI struct {
 	conf   *config.Config
 	client *http.Client
+	keys   *keypool.Pool[string]
+	// primaryKey 账户余额查询以及异步任务（视频生成）使用的密钥，异步任务的结果只能使用创建任务时的密钥查询
+	primaryKey string
 }
 
 func NewStabilityAI(resolver infra.Resolver, conf *config.Config) *StabilityAI {
@@ -43,11 +49,40 @@ func NewStabilityAI(resolver infra.Resolver, conf *config.Config) *StabilityAI {
 		})
 	}
 
-	return &StabilityAI{conf: conf, client: client}
+	return NewStabilityAIWithClient(conf, client)
 }
 
 func NewStabilityAIWithClient(conf *config.Config, client *http.Client) *StabilityAI {
-	return &StabilityAI{conf: conf, client: client}
+	keys := append([]string{conf.StabilityAIKey}, conf.StabilityAIKeys...)
+	keys = array.Distinct(array.Filter(keys, func(key string, _ int) bool {
+		return strings.TrimSpace(key) != ""
+	}))
+
+	ai := &StabilityAI{conf: conf, client: client, keys: keypool.New("stabilityai", keypool.Keys(keys), keypool.OptionsFromConfig(conf))}
+	if len(keys) > 0 {
+		ai.primaryKey, _ = keypool.ParseKey(keys[0])
+	}
+
+	return ai
+}
+
+// do 从密钥池中选择密钥发起请求，密钥在响应体关闭时归还
+func (ai *StabilityAI) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	lease, err := ai.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+lease.Value)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		lease.Done(0)
+		return nil, err
+	}
+
+	lease.DoneOnClose(resp)
+	return resp, nil
 }
 
 type BalanceResponse struct {
@@ -58,7 +93,7 @@ type BalanceResponse struct {
 func (ai *StabilityAI) AccountBalance(ctx context.Context) (float64, error) {
 	// Build the request
 	req, _ := http.NewRequest("GET", ai.conf.StabilityAIServer[0]+"/v1/user/balance", nil)
-	req.Header.Add("Authorization", "Bearer "+ai.conf.StabilityAIKey)
+	req.Header.Add("Authorization", "Bearer "+ai.primaryKey)
 	if ai.conf.StabilityAIOrganization != "" {
 		req.Header.Add("Organization", ai.conf.StabilityAIOrganization)
 	}
@@ -146,12 +181,11 @@ func (ai *StabilityAI) ImageToImage(ctx context.Context, model string, param Ima
 	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/v1/generation/%s/image-to-image", ai.conf.StabilityAIServer[0], model), payload)
 	req.Header.Add("Content-Type", writer.FormDataContentType())
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+ai.conf.StabilityAIKey)
 	if ai.conf.StabilityAIOrganization != "" {
 		req.Header.Add("Organization", ai.conf.StabilityAIOrganization)
 	}
 
-	resp, err := ai.client.Do(req)
+	resp, err := ai.do(ai.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
@@ -217,12 +251,11 @@ func (ai *StabilityAI) Upscale(ctx context.Context, model string, imagePath stri
 	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/v1/generation/%s/image-to-image/upscale", ai.conf.StabilityAIServer[0], model), payload)
 	req.Header.Add("Content-Type", writer.FormDataContentType())
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+ai.conf.StabilityAIKey)
 	if ai.conf.StabilityAIOrganization != "" {
 		req.Header.Add("Organization", ai.conf.StabilityAIOrganization)
 	}
 
-	resp, err := ai.client.Do(req)
+	resp, err := ai.do(ai.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
@@ -264,8 +297,13 @@ func (ai *StabilityAI) TextToImage(model string, param TextToImageRequest) (*Tex
 
 	log.Debugf("request data: %s", string(reqData))
 
+	lease, err := ai.keys.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
 	client := misc.RestyClient(2).R().
-		SetHeader("Authorization", "Bearer "+ai.conf.StabilityAIKey).
+		SetHeader("Authorization", "Bearer "+lease.Value).
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Accept", "application/json")
 
@@ -275,9 +313,12 @@ func (ai *StabilityAI) TextToImage(model string, param TextToImageRequest) (*Tex
 
 	resp, err := client.SetBody(reqData).Post(fmt.Sprintf("%s/v1/generation/%s/text-to-image", ai.conf.StabilityAIServer[0], model))
 	if err != nil {
+		lease.Done(0)
 		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
 
+	lease.Done(resp.StatusCode())
+
 	if resp.IsError() {
 		return nil, errorHandle(resp.Body())
 	}
@@ -136,7 +136,7 @@ func (ai *StabilityAI) ImageToVideo(ctx context.Context, imageToVideoReq VideoRe
 	req, _ := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v2alpha/generation/image-to-video", ai.conf.StabilityAIServer[0]), payload)
 	req.Header.Add("Content-Type", writer.FormDataContentType())
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+ai.conf.StabilityAIKey)
+	req.Header.Add("Authorization", "Bearer "+ai.primaryKey)
 	if ai.conf.StabilityAIOrganization != "" {
 		req.Header.Add("Organization", ai.conf.StabilityAIOrganization)
 	}
@@ -171,7 +171,7 @@ func (ai *StabilityAI) ImageToVideo(ctx context.Context, imageToVideoReq VideoRe
 func (ai *StabilityAI) ImageToVideoResult(ctx context.Context, taskID string) (*VideoResponse, error) {
 	// Build the request
 	req, _ := http.NewRequestWithContext(ctx, "GET", ai.conf.StabilityAIServer[0]+"/v2alpha/generation/image-to-video/result/"+taskID, nil)
-	req.Header.Add("Authorization", "Bearer "+ai.conf.StabilityAIKey)
+	req.Header.Add("Authorization", "Bearer "+ai.primaryKey)
 	if ai.conf.StabilityAIOrganization != "" {
 		req.Header.Add("Organization", ai.conf.StabilityAIOrganization)
 	}
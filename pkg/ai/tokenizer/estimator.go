@@ -0,0 +1,59 @@
+package tokenizer
+
+import (
+	"math"
+	"unicode"
+)
+
+// defaultEstimator 通用估算器，用于词表加载失败时兜底
+var defaultEstimator = NewEstimator("default", 1, 1.3, 1)
+
+// Estimator 基于字符分类的 Token 估算器，用于未公开词表的厂商模型
+// 文本被拆分为中日韩字符、单词（连续的字母或者数字）以及符号，分别乘以对应的系数后求和
+type Estimator struct {
+	name string
+	// cjk 每个中日韩字符对应的 Token 数量
+	cjk float64
+	// word 每个单词对应的 Token 数量
+	word float64
+	// symbol 每个标点符号对应的 Token 数量
+	symbol float64
+}
+
+// NewEstimator 创建一个 Token 估算器
+func NewEstimator(name string, cjk, word, symbol float64) *Estimator {
+	return &Estimator{name: name, cjk: cjk, word: word, symbol: symbol}
+}
+
+func (e *Estimator) Name() string {
+	return "estimator/" + e.name
+}
+
+func (e *Estimator) MessageOverhead() int {
+	return 0
+}
+
+func (e *Estimator) Count(text string) int {
+	var cjk, words, symbols int
+	var inWord bool
+
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case unicode.IsSpace(r):
+			inWord = false
+		default:
+			symbols++
+			inWord = false
+		}
+	}
+
+	return int(math.Ceil(float64(cjk)*e.cjk + float64(words)*e.word + float64(symbols)*e.symbol))
+}
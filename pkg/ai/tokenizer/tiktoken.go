@@ -0,0 +1,54 @@
+package tokenizer
+
+import (
+	"sync"
+
+	"github.com/mylxsw/asteria/log"
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+func init() {
+	// 使用随程序打包的词表文件，避免运行时从 OpenAI 下载
+	tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+}
+
+// Tiktoken OpenAI 的 BPE 分词器
+type Tiktoken struct {
+	encoding        string
+	messageOverhead int
+
+	once sync.Once
+	tkm  *tiktoken.Tiktoken
+}
+
+// NewTiktoken 创建 tiktoken 分词器，encoding 为词表名称，如 `cl100k_base`
+func NewTiktoken(encoding string, messageOverhead int) *Tiktoken {
+	return &Tiktoken{encoding: encoding, messageOverhead: messageOverhead}
+}
+
+func (t *Tiktoken) Name() string {
+	return "tiktoken/" + t.encoding
+}
+
+func (t *Tiktoken) MessageOverhead() int {
+	return t.messageOverhead
+}
+
+func (t *Tiktoken) Count(text string) int {
+	t.once.Do(func() {
+		tkm, err := tiktoken.GetEncoding(t.encoding)
+		if err != nil {
+			log.With(err).Errorf("load tiktoken encoding %s failed", t.encoding)
+			return
+		}
+
+		t.tkm = tkm
+	})
+
+	if t.tkm == nil {
+		return defaultEstimator.Count(text)
+	}
+
+	return len(t.tkm.Encode(text, nil, nil))
+}
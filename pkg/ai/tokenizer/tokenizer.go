@@ -0,0 +1,86 @@
+package tokenizer
+
+import (
+	"strings"
+	"sync"
+)
+
+// Tokenizer 分词器，用于计算文本消耗的 Token 数量
+type Tokenizer interface {
+	// Name 分词器名称
+	Name() string
+	// Count 计算文本的 Token 数量
+	Count(text string) int
+	// MessageOverhead 对话中每条消息额外消耗的 Token 数量（消息格式标记等），为 0 时表示厂商不计算该部分
+	MessageOverhead() int
+}
+
+type family struct {
+	prefixes  []string
+	tokenizer Tokenizer
+}
+
+var (
+	lock     sync.RWMutex
+	families []family
+	// fallback 无法匹配模型时使用的分词器
+	fallback Tokenizer = NewTiktoken("cl100k_base", 4)
+)
+
+func init() {
+	// OpenAI 官方分词器，词表随程序一起打包，无需联网下载
+	Register(NewTiktoken("cl100k_base", 4), "gpt-3.5-turbo", "openai.gpt-3.5-turbo")
+	Register(NewTiktoken("cl100k_base", 3), "gpt-4", "openai.gpt-4")
+
+	// 以下厂商未公开词表，根据厂商文档中的换算规则进行估算
+
+	// 文心千帆：1 个 token 约等于 1 个汉字或 1.3 个英文单词
+	Register(NewEstimator("ernie", 1, 1.3, 1), "model_ernie_bot", "ernie")
+	// 通义千问：1 个 token 约等于 1.5 个汉字或 0.75 个英文单词
+	Register(NewEstimator("qwen", 0.67, 1.3, 1), "qwen")
+	// 讯飞星火：1 个 token 约等于 1.5 个汉字或 0.8 个英文单词
+	Register(NewEstimator("spark", 0.67, 1.25, 1), "general")
+	// Anthropic Claude：英文约 3.5 个字符为 1 个 token，中文基本为 1 个汉字 1 个 token
+	Register(NewEstimator("claude", 1, 1.4, 1), "claude", "anthropic.claude")
+	// Google Gemini：1 个 token 约等于 4 个字符，100 个 token 约等于 60-80 个英文单词
+	Register(NewEstimator("gemini", 0.8, 1.4, 1), "gemini", "google.gemini")
+}
+
+// Register 注册分词器，prefixes 为该分词器负责的模型 ID 前缀，后注册的优先级更高
+func Register(tokenizer Tokenizer, prefixes ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	families = append([]family{{prefixes: prefixes, tokenizer: tokenizer}}, families...)
+}
+
+// ForModel 查询模型对应的分词器，模型 ID 中的厂商前缀（如 `灵积:`）会被忽略
+func ForModel(model string) Tokenizer {
+	if segs := strings.SplitN(model, ":", 2); len(segs) == 2 {
+		model = segs[1]
+	}
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	var matched Tokenizer
+	var matchedPrefix string
+	for _, f := range families {
+		for _, prefix := range f.prefixes {
+			if strings.HasPrefix(model, prefix) && len(prefix) > len(matchedPrefix) {
+				matched, matchedPrefix = f.tokenizer, prefix
+			}
+		}
+	}
+
+	if matched != nil {
+		return matched
+	}
+
+	return fallback
+}
+
+// Count 使用模型对应的分词器计算文本的 Token 数量
+func Count(model string, text string) int {
+	return ForModel(model).Count(text)
+}
@@ -0,0 +1,47 @@
+package tokenizer_test
+
+import (
+	"testing"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/tokenizer"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestForModel(t *testing.T) {
+	testcases := map[string]string{
+		"gpt-4-1106-preview":   "tiktoken/cl100k_base",
+		"gpt-3.5-turbo":        "tiktoken/cl100k_base",
+		"model_ernie_bot_4":    "estimator/ernie",
+		"文心千帆:model_ernie_bot": "estimator/ernie",
+		"qwen-max":             "estimator/qwen",
+		"generalv3":            "estimator/spark",
+		"claude-2":             "estimator/claude",
+		"anthropic.claude-2":   "estimator/claude",
+		"gemini-pro":           "estimator/gemini",
+		"unknown-model":        "tiktoken/cl100k_base",
+	}
+
+	for model, expect := range testcases {
+		assert.Equal(t, expect, tokenizer.ForModel(model).Name())
+	}
+
+	assert.Equal(t, 3, tokenizer.ForModel("gpt-4").MessageOverhead())
+	assert.Equal(t, 4, tokenizer.ForModel("gpt-3.5-turbo-16k").MessageOverhead())
+	assert.Equal(t, 0, tokenizer.ForModel("qwen-max").MessageOverhead())
+}
+
+func TestTiktoken_Count(t *testing.T) {
+	// 词表随程序打包，无需联网
+	assert.Equal(t, 2, tokenizer.Count("gpt-4", "hello world"))
+}
+
+func TestEstimator_Count(t *testing.T) {
+	estimator := tokenizer.NewEstimator("test", 1, 1.3, 1)
+
+	assert.Equal(t, 0, estimator.Count(""))
+	assert.Equal(t, 4, estimator.Count("你好世界"))
+	// 2 个单词 * 1.3 + 1 个符号 = 3.6
+	assert.Equal(t, 4, estimator.Count("hello world!"))
+	// 2 个汉字 + 1 个单词 * 1.3 + 1 个符号 = 4.3
+	assert.Equal(t, 5, estimator.Count("你好, GPT4"))
+}
@@ -87,8 +87,14 @@ type ResponseHeader struct {
 	Status int `json:"status,omitempty"`
 }
 
-// ChatStream 发起聊天
-func (ai *XFYunAI) ChatStream(ctx context.Context, model Model, messages []Message) (<-chan Response, error) {
+// ChatParams 聊天参数，未设置的参数使用默认值
+type ChatParams struct {
+	// Temperature 核采样阈值，用于决定结果随机性，取值越高随机性越强，取值范围 (0,1]，默认为 0.8
+	Temperature float64
+}
+
+// ChatStream 发起聊天，params 为可选的聊天参数
+func (ai *XFYunAI) ChatStream(ctx context.Context, model Model, messages []Message, params ...ChatParams) (<-chan Response, error) {
 	ws := websocket.DefaultDialer
 
 	host := ai.resolveHostForModel(model)
@@ -107,7 +113,12 @@ func (ai *XFYunAI) ChatStream(ctx context.Context, model Model, messages []Messa
 		return nil, fmt.Errorf("创建 WS 连接失败，状态码：%d", resp.StatusCode)
 	}
 
-	req := ai.buildParams(model, messages)
+	var chatParams ChatParams
+	if len(params) > 0 {
+		chatParams = params[0]
+	}
+
+	req := ai.buildParams(model, messages, chatParams)
 	if err := conn.WriteJSON(req); err != nil {
 		return nil, fmt.Errorf("发送消息失败：%w", err)
 	}
@@ -211,7 +222,12 @@ const (
 )
 
 // buildParams 构建请求参数
-func (ai *XFYunAI) buildParams(model Model, messages []Message) map[string]any {
+func (ai *XFYunAI) buildParams(model Model, messages []Message, params ChatParams) map[string]any {
+	temperature := 0.8
+	if params.Temperature > 0 {
+		temperature = params.Temperature
+	}
+
 	data := map[string]any{
 		"header": map[string]any{
 			"app_id": ai.appID,
@@ -219,7 +235,7 @@ func (ai *XFYunAI) buildParams(model Model, messages []Message) map[string]any {
 		"parameter": map[string]any{
 			"chat": map[string]any{
 				"domain":      model,
-				"temperature": 0.8,
+				"temperature": temperature,
 				"top_k":       int64(6),
 				"max_tokens":  int64(2048),
 				"auditing":    "default",
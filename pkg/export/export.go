@@ -0,0 +1,311 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format 导出格式
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+)
+
+// Valid 是否为支持的导出格式
+func (f Format) Valid() bool {
+	return f == FormatMarkdown || f == FormatHTML || f == FormatJSON
+}
+
+// Ext 导出文件的扩展名
+func (f Format) Ext() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	case FormatHTML:
+		return "html"
+	}
+
+	return "json"
+}
+
+// Version 导出的 JSON 格式版本，导入时用于兼容性检查
+const Version = 1
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Conversation 导出的对话
+type Conversation struct {
+	Version    int       `json:"version"`
+	Room       Room      `json:"room"`
+	ExportedAt time.Time `json:"exported_at"`
+	Messages   []Message `json:"messages"`
+}
+
+// Room 对话所属的数字人
+type Room struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Message 对话中的消息，通过 ID 和 PID 保留对话的分支结构
+type Message struct {
+	ID        int64     `json:"id"`
+	PID       int64     `json:"pid,omitempty"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Images    []string  `json:"images,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Render 将对话渲染为指定的格式
+func Render(format Format, conv Conversation) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(conv), nil
+	case FormatHTML:
+		return HTML(conv)
+	case FormatJSON:
+		return json.MarshalIndent(conv, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// speaker 消息发送者的展示名称，回复消息包含生成回复的模型
+func (msg Message) speaker() string {
+	if msg.Role == RoleUser {
+		return "用户"
+	}
+
+	if msg.Model != "" {
+		return fmt.Sprintf("助手（%s）", msg.Model)
+	}
+
+	return "助手"
+}
+
+// Markdown 将对话渲染为 Markdown 格式
+func Markdown(conv Conversation) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf("# %s\n\n", conv.Room.Name))
+	buf.WriteString(fmt.Sprintf("> 导出时间：%s", conv.ExportedAt.Format(timeLayout)))
+	if conv.Room.Model != "" {
+		buf.WriteString(fmt.Sprintf("，模型：%s", conv.Room.Model))
+	}
+	buf.WriteString("\n\n")
+
+	for _, msg := range conv.Messages {
+		buf.WriteString("---\n\n")
+		buf.WriteString(fmt.Sprintf("**%s** · %s\n\n", msg.speaker(), msg.CreatedAt.Format(timeLayout)))
+
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			buf.WriteString(content)
+			buf.WriteString("\n\n")
+		}
+
+		for _, img := range msg.Images {
+			buf.WriteString(fmt.Sprintf("![image](%s)\n\n", img))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+var htmlTemplate = template.Must(template.New("conversation").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format(timeLayout) },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Room.Name }}</title>
+<style>
+body { margin: 0; padding: 24px; background: #f5f5f5; color: #333; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; }
+.container { max-width: 800px; margin: 0 auto; }
+h1 { font-size: 24px; margin-bottom: 4px; }
+.meta { color: #999; font-size: 13px; margin-bottom: 24px; }
+.message { padding: 12px 16px; margin-bottom: 12px; border-radius: 8px; background: #fff; }
+.message.user { background: #e8f4ff; }
+.speaker { font-weight: bold; font-size: 14px; }
+.time { color: #999; font-size: 12px; margin-left: 8px; }
+.content { margin-top: 8px; white-space: pre-wrap; word-wrap: break-word; line-height: 1.6; }
+.content img { display: block; max-width: 100%; margin-top: 8px; border-radius: 4px; }
+</style>
+</head>
+<body>
+<div class="container">
+<h1>{{ .Room.Name }}</h1>
+<div class="meta">导出时间：{{ datetime .ExportedAt }}{{ if .Room.Model }}，模型：{{ .Room.Model }}{{ end }}</div>
+{{ range .Messages }}<div class="message {{ .Role }}">
+<span class="speaker">{{ .Speaker }}</span><span class="time">{{ datetime .CreatedAt }}</span>
+<div class="content">{{ .Content }}{{ range .Images }}<img src="{{ . }}" alt="image">{{ end }}</div>
+</div>
+{{ end }}</div>
+</body>
+</html>
+`))
+
+// HTML 将对话渲染为独立的 HTML 文件（样式内嵌，不依赖外部资源）
+func HTML(conv Conversation) ([]byte, error) {
+	type htmlMessage struct {
+		Message
+		Speaker string
+	}
+
+	messages := make([]htmlMessage, 0, len(conv.Messages))
+	for _, msg := range conv.Messages {
+		msg.Content = strings.TrimSpace(msg.Content)
+		messages = append(messages, htmlMessage{Message: msg, Speaker: msg.speaker()})
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, map[string]any{
+		"Room":       conv.Room,
+		"ExportedAt": conv.ExportedAt,
+		"Messages":   messages,
+	}); err != nil {
+		return nil, fmt.Errorf("render html failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Parse 解析导出的 JSON 格式对话，用于导入
+func Parse(data []byte) (*Conversation, error) {
+	return Decode(bytes.NewReader(data), 0)
+}
+
+// ErrTooManyMessages 导入的对话中消息数量超过限制
+var ErrTooManyMessages = errors.New("too many messages")
+
+// Decode 从 r 中读取并解析导出的 JSON 格式对话
+// maxMessages 大于 0 时，消息数量超过限制立即返回 ErrTooManyMessages，不再继续读取剩余的消息
+func Decode(r io.Reader, maxMessages int) (*Conversation, error) {
+	conv, err := decodeConversation(json.NewDecoder(r), maxMessages)
+	if err != nil {
+		if errors.Is(err, ErrTooManyMessages) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("invalid conversation: %w", err)
+	}
+
+	if conv.Version < 1 || conv.Version > Version {
+		return nil, fmt.Errorf("unsupported conversation version: %d", conv.Version)
+	}
+
+	if len(conv.Messages) == 0 {
+		return nil, errors.New("conversation has no messages")
+	}
+
+	ids := make(map[int64]bool, len(conv.Messages))
+	for _, msg := range conv.Messages {
+		if msg.Role != RoleUser && msg.Role != RoleAssistant {
+			return nil, fmt.Errorf("invalid message role: %s", msg.Role)
+		}
+
+		if msg.ID <= 0 || ids[msg.ID] {
+			return nil, fmt.Errorf("invalid message id: %d", msg.ID)
+		}
+
+		// 父消息必须出现在子消息之前
+		if msg.PID > 0 && !ids[msg.PID] {
+			return nil, fmt.Errorf("parent message %d of message %d not found", msg.PID, msg.ID)
+		}
+
+		ids[msg.ID] = true
+	}
+
+	return conv, nil
+}
+
+// decodeConversation 逐条解析对话中的消息，以便在消息数量超过限制时尽早返回
+func decodeConversation(dec *json.Decoder, maxMessages int) (*Conversation, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var conv Conversation
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch key, _ := token.(string); key {
+		case "version":
+			err = dec.Decode(&conv.Version)
+		case "room":
+			err = dec.Decode(&conv.Room)
+		case "exported_at":
+			err = dec.Decode(&conv.ExportedAt)
+		case "messages":
+			err = decodeMessages(dec, &conv, maxMessages)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	return &conv, nil
+}
+
+func decodeMessages(dec *json.Decoder, conv *Conversation, maxMessages int) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		if maxMessages > 0 && len(conv.Messages) >= maxMessages {
+			return ErrTooManyMessages
+		}
+
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			return err
+		}
+
+		conv.Messages = append(conv.Messages, msg)
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %s, got %v", delim, token)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func testConversation() Conversation {
+	ts := time.Date(2024, 2, 4, 10, 0, 0, 0, time.Local)
+	return Conversation{
+		Version:    Version,
+		Room:       Room{ID: 2, Name: "翻译助手", Model: "gpt-4"},
+		ExportedAt: ts,
+		Messages: []Message{
+			{ID: 10, Role: RoleUser, Content: "这张图片里有什么？<script>", Images: []string{"https://example.com/a.png"}, CreatedAt: ts},
+			{ID: 11, PID: 10, Role: RoleAssistant, Content: "一只猫", Model: "gpt-4-vision-preview", CreatedAt: ts},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	md, err := Render(FormatMarkdown, testConversation())
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(md), "# 翻译助手\n"))
+	assert.True(t, strings.Contains(string(md), "![image](https://example.com/a.png)"))
+	assert.True(t, strings.Contains(string(md), "**助手（gpt-4-vision-preview）**"))
+
+	html, err := Render(FormatHTML, testConversation())
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(html), `<img src="https://example.com/a.png"`))
+	assert.True(t, strings.Contains(string(html), "&lt;script&gt;"))
+	assert.False(t, strings.Contains(string(html), "<script>"))
+
+	data, err := Render(FormatJSON, testConversation())
+	assert.NoError(t, err)
+
+	conv, err := Parse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(conv.Messages))
+	assert.Equal(t, int64(10), conv.Messages[1].PID)
+	assert.Equal(t, []string{"https://example.com/a.png"}, conv.Messages[0].Images)
+
+	_, err = Render(Format("pdf"), testConversation())
+	assert.True(t, err != nil)
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse([]byte(`{"version": 2, "messages": [{"id": 1, "role": "user"}]}`))
+	assert.True(t, err != nil)
+
+	_, err = Parse([]byte(`{"version": 1, "messages": []}`))
+	assert.True(t, err != nil)
+
+	_, err = Parse([]byte(`{"version": 1, "messages": [{"id": 1, "role": "system"}]}`))
+	assert.True(t, err != nil)
+
+	// 父消息需要出现在子消息之前
+	_, err = Parse([]byte(`{"version": 1, "messages": [{"id": 2, "pid": 1, "role": "assistant"}, {"id": 1, "role": "user"}]}`))
+	assert.True(t, err != nil)
+
+	_, err = Parse([]byte(`{"version": 1, "messages": {}}`))
+	assert.True(t, err != nil)
+}
+
+func TestDecode(t *testing.T) {
+	data := `{"version": 1, "extra": {"a": [1, 2]}, "room": {"name": "导入"}, "messages": [{"id": 1, "role": "user"}, {"id": 2, "pid": 1, "role": "assistant"}]}`
+
+	conv, err := Decode(strings.NewReader(data), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "导入", conv.Room.Name)
+	assert.Equal(t, 2, len(conv.Messages))
+
+	// 消息数量超过限制时，不再解析剩余的内容
+	_, err = Decode(strings.NewReader(data), 1)
+	assert.True(t, errors.Is(err, ErrTooManyMessages))
+}
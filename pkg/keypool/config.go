@@ -0,0 +1,17 @@
+package keypool
+
+import "github.com/mylxsw/aidea-server/config"
+
+// OptionsFromConfig 从配置中读取密钥池配置
+func OptionsFromConfig(conf *config.Config) Options {
+	if conf == nil {
+		return Options{}
+	}
+
+	return Options{
+		Cooldown:       conf.KeyPoolCooldown,
+		AuthCooldown:   conf.KeyPoolAuthCooldown,
+		MaxConcurrency: conf.KeyPoolMaxConcurrency,
+		RPM:            conf.KeyPoolRPM,
+	}
+}
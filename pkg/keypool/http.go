@@ -0,0 +1,31 @@
+package keypool
+
+import (
+	"io"
+	"net/http"
+)
+
+// DoneOnClose 在响应体关闭时归还密钥，用于流式响应，使并发限制覆盖整个响应的读取过程
+func (l *Lease[T]) DoneOnClose(resp *http.Response) {
+	resp.Body = &leaseBody[T]{ReadCloser: resp.Body, lease: l, statusCode: resp.StatusCode}
+}
+
+type leaseBody[T any] struct {
+	io.ReadCloser
+	lease      *Lease[T]
+	statusCode int
+}
+
+func (b *leaseBody[T]) Close() error {
+	defer b.lease.Done(b.statusCode)
+	return b.ReadCloser.Close()
+}
+
+// StatusCode 返回请求的状态码，请求失败（未得到响应）时返回 0
+func StatusCode(resp *http.Response, err error) int {
+	if err != nil || resp == nil {
+		return 0
+	}
+
+	return resp.StatusCode
+}
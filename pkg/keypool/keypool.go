@@ -0,0 +1,269 @@
+package keypool
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mylxsw/asteria/log"
+)
+
+// ErrNoAvailableKey 所有密钥都已达到并发或者频率限制
+var ErrNoAvailableKey = errors.New("no available api key")
+
+// Options 密钥池配置，并发和频率限制对池中的每一个密钥单独生效
+type Options struct {
+	// Cooldown 密钥被限流（429）后的隔离时间
+	Cooldown time.Duration
+	// AuthCooldown 密钥认证失败（401/403）后的隔离时间
+	AuthCooldown time.Duration
+	// MaxConcurrency 单个密钥的最大并发请求数，0 表示不限制
+	MaxConcurrency int
+	// RPM 单个密钥每分钟的最大请求数，0 表示不限制
+	RPM int
+}
+
+// Entry 密钥池中的一项，Value 可以是密钥本身，也可以是使用该密钥创建的客户端
+type Entry[T any] struct {
+	// ID 用于日志和监控指标的标识，不应该包含完整的密钥
+	ID     string
+	Value  T
+	Weight int
+}
+
+type key[T any] struct {
+	Entry[T]
+	inflight        int
+	requests        []time.Time
+	quarantineUntil time.Time
+}
+
+// Pool 按照权重选择密钥的密钥池，会跳过隔离中以及达到并发、频率限制的密钥
+// 密钥请求返回 401/403/429 时，会被自动隔离一段时间
+type Pool[T any] struct {
+	vendor string
+	opts   Options
+	now    func() time.Time
+
+	lock sync.Mutex
+	keys []*key[T]
+}
+
+// New 创建密钥池，vendor 为服务商名称，用于日志和监控指标
+func New[T any](vendor string, entries []Entry[T], opts Options) *Pool[T] {
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = time.Minute
+	}
+
+	if opts.AuthCooldown <= 0 {
+		opts.AuthCooldown = 30 * time.Minute
+	}
+
+	pool := &Pool[T]{vendor: vendor, opts: opts, now: time.Now}
+	for _, entry := range entries {
+		if entry.Weight <= 0 {
+			entry.Weight = 1
+		}
+
+		pool.keys = append(pool.keys, &key[T]{Entry: entry})
+		healthyGauge.WithLabelValues(vendor, entry.ID).Set(1)
+	}
+
+	return pool
+}
+
+// Len 密钥池中的密钥数量
+func (p *Pool[T]) Len() int {
+	return len(p.keys)
+}
+
+// Acquire 选择一个可用的密钥，使用完毕后必须调用 Lease.Done
+// 所有密钥都处于隔离状态时，选择最早解除隔离的密钥，避免误判导致服务完全不可用
+func (p *Pool[T]) Acquire() (*Lease[T], error) {
+	if len(p.keys) == 0 {
+		return nil, ErrNoAvailableKey
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	candidates := make([]*key[T], 0, len(p.keys))
+	var limited bool
+	for _, k := range p.keys {
+		if now.Before(k.quarantineUntil) {
+			continue
+		}
+
+		if !k.quarantineUntil.IsZero() {
+			k.quarantineUntil = time.Time{}
+			healthyGauge.WithLabelValues(p.vendor, k.ID).Set(1)
+		}
+
+		if !p.allow(k, now) {
+			limited = true
+			continue
+		}
+
+		candidates = append(candidates, k)
+	}
+
+	var selected *key[T]
+	switch {
+	case len(candidates) > 0:
+		selected = pickWeighted(candidates)
+	case limited:
+		return nil, ErrNoAvailableKey
+	default:
+		for _, k := range p.keys {
+			if selected == nil || k.quarantineUntil.Before(selected.quarantineUntil) {
+				selected = k
+			}
+		}
+
+		log.F(log.M{"vendor": p.vendor, "key": selected.ID}).Warningf("all api keys are quarantined, fallback to the earliest recovered one")
+	}
+
+	selected.inflight++
+	if p.opts.RPM > 0 {
+		selected.requests = append(selected.requests, now)
+	}
+
+	inflightGauge.WithLabelValues(p.vendor, selected.ID).Inc()
+
+	return &Lease[T]{Value: selected.Value, ID: selected.ID, pool: p, key: selected}, nil
+}
+
+// allow 判断密钥是否未达到并发和频率限制
+func (p *Pool[T]) allow(k *key[T], now time.Time) bool {
+	if p.opts.MaxConcurrency > 0 && k.inflight >= p.opts.MaxConcurrency {
+		return false
+	}
+
+	if p.opts.RPM > 0 {
+		windowStart := now.Add(-time.Minute)
+		expired := 0
+		for expired < len(k.requests) && !k.requests[expired].After(windowStart) {
+			expired++
+		}
+
+		k.requests = k.requests[expired:]
+		if len(k.requests) >= p.opts.RPM {
+			return false
+		}
+	}
+
+	return true
+}
+
+// release 请求结束，根据响应状态码决定是否隔离密钥
+func (p *Pool[T]) release(k *key[T], statusCode int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	k.inflight--
+	inflightGauge.WithLabelValues(p.vendor, k.ID).Dec()
+	requestCounter.WithLabelValues(p.vendor, k.ID, statusLabel(statusCode)).Inc()
+
+	var cooldown time.Duration
+	var reason string
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		cooldown, reason = p.opts.AuthCooldown, "unauthorized"
+	case http.StatusTooManyRequests:
+		cooldown, reason = p.opts.Cooldown, "rate_limited"
+	default:
+		return
+	}
+
+	k.quarantineUntil = p.now().Add(cooldown)
+	healthyGauge.WithLabelValues(p.vendor, k.ID).Set(0)
+	quarantineCounter.WithLabelValues(p.vendor, k.ID, reason).Inc()
+
+	log.F(log.M{"vendor": p.vendor, "key": k.ID, "status": statusCode}).Warningf("api key quarantined for %s", cooldown)
+}
+
+// pickWeighted 按照权重随机选择一个密钥
+func pickWeighted[T any](candidates []*key[T]) *key[T] {
+	total := 0
+	for _, k := range candidates {
+		total += k.Weight
+	}
+
+	n := rand.Intn(total)
+	for _, k := range candidates {
+		if n < k.Weight {
+			return k
+		}
+
+		n -= k.Weight
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+func statusLabel(statusCode int) string {
+	if statusCode <= 0 {
+		return "error"
+	}
+
+	return strconv.Itoa(statusCode)
+}
+
+// Lease 从密钥池中取出的密钥
+type Lease[T any] struct {
+	Value T
+	ID    string
+
+	pool *Pool[T]
+	key  *key[T]
+	once sync.Once
+}
+
+// Done 归还密钥，statusCode 为服务端响应的 HTTP 状态码，请求未得到响应时为 0，多次调用只有第一次生效
+func (l *Lease[T]) Done(statusCode int) {
+	l.once.Do(func() {
+		l.pool.release(l.key, statusCode)
+	})
+}
+
+// ParseKey 解析配置中的密钥，格式为 {key} 或者 {key}|{weight}，未指定权重时权重为 1
+func ParseKey(value string) (string, int) {
+	value = strings.TrimSpace(value)
+	if idx := strings.LastIndex(value, "|"); idx > 0 {
+		if weight, err := strconv.Atoi(strings.TrimSpace(value[idx+1:])); err == nil && weight > 0 {
+			return strings.TrimSpace(value[:idx]), weight
+		}
+	}
+
+	return value, 1
+}
+
+// MaskKey 隐藏密钥的中间部分，用作密钥的标识
+func MaskKey(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return string(runes[:3]) + "..." + string(runes[len(runes)-4:])
+}
+
+// Keys 将配置中的密钥转换为密钥池的项，Value 为密钥本身
+func Keys(values []string) []Entry[string] {
+	entries := make([]Entry[string], 0, len(values))
+	for _, value := range values {
+		k, weight := ParseKey(value)
+		if k == "" {
+			continue
+		}
+
+		entries = append(entries, Entry[string]{ID: MaskKey(k), Value: k, Weight: weight})
+	}
+
+	return entries
+}
@@ -0,0 +1,168 @@
+package keypool
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/go-utils/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestPool(opts Options, entries ...Entry[string]) (*Pool[string], *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)}
+	pool := New("test", entries, opts)
+	pool.now = clock.Now
+
+	return pool, clock
+}
+
+func TestPool_Weighted(t *testing.T) {
+	pool, _ := newTestPool(Options{}, Entry[string]{ID: "a", Value: "a", Weight: 9}, Entry[string]{ID: "b", Value: "b", Weight: 1})
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		lease, err := pool.Acquire()
+		assert.NoError(t, err)
+
+		counts[lease.Value]++
+		lease.Done(http.StatusOK)
+	}
+
+	assert.True(t, counts["a"] > counts["b"]*3)
+	assert.True(t, counts["b"] > 0)
+}
+
+func TestPool_Quarantine(t *testing.T) {
+	pool, clock := newTestPool(Options{Cooldown: time.Minute, AuthCooldown: time.Hour}, Keys([]string{"key-aaaaaaaaa", "key-bbbbbbbbb"})...)
+
+	// 收到 429 后，密钥在隔离期间不会被选中
+	lease := acquireValue(t, pool, "key-aaaaaaaaa")
+	lease.Done(http.StatusTooManyRequests)
+	for i := 0; i < 20; i++ {
+		lease, err := pool.Acquire()
+		assert.NoError(t, err)
+		assert.Equal(t, "key-bbbbbbbbb", lease.Value)
+		lease.Done(http.StatusOK)
+	}
+
+	// 所有密钥都被隔离时，选择最早解除隔离的密钥
+	lease = acquireValue(t, pool, "key-bbbbbbbbb")
+	lease.Done(http.StatusUnauthorized)
+
+	lease, err := pool.Acquire()
+	assert.NoError(t, err)
+	assert.Equal(t, "key-aaaaaaaaa", lease.Value)
+	lease.Done(http.StatusOK)
+
+	// 多次归还只有第一次生效
+	lease.Done(http.StatusTooManyRequests)
+
+	// 隔离时间结束后，密钥恢复可用
+	clock.now = clock.now.Add(2 * time.Minute)
+	for i := 0; i < 20; i++ {
+		lease, err := pool.Acquire()
+		assert.NoError(t, err)
+		assert.Equal(t, "key-aaaaaaaaa", lease.Value)
+		lease.Done(http.StatusOK)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	acquireValue(t, pool, "key-bbbbbbbbb").Done(http.StatusOK)
+}
+
+func TestPool_Limits(t *testing.T) {
+	pool, _ := newTestPool(Options{MaxConcurrency: 1}, Entry[string]{ID: "a", Value: "a"})
+
+	lease, err := pool.Acquire()
+	assert.NoError(t, err)
+
+	_, err = pool.Acquire()
+	assert.True(t, errors.Is(err, ErrNoAvailableKey))
+
+	lease.Done(0)
+	lease, err = pool.Acquire()
+	assert.NoError(t, err)
+	lease.Done(http.StatusOK)
+
+	pool, clock := newTestPool(Options{RPM: 2}, Entry[string]{ID: "a", Value: "a"})
+	for i := 0; i < 2; i++ {
+		lease, err := pool.Acquire()
+		assert.NoError(t, err)
+		lease.Done(http.StatusOK)
+	}
+
+	_, err = pool.Acquire()
+	assert.True(t, errors.Is(err, ErrNoAvailableKey))
+
+	clock.now = clock.now.Add(61 * time.Second)
+	_, err = pool.Acquire()
+	assert.NoError(t, err)
+
+	_, err = New[string]("test", nil, Options{}).Acquire()
+	assert.True(t, errors.Is(err, ErrNoAvailableKey))
+}
+
+func TestPool_DoneOnClose(t *testing.T) {
+	pool, _ := newTestPool(Options{MaxConcurrency: 1}, Entry[string]{ID: "a", Value: "a"})
+
+	lease, err := pool.Acquire()
+	assert.NoError(t, err)
+
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("hello"))}
+	lease.DoneOnClose(resp)
+
+	_, err = pool.Acquire()
+	assert.True(t, errors.Is(err, ErrNoAvailableKey))
+
+	assert.NoError(t, resp.Body.Close())
+	_, err = pool.Acquire()
+	assert.NoError(t, err)
+}
+
+func TestParseKey(t *testing.T) {
+	key, weight := ParseKey(" sk-123456 ")
+	assert.Equal(t, "sk-123456", key)
+	assert.Equal(t, 1, weight)
+
+	key, weight = ParseKey("sk-123456|5")
+	assert.Equal(t, "sk-123456", key)
+	assert.Equal(t, 5, weight)
+
+	key, weight = ParseKey("sk-123456|abc")
+	assert.Equal(t, "sk-123456|abc", key)
+	assert.Equal(t, 1, weight)
+
+	assert.Equal(t, "sk-...cdef", MaskKey("sk-1234567890abcdef"))
+	assert.Equal(t, "*****", MaskKey("short"))
+
+	entries := Keys([]string{"", "sk-1234567890abcdef|3"})
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, 3, entries[0].Weight)
+	assert.Equal(t, "sk-1234567890abcdef", entries[0].Value)
+}
+
+func acquireValue(t *testing.T, pool *Pool[string], value string) *Lease[string] {
+	for i := 0; i < 100; i++ {
+		lease, err := pool.Acquire()
+		assert.NoError(t, err)
+		if lease.Value == value {
+			return lease
+		}
+
+		lease.Done(http.StatusOK)
+	}
+
+	t.Fatalf("key %s not selected", value)
+	return nil
+}
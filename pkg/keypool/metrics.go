@@ -0,0 +1,35 @@
+package keypool
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aidea",
+		Name:      "api_key_request_count",
+		Help:      "api key request counts",
+	}, []string{"vendor", "key", "status"})
+
+	quarantineCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aidea",
+		Name:      "api_key_quarantine_count",
+		Help:      "api key quarantine counts",
+	}, []string{"vendor", "key", "reason"})
+
+	inflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aidea",
+		Name:      "api_key_inflight_requests",
+		Help:      "api key inflight requests",
+	}, []string{"vendor", "key"})
+
+	healthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aidea",
+		Name:      "api_key_healthy",
+		Help:      "whether the api key is available (1) or quarantined (0)",
+	}, []string{"vendor", "key"})
+)
+
+func init() {
+	prometheus.MustRegister(requestCounter, quarantineCounter, inflightGauge, healthyGauge)
+}
@@ -0,0 +1,141 @@
+package knowledge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+var ErrUnsupportedDocument = errors.New("unsupported document type")
+
+// SupportedExtensions 支持的文档类型
+var SupportedExtensions = []string{".txt", ".md", ".markdown", ".pdf"}
+
+// IsSupported 判断文档类型是否支持
+func IsSupported(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range SupportedExtensions {
+		if e == ext {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExtractText 从文档中提取文本内容，支持纯文本、Markdown 和 PDF 文档
+func ExtractText(filename string, data []byte) (string, error) {
+	var text string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".txt", ".md", ".markdown":
+		if !utf8.Valid(data) {
+			return "", errors.New("document is not utf-8 encoded")
+		}
+
+		text = string(data)
+	case ".pdf":
+		reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return "", fmt.Errorf("open pdf failed: %w", err)
+		}
+
+		plain, err := reader.GetPlainText()
+		if err != nil {
+			return "", fmt.Errorf("extract pdf text failed: %w", err)
+		}
+
+		content, err := io.ReadAll(plain)
+		if err != nil {
+			return "", fmt.Errorf("extract pdf text failed: %w", err)
+		}
+
+		text = string(content)
+	default:
+		return "", ErrUnsupportedDocument
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", errors.New("document is empty")
+	}
+
+	return text, nil
+}
+
+// Split 将文本切分为多个分块，每个分块最多 size 个字符，相邻分块之间重叠 overlap 个字符
+// 优先在段落边界处切分，单个段落超过 size 时按字符切分
+func Split(text string, size, overlap int) []string {
+	if size <= 0 {
+		return nil
+	}
+
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	chunks := make([]string, 0)
+	var current []rune
+	// pending 当前分块中是否包含尚未输出的内容（不含上一个分块重叠的部分）
+	var pending bool
+
+	flush := func() {
+		if content := strings.TrimSpace(string(current)); content != "" {
+			chunks = append(chunks, content)
+		}
+
+		// 保留上一个分块的末尾作为下一个分块的开头，避免语义在分块边界处断裂
+		if overlap > 0 && len(current) > overlap {
+			current = append([]rune{}, current[len(current)-overlap:]...)
+		} else {
+			current = current[:0]
+		}
+
+		pending = false
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+
+		runes := []rune(para)
+		if pending && len(current)+len(runes)+2 > size {
+			flush()
+		}
+
+		if len(current) > 0 {
+			current = append(current, '\n', '\n')
+		}
+
+		for len(current)+len(runes) > size {
+			n := size - len(current)
+			if n <= 0 {
+				current = current[:0]
+				continue
+			}
+
+			current = append(current, runes[:n]...)
+			runes = runes[n:]
+			pending = true
+			flush()
+		}
+
+		if len(runes) > 0 {
+			current = append(current, runes...)
+			pending = true
+		}
+	}
+
+	if pending {
+		flush()
+	}
+
+	return chunks
+}
@@ -0,0 +1,185 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+)
+
+// Embedder 文本向量化服务
+type Embedder interface {
+	// Model 向量化使用的模型，不同模型生成的向量不能混用
+	Model() string
+	// Embed 将多个文本转换为向量，返回的向量与文本一一对应
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ModelEmbedder 使用向量化服务（embedding 包）中指定模型的向量化服务
+type ModelEmbedder struct {
+	imp   embedding.Embedding
+	model string
+}
+
+// NewModelEmbedder 创建使用指定模型的向量化服务，imp 不支持该模型时返回错误
+func NewModelEmbedder(imp *embedding.Imp, model string) (*ModelEmbedder, error) {
+	if !imp.Support(model) {
+		return nil, fmt.Errorf("unsupported embedding model: %s", model)
+	}
+
+	return &ModelEmbedder{imp: imp, model: model}, nil
+}
+
+func (e *ModelEmbedder) Model() string {
+	return e.model
+}
+
+func (e *ModelEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.imp.Embedding(ctx, embedding.Request{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("create embeddings failed: %w", err)
+	}
+
+	if len(resp.Embeddings) != len(texts) {
+		return nil, errors.New("embedding count mismatch")
+	}
+
+	for _, vector := range resp.Embeddings {
+		if len(vector) == 0 {
+			return nil, errors.New("embedding count mismatch")
+		}
+	}
+
+	return resp.Embeddings, nil
+}
+
+// HashEmbedder 本地哈希向量化服务，将文本中的词（中文按照相邻两个字）哈希到固定维度的向量中
+// 结果是确定性的，不依赖外部服务，用于开发测试，检索效果远不如真实的向量化模型
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder 创建本地哈希向量化服务
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("local-hash-%d", e.dimensions)
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vector := make([]float32, e.dimensions)
+		for _, term := range terms(text) {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(term))
+			sum := h.Sum64()
+
+			// 使用哈希值的最高位作为符号，减少哈希冲突带来的偏差
+			if sum>>63 == 1 {
+				vector[sum%uint64(e.dimensions)] -= 1
+			} else {
+				vector[sum%uint64(e.dimensions)] += 1
+			}
+		}
+
+		vectors = append(vectors, normalize(vector))
+	}
+
+	return vectors, nil
+}
+
+// terms 将文本拆分为词项：连续的字母数字作为一个词（转小写），中文等其它文字使用相邻两个字作为一个词
+func terms(text string) []string {
+	result := make([]string, 0)
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			result = append(result, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	flushHan := func() {
+		if len(han) == 1 {
+			result = append(result, string(han))
+		}
+
+		for i := 0; i+1 < len(han); i++ {
+			result = append(result, string(han[i:i+2]))
+		}
+
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+
+	flushWord()
+	flushHan()
+
+	return result
+}
+
+// normalize 将向量归一化为单位向量
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+
+	if sum == 0 {
+		return vector
+	}
+
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+
+	return vector
+}
+
+// Cosine 计算两个向量的余弦相似度，向量维度不一致时返回 0
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
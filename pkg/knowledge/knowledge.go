@@ -0,0 +1,107 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Knowledge 知识库的索引和检索
+type Knowledge struct {
+	embedder Embedder
+	store    VectorStore
+}
+
+func New(embedder Embedder, store VectorStore) *Knowledge {
+	return &Knowledge{embedder: embedder, store: store}
+}
+
+// Embedder 返回知识库使用的向量化服务
+func (k *Knowledge) Embedder() Embedder {
+	return k.embedder
+}
+
+// Index 将文档内容分块、向量化后保存到向量存储中，返回分块数量
+func (k *Knowledge) Index(ctx context.Context, knowledgeBaseID, documentID int64, text string, chunkSize, chunkOverlap int) (int, error) {
+	contents := Split(text, chunkSize, chunkOverlap)
+	if len(contents) == 0 {
+		return 0, errors.New("document is empty")
+	}
+
+	vectors, err := k.embedder.Embed(ctx, contents)
+	if err != nil {
+		return 0, err
+	}
+
+	chunks := make([]Chunk, 0, len(contents))
+	for i, content := range contents {
+		chunks = append(chunks, Chunk{
+			KnowledgeBaseID: knowledgeBaseID,
+			DocumentID:      documentID,
+			Seq:             int64(i),
+			Content:         content,
+			Vector:          vectors[i],
+		})
+	}
+
+	if err := k.store.Replace(ctx, documentID, chunks); err != nil {
+		return 0, fmt.Errorf("save chunks failed: %w", err)
+	}
+
+	return len(chunks), nil
+}
+
+// Retrieve 从知识库中检索与问题最相关的 topK 个分块，相似度低于 minScore 的分块会被忽略
+func (k *Knowledge) Retrieve(ctx context.Context, knowledgeBaseID int64, question string, topK int, minScore float64) ([]ScoredChunk, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return []ScoredChunk{}, nil
+	}
+
+	vectors, err := k.embedder.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectors) != 1 {
+		return nil, errors.New("embedding count mismatch")
+	}
+
+	chunks, err := k.store.Search(ctx, knowledgeBaseID, vectors[0], topK)
+	if err != nil {
+		return nil, fmt.Errorf("search chunks failed: %w", err)
+	}
+
+	result := make([]ScoredChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.Score >= minScore {
+			result = append(result, chunk)
+		}
+	}
+
+	return result, nil
+}
+
+// Prompt 将检索到的分块构建为系统提示，要求模型在回答中使用编号标注引用的来源
+func Prompt(chunks []ScoredChunk) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("以下是从知识库中检索到的参考资料，请优先依据这些资料回答用户的问题。")
+	sb.WriteString("回答中使用了参考资料时，请在相应内容后使用 [编号] 标注来源，并在回答末尾列出引用的来源文档名称；")
+	sb.WriteString("如果参考资料与问题无关，请忽略参考资料，正常回答。\n")
+
+	for i, chunk := range chunks {
+		name := chunk.DocumentName
+		if name == "" {
+			name = fmt.Sprintf("文档 %d", chunk.DocumentID)
+		}
+
+		sb.WriteString(fmt.Sprintf("\n[%d] 来源：%s\n%s\n", i+1, name, chunk.Content))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestSplit(t *testing.T) {
+	text := "第一段内容。\n\n第二段内容。\n\n" + strings.Repeat("长", 25)
+
+	chunks := Split(text, 20, 0)
+	assert.Equal(t, []string{"第一段内容。\n\n第二段内容。", strings.Repeat("长", 20), strings.Repeat("长", 5)}, chunks)
+
+	chunks = Split(text, 20, 4)
+	for _, chunk := range chunks {
+		assert.True(t, utf8.RuneCountInString(chunk) <= 20)
+	}
+	assert.True(t, strings.HasPrefix(chunks[1], "段内容。"))
+
+	assert.Equal(t, 0, len(Split("  \n\n ", 20, 0)))
+	assert.Equal(t, []string{"hello"}, Split("hello", 20, 10))
+}
+
+func TestExtractText(t *testing.T) {
+	text, err := ExtractText("README.MD", []byte("# 标题\r\n\r\n内容\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# 标题\n\n内容", text)
+
+	_, err = ExtractText("a.docx", []byte("hello"))
+	assert.True(t, err == ErrUnsupportedDocument)
+
+	_, err = ExtractText("a.txt", []byte{0xff, 0xfe})
+	assert.True(t, err != nil)
+
+	_, err = ExtractText("a.pdf", []byte("not a pdf"))
+	assert.True(t, err != nil)
+
+	assert.True(t, IsSupported("a.PDF"))
+	assert.False(t, IsSupported("a.doc"))
+}
+
+type hashEmbedding struct {
+	embedder *HashEmbedder
+}
+
+func (e hashEmbedding) Models() []string {
+	return []string{e.embedder.Model()}
+}
+
+func (e hashEmbedding) Embedding(ctx context.Context, req embedding.Request) (*embedding.Response, error) {
+	vectors, err := e.embedder.Embed(ctx, req.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &embedding.Response{Embeddings: vectors}, nil
+}
+
+func TestModelEmbedder(t *testing.T) {
+	imp := embedding.New(hashEmbedding{embedder: NewHashEmbedder(16)})
+
+	_, err := NewModelEmbedder(imp, "text-embedding-ada-002")
+	assert.True(t, err != nil)
+
+	embedder, err := NewModelEmbedder(imp, "local-hash-16")
+	assert.NoError(t, err)
+	assert.Equal(t, "local-hash-16", embedder.Model())
+
+	vectors, err := embedder.Embed(context.TODO(), []string{"退款流程", "Hello World"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(vectors))
+	assert.Equal(t, 16, len(vectors[0]))
+}
+
+func TestHashEmbedder(t *testing.T) {
+	embedder := NewHashEmbedder(64)
+	vectors, err := embedder.Embed(context.TODO(), []string{"退款流程", "退款流程", "Hello World"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(vectors))
+	assert.Equal(t, 64, len(vectors[0]))
+	assert.Equal(t, vectors[0], vectors[1])
+	assert.True(t, Cosine(vectors[0], vectors[1]) > 0.999)
+	assert.Equal(t, "local-hash-64", embedder.Model())
+}
+
+func TestKnowledge_Retrieve(t *testing.T) {
+	k := New(NewHashEmbedder(256), NewMemoryStore())
+
+	doc := "退款政策：购买后七天内可以申请退款，退款将原路返回。\n\n会员权益：会员每天可以免费使用高级模型十次。\n\nThe lazy brown dog."
+	count, err := k.Index(context.TODO(), 1, 10, doc, 40, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// 其它知识库中的文档不会被检索到
+	_, err = k.Index(context.TODO(), 2, 20, "如何申请退款", 40, 0)
+	assert.NoError(t, err)
+
+	chunks, err := k.Retrieve(context.TODO(), 1, "怎么申请退款？", 1, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(chunks))
+	assert.Equal(t, int64(10), chunks[0].DocumentID)
+	assert.True(t, strings.HasPrefix(chunks[0].Content, "退款政策"))
+
+	chunks, err = k.Retrieve(context.TODO(), 1, "lazy dog", 1, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(chunks))
+	assert.Equal(t, int64(2), chunks[0].Seq)
+
+	// 重新索引时替换文档的分块
+	count, err = k.Index(context.TODO(), 1, 10, "全新的内容", 40, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	chunks, err = k.Retrieve(context.TODO(), 1, "怎么申请退款？", 3, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(chunks))
+}
+
+func TestPrompt(t *testing.T) {
+	assert.Equal(t, "", Prompt(nil))
+
+	prompt := Prompt([]ScoredChunk{
+		{Chunk: Chunk{DocumentID: 1, DocumentName: "退款政策.md", Content: "七天内可退款"}},
+		{Chunk: Chunk{DocumentID: 2, Content: "会员权益"}},
+	})
+	assert.True(t, strings.Contains(prompt, "[1] 来源：退款政策.md\n七天内可退款"))
+	assert.True(t, strings.Contains(prompt, "[2] 来源：文档 2\n会员权益"))
+}
@@ -0,0 +1,144 @@
+package knowledge
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/go-utils/array"
+)
+
+// Chunk 文档分块
+type Chunk struct {
+	KnowledgeBaseID int64
+	DocumentID      int64
+	// DocumentName 分块所属文档的名称，用于在回答中标注引用来源
+	DocumentName string
+	Seq          int64
+	Content      string
+	Vector       []float32
+}
+
+// ScoredChunk 检索到的文档分块以及相似度
+type ScoredChunk struct {
+	Chunk
+	Score float64
+}
+
+// VectorStore 向量存储
+type VectorStore interface {
+	// Replace 替换文档的所有分块
+	Replace(ctx context.Context, documentID int64, chunks []Chunk) error
+	// Search 在知识库中检索与向量最相似的 topK 个分块，按照相似度从高到低排列
+	Search(ctx context.Context, knowledgeBaseID int64, vector []float32, topK int) ([]ScoredChunk, error)
+}
+
+// rank 计算分块与向量的相似度，返回最相似的 topK 个分块
+func rank(chunks []Chunk, vector []float32, topK int) []ScoredChunk {
+	scored := array.Map(chunks, func(chunk Chunk, _ int) ScoredChunk {
+		return ScoredChunk{Chunk: chunk, Score: Cosine(chunk.Vector, vector)}
+	})
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if topK > 0 && len(scored) > topK {
+		scored = scored[:topK]
+	}
+
+	return scored
+}
+
+// MemoryStore 内存向量存储，用于测试
+type MemoryStore struct {
+	lock   sync.RWMutex
+	chunks map[int64][]Chunk
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{chunks: make(map[int64][]Chunk)}
+}
+
+func (s *MemoryStore) Replace(ctx context.Context, documentID int64, chunks []Chunk) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.chunks[documentID] = chunks
+	return nil
+}
+
+func (s *MemoryStore) Search(ctx context.Context, knowledgeBaseID int64, vector []float32, topK int) ([]ScoredChunk, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	candidates := make([]Chunk, 0)
+	for _, chunks := range s.chunks {
+		for _, chunk := range chunks {
+			if chunk.KnowledgeBaseID == knowledgeBaseID {
+				candidates = append(candidates, chunk)
+			}
+		}
+	}
+
+	return rank(candidates, vector, topK), nil
+}
+
+// MySQLStore 基于 MySQL 的向量存储，向量编码后保存在 knowledge_chunk 表中，检索时加载知识库的所有分块计算相似度
+// 适用于单个知识库分块数量不多的场景
+type MySQLStore struct {
+	rep *repo.KnowledgeRepo
+}
+
+func NewMySQLStore(rep *repo.KnowledgeRepo) *MySQLStore {
+	return &MySQLStore{rep: rep}
+}
+
+func (s *MySQLStore) Replace(ctx context.Context, documentID int64, chunks []Chunk) error {
+	return s.rep.ReplaceChunks(ctx, documentID, array.Map(chunks, func(chunk Chunk, _ int) repo.KnowledgeChunkAddReq {
+		return repo.KnowledgeChunkAddReq{
+			KnowledgeBaseID: chunk.KnowledgeBaseID,
+			DocumentID:      documentID,
+			Seq:             chunk.Seq,
+			Content:         chunk.Content,
+			Embedding:       embedding.EncodeBase64(chunk.Vector),
+		}
+	}))
+}
+
+func (s *MySQLStore) Search(ctx context.Context, knowledgeBaseID int64, vector []float32, topK int) ([]ScoredChunk, error) {
+	items, err := s.rep.Chunks(ctx, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]Chunk, 0, len(items))
+	for _, item := range items {
+		vec, err := embedding.DecodeBase64(item.Embedding)
+		if err != nil {
+			log.F(log.M{"chunk_id": item.Id}).Warningf("decode chunk embedding failed: %v", err)
+			continue
+		}
+
+		chunks = append(chunks, Chunk{
+			KnowledgeBaseID: item.KnowledgeBaseId,
+			DocumentID:      item.DocumentId,
+			Seq:             item.Seq,
+			Content:         item.Content,
+			Vector:          vec,
+		})
+	}
+
+	scored := rank(chunks, vector, topK)
+
+	names, err := s.rep.DocumentNames(ctx, array.Uniq(array.Map(scored, func(item ScoredChunk, _ int) int64 { return item.DocumentID })))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range scored {
+		scored[i].DocumentName = names[scored[i].DocumentID]
+	}
+
+	return scored, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-uuid"
 	"github.com/mylxsw/asteria/log"
+	"html"
 	"math/rand"
 	"mime"
 	"net/http"
@@ -22,6 +23,7 @@ import (
 	"gopkg.in/resty.v1"
 
 	"github.com/hashicorp/go-version"
+	"github.com/mylxsw/go-utils/array"
 	"github.com/speps/go-hashids/v2"
 )
 
@@ -336,3 +338,91 @@ func Md5(data []byte) string {
 	h.Write(data)
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// SplitKeywords 将搜索关键词按照空白字符拆分，去除重复的关键词
+func SplitKeywords(keyword string) []string {
+	return array.Uniq(strings.Fields(keyword))
+}
+
+// Highlight 从 text 中截取包含关键词的片段，最长 length 个字符，片段中的关键词使用 pre 和 post 包裹（不区分大小写）
+// text 中不包含任何关键词时，返回 text 的开头部分
+// 返回结果用于 HTML 展示，text 中的内容会进行 HTML 转义，只有 pre 和 post 原样输出
+func Highlight(text string, keywords []string, length int, pre, post string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	// 部分字符转换为小写后长度发生变化，此时不做高亮处理
+	if len(lower) != len(runes) {
+		return html.EscapeString(SubString(text, length))
+	}
+
+	needles := make([][]rune, 0, len(keywords))
+	for _, kw := range keywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" {
+			needles = append(needles, []rune(kw))
+		}
+	}
+
+	matchAt := func(pos int) int {
+		matched := 0
+		for _, needle := range needles {
+			if len(needle) > matched && pos+len(needle) <= len(lower) && string(lower[pos:pos+len(needle)]) == string(needle) {
+				matched = len(needle)
+			}
+		}
+
+		return matched
+	}
+
+	first := -1
+	for i := range lower {
+		if matchAt(i) > 0 {
+			first = i
+			break
+		}
+	}
+
+	if first < 0 {
+		return html.EscapeString(SubString(text, length))
+	}
+
+	// 关键词前保留部分上下文
+	start := first - length/4
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + length
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+
+	segStart := start
+	for i := start; i < end; {
+		if n := matchAt(i); n > 0 {
+			sb.WriteString(html.EscapeString(string(runes[segStart:i])))
+			sb.WriteString(pre)
+			sb.WriteString(html.EscapeString(string(runes[i : i+n])))
+			sb.WriteString(post)
+			i += n
+			segStart = i
+			continue
+		}
+
+		i++
+	}
+
+	if segStart < end {
+		sb.WriteString(html.EscapeString(string(runes[segStart:end])))
+	}
+
+	if end < len(runes) {
+		sb.WriteString("...")
+	}
+
+	return sb.String()
+}
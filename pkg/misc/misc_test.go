@@ -140,3 +140,16 @@ func TestUUID(t *testing.T) {
 func TestFileExt(t *testing.T) {
 	fmt.Println(misc.FileExt("abc.jpg"))
 }
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "你好，<em>世界</em>", misc.Highlight("你好，世界", []string{"世界"}, 20, "<em>", "</em>"))
+	assert.Equal(t, "<em>Hello</em> <em>hello</em> world", misc.Highlight("Hello hello world", []string{"HELLO"}, 20, "<em>", "</em>"))
+	assert.Equal(t, "...jkl<em>mn</em>opqrstu...", misc.Highlight("abcdefghijklmnopqrstuvwxyz", []string{"mn"}, 12, "<em>", "</em>"))
+	assert.Equal(t, "abcde...", misc.Highlight("abcdefg", []string{"xyz"}, 5, "<em>", "</em>"))
+
+	// 文本内容需要进行 HTML 转义，只有高亮标签原样输出
+	assert.Equal(t, "&lt;script&gt;<em>alert</em>(1)&lt;/script&gt;", misc.Highlight("<script>alert(1)</script>", []string{"alert"}, 40, "<em>", "</em>"))
+	assert.Equal(t, "&lt;b&gt;hi&lt;/b&gt;", misc.Highlight("<b>hi</b>", []string{"xyz"}, 40, "<em>", "</em>"))
+
+	assert.Equal(t, []string{"你好", "world"}, misc.SplitKeywords("  你好 world\t你好 "))
+}
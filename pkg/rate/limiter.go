@@ -44,6 +44,20 @@ func (rl *RateLimiter) Allow(ctx context.Context, key string, limit redis_rate.L
 	return nil
 }
 
+// AllowN 检查是否允许同时进行 n 次访问，n 次访问全部计入频率限制
+func (rl *RateLimiter) AllowN(ctx context.Context, key string, limit redis_rate.Limit, n int) error {
+	res, err := rl.limiter.AllowN(ctx, key, limit, n)
+	if err != nil {
+		return err
+	}
+
+	if res.Allowed < n || res.Remaining <= 0 {
+		return ErrRateLimitExceeded
+	}
+
+	return nil
+}
+
 // OperationCount 获取操作次数
 func (rl *RateLimiter) OperationCount(ctx context.Context, key string) (int64, error) {
 	res, err := rl.rds.Get(ctx, key).Result()
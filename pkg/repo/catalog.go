@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ChatModelStatusDisabled = 0
+	ChatModelStatusEnabled  = 1
+)
+
+type CatalogRepo struct {
+	db *sql.DB
+}
+
+// NewCatalogRepo create a new CatalogRepo
+func NewCatalogRepo(db *sql.DB) *CatalogRepo {
+	return &CatalogRepo{db: db}
+}
+
+// Models 获取数据库中定义的所有启用状态的模型，定义格式错误的模型会被忽略
+func (repo *CatalogRepo) Models(ctx context.Context) ([]catalog.Model, error) {
+	q := query.Builder().
+		Where(model.FieldChatModelStatus, ChatModelStatusEnabled).
+		OrderBy(model.FieldChatModelId, "ASC")
+
+	items, err := model.NewChatModelModel(repo.db).Get(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("query chat models failed: %w", err)
+	}
+
+	models := make([]catalog.Model, 0, len(items))
+	for _, item := range items {
+		// JSON 是 YAML 的子集，这里使用 YAML 解析，与模型目录文件的字段保持一致
+		var mod catalog.Model
+		if err := yaml.Unmarshal([]byte(item.Definition.ValueOrZero()), &mod); err != nil {
+			log.F(log.M{"model_id": item.ModelId.ValueOrZero()}).Errorf("invalid chat model definition: %v", err)
+			continue
+		}
+
+		mod.ID = item.ModelId.ValueOrZero()
+		models = append(models, mod)
+	}
+
+	return models, nil
+}
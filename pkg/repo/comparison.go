@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+)
+
+const (
+	// ComparisonAnswerStatusSucceed 模型回复成功
+	ComparisonAnswerStatusSucceed = 1
+	// ComparisonAnswerStatusFailed 模型回复失败
+	ComparisonAnswerStatusFailed = 2
+)
+
+// Comparison 多模型对比记录
+type Comparison struct {
+	model.ChatComparison
+	Models   []string                     `json:"models"`
+	Messages json.RawMessage              `json:"messages,omitempty"`
+	Answers  []model.ChatComparisonAnswer `json:"answers,omitempty"`
+}
+
+type ComparisonRepo struct {
+	db *sql.DB
+}
+
+func NewComparisonRepo(db *sql.DB) *ComparisonRepo {
+	return &ComparisonRepo{db: db}
+}
+
+// CreateComparison 创建对比记录，messages 为 JSON 格式的请求上下文
+func (r *ComparisonRepo) CreateComparison(ctx context.Context, userID int64, prompt string, messages []byte, models []string) (int64, error) {
+	modelsData, _ := json.Marshal(models)
+	return model.NewChatComparisonModel(r.db).Create(ctx, query.KV{
+		model.FieldChatComparisonUserId:   userID,
+		model.FieldChatComparisonPrompt:   prompt,
+		model.FieldChatComparisonMessages: string(messages),
+		model.FieldChatComparisonModels:   string(modelsData),
+	})
+}
+
+// SaveAnswers 保存各模型的回复，同时更新对比记录消耗的智慧果总数
+func (r *ComparisonRepo) SaveAnswers(ctx context.Context, userID, comparisonID int64, answers []model.ChatComparisonAnswer) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		var quotaConsumed int64
+		for _, answer := range answers {
+			answer.Id = 0
+			answer.UserId = userID
+			answer.ComparisonId = comparisonID
+			quotaConsumed += answer.QuotaConsumed
+
+			if _, err := model.NewChatComparisonAnswerModel(tx).Save(ctx, answer.ToChatComparisonAnswerN(
+				model.FieldChatComparisonAnswerComparisonId,
+				model.FieldChatComparisonAnswerUserId,
+				model.FieldChatComparisonAnswerSeq,
+				model.FieldChatComparisonAnswerModel,
+				model.FieldChatComparisonAnswerAnswer,
+				model.FieldChatComparisonAnswerStatus,
+				model.FieldChatComparisonAnswerError,
+				model.FieldChatComparisonAnswerInputTokens,
+				model.FieldChatComparisonAnswerOutputTokens,
+				model.FieldChatComparisonAnswerQuotaConsumed,
+				model.FieldChatComparisonAnswerElapsedMs,
+			)); err != nil {
+				return fmt.Errorf("save comparison answer failed: %w", err)
+			}
+		}
+
+		q := query.Builder().
+			Where(model.FieldChatComparisonUserId, userID).
+			Where(model.FieldChatComparisonId, comparisonID)
+		if _, err := model.NewChatComparisonModel(tx).UpdateFields(ctx, query.KV{model.FieldChatComparisonQuotaConsumed: quotaConsumed}, q); err != nil {
+			return fmt.Errorf("update comparison quota consumed failed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Comparisons 获取用户的对比记录列表（不包含上下文和回复），按照 ID 倒序排列，返回最后一条记录的 ID 用于下一页查询
+func (r *ComparisonRepo) Comparisons(ctx context.Context, userID, startID, perPage int64) ([]Comparison, int64, error) {
+	q := query.Builder().
+		Select(
+			model.FieldChatComparisonId,
+			model.FieldChatComparisonUserId,
+			model.FieldChatComparisonPrompt,
+			model.FieldChatComparisonModels,
+			model.FieldChatComparisonQuotaConsumed,
+			model.FieldChatComparisonCreatedAt,
+			model.FieldChatComparisonUpdatedAt,
+		).
+		Where(model.FieldChatComparisonUserId, userID).
+		OrderBy(model.FieldChatComparisonId, "DESC").
+		Limit(perPage)
+
+	if startID > 0 {
+		q = q.Where(model.FieldChatComparisonId, "<", startID)
+	}
+
+	items, err := model.NewChatComparisonModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query comparisons failed: %w", err)
+	}
+
+	if len(items) == 0 {
+		return []Comparison{}, startID, nil
+	}
+
+	return array.Map(items, func(item model.ChatComparisonN, _ int) Comparison {
+		return resolveComparison(item.ToChatComparison())
+	}), items[len(items)-1].Id.ValueOrZero(), nil
+}
+
+// Comparison 获取对比记录，包含请求上下文以及各模型的回复
+func (r *ComparisonRepo) Comparison(ctx context.Context, userID, id int64) (*Comparison, error) {
+	q := query.Builder().
+		Where(model.FieldChatComparisonUserId, userID).
+		Where(model.FieldChatComparisonId, id)
+
+	item, err := model.NewChatComparisonModel(r.db).First(ctx, q)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	answers, err := model.NewChatComparisonAnswerModel(r.db).Get(ctx, query.Builder().
+		Where(model.FieldChatComparisonAnswerComparisonId, id).
+		OrderBy(model.FieldChatComparisonAnswerSeq, "ASC"))
+	if err != nil {
+		return nil, fmt.Errorf("query comparison answers failed: %w", err)
+	}
+
+	ret := resolveComparison(item.ToChatComparison())
+	ret.Answers = array.Map(answers, func(answer model.ChatComparisonAnswerN, _ int) model.ChatComparisonAnswer {
+		return answer.ToChatComparisonAnswer()
+	})
+
+	return &ret, nil
+}
+
+// DeleteComparison 删除对比记录，同时删除各模型的回复
+func (r *ComparisonRepo) DeleteComparison(ctx context.Context, userID, id int64) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		q := query.Builder().
+			Where(model.FieldChatComparisonUserId, userID).
+			Where(model.FieldChatComparisonId, id)
+
+		affected, err := model.NewChatComparisonModel(tx).Delete(ctx, q)
+		if err != nil {
+			return fmt.Errorf("delete comparison failed: %w", err)
+		}
+
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := model.NewChatComparisonAnswerModel(tx).Delete(ctx, query.Builder().Where(model.FieldChatComparisonAnswerComparisonId, id)); err != nil {
+			return fmt.Errorf("delete comparison answers failed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func resolveComparison(item model.ChatComparison) Comparison {
+	ret := Comparison{ChatComparison: item, Models: []string{}}
+	_ = json.Unmarshal([]byte(item.Models), &ret.Models)
+	if item.Messages != "" {
+		ret.Messages = json.RawMessage(item.Messages)
+	}
+
+	return ret
+}
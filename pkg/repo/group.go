@@ -41,6 +41,10 @@ const (
 	MessageStatusSucceed = 1
 	// MessageStatusFailed 消息状态：失败
 	MessageStatusFailed = 2
+	// MessageStatusDeleted 消息状态：已删除（聊天消息使用软删除，便于多端同步删除操作）
+	MessageStatusDeleted = 3
+	// MessageStatusBlocked 消息状态：模型输出内容违规，已被拦截（消息内容为拦截前已经输出的部分）
+	MessageStatusBlocked = 4
 )
 
 // CreateGroup 创建一个聊天群组
@@ -0,0 +1,304 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+)
+
+const (
+	// KnowledgeDocumentStatusPending 等待处理（分块、向量化）
+	KnowledgeDocumentStatusPending = 0
+	// KnowledgeDocumentStatusSucceed 处理成功
+	KnowledgeDocumentStatusSucceed = 1
+	// KnowledgeDocumentStatusFailed 处理失败
+	KnowledgeDocumentStatusFailed = 2
+)
+
+type KnowledgeRepo struct {
+	db *sql.DB
+}
+
+func NewKnowledgeRepo(db *sql.DB) *KnowledgeRepo {
+	return &KnowledgeRepo{db: db}
+}
+
+// Bases 获取用户的知识库列表
+func (r *KnowledgeRepo) Bases(ctx context.Context, userID int64) ([]model.KnowledgeBase, error) {
+	q := query.Builder().
+		Where(model.FieldKnowledgeBaseUserId, userID).
+		OrderBy(model.FieldKnowledgeBaseId, "DESC")
+
+	items, err := model.NewKnowledgeBaseModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(items, func(item model.KnowledgeBaseN, _ int) model.KnowledgeBase {
+		return item.ToKnowledgeBase()
+	}), nil
+}
+
+// Base 获取知识库
+func (r *KnowledgeRepo) Base(ctx context.Context, userID, id int64) (*model.KnowledgeBase, error) {
+	q := query.Builder().
+		Where(model.FieldKnowledgeBaseUserId, userID).
+		Where(model.FieldKnowledgeBaseId, id)
+
+	item, err := model.NewKnowledgeBaseModel(r.db).First(ctx, q)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := item.ToKnowledgeBase()
+	return &ret, nil
+}
+
+// CreateBase 创建知识库
+func (r *KnowledgeRepo) CreateBase(ctx context.Context, userID int64, name, description string) (int64, error) {
+	return model.NewKnowledgeBaseModel(r.db).Create(ctx, query.KV{
+		model.FieldKnowledgeBaseUserId:      userID,
+		model.FieldKnowledgeBaseName:        name,
+		model.FieldKnowledgeBaseDescription: description,
+	})
+}
+
+// UpdateBase 更新知识库信息
+func (r *KnowledgeRepo) UpdateBase(ctx context.Context, userID, id int64, name, description string) error {
+	q := query.Builder().
+		Where(model.FieldKnowledgeBaseUserId, userID).
+		Where(model.FieldKnowledgeBaseId, id)
+
+	_, err := model.NewKnowledgeBaseModel(r.db).UpdateFields(ctx, query.KV{
+		model.FieldKnowledgeBaseName:        name,
+		model.FieldKnowledgeBaseDescription: description,
+	}, q)
+	return err
+}
+
+// DeleteBase 删除知识库，同时删除知识库中的文档、分块，并解除与数字人的关联
+func (r *KnowledgeRepo) DeleteBase(ctx context.Context, userID, id int64) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		q := query.Builder().
+			Where(model.FieldKnowledgeBaseUserId, userID).
+			Where(model.FieldKnowledgeBaseId, id)
+
+		affected, err := model.NewKnowledgeBaseModel(tx).Delete(ctx, q)
+		if err != nil {
+			return fmt.Errorf("delete knowledge base failed: %w", err)
+		}
+
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := model.NewKnowledgeDocumentModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeDocumentKnowledgeBaseId, id)); err != nil {
+			return fmt.Errorf("delete knowledge documents failed: %w", err)
+		}
+
+		if _, err := model.NewKnowledgeChunkModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeChunkKnowledgeBaseId, id)); err != nil {
+			return fmt.Errorf("delete knowledge chunks failed: %w", err)
+		}
+
+		roomQ := query.Builder().
+			Where(model.FieldRoomsUserId, userID).
+			Where(model.FieldRoomsKnowledgeBaseId, id)
+		if _, err := model.NewRoomsModel(tx).UpdateFields(ctx, query.KV{model.FieldRoomsKnowledgeBaseId: 0}, roomQ); err != nil {
+			return fmt.Errorf("unbind knowledge base from rooms failed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// documentListFields 文档列表查询的字段，不包含文档内容
+var documentListFields = []any{
+	model.FieldKnowledgeDocumentId,
+	model.FieldKnowledgeDocumentUserId,
+	model.FieldKnowledgeDocumentKnowledgeBaseId,
+	model.FieldKnowledgeDocumentName,
+	model.FieldKnowledgeDocumentSize,
+	model.FieldKnowledgeDocumentChunkCount,
+	model.FieldKnowledgeDocumentStatus,
+	model.FieldKnowledgeDocumentError,
+	model.FieldKnowledgeDocumentCreatedAt,
+	model.FieldKnowledgeDocumentUpdatedAt,
+}
+
+// Documents 获取知识库中的文档列表（不包含文档内容）
+func (r *KnowledgeRepo) Documents(ctx context.Context, userID, knowledgeBaseID int64) ([]model.KnowledgeDocument, error) {
+	q := query.Builder().
+		Select(documentListFields...).
+		Where(model.FieldKnowledgeDocumentUserId, userID).
+		Where(model.FieldKnowledgeDocumentKnowledgeBaseId, knowledgeBaseID).
+		OrderBy(model.FieldKnowledgeDocumentId, "DESC")
+
+	items, err := model.NewKnowledgeDocumentModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(items, func(item model.KnowledgeDocumentN, _ int) model.KnowledgeDocument {
+		return item.ToKnowledgeDocument()
+	}), nil
+}
+
+// CountDocuments 获取知识库中的文档数量
+func (r *KnowledgeRepo) CountDocuments(ctx context.Context, knowledgeBaseID int64) (int64, error) {
+	return model.NewKnowledgeDocumentModel(r.db).Count(ctx, query.Builder().Where(model.FieldKnowledgeDocumentKnowledgeBaseId, knowledgeBaseID))
+}
+
+// Document 获取文档，包含文档内容
+func (r *KnowledgeRepo) Document(ctx context.Context, userID, id int64) (*model.KnowledgeDocument, error) {
+	q := query.Builder().
+		Where(model.FieldKnowledgeDocumentUserId, userID).
+		Where(model.FieldKnowledgeDocumentId, id)
+
+	item, err := model.NewKnowledgeDocumentModel(r.db).First(ctx, q)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := item.ToKnowledgeDocument()
+	return &ret, nil
+}
+
+// DocumentNames 批量获取文档名称
+func (r *KnowledgeRepo) DocumentNames(ctx context.Context, ids []int64) (map[int64]string, error) {
+	if len(ids) == 0 {
+		return map[int64]string{}, nil
+	}
+
+	q := query.Builder().
+		Select(model.FieldKnowledgeDocumentId, model.FieldKnowledgeDocumentName).
+		WhereIn(model.FieldKnowledgeDocumentId, ids)
+
+	items, err := model.NewKnowledgeDocumentModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(items))
+	for _, item := range items {
+		names[item.Id.ValueOrZero()] = item.Name.ValueOrZero()
+	}
+
+	return names, nil
+}
+
+// KnowledgeDocumentAddReq 添加文档请求
+type KnowledgeDocumentAddReq struct {
+	UserID          int64
+	KnowledgeBaseID int64
+	Name            string
+	Size            int64
+	// Content 从文档中提取的文本内容
+	Content string
+}
+
+// CreateDocument 添加文档，文档状态为等待处理
+func (r *KnowledgeRepo) CreateDocument(ctx context.Context, req KnowledgeDocumentAddReq) (int64, error) {
+	return model.NewKnowledgeDocumentModel(r.db).Create(ctx, query.KV{
+		model.FieldKnowledgeDocumentUserId:          req.UserID,
+		model.FieldKnowledgeDocumentKnowledgeBaseId: req.KnowledgeBaseID,
+		model.FieldKnowledgeDocumentName:            req.Name,
+		model.FieldKnowledgeDocumentSize:            req.Size,
+		model.FieldKnowledgeDocumentContent:         req.Content,
+		model.FieldKnowledgeDocumentStatus:          KnowledgeDocumentStatusPending,
+	})
+}
+
+// UpdateDocumentStatus 更新文档处理状态
+func (r *KnowledgeRepo) UpdateDocumentStatus(ctx context.Context, id int64, status int64, chunkCount int64, errMsg string) error {
+	_, err := model.NewKnowledgeDocumentModel(r.db).UpdateFields(ctx, query.KV{
+		model.FieldKnowledgeDocumentStatus:     status,
+		model.FieldKnowledgeDocumentChunkCount: chunkCount,
+		model.FieldKnowledgeDocumentError:      errMsg,
+	}, query.Builder().Where(model.FieldKnowledgeDocumentId, id))
+	return err
+}
+
+// DeleteDocument 删除文档以及文档的所有分块
+func (r *KnowledgeRepo) DeleteDocument(ctx context.Context, userID, id int64) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		q := query.Builder().
+			Where(model.FieldKnowledgeDocumentUserId, userID).
+			Where(model.FieldKnowledgeDocumentId, id)
+
+		affected, err := model.NewKnowledgeDocumentModel(tx).Delete(ctx, q)
+		if err != nil {
+			return fmt.Errorf("delete knowledge document failed: %w", err)
+		}
+
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := model.NewKnowledgeChunkModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeChunkDocumentId, id)); err != nil {
+			return fmt.Errorf("delete knowledge chunks failed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// KnowledgeChunkAddReq 添加文档分块请求
+type KnowledgeChunkAddReq struct {
+	KnowledgeBaseID int64
+	DocumentID      int64
+	Seq             int64
+	Content         string
+	// Embedding 编码后的分块向量
+	Embedding string
+}
+
+// ReplaceChunks 替换文档的所有分块，文档重新处理时，旧的分块会被删除
+func (r *KnowledgeRepo) ReplaceChunks(ctx context.Context, documentID int64, chunks []KnowledgeChunkAddReq) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		if _, err := model.NewKnowledgeChunkModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeChunkDocumentId, documentID)); err != nil {
+			return fmt.Errorf("delete knowledge chunks failed: %w", err)
+		}
+
+		for _, chunk := range chunks {
+			if _, err := model.NewKnowledgeChunkModel(tx).Create(ctx, query.KV{
+				model.FieldKnowledgeChunkKnowledgeBaseId: chunk.KnowledgeBaseID,
+				model.FieldKnowledgeChunkDocumentId:      documentID,
+				model.FieldKnowledgeChunkSeq:             chunk.Seq,
+				model.FieldKnowledgeChunkContent:         chunk.Content,
+				model.FieldKnowledgeChunkEmbedding:       chunk.Embedding,
+			}); err != nil {
+				return fmt.Errorf("create knowledge chunk failed: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Chunks 获取知识库中的所有分块，包含分块向量
+func (r *KnowledgeRepo) Chunks(ctx context.Context, knowledgeBaseID int64) ([]model.KnowledgeChunk, error) {
+	q := query.Builder().Where(model.FieldKnowledgeChunkKnowledgeBaseId, knowledgeBaseID)
+
+	items, err := model.NewKnowledgeChunkModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(items, func(item model.KnowledgeChunkN, _ int) model.KnowledgeChunk {
+		return item.ToKnowledgeChunk()
+	}), nil
+}
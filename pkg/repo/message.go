@@ -3,12 +3,16 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	model2 "github.com/mylxsw/aidea-server/pkg/repo/model"
 	"time"
 
 	"github.com/mylxsw/eloquent"
 	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
 	"gopkg.in/guregu/null.v3"
 )
 
@@ -38,6 +42,8 @@ type MessageAddReq struct {
 	Model         string
 	Status        int64
 	Error         string
+	// Images 消息中包含的图片地址
+	Images []string
 }
 
 func (r *MessageRepo) Add(ctx context.Context, req MessageAddReq) (int64, error) {
@@ -68,6 +74,11 @@ func (r *MessageRepo) Add(ctx context.Context, req MessageAddReq) (int64, error)
 		kvs[model2.FieldChatMessagesError] = req.Error
 	}
 
+	if len(req.Images) > 0 {
+		data, _ := json.Marshal(req.Images)
+		kvs[model2.FieldChatMessagesImages] = string(data)
+	}
+
 	return id, eloquent.Transaction(r.db, func(tx query.Database) error {
 		var err error
 		id, err = model2.NewChatMessagesModel(tx).Create(ctx, kvs)
@@ -115,3 +126,355 @@ func (r *MessageRepo) UpdateMessageStatus(ctx context.Context, id int64, req Mes
 	_, err := model2.NewChatMessagesModel(r.db).UpdateFields(ctx, kv, query.Builder().Where(model2.FieldChatMessagesId, id))
 	return err
 }
+
+// GetMessage 获取用户的聊天消息
+func (r *MessageRepo) GetMessage(ctx context.Context, userID, messageID int64) (*model2.ChatMessages, error) {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesId, messageID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted)
+
+	msg, err := model2.NewChatMessagesModel(r.db).First(ctx, q)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("query chat message failed: %w", err)
+	}
+
+	ret := msg.ToChatMessages()
+	return &ret, nil
+}
+
+// GetRoomMessages 获取数字人的聊天消息列表，按照 ID 倒序排列，startID 大于 0 时只返回 ID 小于 startID 的消息
+func (r *MessageRepo) GetRoomMessages(ctx context.Context, userID, roomID int64, startID, perPage int64) ([]model2.ChatMessages, int64, error) {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesRoomId, roomID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+		OrderBy(model2.FieldChatMessagesId, "DESC").
+		Limit(perPage)
+
+	if startID > 0 {
+		q = q.Where(model2.FieldChatMessagesId, "<", startID)
+	}
+
+	messages, err := model2.NewChatMessagesModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query chat messages failed: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return []model2.ChatMessages{}, startID, nil
+	}
+
+	return array.Map(messages, func(msg model2.ChatMessagesN, _ int) model2.ChatMessages {
+		return resolveChatMessage(msg.ToChatMessages())
+	}), messages[len(messages)-1].Id.ValueOrZero(), nil
+}
+
+// GetThread 获取消息所在的对话，返回提问消息以及它的所有回复（包括重新生成的回复），按照 ID 正序排列
+// messageID 可以是提问消息，也可以是回复消息（通过 PID 找到对应的提问）
+func (r *MessageRepo) GetThread(ctx context.Context, userID, messageID int64) ([]model2.ChatMessages, error) {
+	msg, err := r.GetMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootID := msg.Id
+	if msg.Role == int64(MessageRoleAssistant) && msg.Pid > 0 {
+		rootID = msg.Pid
+	}
+
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+		WhereGroup(func(builder query.Condition) {
+			builder.Where(model2.FieldChatMessagesId, rootID).
+				OrWhereGroup(func(builder query.Condition) {
+					builder.Where(model2.FieldChatMessagesPid, rootID).
+						Where(model2.FieldChatMessagesRole, MessageRoleAssistant)
+				})
+		}).
+		OrderBy(model2.FieldChatMessagesId, "ASC")
+
+	messages, err := model2.NewChatMessagesModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("query chat thread failed: %w", err)
+	}
+
+	return array.Map(messages, func(msg model2.ChatMessagesN, _ int) model2.ChatMessages {
+		return resolveChatMessage(msg.ToChatMessages())
+	}), nil
+}
+
+// DeleteMessage 删除聊天消息，删除提问消息时，同时删除它的所有回复
+// 消息采用软删除（状态标记为已删除），以便其它设备同步时能够感知到删除操作
+func (r *MessageRepo) DeleteMessage(ctx context.Context, userID, messageID int64) error {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		WhereGroup(func(builder query.Condition) {
+			builder.Where(model2.FieldChatMessagesId, messageID).
+				OrWhereGroup(func(builder query.Condition) {
+					builder.Where(model2.FieldChatMessagesPid, messageID).
+						Where(model2.FieldChatMessagesRole, MessageRoleAssistant)
+				})
+		})
+
+	_, err := model2.NewChatMessagesModel(r.db).UpdateFields(ctx, query.KV{
+		model2.FieldChatMessagesStatus: MessageStatusDeleted,
+	}, q)
+	return err
+}
+
+// CountRoomMessages 统计数字人的聊天消息数量（不包含已删除的消息）
+func (r *MessageRepo) CountRoomMessages(ctx context.Context, userID, roomID int64) (int64, error) {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesRoomId, roomID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted)
+
+	return model2.NewChatMessagesModel(r.db).Count(ctx, q)
+}
+
+// GetAllRoomMessages 获取数字人的全部聊天消息，按照 ID 正序排列，最多返回 limit 条
+func (r *MessageRepo) GetAllRoomMessages(ctx context.Context, userID, roomID int64, limit int64) ([]model2.ChatMessages, error) {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesRoomId, roomID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+		OrderBy(model2.FieldChatMessagesId, "ASC").
+		Limit(limit)
+
+	messages, err := model2.NewChatMessagesModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("query room messages failed: %w", err)
+	}
+
+	return array.Map(messages, func(msg model2.ChatMessagesN, _ int) model2.ChatMessages {
+		return resolveChatMessage(msg.ToChatMessages())
+	}), nil
+}
+
+// LastRoomAnswerID 获取数字人中最后一条回复消息的 ID（不包含已删除的消息），没有回复时返回 0
+func (r *MessageRepo) LastRoomAnswerID(ctx context.Context, userID, roomID int64) (int64, error) {
+	q := query.Builder().
+		Select(model2.FieldChatMessagesId).
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesRoomId, roomID).
+		Where(model2.FieldChatMessagesRole, MessageRoleAssistant).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+		OrderBy(model2.FieldChatMessagesId, "DESC")
+
+	msg, err := model2.NewChatMessagesModel(r.db).First(ctx, q)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("query last room answer failed: %w", err)
+	}
+
+	return msg.Id.ValueOrZero(), nil
+}
+
+// MessageImportItem 导入的聊天消息，ID 和 PID 为导出时的消息 ID，导入时重新映射
+type MessageImportItem struct {
+	ID        int64
+	PID       int64
+	Role      MessageRole
+	Message   string
+	Model     string
+	Images    []string
+	CreatedAt time.Time
+}
+
+// ImportMessages 将聊天消息导入到数字人中，保留消息之间的父子关系，父消息必须出现在子消息之前
+func (r *MessageRepo) ImportMessages(ctx context.Context, userID, roomID int64, items []MessageImportItem) error {
+	return eloquent.Transaction(r.db, func(tx query.Database) error {
+		ids := make(map[int64]int64, len(items))
+		for _, item := range items {
+			kvs := query.KV{
+				model2.FieldChatMessagesUserId:  userID,
+				model2.FieldChatMessagesRoomId:  roomID,
+				model2.FieldChatMessagesRole:    item.Role,
+				model2.FieldChatMessagesMessage: item.Message,
+				model2.FieldChatMessagesStatus:  MessageStatusSucceed,
+			}
+
+			if pid := ids[item.PID]; pid > 0 {
+				kvs[model2.FieldChatMessagesPid] = pid
+			}
+
+			if item.Model != "" {
+				kvs[model2.FieldChatMessagesModel] = item.Model
+			}
+
+			if len(item.Images) > 0 {
+				data, _ := json.Marshal(item.Images)
+				kvs[model2.FieldChatMessagesImages] = string(data)
+			}
+
+			if !item.CreatedAt.IsZero() {
+				kvs[model2.FieldChatMessagesCreatedAt] = item.CreatedAt
+			}
+
+			id, err := model2.NewChatMessagesModel(tx).Create(ctx, kvs)
+			if err != nil {
+				return fmt.Errorf("import message failed: %w", err)
+			}
+
+			ids[item.ID] = id
+		}
+
+		return nil
+	})
+}
+
+// branchBatchSize 查询对话分支时，每次批量加载的消息数量
+const branchBatchSize = 100
+
+// GetBranch 获取消息所在的对话分支，从 messageID 开始沿着 PID 向上查找，最多返回 limit 条消息，按照对话顺序（从早到晚）排列
+// 分支中的消息被删除时，分支在此处中断
+func (r *MessageRepo) GetBranch(ctx context.Context, userID, messageID int64, limit int) ([]model2.ChatMessages, error) {
+	msg, err := r.GetMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := []model2.ChatMessages{*msg}
+	nextID := msg.Pid
+
+	// 同一个分支中的消息总是属于同一个数字人，且父消息 ID 总是小于子消息 ID，因此批量加载之前的消息，在内存中查找
+	for nextID > 0 && len(branch) < limit {
+		q := query.Builder().
+			Where(model2.FieldChatMessagesUserId, userID).
+			Where(model2.FieldChatMessagesRoomId, msg.RoomId).
+			Where(model2.FieldChatMessagesId, "<=", nextID).
+			Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+			OrderBy(model2.FieldChatMessagesId, "DESC").
+			Limit(branchBatchSize)
+
+		messages, err := model2.NewChatMessagesModel(r.db).Get(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("query chat branch failed: %w", err)
+		}
+
+		if len(messages) == 0 || messages[0].Id.ValueOrZero() != nextID {
+			break
+		}
+
+		for _, m := range messages {
+			if m.Id.ValueOrZero() != nextID {
+				continue
+			}
+
+			branch = append(branch, resolveChatMessage(m.ToChatMessages()))
+			if nextID = m.Pid.ValueOrZero(); nextID <= 0 || len(branch) >= limit {
+				break
+			}
+		}
+	}
+
+	return array.Reverse(branch), nil
+}
+
+// DeleteRoomMessages 清空数字人的聊天消息
+func (r *MessageRepo) DeleteRoomMessages(ctx context.Context, userID, roomID int64) error {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Where(model2.FieldChatMessagesRoomId, roomID).
+		Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted)
+
+	_, err := model2.NewChatMessagesModel(r.db).UpdateFields(ctx, query.KV{
+		model2.FieldChatMessagesStatus: MessageStatusDeleted,
+	}, q)
+	return err
+}
+
+// MessageSyncReq 聊天消息同步请求
+type MessageSyncReq struct {
+	// SinceID 只返回 ID 大于该值的消息（UpdatedAfter 不为空时，作为相同更新时间的消息的游标）
+	SinceID int64
+	// UpdatedAfter 只返回该时间之后有变更（新增、状态变化、删除）的消息，为空时只同步新增的消息
+	UpdatedAfter time.Time
+	// Limit 返回的最大消息数量
+	Limit int64
+}
+
+// MessageSyncRes 聊天消息同步结果
+type MessageSyncRes struct {
+	Messages []model2.ChatMessages
+	// LastID/LastUpdatedAt 本次同步的游标，作为下一次同步的 SinceID 和 UpdatedAfter
+	LastID        int64
+	LastUpdatedAt time.Time
+	// HasMore 是否还有更多的消息需要同步
+	HasMore bool
+}
+
+// SyncMessages 多端同步聊天消息
+// 按照 UpdatedAfter 同步时，结果中会包含已删除的消息（状态为 MessageStatusDeleted），客户端需要据此删除本地的消息
+func (r *MessageRepo) SyncMessages(ctx context.Context, userID int64, req MessageSyncReq) (*MessageSyncRes, error) {
+	q := query.Builder().
+		Where(model2.FieldChatMessagesUserId, userID).
+		Limit(req.Limit + 1)
+
+	if req.UpdatedAfter.IsZero() {
+		q = q.Where(model2.FieldChatMessagesId, ">", req.SinceID).
+			Where(model2.FieldChatMessagesStatus, "!=", MessageStatusDeleted).
+			OrderBy(model2.FieldChatMessagesId, "ASC")
+	} else {
+		q = q.WhereGroup(func(builder query.Condition) {
+			builder.Where(model2.FieldChatMessagesUpdatedAt, ">", req.UpdatedAfter).
+				OrWhereGroup(func(builder query.Condition) {
+					builder.Where(model2.FieldChatMessagesUpdatedAt, req.UpdatedAfter).
+						Where(model2.FieldChatMessagesId, ">", req.SinceID)
+				})
+		}).
+			OrderBy(model2.FieldChatMessagesUpdatedAt, "ASC").
+			OrderBy(model2.FieldChatMessagesId, "ASC")
+	}
+
+	messages, err := model2.NewChatMessagesModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("sync chat messages failed: %w", err)
+	}
+
+	res := MessageSyncRes{Messages: []model2.ChatMessages{}, LastID: req.SinceID, LastUpdatedAt: req.UpdatedAfter}
+	if int64(len(messages)) > req.Limit {
+		res.HasMore = true
+		messages = messages[:req.Limit]
+	}
+
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		res.LastID = last.Id.ValueOrZero()
+		res.LastUpdatedAt = last.UpdatedAt.ValueOrZero()
+		res.Messages = array.Map(messages, func(msg model2.ChatMessagesN, _ int) model2.ChatMessages {
+			return resolveChatMessage(msg.ToChatMessages())
+		})
+	}
+
+	return &res, nil
+}
+
+// resolveChatMessage 3 分钟未完成的消息，标记为失败
+func resolveChatMessage(msg model2.ChatMessages) model2.ChatMessages {
+	if msg.Status == MessageStatusWaiting && msg.CreatedAt.Add(3*time.Minute).Before(time.Now()) {
+		msg.Status = MessageStatusFailed
+	}
+
+	return msg
+}
+
+// MessageImages 解析消息中包含的图片地址
+func MessageImages(msg model2.ChatMessages) []string {
+	if msg.Images == "" {
+		return nil
+	}
+
+	var images []string
+	_ = json.Unmarshal([]byte(msg.Images), &images)
+	return images
+}
@@ -0,0 +1,1646 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// ChatComparisonN is a ChatComparison object, all fields are nullable
+type ChatComparisonN struct {
+	original            *chatComparisonOriginal
+	chatComparisonModel *ChatComparisonModel
+
+	Id            null.Int    `json:"id"`
+	UserId        null.Int    `json:"user_id"`
+	Prompt        null.String `json:"prompt"`
+	Messages      null.String `json:"-"`
+	Models        null.String `json:"-"`
+	QuotaConsumed null.Int    `json:"quota_consumed"`
+	CreatedAt     null.Time   `json:"created_at"`
+	UpdatedAt     null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *ChatComparisonN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for ChatComparison
+func (inst *ChatComparisonN) SetModel(chatComparisonModel *ChatComparisonModel) {
+	inst.chatComparisonModel = chatComparisonModel
+}
+
+// chatComparisonOriginal is an object which stores original ChatComparison from database
+type chatComparisonOriginal struct {
+	Id            null.Int
+	UserId        null.Int
+	Prompt        null.String
+	Messages      null.String
+	Models        null.String
+	QuotaConsumed null.Int
+	CreatedAt     null.Time
+	UpdatedAt     null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *ChatComparisonN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &chatComparisonOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Prompt != inst.original.Prompt {
+			return true
+		}
+		if inst.Messages != inst.original.Messages {
+			return true
+		}
+		if inst.Models != inst.original.Models {
+			return true
+		}
+		if inst.QuotaConsumed != inst.original.QuotaConsumed {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "prompt":
+				if inst.Prompt != inst.original.Prompt {
+					return true
+				}
+			case "messages":
+				if inst.Messages != inst.original.Messages {
+					return true
+				}
+			case "models":
+				if inst.Models != inst.original.Models {
+					return true
+				}
+			case "quota_consumed":
+				if inst.QuotaConsumed != inst.original.QuotaConsumed {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *ChatComparisonN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &chatComparisonOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Prompt != inst.original.Prompt {
+			kv["prompt"] = inst.Prompt
+		}
+		if inst.Messages != inst.original.Messages {
+			kv["messages"] = inst.Messages
+		}
+		if inst.Models != inst.original.Models {
+			kv["models"] = inst.Models
+		}
+		if inst.QuotaConsumed != inst.original.QuotaConsumed {
+			kv["quota_consumed"] = inst.QuotaConsumed
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "prompt":
+				if inst.Prompt != inst.original.Prompt {
+					kv["prompt"] = inst.Prompt
+				}
+			case "messages":
+				if inst.Messages != inst.original.Messages {
+					kv["messages"] = inst.Messages
+				}
+			case "models":
+				if inst.Models != inst.original.Models {
+					kv["models"] = inst.Models
+				}
+			case "quota_consumed":
+				if inst.QuotaConsumed != inst.original.QuotaConsumed {
+					kv["quota_consumed"] = inst.QuotaConsumed
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *ChatComparisonN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.chatComparisonModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.chatComparisonModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a chat_comparison
+func (inst *ChatComparisonN) Delete(ctx context.Context) error {
+	if inst.chatComparisonModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.chatComparisonModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *ChatComparisonN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type chatComparisonScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var chatComparisonGlobalScopes = make([]chatComparisonScope, 0)
+var chatComparisonLocalScopes = make([]chatComparisonScope, 0)
+
+// AddGlobalScopeForChatComparison assign a global scope to a model
+func AddGlobalScopeForChatComparison(name string, apply func(builder query.Condition)) {
+	chatComparisonGlobalScopes = append(chatComparisonGlobalScopes, chatComparisonScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForChatComparison assign a local scope to a model
+func AddLocalScopeForChatComparison(name string, apply func(builder query.Condition)) {
+	chatComparisonLocalScopes = append(chatComparisonLocalScopes, chatComparisonScope{name: name, apply: apply})
+}
+
+func (m *ChatComparisonModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range chatComparisonGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range chatComparisonLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *ChatComparisonModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ChatComparisonModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type ChatComparison struct {
+	Id            int64     `json:"id"`
+	UserId        int64     `json:"user_id"`
+	Prompt        string    `json:"prompt"`
+	Messages      string    `json:"-"`
+	Models        string    `json:"-"`
+	QuotaConsumed int64     `json:"quota_consumed"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (w ChatComparison) ToChatComparisonN(allows ...string) ChatComparisonN {
+	if len(allows) == 0 {
+		return ChatComparisonN{
+
+			Id:            null.IntFrom(int64(w.Id)),
+			UserId:        null.IntFrom(int64(w.UserId)),
+			Prompt:        null.StringFrom(w.Prompt),
+			Messages:      null.StringFrom(w.Messages),
+			Models:        null.StringFrom(w.Models),
+			QuotaConsumed: null.IntFrom(int64(w.QuotaConsumed)),
+			CreatedAt:     null.TimeFrom(w.CreatedAt),
+			UpdatedAt:     null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := ChatComparisonN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "prompt":
+			res.Prompt = null.StringFrom(w.Prompt)
+		case "messages":
+			res.Messages = null.StringFrom(w.Messages)
+		case "models":
+			res.Models = null.StringFrom(w.Models)
+		case "quota_consumed":
+			res.QuotaConsumed = null.IntFrom(int64(w.QuotaConsumed))
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w ChatComparison) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *ChatComparisonN) ToChatComparison() ChatComparison {
+	return ChatComparison{
+
+		Id:            w.Id.Int64,
+		UserId:        w.UserId.Int64,
+		Prompt:        w.Prompt.String,
+		Messages:      w.Messages.String,
+		Models:        w.Models.String,
+		QuotaConsumed: w.QuotaConsumed.Int64,
+		CreatedAt:     w.CreatedAt.Time,
+		UpdatedAt:     w.UpdatedAt.Time,
+	}
+}
+
+// ChatComparisonModel is a model which encapsulates the operations of the object
+type ChatComparisonModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var chatComparisonTableName = "chat_comparison"
+
+// ChatComparisonTable return table name for ChatComparison
+func ChatComparisonTable() string {
+	return chatComparisonTableName
+}
+
+const (
+	FieldChatComparisonId            = "id"
+	FieldChatComparisonUserId        = "user_id"
+	FieldChatComparisonPrompt        = "prompt"
+	FieldChatComparisonMessages      = "messages"
+	FieldChatComparisonModels        = "models"
+	FieldChatComparisonQuotaConsumed = "quota_consumed"
+	FieldChatComparisonCreatedAt     = "created_at"
+	FieldChatComparisonUpdatedAt     = "updated_at"
+)
+
+// ChatComparisonFields return all fields in ChatComparison model
+func ChatComparisonFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"prompt",
+		"messages",
+		"models",
+		"quota_consumed",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetChatComparisonTable(tableName string) {
+	chatComparisonTableName = tableName
+}
+
+// NewChatComparisonModel create a ChatComparisonModel
+func NewChatComparisonModel(db query.Database) *ChatComparisonModel {
+	return &ChatComparisonModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           chatComparisonTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *ChatComparisonModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *ChatComparisonModel) clone() *ChatComparisonModel {
+	return &ChatComparisonModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *ChatComparisonModel) WithoutGlobalScopes(names ...string) *ChatComparisonModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *ChatComparisonModel) WithLocalScopes(names ...string) *ChatComparisonModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *ChatComparisonModel) Condition(builder query.SQLBuilder) *ChatComparisonModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *ChatComparisonModel) Find(ctx context.Context, id int64) (*ChatComparisonN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *ChatComparisonModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *ChatComparisonModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *ChatComparisonModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]ChatComparisonN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *ChatComparisonModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]ChatComparisonN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"user_id",
+			"prompt",
+			"messages",
+			"models",
+			"quota_consumed",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "prompt":
+			selectFields = append(selectFields, f)
+		case "messages":
+			selectFields = append(selectFields, f)
+		case "models":
+			selectFields = append(selectFields, f)
+		case "quota_consumed":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*ChatComparisonN, []interface{}) {
+		var chatComparisonVar ChatComparisonN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &chatComparisonVar.Id)
+			case "user_id":
+				scanFields = append(scanFields, &chatComparisonVar.UserId)
+			case "prompt":
+				scanFields = append(scanFields, &chatComparisonVar.Prompt)
+			case "messages":
+				scanFields = append(scanFields, &chatComparisonVar.Messages)
+			case "models":
+				scanFields = append(scanFields, &chatComparisonVar.Models)
+			case "quota_consumed":
+				scanFields = append(scanFields, &chatComparisonVar.QuotaConsumed)
+			case "created_at":
+				scanFields = append(scanFields, &chatComparisonVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &chatComparisonVar.UpdatedAt)
+			}
+		}
+
+		return &chatComparisonVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	chatComparisons := make([]ChatComparisonN, 0)
+	for rows.Next() {
+		chatComparisonReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		chatComparisonReal.original = &chatComparisonOriginal{}
+		_ = query.Copy(chatComparisonReal, chatComparisonReal.original)
+
+		chatComparisonReal.SetModel(m)
+		chatComparisons = append(chatComparisons, *chatComparisonReal)
+	}
+
+	return chatComparisons, nil
+}
+
+// First return first result for given query
+func (m *ChatComparisonModel) First(ctx context.Context, builders ...query.SQLBuilder) (*ChatComparisonN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new chat_comparison to database
+func (m *ChatComparisonModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all chat_comparisons to database
+func (m *ChatComparisonModel) SaveAll(ctx context.Context, chatComparisons []ChatComparisonN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, chatComparison := range chatComparisons {
+		id, err := m.Save(ctx, chatComparison)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a chat_comparison to database
+func (m *ChatComparisonModel) Save(ctx context.Context, chatComparison ChatComparisonN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, chatComparison.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new chat_comparison or update it when it has a id > 0
+func (m *ChatComparisonModel) SaveOrUpdate(ctx context.Context, chatComparison ChatComparisonN, onlyFields ...string) (id int64, updated bool, err error) {
+	if chatComparison.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, chatComparison.Id.Int64, chatComparison, onlyFields...)
+		return chatComparison.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, chatComparison, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *ChatComparisonModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *ChatComparisonModel) Update(ctx context.Context, builder query.SQLBuilder, chatComparison ChatComparisonN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, chatComparison.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *ChatComparisonModel) UpdateById(ctx context.Context, id int64, chatComparison ChatComparisonN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, chatComparison.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *ChatComparisonModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *ChatComparisonModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
+
+// ChatComparisonAnswerN is a ChatComparisonAnswer object, all fields are nullable
+type ChatComparisonAnswerN struct {
+	original                  *chatComparisonAnswerOriginal
+	chatComparisonAnswerModel *ChatComparisonAnswerModel
+
+	Id            null.Int    `json:"id"`
+	ComparisonId  null.Int    `json:"comparison_id"`
+	UserId        null.Int    `json:"-"`
+	Seq           null.Int    `json:"seq"`
+	Model         null.String `json:"model"`
+	Answer        null.String `json:"answer"`
+	Status        null.Int    `json:"status"`
+	Error         null.String `json:"error,omitempty"`
+	InputTokens   null.Int    `json:"input_tokens"`
+	OutputTokens  null.Int    `json:"output_tokens"`
+	QuotaConsumed null.Int    `json:"quota_consumed"`
+	ElapsedMs     null.Int    `json:"elapsed_ms"`
+	CreatedAt     null.Time   `json:"created_at"`
+	UpdatedAt     null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *ChatComparisonAnswerN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for ChatComparisonAnswer
+func (inst *ChatComparisonAnswerN) SetModel(chatComparisonAnswerModel *ChatComparisonAnswerModel) {
+	inst.chatComparisonAnswerModel = chatComparisonAnswerModel
+}
+
+// chatComparisonAnswerOriginal is an object which stores original ChatComparisonAnswer from database
+type chatComparisonAnswerOriginal struct {
+	Id            null.Int
+	ComparisonId  null.Int
+	UserId        null.Int
+	Seq           null.Int
+	Model         null.String
+	Answer        null.String
+	Status        null.Int
+	Error         null.String
+	InputTokens   null.Int
+	OutputTokens  null.Int
+	QuotaConsumed null.Int
+	ElapsedMs     null.Int
+	CreatedAt     null.Time
+	UpdatedAt     null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *ChatComparisonAnswerN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &chatComparisonAnswerOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.ComparisonId != inst.original.ComparisonId {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Seq != inst.original.Seq {
+			return true
+		}
+		if inst.Model != inst.original.Model {
+			return true
+		}
+		if inst.Answer != inst.original.Answer {
+			return true
+		}
+		if inst.Status != inst.original.Status {
+			return true
+		}
+		if inst.Error != inst.original.Error {
+			return true
+		}
+		if inst.InputTokens != inst.original.InputTokens {
+			return true
+		}
+		if inst.OutputTokens != inst.original.OutputTokens {
+			return true
+		}
+		if inst.QuotaConsumed != inst.original.QuotaConsumed {
+			return true
+		}
+		if inst.ElapsedMs != inst.original.ElapsedMs {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "comparison_id":
+				if inst.ComparisonId != inst.original.ComparisonId {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					return true
+				}
+			case "model":
+				if inst.Model != inst.original.Model {
+					return true
+				}
+			case "answer":
+				if inst.Answer != inst.original.Answer {
+					return true
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					return true
+				}
+			case "error":
+				if inst.Error != inst.original.Error {
+					return true
+				}
+			case "input_tokens":
+				if inst.InputTokens != inst.original.InputTokens {
+					return true
+				}
+			case "output_tokens":
+				if inst.OutputTokens != inst.original.OutputTokens {
+					return true
+				}
+			case "quota_consumed":
+				if inst.QuotaConsumed != inst.original.QuotaConsumed {
+					return true
+				}
+			case "elapsed_ms":
+				if inst.ElapsedMs != inst.original.ElapsedMs {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *ChatComparisonAnswerN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &chatComparisonAnswerOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.ComparisonId != inst.original.ComparisonId {
+			kv["comparison_id"] = inst.ComparisonId
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Seq != inst.original.Seq {
+			kv["seq"] = inst.Seq
+		}
+		if inst.Model != inst.original.Model {
+			kv["model"] = inst.Model
+		}
+		if inst.Answer != inst.original.Answer {
+			kv["answer"] = inst.Answer
+		}
+		if inst.Status != inst.original.Status {
+			kv["status"] = inst.Status
+		}
+		if inst.Error != inst.original.Error {
+			kv["error"] = inst.Error
+		}
+		if inst.InputTokens != inst.original.InputTokens {
+			kv["input_tokens"] = inst.InputTokens
+		}
+		if inst.OutputTokens != inst.original.OutputTokens {
+			kv["output_tokens"] = inst.OutputTokens
+		}
+		if inst.QuotaConsumed != inst.original.QuotaConsumed {
+			kv["quota_consumed"] = inst.QuotaConsumed
+		}
+		if inst.ElapsedMs != inst.original.ElapsedMs {
+			kv["elapsed_ms"] = inst.ElapsedMs
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "comparison_id":
+				if inst.ComparisonId != inst.original.ComparisonId {
+					kv["comparison_id"] = inst.ComparisonId
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					kv["seq"] = inst.Seq
+				}
+			case "model":
+				if inst.Model != inst.original.Model {
+					kv["model"] = inst.Model
+				}
+			case "answer":
+				if inst.Answer != inst.original.Answer {
+					kv["answer"] = inst.Answer
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					kv["status"] = inst.Status
+				}
+			case "error":
+				if inst.Error != inst.original.Error {
+					kv["error"] = inst.Error
+				}
+			case "input_tokens":
+				if inst.InputTokens != inst.original.InputTokens {
+					kv["input_tokens"] = inst.InputTokens
+				}
+			case "output_tokens":
+				if inst.OutputTokens != inst.original.OutputTokens {
+					kv["output_tokens"] = inst.OutputTokens
+				}
+			case "quota_consumed":
+				if inst.QuotaConsumed != inst.original.QuotaConsumed {
+					kv["quota_consumed"] = inst.QuotaConsumed
+				}
+			case "elapsed_ms":
+				if inst.ElapsedMs != inst.original.ElapsedMs {
+					kv["elapsed_ms"] = inst.ElapsedMs
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *ChatComparisonAnswerN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.chatComparisonAnswerModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.chatComparisonAnswerModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a chat_comparison_answer
+func (inst *ChatComparisonAnswerN) Delete(ctx context.Context) error {
+	if inst.chatComparisonAnswerModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.chatComparisonAnswerModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *ChatComparisonAnswerN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type chatComparisonAnswerScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var chatComparisonAnswerGlobalScopes = make([]chatComparisonAnswerScope, 0)
+var chatComparisonAnswerLocalScopes = make([]chatComparisonAnswerScope, 0)
+
+// AddGlobalScopeForChatComparisonAnswer assign a global scope to a model
+func AddGlobalScopeForChatComparisonAnswer(name string, apply func(builder query.Condition)) {
+	chatComparisonAnswerGlobalScopes = append(chatComparisonAnswerGlobalScopes, chatComparisonAnswerScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForChatComparisonAnswer assign a local scope to a model
+func AddLocalScopeForChatComparisonAnswer(name string, apply func(builder query.Condition)) {
+	chatComparisonAnswerLocalScopes = append(chatComparisonAnswerLocalScopes, chatComparisonAnswerScope{name: name, apply: apply})
+}
+
+func (m *ChatComparisonAnswerModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range chatComparisonAnswerGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range chatComparisonAnswerLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *ChatComparisonAnswerModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ChatComparisonAnswerModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type ChatComparisonAnswer struct {
+	Id            int64     `json:"id"`
+	ComparisonId  int64     `json:"comparison_id"`
+	UserId        int64     `json:"-"`
+	Seq           int64     `json:"seq"`
+	Model         string    `json:"model"`
+	Answer        string    `json:"answer"`
+	Status        int64     `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	InputTokens   int64     `json:"input_tokens"`
+	OutputTokens  int64     `json:"output_tokens"`
+	QuotaConsumed int64     `json:"quota_consumed"`
+	ElapsedMs     int64     `json:"elapsed_ms"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (w ChatComparisonAnswer) ToChatComparisonAnswerN(allows ...string) ChatComparisonAnswerN {
+	if len(allows) == 0 {
+		return ChatComparisonAnswerN{
+
+			Id:            null.IntFrom(int64(w.Id)),
+			ComparisonId:  null.IntFrom(int64(w.ComparisonId)),
+			UserId:        null.IntFrom(int64(w.UserId)),
+			Seq:           null.IntFrom(int64(w.Seq)),
+			Model:         null.StringFrom(w.Model),
+			Answer:        null.StringFrom(w.Answer),
+			Status:        null.IntFrom(int64(w.Status)),
+			Error:         null.StringFrom(w.Error),
+			InputTokens:   null.IntFrom(int64(w.InputTokens)),
+			OutputTokens:  null.IntFrom(int64(w.OutputTokens)),
+			QuotaConsumed: null.IntFrom(int64(w.QuotaConsumed)),
+			ElapsedMs:     null.IntFrom(int64(w.ElapsedMs)),
+			CreatedAt:     null.TimeFrom(w.CreatedAt),
+			UpdatedAt:     null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := ChatComparisonAnswerN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "comparison_id":
+			res.ComparisonId = null.IntFrom(int64(w.ComparisonId))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "seq":
+			res.Seq = null.IntFrom(int64(w.Seq))
+		case "model":
+			res.Model = null.StringFrom(w.Model)
+		case "answer":
+			res.Answer = null.StringFrom(w.Answer)
+		case "status":
+			res.Status = null.IntFrom(int64(w.Status))
+		case "error":
+			res.Error = null.StringFrom(w.Error)
+		case "input_tokens":
+			res.InputTokens = null.IntFrom(int64(w.InputTokens))
+		case "output_tokens":
+			res.OutputTokens = null.IntFrom(int64(w.OutputTokens))
+		case "quota_consumed":
+			res.QuotaConsumed = null.IntFrom(int64(w.QuotaConsumed))
+		case "elapsed_ms":
+			res.ElapsedMs = null.IntFrom(int64(w.ElapsedMs))
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w ChatComparisonAnswer) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *ChatComparisonAnswerN) ToChatComparisonAnswer() ChatComparisonAnswer {
+	return ChatComparisonAnswer{
+
+		Id:            w.Id.Int64,
+		ComparisonId:  w.ComparisonId.Int64,
+		UserId:        w.UserId.Int64,
+		Seq:           w.Seq.Int64,
+		Model:         w.Model.String,
+		Answer:        w.Answer.String,
+		Status:        w.Status.Int64,
+		Error:         w.Error.String,
+		InputTokens:   w.InputTokens.Int64,
+		OutputTokens:  w.OutputTokens.Int64,
+		QuotaConsumed: w.QuotaConsumed.Int64,
+		ElapsedMs:     w.ElapsedMs.Int64,
+		CreatedAt:     w.CreatedAt.Time,
+		UpdatedAt:     w.UpdatedAt.Time,
+	}
+}
+
+// ChatComparisonAnswerModel is a model which encapsulates the operations of the object
+type ChatComparisonAnswerModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var chatComparisonAnswerTableName = "chat_comparison_answer"
+
+// ChatComparisonAnswerTable return table name for ChatComparisonAnswer
+func ChatComparisonAnswerTable() string {
+	return chatComparisonAnswerTableName
+}
+
+const (
+	FieldChatComparisonAnswerId            = "id"
+	FieldChatComparisonAnswerComparisonId  = "comparison_id"
+	FieldChatComparisonAnswerUserId        = "user_id"
+	FieldChatComparisonAnswerSeq           = "seq"
+	FieldChatComparisonAnswerModel         = "model"
+	FieldChatComparisonAnswerAnswer        = "answer"
+	FieldChatComparisonAnswerStatus        = "status"
+	FieldChatComparisonAnswerError         = "error"
+	FieldChatComparisonAnswerInputTokens   = "input_tokens"
+	FieldChatComparisonAnswerOutputTokens  = "output_tokens"
+	FieldChatComparisonAnswerQuotaConsumed = "quota_consumed"
+	FieldChatComparisonAnswerElapsedMs     = "elapsed_ms"
+	FieldChatComparisonAnswerCreatedAt     = "created_at"
+	FieldChatComparisonAnswerUpdatedAt     = "updated_at"
+)
+
+// ChatComparisonAnswerFields return all fields in ChatComparisonAnswer model
+func ChatComparisonAnswerFields() []string {
+	return []string{
+		"id",
+		"comparison_id",
+		"user_id",
+		"seq",
+		"model",
+		"answer",
+		"status",
+		"error",
+		"input_tokens",
+		"output_tokens",
+		"quota_consumed",
+		"elapsed_ms",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetChatComparisonAnswerTable(tableName string) {
+	chatComparisonAnswerTableName = tableName
+}
+
+// NewChatComparisonAnswerModel create a ChatComparisonAnswerModel
+func NewChatComparisonAnswerModel(db query.Database) *ChatComparisonAnswerModel {
+	return &ChatComparisonAnswerModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           chatComparisonAnswerTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *ChatComparisonAnswerModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *ChatComparisonAnswerModel) clone() *ChatComparisonAnswerModel {
+	return &ChatComparisonAnswerModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *ChatComparisonAnswerModel) WithoutGlobalScopes(names ...string) *ChatComparisonAnswerModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *ChatComparisonAnswerModel) WithLocalScopes(names ...string) *ChatComparisonAnswerModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *ChatComparisonAnswerModel) Condition(builder query.SQLBuilder) *ChatComparisonAnswerModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *ChatComparisonAnswerModel) Find(ctx context.Context, id int64) (*ChatComparisonAnswerN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *ChatComparisonAnswerModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *ChatComparisonAnswerModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *ChatComparisonAnswerModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]ChatComparisonAnswerN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *ChatComparisonAnswerModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]ChatComparisonAnswerN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"comparison_id",
+			"user_id",
+			"seq",
+			"model",
+			"answer",
+			"status",
+			"error",
+			"input_tokens",
+			"output_tokens",
+			"quota_consumed",
+			"elapsed_ms",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "comparison_id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "seq":
+			selectFields = append(selectFields, f)
+		case "model":
+			selectFields = append(selectFields, f)
+		case "answer":
+			selectFields = append(selectFields, f)
+		case "status":
+			selectFields = append(selectFields, f)
+		case "error":
+			selectFields = append(selectFields, f)
+		case "input_tokens":
+			selectFields = append(selectFields, f)
+		case "output_tokens":
+			selectFields = append(selectFields, f)
+		case "quota_consumed":
+			selectFields = append(selectFields, f)
+		case "elapsed_ms":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*ChatComparisonAnswerN, []interface{}) {
+		var chatComparisonAnswerVar ChatComparisonAnswerN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Id)
+			case "comparison_id":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.ComparisonId)
+			case "user_id":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.UserId)
+			case "seq":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Seq)
+			case "model":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Model)
+			case "answer":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Answer)
+			case "status":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Status)
+			case "error":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.Error)
+			case "input_tokens":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.InputTokens)
+			case "output_tokens":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.OutputTokens)
+			case "quota_consumed":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.QuotaConsumed)
+			case "elapsed_ms":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.ElapsedMs)
+			case "created_at":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &chatComparisonAnswerVar.UpdatedAt)
+			}
+		}
+
+		return &chatComparisonAnswerVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	chatComparisonAnswers := make([]ChatComparisonAnswerN, 0)
+	for rows.Next() {
+		chatComparisonAnswerReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		chatComparisonAnswerReal.original = &chatComparisonAnswerOriginal{}
+		_ = query.Copy(chatComparisonAnswerReal, chatComparisonAnswerReal.original)
+
+		chatComparisonAnswerReal.SetModel(m)
+		chatComparisonAnswers = append(chatComparisonAnswers, *chatComparisonAnswerReal)
+	}
+
+	return chatComparisonAnswers, nil
+}
+
+// First return first result for given query
+func (m *ChatComparisonAnswerModel) First(ctx context.Context, builders ...query.SQLBuilder) (*ChatComparisonAnswerN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new chat_comparison_answer to database
+func (m *ChatComparisonAnswerModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all chat_comparison_answers to database
+func (m *ChatComparisonAnswerModel) SaveAll(ctx context.Context, chatComparisonAnswers []ChatComparisonAnswerN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, chatComparisonAnswer := range chatComparisonAnswers {
+		id, err := m.Save(ctx, chatComparisonAnswer)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a chat_comparison_answer to database
+func (m *ChatComparisonAnswerModel) Save(ctx context.Context, chatComparisonAnswer ChatComparisonAnswerN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, chatComparisonAnswer.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new chat_comparison_answer or update it when it has a id > 0
+func (m *ChatComparisonAnswerModel) SaveOrUpdate(ctx context.Context, chatComparisonAnswer ChatComparisonAnswerN, onlyFields ...string) (id int64, updated bool, err error) {
+	if chatComparisonAnswer.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, chatComparisonAnswer.Id.Int64, chatComparisonAnswer, onlyFields...)
+		return chatComparisonAnswer.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, chatComparisonAnswer, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *ChatComparisonAnswerModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *ChatComparisonAnswerModel) Update(ctx context.Context, builder query.SQLBuilder, chatComparisonAnswer ChatComparisonAnswerN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, chatComparisonAnswer.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *ChatComparisonAnswerModel) UpdateById(ctx context.Context, id int64, chatComparisonAnswer ChatComparisonAnswerN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, chatComparisonAnswer.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *ChatComparisonAnswerModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *ChatComparisonAnswerModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
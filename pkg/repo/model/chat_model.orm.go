@@ -0,0 +1,691 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// ChatModelN is a ChatModel object, all fields are nullable
+type ChatModelN struct {
+	original       *chatModelOriginal
+	chatModelModel *ChatModelModel
+
+	Id         null.Int    `json:"id"`
+	ModelId    null.String `json:"model_id"`
+	Definition null.String `json:"definition"`
+	Status     null.Int    `json:"status"`
+	CreatedAt  null.Time   `json:"created_at"`
+	UpdatedAt  null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *ChatModelN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for ChatModel
+func (inst *ChatModelN) SetModel(chatModelModel *ChatModelModel) {
+	inst.chatModelModel = chatModelModel
+}
+
+// chatModelOriginal is an object which stores original ChatModel from database
+type chatModelOriginal struct {
+	Id         null.Int
+	ModelId    null.String
+	Definition null.String
+	Status     null.Int
+	CreatedAt  null.Time
+	UpdatedAt  null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *ChatModelN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &chatModelOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.ModelId != inst.original.ModelId {
+			return true
+		}
+		if inst.Definition != inst.original.Definition {
+			return true
+		}
+		if inst.Status != inst.original.Status {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "model_id":
+				if inst.ModelId != inst.original.ModelId {
+					return true
+				}
+			case "definition":
+				if inst.Definition != inst.original.Definition {
+					return true
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *ChatModelN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &chatModelOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.ModelId != inst.original.ModelId {
+			kv["model_id"] = inst.ModelId
+		}
+		if inst.Definition != inst.original.Definition {
+			kv["definition"] = inst.Definition
+		}
+		if inst.Status != inst.original.Status {
+			kv["status"] = inst.Status
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "model_id":
+				if inst.ModelId != inst.original.ModelId {
+					kv["model_id"] = inst.ModelId
+				}
+			case "definition":
+				if inst.Definition != inst.original.Definition {
+					kv["definition"] = inst.Definition
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					kv["status"] = inst.Status
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *ChatModelN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.chatModelModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.chatModelModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a chat_model
+func (inst *ChatModelN) Delete(ctx context.Context) error {
+	if inst.chatModelModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.chatModelModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *ChatModelN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type chatModelScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var chatModelGlobalScopes = make([]chatModelScope, 0)
+var chatModelLocalScopes = make([]chatModelScope, 0)
+
+// AddGlobalScopeForChatModel assign a global scope to a model
+func AddGlobalScopeForChatModel(name string, apply func(builder query.Condition)) {
+	chatModelGlobalScopes = append(chatModelGlobalScopes, chatModelScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForChatModel assign a local scope to a model
+func AddLocalScopeForChatModel(name string, apply func(builder query.Condition)) {
+	chatModelLocalScopes = append(chatModelLocalScopes, chatModelScope{name: name, apply: apply})
+}
+
+func (m *ChatModelModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range chatModelGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range chatModelLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *ChatModelModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ChatModelModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type ChatModel struct {
+	Id         int64     `json:"id"`
+	ModelId    string    `json:"model_id"`
+	Definition string    `json:"definition"`
+	Status     int64     `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (w ChatModel) ToChatModelN(allows ...string) ChatModelN {
+	if len(allows) == 0 {
+		return ChatModelN{
+
+			Id:         null.IntFrom(int64(w.Id)),
+			ModelId:    null.StringFrom(w.ModelId),
+			Definition: null.StringFrom(w.Definition),
+			Status:     null.IntFrom(int64(w.Status)),
+			CreatedAt:  null.TimeFrom(w.CreatedAt),
+			UpdatedAt:  null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := ChatModelN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "model_id":
+			res.ModelId = null.StringFrom(w.ModelId)
+		case "definition":
+			res.Definition = null.StringFrom(w.Definition)
+		case "status":
+			res.Status = null.IntFrom(int64(w.Status))
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w ChatModel) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *ChatModelN) ToChatModel() ChatModel {
+	return ChatModel{
+
+		Id:         w.Id.Int64,
+		ModelId:    w.ModelId.String,
+		Definition: w.Definition.String,
+		Status:     w.Status.Int64,
+		CreatedAt:  w.CreatedAt.Time,
+		UpdatedAt:  w.UpdatedAt.Time,
+	}
+}
+
+// ChatModelModel is a model which encapsulates the operations of the object
+type ChatModelModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var chatModelTableName = "chat_model"
+
+// ChatModelTable return table name for ChatModel
+func ChatModelTable() string {
+	return chatModelTableName
+}
+
+const (
+	FieldChatModelId         = "id"
+	FieldChatModelModelId    = "model_id"
+	FieldChatModelDefinition = "definition"
+	FieldChatModelStatus     = "status"
+	FieldChatModelCreatedAt  = "created_at"
+	FieldChatModelUpdatedAt  = "updated_at"
+)
+
+// ChatModelFields return all fields in ChatModel model
+func ChatModelFields() []string {
+	return []string{
+		"id",
+		"model_id",
+		"definition",
+		"status",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetChatModelTable(tableName string) {
+	chatModelTableName = tableName
+}
+
+// NewChatModelModel create a ChatModelModel
+func NewChatModelModel(db query.Database) *ChatModelModel {
+	return &ChatModelModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           chatModelTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *ChatModelModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *ChatModelModel) clone() *ChatModelModel {
+	return &ChatModelModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *ChatModelModel) WithoutGlobalScopes(names ...string) *ChatModelModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *ChatModelModel) WithLocalScopes(names ...string) *ChatModelModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *ChatModelModel) Condition(builder query.SQLBuilder) *ChatModelModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *ChatModelModel) Find(ctx context.Context, id int64) (*ChatModelN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *ChatModelModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *ChatModelModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *ChatModelModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]ChatModelN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *ChatModelModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]ChatModelN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"model_id",
+			"definition",
+			"status",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "model_id":
+			selectFields = append(selectFields, f)
+		case "definition":
+			selectFields = append(selectFields, f)
+		case "status":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*ChatModelN, []interface{}) {
+		var chatModelVar ChatModelN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &chatModelVar.Id)
+			case "model_id":
+				scanFields = append(scanFields, &chatModelVar.ModelId)
+			case "definition":
+				scanFields = append(scanFields, &chatModelVar.Definition)
+			case "status":
+				scanFields = append(scanFields, &chatModelVar.Status)
+			case "created_at":
+				scanFields = append(scanFields, &chatModelVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &chatModelVar.UpdatedAt)
+			}
+		}
+
+		return &chatModelVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	chatModels := make([]ChatModelN, 0)
+	for rows.Next() {
+		chatModelReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		chatModelReal.original = &chatModelOriginal{}
+		_ = query.Copy(chatModelReal, chatModelReal.original)
+
+		chatModelReal.SetModel(m)
+		chatModels = append(chatModels, *chatModelReal)
+	}
+
+	return chatModels, nil
+}
+
+// First return first result for given query
+func (m *ChatModelModel) First(ctx context.Context, builders ...query.SQLBuilder) (*ChatModelN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new chat_model to database
+func (m *ChatModelModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all chat_models to database
+func (m *ChatModelModel) SaveAll(ctx context.Context, chatModels []ChatModelN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, chatModel := range chatModels {
+		id, err := m.Save(ctx, chatModel)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a chat_model to database
+func (m *ChatModelModel) Save(ctx context.Context, chatModel ChatModelN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, chatModel.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new chat_model or update it when it has a id > 0
+func (m *ChatModelModel) SaveOrUpdate(ctx context.Context, chatModel ChatModelN, onlyFields ...string) (id int64, updated bool, err error) {
+	if chatModel.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, chatModel.Id.Int64, chatModel, onlyFields...)
+		return chatModel.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, chatModel, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *ChatModelModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *ChatModelModel) Update(ctx context.Context, builder query.SQLBuilder, chatModel ChatModelN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, chatModel.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *ChatModelModel) UpdateById(ctx context.Context, id int64, chatModel ChatModelN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, chatModel.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *ChatModelModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *ChatModelModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,2237 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// KnowledgeBaseN is a KnowledgeBase object, all fields are nullable
+type KnowledgeBaseN struct {
+	original           *knowledgeBaseOriginal
+	knowledgeBaseModel *KnowledgeBaseModel
+
+	Id          null.Int    `json:"id"`
+	UserId      null.Int    `json:"user_id"`
+	Name        null.String `json:"name"`
+	Description null.String `json:"description,omitempty"`
+	CreatedAt   null.Time   `json:"created_at"`
+	UpdatedAt   null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeBaseN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeBase
+func (inst *KnowledgeBaseN) SetModel(knowledgeBaseModel *KnowledgeBaseModel) {
+	inst.knowledgeBaseModel = knowledgeBaseModel
+}
+
+// knowledgeBaseOriginal is an object which stores original KnowledgeBase from database
+type knowledgeBaseOriginal struct {
+	Id          null.Int
+	UserId      null.Int
+	Name        null.String
+	Description null.String
+	CreatedAt   null.Time
+	UpdatedAt   null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeBaseN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeBaseOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Name != inst.original.Name {
+			return true
+		}
+		if inst.Description != inst.original.Description {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					return true
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeBaseN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeBaseOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Name != inst.original.Name {
+			kv["name"] = inst.Name
+		}
+		if inst.Description != inst.original.Description {
+			kv["description"] = inst.Description
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					kv["name"] = inst.Name
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					kv["description"] = inst.Description
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeBaseN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeBaseModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeBaseModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_base
+func (inst *KnowledgeBaseN) Delete(ctx context.Context) error {
+	if inst.knowledgeBaseModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeBaseModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeBaseN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeBaseScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeBaseGlobalScopes = make([]knowledgeBaseScope, 0)
+var knowledgeBaseLocalScopes = make([]knowledgeBaseScope, 0)
+
+// AddGlobalScopeForKnowledgeBase assign a global scope to a model
+func AddGlobalScopeForKnowledgeBase(name string, apply func(builder query.Condition)) {
+	knowledgeBaseGlobalScopes = append(knowledgeBaseGlobalScopes, knowledgeBaseScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeBase assign a local scope to a model
+func AddLocalScopeForKnowledgeBase(name string, apply func(builder query.Condition)) {
+	knowledgeBaseLocalScopes = append(knowledgeBaseLocalScopes, knowledgeBaseScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeBaseModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeBaseGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeBaseLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeBaseModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeBaseModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeBase struct {
+	Id          int64     `json:"id"`
+	UserId      int64     `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (w KnowledgeBase) ToKnowledgeBaseN(allows ...string) KnowledgeBaseN {
+	if len(allows) == 0 {
+		return KnowledgeBaseN{
+
+			Id:          null.IntFrom(int64(w.Id)),
+			UserId:      null.IntFrom(int64(w.UserId)),
+			Name:        null.StringFrom(w.Name),
+			Description: null.StringFrom(w.Description),
+			CreatedAt:   null.TimeFrom(w.CreatedAt),
+			UpdatedAt:   null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeBaseN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "name":
+			res.Name = null.StringFrom(w.Name)
+		case "description":
+			res.Description = null.StringFrom(w.Description)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeBase) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeBaseN) ToKnowledgeBase() KnowledgeBase {
+	return KnowledgeBase{
+
+		Id:          w.Id.Int64,
+		UserId:      w.UserId.Int64,
+		Name:        w.Name.String,
+		Description: w.Description.String,
+		CreatedAt:   w.CreatedAt.Time,
+		UpdatedAt:   w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeBaseModel is a model which encapsulates the operations of the object
+type KnowledgeBaseModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeBaseTableName = "knowledge_base"
+
+// KnowledgeBaseTable return table name for KnowledgeBase
+func KnowledgeBaseTable() string {
+	return knowledgeBaseTableName
+}
+
+const (
+	FieldKnowledgeBaseId          = "id"
+	FieldKnowledgeBaseUserId      = "user_id"
+	FieldKnowledgeBaseName        = "name"
+	FieldKnowledgeBaseDescription = "description"
+	FieldKnowledgeBaseCreatedAt   = "created_at"
+	FieldKnowledgeBaseUpdatedAt   = "updated_at"
+)
+
+// KnowledgeBaseFields return all fields in KnowledgeBase model
+func KnowledgeBaseFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"name",
+		"description",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeBaseTable(tableName string) {
+	knowledgeBaseTableName = tableName
+}
+
+// NewKnowledgeBaseModel create a KnowledgeBaseModel
+func NewKnowledgeBaseModel(db query.Database) *KnowledgeBaseModel {
+	return &KnowledgeBaseModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeBaseTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeBaseModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeBaseModel) clone() *KnowledgeBaseModel {
+	return &KnowledgeBaseModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeBaseModel) WithoutGlobalScopes(names ...string) *KnowledgeBaseModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeBaseModel) WithLocalScopes(names ...string) *KnowledgeBaseModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeBaseModel) Condition(builder query.SQLBuilder) *KnowledgeBaseModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeBaseModel) Find(ctx context.Context, id int64) (*KnowledgeBaseN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeBaseModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeBaseModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeBaseModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeBaseN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeBaseModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeBaseN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"user_id",
+			"name",
+			"description",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "name":
+			selectFields = append(selectFields, f)
+		case "description":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeBaseN, []interface{}) {
+		var knowledgeBaseVar KnowledgeBaseN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeBaseVar.Id)
+			case "user_id":
+				scanFields = append(scanFields, &knowledgeBaseVar.UserId)
+			case "name":
+				scanFields = append(scanFields, &knowledgeBaseVar.Name)
+			case "description":
+				scanFields = append(scanFields, &knowledgeBaseVar.Description)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeBaseVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeBaseVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeBaseVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeBases := make([]KnowledgeBaseN, 0)
+	for rows.Next() {
+		knowledgeBaseReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeBaseReal.original = &knowledgeBaseOriginal{}
+		_ = query.Copy(knowledgeBaseReal, knowledgeBaseReal.original)
+
+		knowledgeBaseReal.SetModel(m)
+		knowledgeBases = append(knowledgeBases, *knowledgeBaseReal)
+	}
+
+	return knowledgeBases, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeBaseModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeBaseN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_base to database
+func (m *KnowledgeBaseModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_bases to database
+func (m *KnowledgeBaseModel) SaveAll(ctx context.Context, knowledgeBases []KnowledgeBaseN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeBase := range knowledgeBases {
+		id, err := m.Save(ctx, knowledgeBase)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_base to database
+func (m *KnowledgeBaseModel) Save(ctx context.Context, knowledgeBase KnowledgeBaseN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeBase.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_base or update it when it has a id > 0
+func (m *KnowledgeBaseModel) SaveOrUpdate(ctx context.Context, knowledgeBase KnowledgeBaseN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeBase.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeBase.Id.Int64, knowledgeBase, onlyFields...)
+		return knowledgeBase.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeBase, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeBaseModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeBaseModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeBase KnowledgeBaseN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeBase.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeBaseModel) UpdateById(ctx context.Context, id int64, knowledgeBase KnowledgeBaseN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeBase.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeBaseModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeBaseModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
+
+// KnowledgeDocumentN is a KnowledgeDocument object, all fields are nullable
+type KnowledgeDocumentN struct {
+	original               *knowledgeDocumentOriginal
+	knowledgeDocumentModel *KnowledgeDocumentModel
+
+	Id              null.Int    `json:"id"`
+	UserId          null.Int    `json:"user_id"`
+	KnowledgeBaseId null.Int    `json:"knowledge_base_id"`
+	Name            null.String `json:"name"`
+	Size            null.Int    `json:"size"`
+	Content         null.String `json:"-"`
+	ChunkCount      null.Int    `json:"chunk_count"`
+	Status          null.Int    `json:"status"`
+	Error           null.String `json:"error,omitempty"`
+	CreatedAt       null.Time   `json:"created_at"`
+	UpdatedAt       null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeDocumentN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeDocument
+func (inst *KnowledgeDocumentN) SetModel(knowledgeDocumentModel *KnowledgeDocumentModel) {
+	inst.knowledgeDocumentModel = knowledgeDocumentModel
+}
+
+// knowledgeDocumentOriginal is an object which stores original KnowledgeDocument from database
+type knowledgeDocumentOriginal struct {
+	Id              null.Int
+	UserId          null.Int
+	KnowledgeBaseId null.Int
+	Name            null.String
+	Size            null.Int
+	Content         null.String
+	ChunkCount      null.Int
+	Status          null.Int
+	Error           null.String
+	CreatedAt       null.Time
+	UpdatedAt       null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeDocumentN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeDocumentOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			return true
+		}
+		if inst.Name != inst.original.Name {
+			return true
+		}
+		if inst.Size != inst.original.Size {
+			return true
+		}
+		if inst.Content != inst.original.Content {
+			return true
+		}
+		if inst.ChunkCount != inst.original.ChunkCount {
+			return true
+		}
+		if inst.Status != inst.original.Status {
+			return true
+		}
+		if inst.Error != inst.original.Error {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					return true
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					return true
+				}
+			case "size":
+				if inst.Size != inst.original.Size {
+					return true
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					return true
+				}
+			case "chunk_count":
+				if inst.ChunkCount != inst.original.ChunkCount {
+					return true
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					return true
+				}
+			case "error":
+				if inst.Error != inst.original.Error {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeDocumentN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeDocumentOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			kv["knowledge_base_id"] = inst.KnowledgeBaseId
+		}
+		if inst.Name != inst.original.Name {
+			kv["name"] = inst.Name
+		}
+		if inst.Size != inst.original.Size {
+			kv["size"] = inst.Size
+		}
+		if inst.Content != inst.original.Content {
+			kv["content"] = inst.Content
+		}
+		if inst.ChunkCount != inst.original.ChunkCount {
+			kv["chunk_count"] = inst.ChunkCount
+		}
+		if inst.Status != inst.original.Status {
+			kv["status"] = inst.Status
+		}
+		if inst.Error != inst.original.Error {
+			kv["error"] = inst.Error
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					kv["knowledge_base_id"] = inst.KnowledgeBaseId
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					kv["name"] = inst.Name
+				}
+			case "size":
+				if inst.Size != inst.original.Size {
+					kv["size"] = inst.Size
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					kv["content"] = inst.Content
+				}
+			case "chunk_count":
+				if inst.ChunkCount != inst.original.ChunkCount {
+					kv["chunk_count"] = inst.ChunkCount
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					kv["status"] = inst.Status
+				}
+			case "error":
+				if inst.Error != inst.original.Error {
+					kv["error"] = inst.Error
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeDocumentN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeDocumentModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeDocumentModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_document
+func (inst *KnowledgeDocumentN) Delete(ctx context.Context) error {
+	if inst.knowledgeDocumentModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeDocumentModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeDocumentN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeDocumentScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeDocumentGlobalScopes = make([]knowledgeDocumentScope, 0)
+var knowledgeDocumentLocalScopes = make([]knowledgeDocumentScope, 0)
+
+// AddGlobalScopeForKnowledgeDocument assign a global scope to a model
+func AddGlobalScopeForKnowledgeDocument(name string, apply func(builder query.Condition)) {
+	knowledgeDocumentGlobalScopes = append(knowledgeDocumentGlobalScopes, knowledgeDocumentScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeDocument assign a local scope to a model
+func AddLocalScopeForKnowledgeDocument(name string, apply func(builder query.Condition)) {
+	knowledgeDocumentLocalScopes = append(knowledgeDocumentLocalScopes, knowledgeDocumentScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeDocumentModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeDocumentGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeDocumentLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeDocumentModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeDocumentModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeDocument struct {
+	Id              int64     `json:"id"`
+	UserId          int64     `json:"user_id"`
+	KnowledgeBaseId int64     `json:"knowledge_base_id"`
+	Name            string    `json:"name"`
+	Size            int64     `json:"size"`
+	Content         string    `json:"-"`
+	ChunkCount      int64     `json:"chunk_count"`
+	Status          int64     `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (w KnowledgeDocument) ToKnowledgeDocumentN(allows ...string) KnowledgeDocumentN {
+	if len(allows) == 0 {
+		return KnowledgeDocumentN{
+
+			Id:              null.IntFrom(int64(w.Id)),
+			UserId:          null.IntFrom(int64(w.UserId)),
+			KnowledgeBaseId: null.IntFrom(int64(w.KnowledgeBaseId)),
+			Name:            null.StringFrom(w.Name),
+			Size:            null.IntFrom(int64(w.Size)),
+			Content:         null.StringFrom(w.Content),
+			ChunkCount:      null.IntFrom(int64(w.ChunkCount)),
+			Status:          null.IntFrom(int64(w.Status)),
+			Error:           null.StringFrom(w.Error),
+			CreatedAt:       null.TimeFrom(w.CreatedAt),
+			UpdatedAt:       null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeDocumentN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "knowledge_base_id":
+			res.KnowledgeBaseId = null.IntFrom(int64(w.KnowledgeBaseId))
+		case "name":
+			res.Name = null.StringFrom(w.Name)
+		case "size":
+			res.Size = null.IntFrom(int64(w.Size))
+		case "content":
+			res.Content = null.StringFrom(w.Content)
+		case "chunk_count":
+			res.ChunkCount = null.IntFrom(int64(w.ChunkCount))
+		case "status":
+			res.Status = null.IntFrom(int64(w.Status))
+		case "error":
+			res.Error = null.StringFrom(w.Error)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeDocument) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeDocumentN) ToKnowledgeDocument() KnowledgeDocument {
+	return KnowledgeDocument{
+
+		Id:              w.Id.Int64,
+		UserId:          w.UserId.Int64,
+		KnowledgeBaseId: w.KnowledgeBaseId.Int64,
+		Name:            w.Name.String,
+		Size:            w.Size.Int64,
+		Content:         w.Content.String,
+		ChunkCount:      w.ChunkCount.Int64,
+		Status:          w.Status.Int64,
+		Error:           w.Error.String,
+		CreatedAt:       w.CreatedAt.Time,
+		UpdatedAt:       w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeDocumentModel is a model which encapsulates the operations of the object
+type KnowledgeDocumentModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeDocumentTableName = "knowledge_document"
+
+// KnowledgeDocumentTable return table name for KnowledgeDocument
+func KnowledgeDocumentTable() string {
+	return knowledgeDocumentTableName
+}
+
+const (
+	FieldKnowledgeDocumentId              = "id"
+	FieldKnowledgeDocumentUserId          = "user_id"
+	FieldKnowledgeDocumentKnowledgeBaseId = "knowledge_base_id"
+	FieldKnowledgeDocumentName            = "name"
+	FieldKnowledgeDocumentSize            = "size"
+	FieldKnowledgeDocumentContent         = "content"
+	FieldKnowledgeDocumentChunkCount      = "chunk_count"
+	FieldKnowledgeDocumentStatus          = "status"
+	FieldKnowledgeDocumentError           = "error"
+	FieldKnowledgeDocumentCreatedAt       = "created_at"
+	FieldKnowledgeDocumentUpdatedAt       = "updated_at"
+)
+
+// KnowledgeDocumentFields return all fields in KnowledgeDocument model
+func KnowledgeDocumentFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"knowledge_base_id",
+		"name",
+		"size",
+		"content",
+		"chunk_count",
+		"status",
+		"error",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeDocumentTable(tableName string) {
+	knowledgeDocumentTableName = tableName
+}
+
+// NewKnowledgeDocumentModel create a KnowledgeDocumentModel
+func NewKnowledgeDocumentModel(db query.Database) *KnowledgeDocumentModel {
+	return &KnowledgeDocumentModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeDocumentTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeDocumentModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeDocumentModel) clone() *KnowledgeDocumentModel {
+	return &KnowledgeDocumentModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeDocumentModel) WithoutGlobalScopes(names ...string) *KnowledgeDocumentModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeDocumentModel) WithLocalScopes(names ...string) *KnowledgeDocumentModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeDocumentModel) Condition(builder query.SQLBuilder) *KnowledgeDocumentModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeDocumentModel) Find(ctx context.Context, id int64) (*KnowledgeDocumentN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeDocumentModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeDocumentModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeDocumentModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeDocumentN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeDocumentModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeDocumentN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"user_id",
+			"knowledge_base_id",
+			"name",
+			"size",
+			"content",
+			"chunk_count",
+			"status",
+			"error",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "knowledge_base_id":
+			selectFields = append(selectFields, f)
+		case "name":
+			selectFields = append(selectFields, f)
+		case "size":
+			selectFields = append(selectFields, f)
+		case "content":
+			selectFields = append(selectFields, f)
+		case "chunk_count":
+			selectFields = append(selectFields, f)
+		case "status":
+			selectFields = append(selectFields, f)
+		case "error":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeDocumentN, []interface{}) {
+		var knowledgeDocumentVar KnowledgeDocumentN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Id)
+			case "user_id":
+				scanFields = append(scanFields, &knowledgeDocumentVar.UserId)
+			case "knowledge_base_id":
+				scanFields = append(scanFields, &knowledgeDocumentVar.KnowledgeBaseId)
+			case "name":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Name)
+			case "size":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Size)
+			case "content":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Content)
+			case "chunk_count":
+				scanFields = append(scanFields, &knowledgeDocumentVar.ChunkCount)
+			case "status":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Status)
+			case "error":
+				scanFields = append(scanFields, &knowledgeDocumentVar.Error)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeDocumentVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeDocumentVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeDocumentVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeDocuments := make([]KnowledgeDocumentN, 0)
+	for rows.Next() {
+		knowledgeDocumentReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeDocumentReal.original = &knowledgeDocumentOriginal{}
+		_ = query.Copy(knowledgeDocumentReal, knowledgeDocumentReal.original)
+
+		knowledgeDocumentReal.SetModel(m)
+		knowledgeDocuments = append(knowledgeDocuments, *knowledgeDocumentReal)
+	}
+
+	return knowledgeDocuments, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeDocumentModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeDocumentN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_document to database
+func (m *KnowledgeDocumentModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_documents to database
+func (m *KnowledgeDocumentModel) SaveAll(ctx context.Context, knowledgeDocuments []KnowledgeDocumentN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeDocument := range knowledgeDocuments {
+		id, err := m.Save(ctx, knowledgeDocument)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_document to database
+func (m *KnowledgeDocumentModel) Save(ctx context.Context, knowledgeDocument KnowledgeDocumentN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeDocument.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_document or update it when it has a id > 0
+func (m *KnowledgeDocumentModel) SaveOrUpdate(ctx context.Context, knowledgeDocument KnowledgeDocumentN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeDocument.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeDocument.Id.Int64, knowledgeDocument, onlyFields...)
+		return knowledgeDocument.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeDocument, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeDocumentModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeDocumentModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeDocument KnowledgeDocumentN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeDocument.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeDocumentModel) UpdateById(ctx context.Context, id int64, knowledgeDocument KnowledgeDocumentN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeDocument.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeDocumentModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeDocumentModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
+
+// KnowledgeChunkN is a KnowledgeChunk object, all fields are nullable
+type KnowledgeChunkN struct {
+	original            *knowledgeChunkOriginal
+	knowledgeChunkModel *KnowledgeChunkModel
+
+	Id              null.Int    `json:"id"`
+	KnowledgeBaseId null.Int    `json:"knowledge_base_id"`
+	DocumentId      null.Int    `json:"document_id"`
+	Seq             null.Int    `json:"seq"`
+	Content         null.String `json:"content"`
+	Embedding       null.String `json:"-"`
+	CreatedAt       null.Time   `json:"created_at"`
+	UpdatedAt       null.Time   `json:"updated_at"`
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeChunkN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeChunk
+func (inst *KnowledgeChunkN) SetModel(knowledgeChunkModel *KnowledgeChunkModel) {
+	inst.knowledgeChunkModel = knowledgeChunkModel
+}
+
+// knowledgeChunkOriginal is an object which stores original KnowledgeChunk from database
+type knowledgeChunkOriginal struct {
+	Id              null.Int
+	KnowledgeBaseId null.Int
+	DocumentId      null.Int
+	Seq             null.Int
+	Content         null.String
+	Embedding       null.String
+	CreatedAt       null.Time
+	UpdatedAt       null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeChunkN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeChunkOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			return true
+		}
+		if inst.DocumentId != inst.original.DocumentId {
+			return true
+		}
+		if inst.Seq != inst.original.Seq {
+			return true
+		}
+		if inst.Content != inst.original.Content {
+			return true
+		}
+		if inst.Embedding != inst.original.Embedding {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					return true
+				}
+			case "document_id":
+				if inst.DocumentId != inst.original.DocumentId {
+					return true
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					return true
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					return true
+				}
+			case "embedding":
+				if inst.Embedding != inst.original.Embedding {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeChunkN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeChunkOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			kv["knowledge_base_id"] = inst.KnowledgeBaseId
+		}
+		if inst.DocumentId != inst.original.DocumentId {
+			kv["document_id"] = inst.DocumentId
+		}
+		if inst.Seq != inst.original.Seq {
+			kv["seq"] = inst.Seq
+		}
+		if inst.Content != inst.original.Content {
+			kv["content"] = inst.Content
+		}
+		if inst.Embedding != inst.original.Embedding {
+			kv["embedding"] = inst.Embedding
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					kv["knowledge_base_id"] = inst.KnowledgeBaseId
+				}
+			case "document_id":
+				if inst.DocumentId != inst.original.DocumentId {
+					kv["document_id"] = inst.DocumentId
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					kv["seq"] = inst.Seq
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					kv["content"] = inst.Content
+				}
+			case "embedding":
+				if inst.Embedding != inst.original.Embedding {
+					kv["embedding"] = inst.Embedding
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeChunkN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeChunkModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeChunkModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_chunk
+func (inst *KnowledgeChunkN) Delete(ctx context.Context) error {
+	if inst.knowledgeChunkModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeChunkModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeChunkN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeChunkScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeChunkGlobalScopes = make([]knowledgeChunkScope, 0)
+var knowledgeChunkLocalScopes = make([]knowledgeChunkScope, 0)
+
+// AddGlobalScopeForKnowledgeChunk assign a global scope to a model
+func AddGlobalScopeForKnowledgeChunk(name string, apply func(builder query.Condition)) {
+	knowledgeChunkGlobalScopes = append(knowledgeChunkGlobalScopes, knowledgeChunkScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeChunk assign a local scope to a model
+func AddLocalScopeForKnowledgeChunk(name string, apply func(builder query.Condition)) {
+	knowledgeChunkLocalScopes = append(knowledgeChunkLocalScopes, knowledgeChunkScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeChunkModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeChunkGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeChunkLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeChunkModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeChunkModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeChunk struct {
+	Id              int64     `json:"id"`
+	KnowledgeBaseId int64     `json:"knowledge_base_id"`
+	DocumentId      int64     `json:"document_id"`
+	Seq             int64     `json:"seq"`
+	Content         string    `json:"content"`
+	Embedding       string    `json:"-"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (w KnowledgeChunk) ToKnowledgeChunkN(allows ...string) KnowledgeChunkN {
+	if len(allows) == 0 {
+		return KnowledgeChunkN{
+
+			Id:              null.IntFrom(int64(w.Id)),
+			KnowledgeBaseId: null.IntFrom(int64(w.KnowledgeBaseId)),
+			DocumentId:      null.IntFrom(int64(w.DocumentId)),
+			Seq:             null.IntFrom(int64(w.Seq)),
+			Content:         null.StringFrom(w.Content),
+			Embedding:       null.StringFrom(w.Embedding),
+			CreatedAt:       null.TimeFrom(w.CreatedAt),
+			UpdatedAt:       null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeChunkN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "knowledge_base_id":
+			res.KnowledgeBaseId = null.IntFrom(int64(w.KnowledgeBaseId))
+		case "document_id":
+			res.DocumentId = null.IntFrom(int64(w.DocumentId))
+		case "seq":
+			res.Seq = null.IntFrom(int64(w.Seq))
+		case "content":
+			res.Content = null.StringFrom(w.Content)
+		case "embedding":
+			res.Embedding = null.StringFrom(w.Embedding)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeChunk) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeChunkN) ToKnowledgeChunk() KnowledgeChunk {
+	return KnowledgeChunk{
+
+		Id:              w.Id.Int64,
+		KnowledgeBaseId: w.KnowledgeBaseId.Int64,
+		DocumentId:      w.DocumentId.Int64,
+		Seq:             w.Seq.Int64,
+		Content:         w.Content.String,
+		Embedding:       w.Embedding.String,
+		CreatedAt:       w.CreatedAt.Time,
+		UpdatedAt:       w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeChunkModel is a model which encapsulates the operations of the object
+type KnowledgeChunkModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeChunkTableName = "knowledge_chunk"
+
+// KnowledgeChunkTable return table name for KnowledgeChunk
+func KnowledgeChunkTable() string {
+	return knowledgeChunkTableName
+}
+
+const (
+	FieldKnowledgeChunkId              = "id"
+	FieldKnowledgeChunkKnowledgeBaseId = "knowledge_base_id"
+	FieldKnowledgeChunkDocumentId      = "document_id"
+	FieldKnowledgeChunkSeq             = "seq"
+	FieldKnowledgeChunkContent         = "content"
+	FieldKnowledgeChunkEmbedding       = "embedding"
+	FieldKnowledgeChunkCreatedAt       = "created_at"
+	FieldKnowledgeChunkUpdatedAt       = "updated_at"
+)
+
+// KnowledgeChunkFields return all fields in KnowledgeChunk model
+func KnowledgeChunkFields() []string {
+	return []string{
+		"id",
+		"knowledge_base_id",
+		"document_id",
+		"seq",
+		"content",
+		"embedding",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeChunkTable(tableName string) {
+	knowledgeChunkTableName = tableName
+}
+
+// NewKnowledgeChunkModel create a KnowledgeChunkModel
+func NewKnowledgeChunkModel(db query.Database) *KnowledgeChunkModel {
+	return &KnowledgeChunkModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeChunkTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeChunkModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeChunkModel) clone() *KnowledgeChunkModel {
+	return &KnowledgeChunkModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeChunkModel) WithoutGlobalScopes(names ...string) *KnowledgeChunkModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeChunkModel) WithLocalScopes(names ...string) *KnowledgeChunkModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeChunkModel) Condition(builder query.SQLBuilder) *KnowledgeChunkModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeChunkModel) Find(ctx context.Context, id int64) (*KnowledgeChunkN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeChunkModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeChunkModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeChunkModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeChunkN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeChunkModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeChunkN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"knowledge_base_id",
+			"document_id",
+			"seq",
+			"content",
+			"embedding",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "knowledge_base_id":
+			selectFields = append(selectFields, f)
+		case "document_id":
+			selectFields = append(selectFields, f)
+		case "seq":
+			selectFields = append(selectFields, f)
+		case "content":
+			selectFields = append(selectFields, f)
+		case "embedding":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeChunkN, []interface{}) {
+		var knowledgeChunkVar KnowledgeChunkN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeChunkVar.Id)
+			case "knowledge_base_id":
+				scanFields = append(scanFields, &knowledgeChunkVar.KnowledgeBaseId)
+			case "document_id":
+				scanFields = append(scanFields, &knowledgeChunkVar.DocumentId)
+			case "seq":
+				scanFields = append(scanFields, &knowledgeChunkVar.Seq)
+			case "content":
+				scanFields = append(scanFields, &knowledgeChunkVar.Content)
+			case "embedding":
+				scanFields = append(scanFields, &knowledgeChunkVar.Embedding)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeChunkVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeChunkVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeChunkVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeChunks := make([]KnowledgeChunkN, 0)
+	for rows.Next() {
+		knowledgeChunkReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeChunkReal.original = &knowledgeChunkOriginal{}
+		_ = query.Copy(knowledgeChunkReal, knowledgeChunkReal.original)
+
+		knowledgeChunkReal.SetModel(m)
+		knowledgeChunks = append(knowledgeChunks, *knowledgeChunkReal)
+	}
+
+	return knowledgeChunks, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeChunkModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeChunkN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_chunk to database
+func (m *KnowledgeChunkModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_chunks to database
+func (m *KnowledgeChunkModel) SaveAll(ctx context.Context, knowledgeChunks []KnowledgeChunkN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeChunk := range knowledgeChunks {
+		id, err := m.Save(ctx, knowledgeChunk)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_chunk to database
+func (m *KnowledgeChunkModel) Save(ctx context.Context, knowledgeChunk KnowledgeChunkN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeChunk.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_chunk or update it when it has a id > 0
+func (m *KnowledgeChunkModel) SaveOrUpdate(ctx context.Context, knowledgeChunk KnowledgeChunkN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeChunk.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeChunk.Id.Int64, knowledgeChunk, onlyFields...)
+		return knowledgeChunk.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeChunk, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeChunkModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeChunkModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeChunk KnowledgeChunkN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeChunk.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeChunkModel) UpdateById(ctx context.Context, id int64, knowledgeChunk KnowledgeChunkN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeChunk.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeChunkModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeChunkModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -31,6 +31,7 @@ type ChatMessagesN struct {
 	Model         null.String `json:"model,omitempty"`
 	Status        null.Int    `json:"status,omitempty"`
 	Error         null.String `json:"error,omitempty"`
+	Images        null.String `json:"images,omitempty"`
 	CreatedAt     null.Time
 	UpdatedAt     null.Time
 }
@@ -59,6 +60,7 @@ type chatMessagesOriginal struct {
 	Model         null.String
 	Status        null.Int
 	Error         null.String
+	Images        null.String
 	CreatedAt     null.Time
 	UpdatedAt     null.Time
 }
@@ -104,6 +106,9 @@ func (inst *ChatMessagesN) Staled(onlyFields ...string) bool {
 		if inst.Error != inst.original.Error {
 			return true
 		}
+		if inst.Images != inst.original.Images {
+			return true
+		}
 		if inst.CreatedAt != inst.original.CreatedAt {
 			return true
 		}
@@ -158,6 +163,10 @@ func (inst *ChatMessagesN) Staled(onlyFields ...string) bool {
 				if inst.Error != inst.original.Error {
 					return true
 				}
+			case "images":
+				if inst.Images != inst.original.Images {
+					return true
+				}
 			case "created_at":
 				if inst.CreatedAt != inst.original.CreatedAt {
 					return true
@@ -217,6 +226,9 @@ func (inst *ChatMessagesN) StaledKV(onlyFields ...string) query.KV {
 		if inst.Error != inst.original.Error {
 			kv["error"] = inst.Error
 		}
+		if inst.Images != inst.original.Images {
+			kv["images"] = inst.Images
+		}
 		if inst.CreatedAt != inst.original.CreatedAt {
 			kv["created_at"] = inst.CreatedAt
 		}
@@ -271,6 +283,10 @@ func (inst *ChatMessagesN) StaledKV(onlyFields ...string) query.KV {
 				if inst.Error != inst.original.Error {
 					kv["error"] = inst.Error
 				}
+			case "images":
+				if inst.Images != inst.original.Images {
+					kv["images"] = inst.Images
+				}
 			case "created_at":
 				if inst.CreatedAt != inst.original.CreatedAt {
 					kv["created_at"] = inst.CreatedAt
@@ -389,6 +405,7 @@ type ChatMessages struct {
 	Model         string `json:"model,omitempty"`
 	Status        int64  `json:"status,omitempty"`
 	Error         string `json:"error,omitempty"`
+	Images        string `json:"images,omitempty"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -408,6 +425,7 @@ func (w ChatMessages) ToChatMessagesN(allows ...string) ChatMessagesN {
 			Model:         null.StringFrom(w.Model),
 			Status:        null.IntFrom(int64(w.Status)),
 			Error:         null.StringFrom(w.Error),
+			Images:        null.StringFrom(w.Images),
 			CreatedAt:     null.TimeFrom(w.CreatedAt),
 			UpdatedAt:     null.TimeFrom(w.UpdatedAt),
 		}
@@ -439,6 +457,8 @@ func (w ChatMessages) ToChatMessagesN(allows ...string) ChatMessagesN {
 			res.Status = null.IntFrom(int64(w.Status))
 		case "error":
 			res.Error = null.StringFrom(w.Error)
+		case "images":
+			res.Images = null.StringFrom(w.Images)
 		case "created_at":
 			res.CreatedAt = null.TimeFrom(w.CreatedAt)
 		case "updated_at":
@@ -470,6 +490,7 @@ func (w *ChatMessagesN) ToChatMessages() ChatMessages {
 		Model:         w.Model.String,
 		Status:        w.Status.Int64,
 		Error:         w.Error.String,
+		Images:        w.Images.String,
 		CreatedAt:     w.CreatedAt.Time,
 		UpdatedAt:     w.UpdatedAt.Time,
 	}
@@ -505,6 +526,7 @@ const (
 	FieldChatMessagesModel         = "model"
 	FieldChatMessagesStatus        = "status"
 	FieldChatMessagesError         = "error"
+	FieldChatMessagesImages        = "images"
 	FieldChatMessagesCreatedAt     = "created_at"
 	FieldChatMessagesUpdatedAt     = "updated_at"
 )
@@ -523,6 +545,7 @@ func ChatMessagesFields() []string {
 		"model",
 		"status",
 		"error",
+		"images",
 		"created_at",
 		"updated_at",
 	}
@@ -666,6 +689,7 @@ func (m *ChatMessagesModel) Get(ctx context.Context, builders ...query.SQLBuilde
 			"model",
 			"status",
 			"error",
+			"images",
 			"created_at",
 			"updated_at",
 		)
@@ -699,6 +723,8 @@ func (m *ChatMessagesModel) Get(ctx context.Context, builders ...query.SQLBuilde
 			selectFields = append(selectFields, f)
 		case "error":
 			selectFields = append(selectFields, f)
+		case "images":
+			selectFields = append(selectFields, f)
 		case "created_at":
 			selectFields = append(selectFields, f)
 		case "updated_at":
@@ -735,6 +761,8 @@ func (m *ChatMessagesModel) Get(ctx context.Context, builders ...query.SQLBuilde
 				scanFields = append(scanFields, &chatMessagesVar.Status)
 			case "error":
 				scanFields = append(scanFields, &chatMessagesVar.Error)
+			case "images":
+				scanFields = append(scanFields, &chatMessagesVar.Images)
 			case "created_at":
 				scanFields = append(scanFields, &chatMessagesVar.CreatedAt)
 			case "updated_at":
@@ -20,22 +20,25 @@ type RoomsN struct {
 	original   *roomsOriginal
 	roomsModel *RoomsModel
 
-	Id             null.Int    `json:"id"`
-	UserId         null.Int    `json:"user_id"`
-	AvatarId       null.Int    `json:"avatar_id,omitempty"`
-	AvatarUrl      null.String `json:"avatar_url,omitempty"`
-	Name           null.String `json:"name,omitempty"`
-	Description    null.String `json:"description,omitempty"`
-	Priority       null.Int    `json:"priority,omitempty"`
-	Model          null.String `json:"model,omitempty"`
-	Vendor         null.String `json:"vendor,omitempty"`
-	SystemPrompt   null.String `json:"system_prompt,omitempty"`
-	MaxContext     null.Int    `json:"max_context,omitempty"`
-	RoomType       null.Int    `json:"room_type,omitempty"`
-	InitMessage    null.String `json:"init_message,omitempty"`
-	LastActiveTime null.Time   `json:"last_active_time,omitempty"`
-	CreatedAt      null.Time
-	UpdatedAt      null.Time
+	Id              null.Int    `json:"id"`
+	UserId          null.Int    `json:"user_id"`
+	AvatarId        null.Int    `json:"avatar_id,omitempty"`
+	AvatarUrl       null.String `json:"avatar_url,omitempty"`
+	Name            null.String `json:"name,omitempty"`
+	Description     null.String `json:"description,omitempty"`
+	Priority        null.Int    `json:"priority,omitempty"`
+	Model           null.String `json:"model,omitempty"`
+	Vendor          null.String `json:"vendor,omitempty"`
+	SystemPrompt    null.String `json:"system_prompt,omitempty"`
+	MaxContext      null.Int    `json:"max_context,omitempty"`
+	RoomType        null.Int    `json:"room_type,omitempty"`
+	InitMessage     null.String `json:"init_message,omitempty"`
+	Sampling        null.String `json:"sampling,omitempty"`
+	KnowledgeBaseId null.Int    `json:"knowledge_base_id,omitempty"`
+	WebSearch       null.Int    `json:"web_search,omitempty"`
+	LastActiveTime  null.Time   `json:"last_active_time,omitempty"`
+	CreatedAt       null.Time
+	UpdatedAt       null.Time
 }
 
 // As convert object to other type
@@ -51,22 +54,25 @@ func (inst *RoomsN) SetModel(roomsModel *RoomsModel) {
 
 // roomsOriginal is an object which stores original Rooms from database
 type roomsOriginal struct {
-	Id             null.Int
-	UserId         null.Int
-	AvatarId       null.Int
-	AvatarUrl      null.String
-	Name           null.String
-	Description    null.String
-	Priority       null.Int
-	Model          null.String
-	Vendor         null.String
-	SystemPrompt   null.String
-	MaxContext     null.Int
-	RoomType       null.Int
-	InitMessage    null.String
-	LastActiveTime null.Time
-	CreatedAt      null.Time
-	UpdatedAt      null.Time
+	Id              null.Int
+	UserId          null.Int
+	AvatarId        null.Int
+	AvatarUrl       null.String
+	Name            null.String
+	Description     null.String
+	Priority        null.Int
+	Model           null.String
+	Vendor          null.String
+	SystemPrompt    null.String
+	MaxContext      null.Int
+	RoomType        null.Int
+	InitMessage     null.String
+	Sampling        null.String
+	KnowledgeBaseId null.Int
+	WebSearch       null.Int
+	LastActiveTime  null.Time
+	CreatedAt       null.Time
+	UpdatedAt       null.Time
 }
 
 // Staled identify whether the object has been modified
@@ -116,6 +122,15 @@ func (inst *RoomsN) Staled(onlyFields ...string) bool {
 		if inst.InitMessage != inst.original.InitMessage {
 			return true
 		}
+		if inst.Sampling != inst.original.Sampling {
+			return true
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			return true
+		}
+		if inst.WebSearch != inst.original.WebSearch {
+			return true
+		}
 		if inst.LastActiveTime != inst.original.LastActiveTime {
 			return true
 		}
@@ -181,6 +196,18 @@ func (inst *RoomsN) Staled(onlyFields ...string) bool {
 				if inst.InitMessage != inst.original.InitMessage {
 					return true
 				}
+			case "sampling":
+				if inst.Sampling != inst.original.Sampling {
+					return true
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					return true
+				}
+			case "web_search":
+				if inst.WebSearch != inst.original.WebSearch {
+					return true
+				}
 			case "last_active_time":
 				if inst.LastActiveTime != inst.original.LastActiveTime {
 					return true
@@ -250,6 +277,15 @@ func (inst *RoomsN) StaledKV(onlyFields ...string) query.KV {
 		if inst.InitMessage != inst.original.InitMessage {
 			kv["init_message"] = inst.InitMessage
 		}
+		if inst.Sampling != inst.original.Sampling {
+			kv["sampling"] = inst.Sampling
+		}
+		if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+			kv["knowledge_base_id"] = inst.KnowledgeBaseId
+		}
+		if inst.WebSearch != inst.original.WebSearch {
+			kv["web_search"] = inst.WebSearch
+		}
 		if inst.LastActiveTime != inst.original.LastActiveTime {
 			kv["last_active_time"] = inst.LastActiveTime
 		}
@@ -315,6 +351,18 @@ func (inst *RoomsN) StaledKV(onlyFields ...string) query.KV {
 				if inst.InitMessage != inst.original.InitMessage {
 					kv["init_message"] = inst.InitMessage
 				}
+			case "sampling":
+				if inst.Sampling != inst.original.Sampling {
+					kv["sampling"] = inst.Sampling
+				}
+			case "knowledge_base_id":
+				if inst.KnowledgeBaseId != inst.original.KnowledgeBaseId {
+					kv["knowledge_base_id"] = inst.KnowledgeBaseId
+				}
+			case "web_search":
+				if inst.WebSearch != inst.original.WebSearch {
+					kv["web_search"] = inst.WebSearch
+				}
 			case "last_active_time":
 				if inst.LastActiveTime != inst.original.LastActiveTime {
 					kv["last_active_time"] = inst.LastActiveTime
@@ -426,44 +474,50 @@ func (m *RoomsModel) globalScopeEnabled(name string) bool {
 }
 
 type Rooms struct {
-	Id             int64     `json:"id"`
-	UserId         int64     `json:"user_id"`
-	AvatarId       int64     `json:"avatar_id,omitempty"`
-	AvatarUrl      string    `json:"avatar_url,omitempty"`
-	Name           string    `json:"name,omitempty"`
-	Description    string    `json:"description,omitempty"`
-	Priority       int64     `json:"priority,omitempty"`
-	Model          string    `json:"model,omitempty"`
-	Vendor         string    `json:"vendor,omitempty"`
-	SystemPrompt   string    `json:"system_prompt,omitempty"`
-	MaxContext     int64     `json:"max_context,omitempty"`
-	RoomType       int64     `json:"room_type,omitempty"`
-	InitMessage    string    `json:"init_message,omitempty"`
-	LastActiveTime time.Time `json:"last_active_time,omitempty"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	Id              int64     `json:"id"`
+	UserId          int64     `json:"user_id"`
+	AvatarId        int64     `json:"avatar_id,omitempty"`
+	AvatarUrl       string    `json:"avatar_url,omitempty"`
+	Name            string    `json:"name,omitempty"`
+	Description     string    `json:"description,omitempty"`
+	Priority        int64     `json:"priority,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	Vendor          string    `json:"vendor,omitempty"`
+	SystemPrompt    string    `json:"system_prompt,omitempty"`
+	MaxContext      int64     `json:"max_context,omitempty"`
+	RoomType        int64     `json:"room_type,omitempty"`
+	InitMessage     string    `json:"init_message,omitempty"`
+	Sampling        string    `json:"sampling,omitempty"`
+	KnowledgeBaseId int64     `json:"knowledge_base_id,omitempty"`
+	WebSearch       int64     `json:"web_search,omitempty"`
+	LastActiveTime  time.Time `json:"last_active_time,omitempty"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 func (w Rooms) ToRoomsN(allows ...string) RoomsN {
 	if len(allows) == 0 {
 		return RoomsN{
 
-			Id:             null.IntFrom(int64(w.Id)),
-			UserId:         null.IntFrom(int64(w.UserId)),
-			AvatarId:       null.IntFrom(int64(w.AvatarId)),
-			AvatarUrl:      null.StringFrom(w.AvatarUrl),
-			Name:           null.StringFrom(w.Name),
-			Description:    null.StringFrom(w.Description),
-			Priority:       null.IntFrom(int64(w.Priority)),
-			Model:          null.StringFrom(w.Model),
-			Vendor:         null.StringFrom(w.Vendor),
-			SystemPrompt:   null.StringFrom(w.SystemPrompt),
-			MaxContext:     null.IntFrom(int64(w.MaxContext)),
-			RoomType:       null.IntFrom(int64(w.RoomType)),
-			InitMessage:    null.StringFrom(w.InitMessage),
-			LastActiveTime: null.TimeFrom(w.LastActiveTime),
-			CreatedAt:      null.TimeFrom(w.CreatedAt),
-			UpdatedAt:      null.TimeFrom(w.UpdatedAt),
+			Id:              null.IntFrom(int64(w.Id)),
+			UserId:          null.IntFrom(int64(w.UserId)),
+			AvatarId:        null.IntFrom(int64(w.AvatarId)),
+			AvatarUrl:       null.StringFrom(w.AvatarUrl),
+			Name:            null.StringFrom(w.Name),
+			Description:     null.StringFrom(w.Description),
+			Priority:        null.IntFrom(int64(w.Priority)),
+			Model:           null.StringFrom(w.Model),
+			Vendor:          null.StringFrom(w.Vendor),
+			SystemPrompt:    null.StringFrom(w.SystemPrompt),
+			MaxContext:      null.IntFrom(int64(w.MaxContext)),
+			RoomType:        null.IntFrom(int64(w.RoomType)),
+			InitMessage:     null.StringFrom(w.InitMessage),
+			Sampling:        null.StringFrom(w.Sampling),
+			KnowledgeBaseId: null.IntFrom(int64(w.KnowledgeBaseId)),
+			WebSearch:       null.IntFrom(int64(w.WebSearch)),
+			LastActiveTime:  null.TimeFrom(w.LastActiveTime),
+			CreatedAt:       null.TimeFrom(w.CreatedAt),
+			UpdatedAt:       null.TimeFrom(w.UpdatedAt),
 		}
 	}
 
@@ -497,6 +551,12 @@ func (w Rooms) ToRoomsN(allows ...string) RoomsN {
 			res.RoomType = null.IntFrom(int64(w.RoomType))
 		case "init_message":
 			res.InitMessage = null.StringFrom(w.InitMessage)
+		case "sampling":
+			res.Sampling = null.StringFrom(w.Sampling)
+		case "knowledge_base_id":
+			res.KnowledgeBaseId = null.IntFrom(int64(w.KnowledgeBaseId))
+		case "web_search":
+			res.WebSearch = null.IntFrom(int64(w.WebSearch))
 		case "last_active_time":
 			res.LastActiveTime = null.TimeFrom(w.LastActiveTime)
 		case "created_at":
@@ -519,22 +579,25 @@ func (w Rooms) As(dst interface{}) error {
 func (w *RoomsN) ToRooms() Rooms {
 	return Rooms{
 
-		Id:             w.Id.Int64,
-		UserId:         w.UserId.Int64,
-		AvatarId:       w.AvatarId.Int64,
-		AvatarUrl:      w.AvatarUrl.String,
-		Name:           w.Name.String,
-		Description:    w.Description.String,
-		Priority:       w.Priority.Int64,
-		Model:          w.Model.String,
-		Vendor:         w.Vendor.String,
-		SystemPrompt:   w.SystemPrompt.String,
-		MaxContext:     w.MaxContext.Int64,
-		RoomType:       w.RoomType.Int64,
-		InitMessage:    w.InitMessage.String,
-		LastActiveTime: w.LastActiveTime.Time,
-		CreatedAt:      w.CreatedAt.Time,
-		UpdatedAt:      w.UpdatedAt.Time,
+		Id:              w.Id.Int64,
+		UserId:          w.UserId.Int64,
+		AvatarId:        w.AvatarId.Int64,
+		AvatarUrl:       w.AvatarUrl.String,
+		Name:            w.Name.String,
+		Description:     w.Description.String,
+		Priority:        w.Priority.Int64,
+		Model:           w.Model.String,
+		Vendor:          w.Vendor.String,
+		SystemPrompt:    w.SystemPrompt.String,
+		MaxContext:      w.MaxContext.Int64,
+		RoomType:        w.RoomType.Int64,
+		InitMessage:     w.InitMessage.String,
+		Sampling:        w.Sampling.String,
+		KnowledgeBaseId: w.KnowledgeBaseId.Int64,
+		WebSearch:       w.WebSearch.Int64,
+		LastActiveTime:  w.LastActiveTime.Time,
+		CreatedAt:       w.CreatedAt.Time,
+		UpdatedAt:       w.UpdatedAt.Time,
 	}
 }
 
@@ -557,22 +620,25 @@ func RoomsTable() string {
 }
 
 const (
-	FieldRoomsId             = "id"
-	FieldRoomsUserId         = "user_id"
-	FieldRoomsAvatarId       = "avatar_id"
-	FieldRoomsAvatarUrl      = "avatar_url"
-	FieldRoomsName           = "name"
-	FieldRoomsDescription    = "description"
-	FieldRoomsPriority       = "priority"
-	FieldRoomsModel          = "model"
-	FieldRoomsVendor         = "vendor"
-	FieldRoomsSystemPrompt   = "system_prompt"
-	FieldRoomsMaxContext     = "max_context"
-	FieldRoomsRoomType       = "room_type"
-	FieldRoomsInitMessage    = "init_message"
-	FieldRoomsLastActiveTime = "last_active_time"
-	FieldRoomsCreatedAt      = "created_at"
-	FieldRoomsUpdatedAt      = "updated_at"
+	FieldRoomsId              = "id"
+	FieldRoomsUserId          = "user_id"
+	FieldRoomsAvatarId        = "avatar_id"
+	FieldRoomsAvatarUrl       = "avatar_url"
+	FieldRoomsName            = "name"
+	FieldRoomsDescription     = "description"
+	FieldRoomsPriority        = "priority"
+	FieldRoomsModel           = "model"
+	FieldRoomsVendor          = "vendor"
+	FieldRoomsSystemPrompt    = "system_prompt"
+	FieldRoomsMaxContext      = "max_context"
+	FieldRoomsRoomType        = "room_type"
+	FieldRoomsInitMessage     = "init_message"
+	FieldRoomsSampling        = "sampling"
+	FieldRoomsKnowledgeBaseId = "knowledge_base_id"
+	FieldRoomsWebSearch       = "web_search"
+	FieldRoomsLastActiveTime  = "last_active_time"
+	FieldRoomsCreatedAt       = "created_at"
+	FieldRoomsUpdatedAt       = "updated_at"
 )
 
 // RoomsFields return all fields in Rooms model
@@ -591,6 +657,9 @@ func RoomsFields() []string {
 		"max_context",
 		"room_type",
 		"init_message",
+		"sampling",
+		"knowledge_base_id",
+		"web_search",
 		"last_active_time",
 		"created_at",
 		"updated_at",
@@ -737,6 +806,9 @@ func (m *RoomsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]R
 			"max_context",
 			"room_type",
 			"init_message",
+			"sampling",
+			"knowledge_base_id",
+			"web_search",
 			"last_active_time",
 			"created_at",
 			"updated_at",
@@ -775,6 +847,12 @@ func (m *RoomsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]R
 			selectFields = append(selectFields, f)
 		case "init_message":
 			selectFields = append(selectFields, f)
+		case "sampling":
+			selectFields = append(selectFields, f)
+		case "knowledge_base_id":
+			selectFields = append(selectFields, f)
+		case "web_search":
+			selectFields = append(selectFields, f)
 		case "last_active_time":
 			selectFields = append(selectFields, f)
 		case "created_at":
@@ -817,6 +895,12 @@ func (m *RoomsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]R
 				scanFields = append(scanFields, &roomsVar.RoomType)
 			case "init_message":
 				scanFields = append(scanFields, &roomsVar.InitMessage)
+			case "sampling":
+				scanFields = append(scanFields, &roomsVar.Sampling)
+			case "knowledge_base_id":
+				scanFields = append(scanFields, &roomsVar.KnowledgeBaseId)
+			case "web_search":
+				scanFields = append(scanFields, &roomsVar.WebSearch)
 			case "last_active_time":
 				scanFields = append(scanFields, &roomsVar.LastActiveTime)
 			case "created_at":
@@ -38,8 +38,12 @@ func (Provider) Register(binder infra.Binder) {
 	binder.MustSingleton(NewPromptRepo)
 	binder.MustSingleton(NewChatGroupRepo)
 	binder.MustSingleton(NewFileStorageRepo)
+	binder.MustSingleton(NewSearchRepo)
 	binder.MustSingleton(NewArticleRepo)
 	binder.MustSingleton(NewNotificationRepo)
+	binder.MustSingleton(NewKnowledgeRepo)
+	binder.MustSingleton(NewCatalogRepo)
+	binder.MustSingleton(NewComparisonRepo)
 
 	// MySQL 数据库连接
 	binder.MustSingleton(func(conf *config.Config) (*sql.DB, error) {
@@ -100,4 +104,8 @@ type Repository struct {
 	FileStorage  *FileStorageRepo  `autowire:"@"`
 	Notification *NotificationRepo `autowire:"@"`
 	Article      *ArticleRepo      `autowire:"@"`
+	Search       *SearchRepo       `autowire:"@"`
+	Knowledge    *KnowledgeRepo    `autowire:"@"`
+	Catalog      *CatalogRepo      `autowire:"@"`
+	Comparison   *ComparisonRepo   `autowire:"@"`
 }
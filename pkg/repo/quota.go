@@ -147,6 +147,22 @@ func (repo *QuotaRepo) GetUserQuota(ctx context.Context, userID int64) (*QuotaSu
 type QuotaUsedMeta struct {
 	Models []string `json:"models"`
 	Tag    string   `json:"tag"`
+	// Usage 文本模型的 Token 用量，用于计费审计
+	Usage *QuotaTokenUsage `json:"usage,omitempty"`
+}
+
+// QuotaTokenUsage 文本模型的 Token 用量
+type QuotaTokenUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	// Estimated 服务商未上报用量，使用分词器估算
+	Estimated bool `json:"estimated,omitempty"`
+	// ReportedInputTokens/ReportedOutputTokens 服务商上报的用量，未上报时为 0
+	ReportedInputTokens  int64 `json:"reported_input_tokens,omitempty"`
+	ReportedOutputTokens int64 `json:"reported_output_tokens,omitempty"`
+	// EstimatedInputTokens/EstimatedOutputTokens 分词器估算的用量
+	EstimatedInputTokens  int64 `json:"estimated_input_tokens,omitempty"`
+	EstimatedOutputTokens int64 `json:"estimated_output_tokens,omitempty"`
 }
 
 func NewQuotaUsedMeta(tag string, models ...string) QuotaUsedMeta {
@@ -156,6 +172,30 @@ func NewQuotaUsedMeta(tag string, models ...string) QuotaUsedMeta {
 	}
 }
 
+// WithUsage 记录本次消耗对应的 Token 用量，同时保存服务商上报的用量和分词器估算的用量
+// 计费使用服务商上报的用量，未上报时使用估算值
+func (meta QuotaUsedMeta) WithUsage(reportedInput, reportedOutput, estimatedInput, estimatedOutput int64) QuotaUsedMeta {
+	usage := &QuotaTokenUsage{
+		InputTokens:           reportedInput,
+		OutputTokens:          reportedOutput,
+		ReportedInputTokens:   reportedInput,
+		ReportedOutputTokens:  reportedOutput,
+		EstimatedInputTokens:  estimatedInput,
+		EstimatedOutputTokens: estimatedOutput,
+	}
+
+	if usage.InputTokens <= 0 {
+		usage.InputTokens, usage.Estimated = estimatedInput, true
+	}
+
+	if usage.OutputTokens <= 0 && estimatedOutput > 0 {
+		usage.OutputTokens, usage.Estimated = estimatedOutput, true
+	}
+
+	meta.Usage = usage
+	return meta
+}
+
 // QuotaConsume 更新用户配额已使用量
 func (repo *QuotaRepo) QuotaConsume(ctx context.Context, userID int64, used int64, meta QuotaUsedMeta) error {
 	relatedQuotaIds := make(map[int64]int64)
@@ -31,6 +31,9 @@ const (
 	RoomTypeGroupChat = 4
 )
 
+// RoomDefaultName 创建数字人时未指定名称使用的默认名称，开启自动标题后，首次对话完成时会替换为生成的标题
+const RoomDefaultName = "新对话"
+
 type RoomRepo struct {
 	db *sql.DB
 }
@@ -156,6 +159,9 @@ func (r *RoomRepo) Create(ctx context.Context, userID int64, room *model.Rooms,
 		model.FieldRoomsMaxContext,
 		model.FieldRoomsRoomType,
 		model.FieldRoomsInitMessage,
+		model.FieldRoomsSampling,
+		model.FieldRoomsKnowledgeBaseId,
+		model.FieldRoomsWebSearch,
 	)
 
 	id, err = model.NewRoomsModel(r.db).Save(ctx, roomN)
@@ -189,11 +195,25 @@ func (r *RoomRepo) Update(ctx context.Context, userID, roomID int64, room *model
 		model.FieldRoomsMaxContext,
 		model.FieldRoomsRoomType,
 		model.FieldRoomsInitMessage,
+		model.FieldRoomsSampling,
+		model.FieldRoomsKnowledgeBaseId,
+		model.FieldRoomsWebSearch,
 	))
 
 	return err
 }
 
+// UpdateDefaultName 数字人名称为空或者为默认名称时更新名称，不会覆盖用户设置的名称，返回是否更新成功
+func (r *RoomRepo) UpdateDefaultName(ctx context.Context, userID, roomID int64, name string) (bool, error) {
+	q := query.Builder().
+		Where(model.FieldRoomsUserId, userID).
+		Where(model.FieldRoomsId, roomID).
+		WhereIn(model.FieldRoomsName, []string{"", RoomDefaultName})
+
+	affected, err := model.NewRoomsModel(r.db).UpdateFields(ctx, query.KV{model.FieldRoomsName: name}, q)
+	return affected > 0, err
+}
+
 func (r *RoomRepo) UpdateLastActiveTime(ctx context.Context, userID, roomID int64) error {
 	q := query.Builder().
 		Where(model.FieldRoomsUserId, userID).
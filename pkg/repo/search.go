@@ -0,0 +1,256 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+)
+
+type SearchRepo struct {
+	db *sql.DB
+}
+
+func NewSearchRepo(db *sql.DB) *SearchRepo {
+	return &SearchRepo{db: db}
+}
+
+// SearchSource 搜索的数据来源
+type SearchSource string
+
+const (
+	// SearchSourceChat 数字人聊天记录
+	SearchSourceChat SearchSource = "chat"
+	// SearchSourceGroupChat 群聊聊天记录
+	SearchSourceGroupChat SearchSource = "group_chat"
+	// SearchSourceCreative 创作岛历史记录（提示语）
+	SearchSourceCreative SearchSource = "creative"
+)
+
+// ngramTokenSize MySQL ngram 分词器的分词长度（ngram_token_size 默认值），短于该长度的关键词无法使用全文索引
+const ngramTokenSize = 2
+
+// searchSnippetLength 搜索结果中高亮片段的最大长度
+const searchSnippetLength = 120
+
+// SearchReq 搜索请求
+type SearchReq struct {
+	// Keyword 搜索关键词，多个关键词使用空白字符分隔，结果需要包含所有关键词
+	Keyword string
+	// Source 搜索的数据来源
+	Source SearchSource
+	// RoomID 数字人 ID（聊天记录）或者群组 ID（群聊记录），创作岛历史记录不支持
+	RoomID int64
+	// Model 模型 ID，创作岛历史记录为创作岛模型
+	Model string
+	// StartTime/EndTime 创建时间范围
+	StartTime time.Time
+	EndTime   time.Time
+	// StartID 分页游标，只返回 ID 小于该值的记录
+	StartID int64
+	PerPage int64
+	// HighlightPre/HighlightPost 高亮片段中关键词的前后标记
+	HighlightPre  string
+	HighlightPost string
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	ID     int64        `json:"id"`
+	Source SearchSource `json:"source"`
+	// RoomID 数字人 ID 或者群组 ID
+	RoomID int64 `json:"room_id,omitempty"`
+	// IslandID 创作岛 ID
+	IslandID string `json:"island_id,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Role     int64  `json:"role,omitempty"`
+	// Snippet 包含关键词的高亮片段
+	Snippet   string    `json:"snippet"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Search 搜索用户的聊天记录和创作岛历史记录，按照 ID 倒序排列
+func (r *SearchRepo) Search(ctx context.Context, userID int64, req SearchReq) ([]SearchResult, int64, error) {
+	keywords := misc.SplitKeywords(req.Keyword)
+	if len(keywords) == 0 {
+		return []SearchResult{}, req.StartID, nil
+	}
+
+	var results []SearchResult
+	var err error
+
+	switch req.Source {
+	case SearchSourceGroupChat:
+		results, err = r.searchGroupChat(ctx, userID, keywords, req)
+	case SearchSourceCreative:
+		results, err = r.searchCreative(ctx, userID, keywords, req)
+	default:
+		results, err = r.searchChat(ctx, userID, keywords, req)
+	}
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("search %s failed: %w", req.Source, err)
+	}
+
+	if len(results) == 0 {
+		return []SearchResult{}, req.StartID, nil
+	}
+
+	return results, results[len(results)-1].ID, nil
+}
+
+func (r *SearchRepo) searchChat(ctx context.Context, userID int64, keywords []string, req SearchReq) ([]SearchResult, error) {
+	q := buildSearchQuery(
+		query.Builder().
+			Where(model.FieldChatMessagesUserId, userID).
+			Where(model.FieldChatMessagesStatus, "!=", MessageStatusDeleted),
+		model.FieldChatMessagesId, model.FieldChatMessagesMessage, model.FieldChatMessagesCreatedAt,
+		keywords, req,
+	)
+
+	if req.RoomID > 0 {
+		q = q.Where(model.FieldChatMessagesRoomId, req.RoomID)
+	}
+
+	if req.Model != "" {
+		q = q.Where(model.FieldChatMessagesModel, req.Model)
+	}
+
+	messages, err := model.NewChatMessagesModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(messages, func(item model.ChatMessagesN, _ int) SearchResult {
+		return SearchResult{
+			ID:        item.Id.ValueOrZero(),
+			Source:    SearchSourceChat,
+			RoomID:    item.RoomId.ValueOrZero(),
+			Model:     item.Model.ValueOrZero(),
+			Role:      item.Role.ValueOrZero(),
+			Snippet:   misc.Highlight(item.Message.ValueOrZero(), keywords, searchSnippetLength, req.HighlightPre, req.HighlightPost),
+			CreatedAt: item.CreatedAt.ValueOrZero(),
+		}
+	}), nil
+}
+
+func (r *SearchRepo) searchGroupChat(ctx context.Context, userID int64, keywords []string, req SearchReq) ([]SearchResult, error) {
+	q := buildSearchQuery(
+		query.Builder().
+			Where(model.FieldChatGroupMessageUserId, userID).
+			Where(model.FieldChatGroupMessageStatus, "!=", MessageStatusDeleted),
+		model.FieldChatGroupMessageId, model.FieldChatGroupMessageMessage, model.FieldChatGroupMessageCreatedAt,
+		keywords, req,
+	)
+
+	if req.RoomID > 0 {
+		q = q.Where(model.FieldChatGroupMessageGroupId, req.RoomID)
+	}
+
+	// 群聊消息中只记录了群成员 ID，通过群成员查找对应的模型
+	if req.Model != "" {
+		q = q.WhereRaw(
+			"member_id IN (SELECT id FROM chat_group_member WHERE user_id = ? AND model_id = ?)",
+			userID, req.Model,
+		)
+	}
+
+	messages, err := model.NewChatGroupMessageModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(messages, func(item model.ChatGroupMessageN, _ int) SearchResult {
+		return SearchResult{
+			ID:        item.Id.ValueOrZero(),
+			Source:    SearchSourceGroupChat,
+			RoomID:    item.GroupId.ValueOrZero(),
+			Role:      item.Role.ValueOrZero(),
+			Snippet:   misc.Highlight(item.Message.ValueOrZero(), keywords, searchSnippetLength, req.HighlightPre, req.HighlightPost),
+			CreatedAt: item.CreatedAt.ValueOrZero(),
+		}
+	}), nil
+}
+
+func (r *SearchRepo) searchCreative(ctx context.Context, userID int64, keywords []string, req SearchReq) ([]SearchResult, error) {
+	q := buildSearchQuery(
+		query.Builder().Where(model.FieldCreativeHistoryUserId, userID),
+		model.FieldCreativeHistoryId, model.FieldCreativeHistoryPrompt, model.FieldCreativeHistoryCreatedAt,
+		keywords, req,
+	)
+
+	if req.Model != "" {
+		q = q.Where(model.FieldCreativeHistoryIslandModel, req.Model)
+	}
+
+	items, err := model.NewCreativeHistoryModel(r.db).Get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(items, func(item model.CreativeHistoryN, _ int) SearchResult {
+		return SearchResult{
+			ID:        item.Id.ValueOrZero(),
+			Source:    SearchSourceCreative,
+			IslandID:  item.IslandId.ValueOrZero(),
+			Model:     item.IslandModel.ValueOrZero(),
+			Snippet:   misc.Highlight(item.Prompt.ValueOrZero(), keywords, searchSnippetLength, req.HighlightPre, req.HighlightPost),
+			CreatedAt: item.CreatedAt.ValueOrZero(),
+		}
+	}), nil
+}
+
+// buildSearchQuery 构建搜索的公共查询条件：关键词匹配、创建时间范围以及分页
+func buildSearchQuery(q query.SQLBuilder, idField, contentField, createdAtField string, keywords []string, req SearchReq) query.SQLBuilder {
+	// 关键词长度不小于 ngram 分词长度时使用全文索引，否则只能使用 LIKE 匹配
+	fulltextKeywords := array.Filter(keywords, func(kw string, _ int) bool {
+		return utf8.RuneCountInString(kw) >= ngramTokenSize
+	})
+	if expr := buildFulltextQuery(fulltextKeywords); expr != "" {
+		q = q.WhereRaw(fmt.Sprintf("MATCH(%s) AGAINST(? IN BOOLEAN MODE)", contentField), expr)
+	}
+
+	for _, kw := range keywords {
+		if utf8.RuneCountInString(kw) < ngramTokenSize {
+			q = q.Where(contentField, "LIKE", "%"+escapeLike(kw)+"%")
+		}
+	}
+
+	if !req.StartTime.IsZero() {
+		q = q.Where(createdAtField, ">=", req.StartTime)
+	}
+
+	if !req.EndTime.IsZero() {
+		q = q.Where(createdAtField, "<", req.EndTime)
+	}
+
+	if req.StartID > 0 {
+		q = q.Where(idField, "<", req.StartID)
+	}
+
+	return q.OrderBy(idField, "DESC").Limit(req.PerPage)
+}
+
+// buildFulltextQuery 构建 BOOLEAN MODE 全文检索表达式，每个关键词作为必须包含的短语，避免关键词中的字符被当作运算符
+func buildFulltextQuery(keywords []string) string {
+	terms := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		if kw = strings.ReplaceAll(kw, `"`, ""); kw != "" {
+			terms = append(terms, `+"`+kw+`"`)
+		}
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// escapeLike 转义 LIKE 表达式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,130 @@
+package safety
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Match 关键词匹配结果，Start 和 End 为关键词在文本中的位置（按照字符计算，左闭右开）
+type Match struct {
+	Word  string
+	Start int
+	End   int
+}
+
+type acNode struct {
+	children map[rune]int
+	fail     int
+	// outputs 以当前节点结尾的关键词（含通过失败指针可达的关键词）在 words 中的下标
+	outputs []int
+}
+
+// AhoCorasick 基于 Aho-Corasick 自动机（DFA）的多关键词匹配，匹配时忽略大小写
+type AhoCorasick struct {
+	nodes []acNode
+	words []string
+	// lengths 关键词的字符数
+	lengths []int
+}
+
+// NewAhoCorasick 使用关键词列表构建自动机，空白关键词会被忽略
+func NewAhoCorasick(words []string) *AhoCorasick {
+	ac := &AhoCorasick{nodes: []acNode{{children: make(map[rune]int)}}}
+
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+
+		runes := normalizeRune(word)
+		cur := 0
+		for _, r := range runes {
+			next, ok := ac.nodes[cur].children[r]
+			if !ok {
+				ac.nodes = append(ac.nodes, acNode{children: make(map[rune]int)})
+				next = len(ac.nodes) - 1
+				ac.nodes[cur].children[r] = next
+			}
+
+			cur = next
+		}
+
+		ac.nodes[cur].outputs = append(ac.nodes[cur].outputs, len(ac.words))
+		ac.words = append(ac.words, word)
+		ac.lengths = append(ac.lengths, len(runes))
+	}
+
+	ac.build()
+	return ac
+}
+
+// build 广度优先构建失败指针
+func (ac *AhoCorasick) build() {
+	queue := make([]int, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].children {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for r, child := range ac.nodes[cur].children {
+			fail := ac.nodes[cur].fail
+			for fail > 0 {
+				if _, ok := ac.nodes[fail].children[r]; ok {
+					break
+				}
+
+				fail = ac.nodes[fail].fail
+			}
+
+			if next, ok := ac.nodes[fail].children[r]; ok && next != child {
+				ac.nodes[child].fail = next
+			}
+
+			ac.nodes[child].outputs = append(ac.nodes[child].outputs, ac.nodes[ac.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 查找文本中出现的所有关键词
+func (ac *AhoCorasick) FindAll(text string) []Match {
+	if len(ac.words) == 0 {
+		return nil
+	}
+
+	matches := make([]Match, 0)
+	cur := 0
+	for i, r := range normalizeRune(text) {
+		for cur > 0 {
+			if _, ok := ac.nodes[cur].children[r]; ok {
+				break
+			}
+
+			cur = ac.nodes[cur].fail
+		}
+
+		if next, ok := ac.nodes[cur].children[r]; ok {
+			cur = next
+		}
+
+		for _, idx := range ac.nodes[cur].outputs {
+			matches = append(matches, Match{Word: ac.words[idx], Start: i + 1 - ac.lengths[idx], End: i + 1})
+		}
+	}
+
+	return matches
+}
+
+// normalizeRune 将文本转换为小写字符序列
+func normalizeRune(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+
+	return runes
+}
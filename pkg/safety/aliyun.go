@@ -0,0 +1,34 @@
+package safety
+
+import (
+	"context"
+
+	"github.com/mylxsw/aidea-server/pkg/aliyun"
+)
+
+// AliyunDetector 阿里云内容安全检测器
+type AliyunDetector struct {
+	client *aliyun.Aliyun
+}
+
+func NewAliyunDetector(client *aliyun.Aliyun) *AliyunDetector {
+	return &AliyunDetector{client: client}
+}
+
+func (d *AliyunDetector) Name() string {
+	return "aliyun"
+}
+
+func (d *AliyunDetector) Detect(ctx context.Context, typ CheckType, content string) (*CheckResult, error) {
+	res, err := d.client.ContentDetect(aliyun.CheckType(typ), content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckResult{
+		Safe:     res.Safe,
+		Label:    res.Label,
+		Reason:   Reason{RiskTips: res.Reason.RiskTips, RiskWords: res.Reason.RiskWords},
+		Detector: d.Name(),
+	}, nil
+}
@@ -0,0 +1,346 @@
+package safety
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mylxsw/asteria/log"
+)
+
+// allowlistFile 词库目录中的白名单文件名
+const allowlistFile = "allowlist.txt"
+
+// Rules 本地内容安全检测规则
+type Rules struct {
+	// Keywords 敏感词，key 为分类名称
+	Keywords map[string][]string
+	// Regexps 正则表达式规则，key 为分类名称
+	Regexps map[string][]string
+	// Allowlist 白名单，敏感词或者正则表达式命中的内容完全包含在白名单词语中时，不认为是敏感内容
+	Allowlist []string
+}
+
+type categoryRegexp struct {
+	category string
+	re       *regexp.Regexp
+}
+
+type compiledRules struct {
+	keywords *AhoCorasick
+	// categories 敏感词（小写）对应的分类
+	categories map[string]string
+	regexps    []categoryRegexp
+	allowlist  *AhoCorasick
+}
+
+func compileRules(rules Rules) (*compiledRules, error) {
+	compiled := &compiledRules{categories: make(map[string]string)}
+
+	// 分类按照名称排序，同一个词出现在多个分类中时，使用排序靠前的分类
+	categories := make([]string, 0, len(rules.Keywords))
+	for category := range rules.Keywords {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	words := make([]string, 0)
+	for _, category := range categories {
+		for _, word := range rules.Keywords[category] {
+			key := strings.ToLower(strings.TrimSpace(word))
+			if key == "" {
+				continue
+			}
+
+			if _, ok := compiled.categories[key]; !ok {
+				compiled.categories[key] = category
+				words = append(words, word)
+			}
+		}
+	}
+
+	compiled.keywords = NewAhoCorasick(words)
+	compiled.allowlist = NewAhoCorasick(rules.Allowlist)
+
+	for category, patterns := range rules.Regexps {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp rule %q in category %s: %w", pattern, category, err)
+			}
+
+			compiled.regexps = append(compiled.regexps, categoryRegexp{category: category, re: re})
+		}
+	}
+
+	return compiled, nil
+}
+
+// detect 返回命中的分类以及敏感内容
+func (rules *compiledRules) detect(content string) ([]string, []string) {
+	allowed := rules.allowlist.FindAll(content)
+	isAllowed := func(start, end int) bool {
+		for _, a := range allowed {
+			if a.Start <= start && end <= a.End {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	categories := make([]string, 0)
+	words := make([]string, 0)
+	seen := make(map[string]bool)
+	hit := func(category, word string) {
+		if !seen["c:"+category] {
+			seen["c:"+category] = true
+			categories = append(categories, category)
+		}
+
+		if !seen["w:"+word] {
+			seen["w:"+word] = true
+			words = append(words, word)
+		}
+	}
+
+	for _, m := range rules.keywords.FindAll(content) {
+		if !isAllowed(m.Start, m.End) {
+			hit(rules.categories[strings.ToLower(m.Word)], m.Word)
+		}
+	}
+
+	for _, cr := range rules.regexps {
+		for _, loc := range cr.re.FindAllStringIndex(content, -1) {
+			start := utf8.RuneCountInString(content[:loc[0]])
+			end := start + utf8.RuneCountInString(content[loc[0]:loc[1]])
+			if !isAllowed(start, end) {
+				hit(cr.category, content[loc[0]:loc[1]])
+			}
+		}
+	}
+
+	return categories, words
+}
+
+// KeywordDetector 本地敏感词检测器，支持按分类的敏感词（Aho-Corasick 自动机匹配）、正则表达式规则以及白名单
+//
+// 从词库目录加载时，目录中的文件约定如下，每行一条规则，空行以及 # 开头的行会被忽略：
+//
+//	{分类}.txt    敏感词
+//	{分类}.regex  正则表达式规则
+//	allowlist.txt 白名单
+//
+// 词库文件发生变化后，会在下一次检测时自动重新加载（检查间隔为 reloadInterval）
+type KeywordDetector struct {
+	dir            string
+	reloadInterval time.Duration
+
+	lock      sync.RWMutex
+	rules     *compiledRules
+	signature string
+	lastCheck time.Time
+}
+
+// NewKeywordDetector 使用固定的规则创建本地敏感词检测器
+func NewKeywordDetector(rules Rules) (*KeywordDetector, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeywordDetector{rules: compiled}, nil
+}
+
+// LoadKeywordDetector 从词库目录加载本地敏感词检测器，reloadInterval 为 0 时不自动重新加载
+func LoadKeywordDetector(dir string, reloadInterval time.Duration) (*KeywordDetector, error) {
+	d := &KeywordDetector{dir: dir, reloadInterval: reloadInterval}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *KeywordDetector) Name() string {
+	return "local"
+}
+
+// Reload 重新加载词库目录
+func (d *KeywordDetector) Reload() error {
+	if d.dir == "" {
+		return nil
+	}
+
+	signature, err := dirSignature(d.dir)
+	if err != nil {
+		return err
+	}
+
+	rules, err := LoadRules(d.dir)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := compileRules(*rules)
+	if err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.rules = compiled
+	d.signature = signature
+	d.lastCheck = time.Now()
+
+	return nil
+}
+
+// reloadIfChanged 距离上次检查超过 reloadInterval 时，检查词库目录是否发生变化，有变化时重新加载
+func (d *KeywordDetector) reloadIfChanged() {
+	if d.dir == "" || d.reloadInterval <= 0 {
+		return
+	}
+
+	d.lock.Lock()
+	if time.Since(d.lastCheck) < d.reloadInterval {
+		d.lock.Unlock()
+		return
+	}
+
+	d.lastCheck = time.Now()
+	previous := d.signature
+	d.lock.Unlock()
+
+	signature, err := dirSignature(d.dir)
+	if err != nil {
+		log.F(log.M{"dir": d.dir}).Errorf("check content safety rules failed: %v", err)
+		return
+	}
+
+	if signature == previous {
+		return
+	}
+
+	if err := d.Reload(); err != nil {
+		log.F(log.M{"dir": d.dir}).Errorf("reload content safety rules failed, keep using the old rules: %v", err)
+		return
+	}
+
+	log.F(log.M{"dir": d.dir}).Infof("content safety rules reloaded")
+}
+
+func (d *KeywordDetector) Detect(ctx context.Context, typ CheckType, content string) (*CheckResult, error) {
+	d.reloadIfChanged()
+
+	d.lock.RLock()
+	rules := d.rules
+	d.lock.RUnlock()
+
+	categories, words := rules.detect(content)
+	if len(words) == 0 {
+		return &CheckResult{Safe: true}, nil
+	}
+
+	return &CheckResult{
+		Safe:  false,
+		Label: strings.Join(categories, ","),
+		Reason: Reason{
+			RiskTips:  strings.Join(categories, ","),
+			RiskWords: strings.Join(words, ","),
+		},
+		Detector: d.Name(),
+	}, nil
+}
+
+// LoadRules 从词库目录中加载规则
+func LoadRules(dir string) (*Rules, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read content safety rules dir failed: %w", err)
+	}
+
+	rules := Rules{Keywords: make(map[string][]string), Regexps: make(map[string][]string)}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if ext != ".txt" && ext != ".regex" {
+			continue
+		}
+
+		lines, err := readRuleLines(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		category := strings.TrimSuffix(name, ext)
+		switch {
+		case name == allowlistFile:
+			rules.Allowlist = append(rules.Allowlist, lines...)
+		case ext == ".regex":
+			rules.Regexps[category] = append(rules.Regexps[category], lines...)
+		default:
+			rules.Keywords[category] = append(rules.Keywords[category], lines...)
+		}
+	}
+
+	return &rules, nil
+}
+
+func readRuleLines(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read content safety rules file failed: %w", err)
+	}
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// dirSignature 词库目录中所有规则文件的名称、大小以及修改时间，用于判断词库是否发生变化
+func dirSignature(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("read content safety rules dir failed: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(fmt.Sprintf("%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,94 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type CheckType string
+
+const (
+	CheckTypeNickname   CheckType = "nickname_detection"
+	CheckTypeChat       CheckType = "chat_detection"
+	CheckTypeAIGCPrompt CheckType = "ai_art_detection"
+)
+
+// CheckResult 内容安全检测结果
+type CheckResult struct {
+	Safe   bool   `json:"safe"`
+	Reason Reason `json:"reason"`
+	Label  string `json:"label"`
+	// Detector 给出检测结果的检测器名称
+	Detector string `json:"detector,omitempty"`
+}
+
+func (res *CheckResult) IsReallyUnSafe() bool {
+	return !res.Safe && res.Reason.RiskWords != ""
+}
+
+func (res *CheckResult) ReasonDetail() string {
+	detail := res.Reason.RiskTips
+	if res.Reason.RiskWords != "" {
+		detail += fmt.Sprintf("（敏感词：%s）", res.Reason.RiskWords)
+	}
+
+	return detail
+}
+
+type Reason struct {
+	RiskTips  string `json:"risk_tips"`
+	RiskWords string `json:"risk_words"`
+}
+
+// ContentDetector 内容安全检测器
+type ContentDetector interface {
+	// Name 检测器名称
+	Name() string
+	// Detect 检测内容是否安全
+	Detect(ctx context.Context, typ CheckType, content string) (*CheckResult, error)
+}
+
+// Chain 组合多个检测器，按照顺序依次检测，任意一个检测器认为内容不安全时返回该结果
+// 单个检测器失败时继续使用后续的检测器，所有检测器都失败时返回错误
+type Chain struct {
+	detectors []ContentDetector
+}
+
+func NewChain(detectors ...ContentDetector) *Chain {
+	return &Chain{detectors: detectors}
+}
+
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Empty 是否没有任何检测器
+func (c *Chain) Empty() bool {
+	return len(c.detectors) == 0
+}
+
+func (c *Chain) Detect(ctx context.Context, typ CheckType, content string) (*CheckResult, error) {
+	var errs []error
+	for _, detector := range c.detectors {
+		res, err := detector.Detect(ctx, typ, content)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", detector.Name(), err))
+			continue
+		}
+
+		if !res.Safe {
+			if res.Detector == "" {
+				res.Detector = detector.Name()
+			}
+
+			return res, nil
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(c.detectors) {
+		return nil, errors.Join(errs...)
+	}
+
+	return &CheckResult{Safe: true}, nil
+}
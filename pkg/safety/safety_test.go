@@ -0,0 +1,178 @@
+package safety_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mylxsw/aidea-server/pkg/safety"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestAhoCorasick_FindAll(t *testing.T) {
+	ac := safety.NewAhoCorasick([]string{"he", "she", "his", "hers", "敏感词", "  "})
+
+	matches := ac.FindAll("ushers")
+	assert.Equal(t, 3, len(matches))
+	assert.Equal(t, safety.Match{Word: "she", Start: 1, End: 4}, matches[0])
+	assert.Equal(t, safety.Match{Word: "he", Start: 2, End: 4}, matches[1])
+	assert.Equal(t, safety.Match{Word: "hers", Start: 2, End: 6}, matches[2])
+
+	matches = ac.FindAll("这是一个敏感词测试，HIS")
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, safety.Match{Word: "敏感词", Start: 4, End: 7}, matches[0])
+	assert.Equal(t, "his", matches[1].Word)
+
+	assert.Equal(t, 0, len(safety.NewAhoCorasick(nil).FindAll("hello")))
+}
+
+func TestKeywordDetector_Detect(t *testing.T) {
+	detector, err := safety.NewKeywordDetector(safety.Rules{
+		Keywords: map[string][]string{
+			"porn":     {"成人", "色情"},
+			"gambling": {"赌博"},
+		},
+		Regexps: map[string][]string{
+			"contact": {`1[3-9]\d{9}`},
+		},
+		Allowlist: []string{"成人教育", "13800138000"},
+	})
+	assert.NoError(t, err)
+
+	res, err := detector.Detect(context.TODO(), safety.CheckTypeChat, "今天天气不错")
+	assert.NoError(t, err)
+	assert.True(t, res.Safe)
+
+	// 白名单
+	res, err = detector.Detect(context.TODO(), safety.CheckTypeChat, "我想报名成人教育，电话 13800138000")
+	assert.NoError(t, err)
+	assert.True(t, res.Safe)
+
+	res, err = detector.Detect(context.TODO(), safety.CheckTypeChat, "成人网站和网络赌博，联系 13912345678")
+	assert.NoError(t, err)
+	assert.False(t, res.Safe)
+	assert.True(t, res.IsReallyUnSafe())
+	assert.Equal(t, "porn,gambling,contact", res.Label)
+	assert.Equal(t, "成人,赌博,13912345678", res.Reason.RiskWords)
+	assert.Equal(t, "local", res.Detector)
+
+	_, err = safety.NewKeywordDetector(safety.Rules{Regexps: map[string][]string{"bad": {"("}}})
+	assert.True(t, err != nil)
+}
+
+func TestKeywordDetector_Reload(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gambling.txt"), []byte("# 赌博相关\n赌博\n\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "allowlist.txt"), []byte("反赌博\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "readme.md"), []byte("ignored"), 0644))
+
+	detector, err := safety.LoadKeywordDetector(dir, time.Nanosecond)
+	assert.NoError(t, err)
+
+	res, _ := detector.Detect(context.TODO(), safety.CheckTypeChat, "反赌博宣传")
+	assert.True(t, res.Safe)
+
+	res, _ = detector.Detect(context.TODO(), safety.CheckTypeChat, "在线赌博和毒品")
+	assert.False(t, res.Safe)
+	assert.Equal(t, "赌博", res.Reason.RiskWords)
+
+	// 词库变化后自动重新加载
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "drugs.txt"), []byte("毒品\n"), 0644))
+	res, _ = detector.Detect(context.TODO(), safety.CheckTypeChat, "毒品")
+	assert.False(t, res.Safe)
+	assert.Equal(t, "drugs", res.Label)
+
+	// 重新加载失败时继续使用原来的规则
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.regex"), []byte("(\n"), 0644))
+	res, _ = detector.Detect(context.TODO(), safety.CheckTypeChat, "毒品")
+	assert.False(t, res.Safe)
+
+	_, err = safety.LoadKeywordDetector(filepath.Join(dir, "not-exist"), 0)
+	assert.True(t, err != nil)
+}
+
+type fakeDetector struct {
+	name string
+	res  *safety.CheckResult
+	err  error
+}
+
+func (f fakeDetector) Name() string {
+	return f.name
+}
+
+func (f fakeDetector) Detect(ctx context.Context, typ safety.CheckType, content string) (*safety.CheckResult, error) {
+	return f.res, f.err
+}
+
+func TestChain_Detect(t *testing.T) {
+	failed := fakeDetector{name: "failed", err: errors.New("service unavailable")}
+	safe := fakeDetector{name: "safe", res: &safety.CheckResult{Safe: true}}
+	unsafe := fakeDetector{name: "unsafe", res: &safety.CheckResult{Safe: false, Reason: safety.Reason{RiskWords: "xxx"}}}
+
+	assert.True(t, safety.NewChain().Empty())
+
+	res, err := safety.NewChain(failed, safe).Detect(context.TODO(), safety.CheckTypeChat, "hello")
+	assert.NoError(t, err)
+	assert.True(t, res.Safe)
+
+	res, err = safety.NewChain(safe, failed, unsafe).Detect(context.TODO(), safety.CheckTypeChat, "hello")
+	assert.NoError(t, err)
+	assert.False(t, res.Safe)
+	assert.Equal(t, "unsafe", res.Detector)
+
+	_, err = safety.NewChain(failed, failed).Detect(context.TODO(), safety.CheckTypeChat, "hello")
+	assert.True(t, err != nil)
+}
+
+func TestStreamModerator(t *testing.T) {
+	detector, err := safety.NewKeywordDetector(safety.Rules{Keywords: map[string][]string{"gambling": {"赌博"}}})
+	assert.NoError(t, err)
+
+	m := safety.NewStreamModerator(detector, safety.CheckTypeChat, 6, 4)
+
+	// 未达到检测长度时，内容缓存在审核器中
+	passed, res, err := m.Write(context.TODO(), "你好")
+	assert.NoError(t, err)
+	assert.True(t, res == nil)
+	assert.Equal(t, "", passed)
+
+	passed, res, err = m.Write(context.TODO(), "世界，")
+	assert.NoError(t, err)
+	assert.True(t, res == nil)
+	assert.Equal(t, "你好世界，", passed)
+
+	// 敏感词跨越了两次输出
+	passed, _, _ = m.Write(context.TODO(), "赌")
+	assert.Equal(t, "", passed)
+
+	passed, res, err = m.Write(context.TODO(), "博网站")
+	assert.NoError(t, err)
+	assert.Equal(t, "", passed)
+	assert.False(t, res.Safe)
+	assert.Equal(t, "赌博", res.Reason.RiskWords)
+	assert.Equal(t, "你好世界，", m.Passed())
+
+	m = safety.NewStreamModerator(detector, safety.CheckTypeChat, 200, 100)
+	passed, _, _ = m.Write(context.TODO(), "一切正常")
+	assert.Equal(t, "", passed)
+
+	passed, res, err = m.Flush(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, res == nil)
+	assert.Equal(t, "一切正常", passed)
+
+	passed, res, _ = m.Flush(context.TODO())
+	assert.True(t, res == nil)
+	assert.Equal(t, "", passed)
+
+	// 检测服务出错时内容视为检测通过
+	m = safety.NewStreamModerator(fakeDetector{name: "failed", err: errors.New("service unavailable")}, safety.CheckTypeChat, 0, 2)
+	passed, res, err = m.Write(context.TODO(), "hello")
+	assert.True(t, err != nil)
+	assert.True(t, res == nil)
+	assert.Equal(t, "hello", passed)
+}
@@ -0,0 +1,82 @@
+package safety
+
+import (
+	"context"
+)
+
+// StreamModerator 流式输出内容审核
+//
+// 输出内容先缓存起来，每累积 step 个字符，检测最近的 window 个字符（相邻窗口之间有重叠，避免敏感词被截断），
+// 检测通过的内容才可以发送给客户端
+type StreamModerator struct {
+	detector ContentDetector
+	typ      CheckType
+	window   int
+	step     int
+
+	text []rune
+	// checked 已经检测通过的字符数
+	checked int
+}
+
+// NewStreamModerator 创建流式输出内容审核，window 小于 step 时使用 step 作为窗口大小
+func NewStreamModerator(detector ContentDetector, typ CheckType, window, step int) *StreamModerator {
+	if step <= 0 {
+		step = 100
+	}
+
+	if window < step {
+		window = step
+	}
+
+	return &StreamModerator{detector: detector, typ: typ, window: window, step: step}
+}
+
+// Write 追加输出内容，未检测的内容达到 step 个字符时进行检测
+// 返回本次检测通过、可以发送给客户端的内容（未进行检测时为空），以及检测结果（内容不安全时返回）
+// 检测服务出错时，内容视为检测通过，同时返回错误
+func (m *StreamModerator) Write(ctx context.Context, text string) (string, *CheckResult, error) {
+	m.text = append(m.text, []rune(text)...)
+	if len(m.text)-m.checked < m.step {
+		return "", nil, nil
+	}
+
+	return m.check(ctx)
+}
+
+// Flush 检测所有尚未检测的内容，输出结束时调用
+func (m *StreamModerator) Flush(ctx context.Context) (string, *CheckResult, error) {
+	if len(m.text) == m.checked {
+		return "", nil, nil
+	}
+
+	return m.check(ctx)
+}
+
+// Passed 已经检测通过的内容
+func (m *StreamModerator) Passed() string {
+	return string(m.text[:m.checked])
+}
+
+func (m *StreamModerator) check(ctx context.Context) (string, *CheckResult, error) {
+	end := len(m.text)
+	start := end - m.window
+	// 窗口需要覆盖所有未检测的内容
+	if start > m.checked {
+		start = m.checked
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	res, err := m.detector.Detect(ctx, m.typ, string(m.text[start:end]))
+	if err == nil && !res.Safe {
+		return "", res, nil
+	}
+
+	passed := string(m.text[m.checked:end])
+	m.checked = end
+
+	return passed, nil, err
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+)
+
+// Bing Bing Web Search API
+// https://learn.microsoft.com/en-us/bing/search-apis/bing-web-search/reference/endpoints
+type Bing struct {
+	apiKey string
+}
+
+func NewBing(apiKey string) *Bing {
+	return &Bing{apiKey: apiKey}
+}
+
+func (b *Bing) Name() string {
+	return "bing"
+}
+
+type bingResponse struct {
+	WebPages struct {
+		Value []struct {
+			Name    string `json:"name"`
+			URL     string `json:"url"`
+			Snippet string `json:"snippet"`
+		} `json:"value"`
+	} `json:"webPages"`
+}
+
+func (b *Bing) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	resp, err := misc.RestyClient(1).R().
+		SetContext(ctx).
+		SetHeader("Ocp-Apim-Subscription-Key", b.apiKey).
+		SetQueryParam("q", query).
+		SetQueryParam("count", strconv.Itoa(limit)).
+		SetQueryParam("textFormat", "Raw").
+		Get("https://api.bing.microsoft.com/v7.0/search")
+	if err != nil {
+		return nil, fmt.Errorf("bing search failed: %w", err)
+	}
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("bing search failed [%d]: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var ret bingResponse
+	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	results := make([]Result, 0, limit)
+	for _, item := range ret.WebPages.Value {
+		if len(results) >= limit {
+			break
+		}
+
+		results = append(results, Result{Title: item.Name, URL: item.URL, Snippet: item.Snippet})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,25 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/mylxsw/aidea-server/config"
+)
+
+// NewFromConfig 根据配置创建搜索服务，未配置搜索服务时返回 ErrNoProvider
+func NewFromConfig(conf *config.Config) (Searcher, error) {
+	switch conf.WebSearchProvider {
+	case "searxng":
+		return NewSearXNG(conf.WebSearchSearXNGURL), nil
+	case "bing":
+		return NewBing(conf.WebSearchBingKey), nil
+	case "google":
+		return NewGoogleCSE(conf.WebSearchGoogleKey, conf.WebSearchGoogleCX), nil
+	case "fixture":
+		return LoadFixture(conf.WebSearchFixtureFile)
+	case "":
+		return nil, ErrNoProvider
+	default:
+		return nil, fmt.Errorf("unsupported web search provider: %s", conf.WebSearchProvider)
+	}
+}
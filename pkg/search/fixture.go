@@ -0,0 +1,58 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Fixture 本地测试搜索服务，从固定的结果集中返回标题或摘要包含关键词的结果，不依赖外部服务
+type Fixture struct {
+	results []Result
+}
+
+func NewFixture(results ...Result) *Fixture {
+	return &Fixture{results: results}
+}
+
+// LoadFixture 从 JSON 文件中加载测试搜索结果，文件格式为 Result 数组
+func LoadFixture(filename string) (*Fixture, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture file failed: %w", err)
+	}
+
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("parse fixture file failed: %w", err)
+	}
+
+	return NewFixture(results...), nil
+}
+
+func (f *Fixture) Name() string {
+	return "fixture"
+}
+
+func (f *Fixture) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	words := strings.Fields(strings.ToLower(query))
+
+	results := make([]Result, 0, limit)
+	for _, res := range f.results {
+		if len(results) >= limit {
+			break
+		}
+
+		content := strings.ToLower(res.Title + " " + res.Snippet)
+		for _, word := range words {
+			if strings.Contains(content, word) {
+				results = append(results, res)
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
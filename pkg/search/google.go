@@ -0,0 +1,73 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+)
+
+// googleMaxResults Google Custom Search 单次请求最多返回的结果数量
+const googleMaxResults = 10
+
+// GoogleCSE Google Custom Search JSON API
+// https://developers.google.com/custom-search/v1/reference/rest/v1/cse/list
+type GoogleCSE struct {
+	apiKey string
+	cx     string
+}
+
+func NewGoogleCSE(apiKey, cx string) *GoogleCSE {
+	return &GoogleCSE{apiKey: apiKey, cx: cx}
+}
+
+func (g *GoogleCSE) Name() string {
+	return "google"
+}
+
+type googleResponse struct {
+	Items []struct {
+		Title   string `json:"title"`
+		Link    string `json:"link"`
+		Snippet string `json:"snippet"`
+	} `json:"items"`
+}
+
+func (g *GoogleCSE) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	if limit > googleMaxResults {
+		limit = googleMaxResults
+	}
+
+	resp, err := misc.RestyClient(1).R().
+		SetContext(ctx).
+		SetQueryParam("key", g.apiKey).
+		SetQueryParam("cx", g.cx).
+		SetQueryParam("q", query).
+		SetQueryParam("num", strconv.Itoa(limit)).
+		Get("https://www.googleapis.com/customsearch/v1")
+	if err != nil {
+		return nil, fmt.Errorf("google search failed: %w", err)
+	}
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("google search failed [%d]: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var ret googleResponse
+	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	results := make([]Result, 0, limit)
+	for _, item := range ret.Items {
+		if len(results) >= limit {
+			break
+		}
+
+		results = append(results, Result{Title: item.Title, URL: item.Link, Snippet: item.Snippet})
+	}
+
+	return results, nil
+}
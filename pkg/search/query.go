@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// queryMaxLength 搜索关键词的最大字符数
+const queryMaxLength = 64
+
+// queryShortLength 问题字符数小于该值时，认为问题依赖上下文（如“那明天呢”），需要结合上一个问题
+const queryShortLength = 8
+
+// queryFillers 问题中与搜索内容无关的前缀
+var queryFillers = []string{
+	"请帮我搜索一下", "请帮我搜索", "帮我搜索一下", "帮我搜索", "搜索一下", "搜索",
+	"请帮我查一下", "请帮我查询", "帮我查一下", "帮我查询", "查一下", "查询一下",
+	"请问一下", "请问", "我想知道", "告诉我",
+	"please search for", "search for", "please tell me", "tell me", "search",
+}
+
+// RewriteQuery 将用户的问题改写为搜索关键词
+// questions 为用户的历史问题，按照时间正序排列，最后一个为当前问题
+func RewriteQuery(questions []string) string {
+	if len(questions) == 0 {
+		return ""
+	}
+
+	query := cleanQuery(questions[len(questions)-1])
+
+	// 追问通常比较简短，且省略了主题，需要结合上一个问题
+	if utf8.RuneCountInString(query) < queryShortLength && len(questions) > 1 {
+		query = strings.TrimSpace(cleanQuery(questions[len(questions)-2]) + " " + query)
+	}
+
+	if utf8.RuneCountInString(query) > queryMaxLength {
+		query = string([]rune(query)[:queryMaxLength])
+	}
+
+	return strings.TrimSpace(query)
+}
+
+// cleanQuery 去掉问题中的无关前缀、标点和多余空白
+func cleanQuery(question string) string {
+	query := strings.Join(strings.Fields(question), " ")
+	for {
+		trimmed := strings.TrimLeft(query, " ，,：:")
+		lower := strings.ToLower(trimmed)
+		for _, filler := range queryFillers {
+			if strings.HasPrefix(lower, filler) {
+				trimmed = trimmed[len(filler):]
+				break
+			}
+		}
+
+		if trimmed == query {
+			break
+		}
+
+		query = trimmed
+	}
+
+	return strings.TrimRight(query, " ？?。.！!~")
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrNoProvider = errors.New("web search provider is not configured")
+
+// Result 搜索结果
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+	// Index 回答中引用该结果时使用的编号
+	Index int `json:"index,omitempty"`
+}
+
+// Searcher 联网搜索服务
+type Searcher interface {
+	// Name 搜索服务名称
+	Name() string
+	// Search 搜索关键词，返回最多 limit 条结果
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// snippetMaxLength 加入到上下文中的单条搜索结果摘要最大字符数
+const snippetMaxLength = 300
+
+// Prompt 将搜索结果构建为系统提示，要求模型在回答中使用编号标注引用的来源
+// 编号从 offset+1 开始，与知识库参考资料同时使用时，避免两者的编号冲突
+func Prompt(query string, results []Result, offset int) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("以下是使用关键词“%s”在互联网上搜索到的结果，请结合这些结果回答用户的问题。", query))
+	sb.WriteString("回答中使用了搜索结果时，请在相应内容后使用 [编号] 标注来源；")
+	sb.WriteString("如果搜索结果与问题无关，请忽略搜索结果，正常回答。\n")
+
+	for i, res := range results {
+		snippet := res.Snippet
+		if utf8.RuneCountInString(snippet) > snippetMaxLength {
+			snippet = string([]rune(snippet)[:snippetMaxLength]) + "..."
+		}
+
+		sb.WriteString(fmt.Sprintf("\n[%d] %s\n链接：%s\n%s\n", offset+i+1, res.Title, res.URL, snippet))
+	}
+
+	return sb.String()
+}
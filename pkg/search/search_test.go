@@ -0,0 +1,85 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/search"
+	"github.com/mylxsw/go-utils/assert"
+)
+
+func TestRewriteQuery(t *testing.T) {
+	assert.Equal(t, "", search.RewriteQuery(nil))
+	assert.Equal(t, "今天北京的天气怎么样", search.RewriteQuery([]string{"请帮我搜索一下：今天北京的天气怎么样？"}))
+	assert.Equal(t, "golang 1.22 release notes", search.RewriteQuery([]string{"Search for   golang 1.22 release notes?"}))
+
+	// 简短的追问需要结合上一个问题
+	assert.Equal(t, "今天北京的天气怎么样 那上海呢", search.RewriteQuery([]string{"今天北京的天气怎么样？", "那上海呢？"}))
+	assert.Equal(t, "上海明天会不会下雨，需要带伞吗", search.RewriteQuery([]string{"今天北京的天气怎么样？", "上海明天会不会下雨，需要带伞吗？"}))
+
+	long := strings.Repeat("长", 100)
+	assert.Equal(t, 64, len([]rune(search.RewriteQuery([]string{long}))))
+}
+
+func TestFixture_Search(t *testing.T) {
+	fixture := search.NewFixture(
+		search.Result{Title: "Go 1.22 Release Notes", URL: "https://go.dev/doc/go1.22", Snippet: "The latest Go release"},
+		search.Result{Title: "北京天气预报", URL: "https://weather.example.com/beijing", Snippet: "北京今天晴"},
+		search.Result{Title: "Rust", URL: "https://www.rust-lang.org", Snippet: "A language empowering everyone"},
+	)
+
+	results, err := fixture.Search(context.TODO(), "go release", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "https://go.dev/doc/go1.22", results[0].URL)
+
+	results, err = fixture.Search(context.TODO(), "北京 rust", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+
+	results, err = fixture.Search(context.TODO(), "python", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(results))
+}
+
+func TestPrompt(t *testing.T) {
+	assert.Equal(t, "", search.Prompt("go", nil, 0))
+
+	prompt := search.Prompt("go", []search.Result{
+		{Title: "Go", URL: "https://go.dev", Snippet: "Build simple, secure, scalable systems with Go"},
+		{Title: "Go Blog", URL: "https://go.dev/blog", Snippet: strings.Repeat("x", 500)},
+	}, 0)
+
+	assert.True(t, strings.Contains(prompt, "[1] Go\n链接：https://go.dev"))
+	assert.True(t, strings.Contains(prompt, "[2] Go Blog"))
+	assert.False(t, strings.Contains(prompt, strings.Repeat("x", 301)))
+
+	// 编号排在知识库参考资料之后
+	prompt = search.Prompt("go", []search.Result{{Title: "Go", URL: "https://go.dev"}}, 3)
+	assert.True(t, strings.Contains(prompt, "[4] Go\n链接：https://go.dev"))
+	assert.False(t, strings.Contains(prompt, "[1]"))
+}
+
+func TestNewFromConfig(t *testing.T) {
+	_, err := search.NewFromConfig(&config.Config{})
+	assert.True(t, errors.Is(err, search.ErrNoProvider))
+
+	_, err = search.NewFromConfig(&config.Config{WebSearchProvider: "unknown"})
+	assert.True(t, err != nil)
+
+	filename := filepath.Join(t.TempDir(), "fixture.json")
+	assert.NoError(t, os.WriteFile(filename, []byte(`[{"title": "AIdea", "url": "https://aidea.aicode.cc", "snippet": "AIdea app"}]`), 0644))
+
+	searcher, err := search.NewFromConfig(&config.Config{WebSearchProvider: "fixture", WebSearchFixtureFile: filename})
+	assert.NoError(t, err)
+	assert.Equal(t, "fixture", searcher.Name())
+
+	results, err := searcher.Search(context.TODO(), "aidea", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+)
+
+// SearXNG 基于自建 SearXNG 服务的搜索，需要在 SearXNG 配置中开启 json 输出格式
+type SearXNG struct {
+	serverURL string
+}
+
+func NewSearXNG(serverURL string) *SearXNG {
+	return &SearXNG{serverURL: strings.TrimSuffix(serverURL, "/")}
+}
+
+func (s *SearXNG) Name() string {
+	return "searxng"
+}
+
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (s *SearXNG) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	resp, err := misc.RestyClient(1).R().
+		SetContext(ctx).
+		SetQueryParam("q", query).
+		SetQueryParam("format", "json").
+		Get(s.serverURL + "/search")
+	if err != nil {
+		return nil, fmt.Errorf("searxng search failed: %w", err)
+	}
+
+	if resp.IsError() {
+		return nil, fmt.Errorf("searxng search failed [%d]: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var ret searxngResponse
+	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	results := make([]Result, 0, limit)
+	for _, item := range ret.Results {
+		if len(results) >= limit {
+			break
+		}
+
+		results = append(results, Result{Title: item.Title, URL: item.URL, Snippet: item.Content})
+	}
+
+	return results, nil
+}
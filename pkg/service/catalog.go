@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+)
+
+// CatalogService 模型目录服务，从模型目录文件以及数据库中加载模型目录
+type CatalogService struct {
+	conf *config.Config   `autowire:"@"`
+	repo *repo.Repository `autowire:"@"`
+}
+
+func NewCatalogService(resolver infra.Resolver) *CatalogService {
+	srv := &CatalogService{}
+	resolver.MustAutoWire(srv)
+
+	return srv
+}
+
+// Enabled 是否配置了模型目录
+func (srv *CatalogService) Enabled() bool {
+	return srv.conf.ModelCatalogFile != "" || srv.conf.ModelCatalogDB
+}
+
+// Reload 重新加载模型目录，同一个模型在数据库中的定义会覆盖模型目录文件中的定义
+func (srv *CatalogService) Reload(ctx context.Context) error {
+	models := make([]catalog.Model, 0)
+	if srv.conf.ModelCatalogFile != "" {
+		fileModels, err := catalog.ParseFile(srv.conf.ModelCatalogFile)
+		if err != nil {
+			return fmt.Errorf("load model catalog file failed: %w", err)
+		}
+
+		models = append(models, fileModels...)
+	}
+
+	if srv.conf.ModelCatalogDB {
+		dbModels, err := srv.repo.Catalog.Models(ctx)
+		if err != nil {
+			return fmt.Errorf("load model catalog from database failed: %w", err)
+		}
+
+		models = append(models, dbModels...)
+	}
+
+	catalog.Default().Replace(models)
+	return nil
+}
+
+// Watch 按照配置的刷新间隔定期重新加载模型目录，直到 ctx 结束
+func (srv *CatalogService) Watch(ctx context.Context) {
+	reload := func() {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		// 加载失败时保留上一次加载的模型目录
+		if err := srv.Reload(ctx); err != nil {
+			log.Errorf("reload model catalog failed: %v", err)
+		}
+	}
+
+	reload()
+	if srv.conf.ModelCatalogRefresh <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(srv.conf.ModelCatalogRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload()
+		}
+	}
+}
@@ -25,8 +25,12 @@ func NewChatService(resolver infra.Resolver) *ChatService {
 	return svc
 }
 
+func roomCacheKey(userID int64, roomID int64) string {
+	return fmt.Sprintf("chat-room:%d:%d:info", userID, roomID)
+}
+
 func (svc *ChatService) Room(ctx context.Context, userID int64, roomID int64) (*model.Rooms, error) {
-	roomKey := fmt.Sprintf("chat-room:%d:%d:info", userID, roomID)
+	roomKey := roomCacheKey(userID, roomID)
 	if roomStr, err := svc.rds.Get(ctx, roomKey).Result(); err == nil {
 		var room model.Rooms
 		if err := json.Unmarshal([]byte(roomStr), &room); err == nil {
@@ -45,3 +49,8 @@ func (svc *ChatService) Room(ctx context.Context, userID int64, roomID int64) (*
 
 	return room, nil
 }
+
+// ForgetRoom 清除数字人信息缓存，数字人信息变更后需要调用
+func (svc *ChatService) ForgetRoom(ctx context.Context, userID int64, roomID int64) error {
+	return svc.rds.Del(ctx, roomCacheKey(userID, roomID)).Err()
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mylxsw/aidea-server/pkg/export"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/aidea-server/pkg/uploader"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/ternary"
+)
+
+const (
+	// ExportMaxMessages 单次最多导出（导入）的消息数量
+	ExportMaxMessages = 10000
+	// ImportMaxBodySize 导入聊天记录时，请求体的最大字节数
+	ImportMaxBodySize = 32 << 20
+	// ExportAsyncThreshold 消息数量超过该值时，通过异步任务导出
+	ExportAsyncThreshold = 200
+	// ExportURLTTL 导出文件访问地址的有效期
+	ExportURLTTL = 24 * time.Hour
+	// exportExpireAfterDays 导出的文件保留天数
+	exportExpireAfterDays = 1
+)
+
+// ExportService 聊天记录导出、导入
+type ExportService struct {
+	rep *repo.Repository   `autowire:"@"`
+	up  *uploader.Uploader `autowire:"@"`
+}
+
+func NewExportService(resolver infra.Resolver) *ExportService {
+	svc := &ExportService{}
+	resolver.MustAutoWire(svc)
+	return svc
+}
+
+// ShouldAsync 数字人的消息数量较多时，需要通过异步任务导出
+func (svc *ExportService) ShouldAsync(ctx context.Context, userID, roomID int64) (bool, error) {
+	count, err := svc.rep.Message.CountRoomMessages(ctx, userID, roomID)
+	if err != nil {
+		return false, err
+	}
+
+	return count > ExportAsyncThreshold, nil
+}
+
+// Conversation 查询数字人的对话内容
+func (svc *ExportService) Conversation(ctx context.Context, userID, roomID int64) (*export.Conversation, error) {
+	room, err := svc.rep.Room.Room(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := svc.rep.Message.GetAllRoomMessages(ctx, userID, roomID, ExportMaxMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export.Conversation{
+		Version:    export.Version,
+		Room:       export.Room{ID: room.Id, Name: room.Name, Model: room.Model, Prompt: room.SystemPrompt},
+		ExportedAt: time.Now(),
+		Messages: array.Map(messages, func(msg model.ChatMessages, _ int) export.Message {
+			return export.Message{
+				ID:        msg.Id,
+				PID:       msg.Pid,
+				Role:      ternary.If(msg.Role == int64(repo.MessageRoleAssistant), export.RoleAssistant, export.RoleUser),
+				Content:   msg.Message,
+				Images:    repo.MessageImages(msg),
+				Model:     ternary.If(msg.Role == int64(repo.MessageRoleAssistant), msg.Model, ""),
+				CreatedAt: msg.CreatedAt,
+			}
+		}),
+	}, nil
+}
+
+// Export 导出数字人的对话，上传后返回有效期为 ExportURLTTL 的私有访问地址
+func (svc *ExportService) Export(ctx context.Context, userID, roomID int64, format export.Format) (string, error) {
+	conv, err := svc.Conversation(ctx, userID, roomID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := export.Render(format, *conv)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := svc.up.UploadPrivateStream(ctx, int(userID), exportExpireAfterDays, data, format.Ext(), ExportURLTTL)
+	if err != nil {
+		return "", fmt.Errorf("upload export file failed: %w", err)
+	}
+
+	return url, nil
+}
+
+// Import 将导出的 JSON 格式对话导入到数字人中
+func (svc *ExportService) Import(ctx context.Context, userID, roomID int64, conv *export.Conversation) error {
+	if _, err := svc.rep.Room.Room(ctx, userID, roomID); err != nil {
+		return err
+	}
+
+	if len(conv.Messages) > ExportMaxMessages {
+		return errors.New("too many messages")
+	}
+
+	return svc.rep.Message.ImportMessages(ctx, userID, roomID, array.Map(conv.Messages, func(msg export.Message, _ int) repo.MessageImportItem {
+		return repo.MessageImportItem{
+			ID:        msg.ID,
+			PID:       msg.PID,
+			Role:      ternary.If(msg.Role == export.RoleAssistant, repo.MessageRoleAssistant, repo.MessageRoleUser),
+			Message:   msg.Content,
+			Model:     msg.Model,
+			Images:    msg.Images,
+			CreatedAt: msg.CreatedAt,
+		}
+	}))
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/embedding"
+	"github.com/mylxsw/aidea-server/pkg/knowledge"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+)
+
+const (
+	// KnowledgeMaxDocuments 单个知识库最多包含的文档数量
+	KnowledgeMaxDocuments = 50
+	// KnowledgeMaxFileSize 上传文档的最大文件大小
+	KnowledgeMaxFileSize = 10 * 1024 * 1024
+	// KnowledgeMaxContentLength 单个文档提取出的文本内容最大字符数
+	KnowledgeMaxContentLength = 200000
+	// knowledgeLocalDimensions 本地哈希向量的维度
+	knowledgeLocalDimensions = 256
+)
+
+var (
+	ErrKnowledgeDisabled          = errors.New("knowledge base is disabled")
+	ErrKnowledgeTooManyDocuments  = errors.New("too many documents in knowledge base")
+	ErrKnowledgeDocumentTooLarge  = errors.New("document is too large")
+	ErrKnowledgeDocumentNotParsed = errors.New("document can not be parsed")
+)
+
+// KnowledgeService 知识库文档处理与检索
+type KnowledgeService struct {
+	conf  *config.Config   `autowire:"@"`
+	rep   *repo.Repository `autowire:"@"`
+	embed *embedding.Imp   `autowire:"@"`
+
+	// knowledge 未启用知识库时为 nil
+	knowledge *knowledge.Knowledge
+}
+
+func NewKnowledgeService(resolver infra.Resolver) *KnowledgeService {
+	svc := &KnowledgeService{}
+	resolver.MustAutoWire(svc)
+
+	if !svc.conf.EnableKnowledgeBase {
+		return svc
+	}
+
+	var embedder knowledge.Embedder
+	if svc.conf.KnowledgeEmbeddingProvider == "local" {
+		embedder = knowledge.NewHashEmbedder(knowledgeLocalDimensions)
+	} else {
+		me, err := knowledge.NewModelEmbedder(svc.embed, svc.conf.KnowledgeEmbeddingModel)
+		if err != nil {
+			log.Errorf("知识库向量化服务初始化失败，知识库功能不可用: %v", err)
+			return svc
+		}
+
+		embedder = me
+	}
+
+	svc.knowledge = knowledge.New(embedder, knowledge.NewMySQLStore(svc.rep.Knowledge))
+	return svc
+}
+
+// Enabled 知识库功能是否可用
+func (svc *KnowledgeService) Enabled() bool {
+	return svc.knowledge != nil
+}
+
+// AddDocument 从上传的文档中提取文本内容，添加到知识库中，返回文档 ID，文档需要调用 IndexDocument 处理后才能被检索
+func (svc *KnowledgeService) AddDocument(ctx context.Context, userID, knowledgeBaseID int64, filename string, data []byte) (int64, error) {
+	if !svc.Enabled() {
+		return 0, ErrKnowledgeDisabled
+	}
+
+	if len(data) > KnowledgeMaxFileSize {
+		return 0, ErrKnowledgeDocumentTooLarge
+	}
+
+	count, err := svc.rep.Knowledge.CountDocuments(ctx, knowledgeBaseID)
+	if err != nil {
+		return 0, err
+	}
+
+	if count >= KnowledgeMaxDocuments {
+		return 0, ErrKnowledgeTooManyDocuments
+	}
+
+	text, err := knowledge.ExtractText(filename, data)
+	if err != nil {
+		log.F(log.M{"user_id": userID, "filename": filename}).Warningf("extract document text failed: %v", err)
+		return 0, ErrKnowledgeDocumentNotParsed
+	}
+
+	if utf8.RuneCountInString(text) > KnowledgeMaxContentLength {
+		return 0, ErrKnowledgeDocumentTooLarge
+	}
+
+	return svc.rep.Knowledge.CreateDocument(ctx, repo.KnowledgeDocumentAddReq{
+		UserID:          userID,
+		KnowledgeBaseID: knowledgeBaseID,
+		Name:            filename,
+		Size:            int64(len(data)),
+		Content:         text,
+	})
+}
+
+// IndexDocument 将文档分块、向量化后保存，并更新文档的处理状态
+func (svc *KnowledgeService) IndexDocument(ctx context.Context, userID, documentID int64) error {
+	if !svc.Enabled() {
+		return ErrKnowledgeDisabled
+	}
+
+	doc, err := svc.rep.Knowledge.Document(ctx, userID, documentID)
+	if err != nil {
+		return fmt.Errorf("query document failed: %w", err)
+	}
+
+	count, err := svc.knowledge.Index(ctx, doc.KnowledgeBaseId, doc.Id, doc.Content, svc.conf.KnowledgeChunkSize, svc.conf.KnowledgeChunkOverlap)
+	if err != nil {
+		if err2 := svc.rep.Knowledge.UpdateDocumentStatus(ctx, doc.Id, repo.KnowledgeDocumentStatusFailed, 0, "文档处理失败"); err2 != nil {
+			log.F(log.M{"document_id": doc.Id}).Errorf("update document status failed: %v", err2)
+		}
+
+		return fmt.Errorf("index document failed: %w", err)
+	}
+
+	return svc.rep.Knowledge.UpdateDocumentStatus(ctx, doc.Id, repo.KnowledgeDocumentStatusSucceed, int64(count), "")
+}
+
+// Retrieve 从知识库中检索与问题相关的文档分块
+func (svc *KnowledgeService) Retrieve(ctx context.Context, knowledgeBaseID int64, question string) ([]knowledge.ScoredChunk, error) {
+	if !svc.Enabled() {
+		return nil, ErrKnowledgeDisabled
+	}
+
+	return svc.knowledge.Retrieve(ctx, knowledgeBaseID, question, svc.conf.KnowledgeTopK, float64(svc.conf.KnowledgeMinScore)/100)
+}
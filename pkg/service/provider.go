@@ -1,6 +1,10 @@
 package service
 
-import "github.com/mylxsw/glacier/infra"
+import (
+	"context"
+
+	"github.com/mylxsw/glacier/infra"
+)
 
 type Provider struct{}
 
@@ -9,4 +13,16 @@ func (Provider) Register(binder infra.Binder) {
 	binder.MustSingleton(NewSecurityService)
 	binder.MustSingleton(NewGalleryService)
 	binder.MustSingleton(NewChatService)
+	binder.MustSingleton(NewExportService)
+	binder.MustSingleton(NewKnowledgeService)
+	binder.MustSingleton(NewWebSearchService)
+	binder.MustSingleton(NewCatalogService)
+}
+
+func (Provider) Daemon(ctx context.Context, resolver infra.Resolver) {
+	resolver.MustResolve(func(catalogSrv *CatalogService) {
+		if catalogSrv.Enabled() {
+			catalogSrv.Watch(ctx)
+		}
+	})
 }
@@ -5,10 +5,11 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
-	"github.com/mylxsw/aidea-server/pkg/aliyun"
 	"time"
 
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/aliyun"
+	"github.com/mylxsw/aidea-server/pkg/safety"
 
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/glacier/infra"
@@ -19,54 +20,101 @@ type SecurityService struct {
 	aliClient *aliyun.Aliyun `autowire:"@"`
 	rds       *redis.Client  `autowire:"@"`
 	conf      *config.Config `autowire:"@"`
+
+	// detector 本地检测器在前，远程检测器（检测结果会被缓存）在后
+	detector *safety.Chain
 }
 
 func NewSecurityService(resolver infra.Resolver) *SecurityService {
 	srv := &SecurityService{}
 	resolver.MustAutoWire(srv)
+
+	detectors := make([]safety.ContentDetector, 0)
+	if srv.conf.EnableLocalContentDetect {
+		local, err := safety.LoadKeywordDetector(srv.conf.LocalContentDetectDir, srv.conf.LocalContentDetectReloadInterval)
+		if err != nil {
+			log.Errorf("本地内容安全检测词库加载失败，本地内容安全检测不可用: %v", err)
+		} else {
+			detectors = append(detectors, local)
+		}
+	}
+
+	if srv.conf.EnableContentDetect {
+		detectors = append(detectors, &cachedDetector{rds: srv.rds, detector: safety.NewAliyunDetector(srv.aliClient)})
+	}
+
+	srv.detector = safety.NewChain(detectors...)
 	return srv
 }
 
-func (s *SecurityService) NicknameDetect(nickname string) *aliyun.CheckResult {
-	return s.contentDetect(aliyun.CheckTypeNickname, nickname)
+func (s *SecurityService) NicknameDetect(nickname string) *safety.CheckResult {
+	return s.contentDetect(safety.CheckTypeNickname, nickname)
+}
+
+func (s *SecurityService) PromptDetect(prompt string) *safety.CheckResult {
+	return s.contentDetect(safety.CheckTypeAIGCPrompt, prompt)
 }
 
-func (s *SecurityService) PromptDetect(prompt string) *aliyun.CheckResult {
-	return s.contentDetect(aliyun.CheckTypeAIGCPrompt, prompt)
+func (s *SecurityService) ChatDetect(message string) *safety.CheckResult {
+	return s.contentDetect(safety.CheckTypeChat, message)
 }
 
-func (s *SecurityService) ChatDetect(message string) *aliyun.CheckResult {
-	return s.contentDetect(aliyun.CheckTypeChat, message)
+// NewChatOutputModerator 创建聊天输出内容审核，未启用输出内容检测时返回 nil
+func (s *SecurityService) NewChatOutputModerator() *safety.StreamModerator {
+	if !s.conf.EnableChatOutputContentDetect || s.detector.Empty() {
+		return nil
+	}
+
+	return safety.NewStreamModerator(s.detector, safety.CheckTypeChat, s.conf.ChatOutputContentDetectWindow, s.conf.ChatOutputContentDetectStep)
 }
 
-func (s *SecurityService) contentDetect(typ aliyun.CheckType, content string) *aliyun.CheckResult {
-	if !s.conf.EnableContentDetect {
-		return &aliyun.CheckResult{Safe: true}
+func (s *SecurityService) contentDetect(typ safety.CheckType, content string) *safety.CheckResult {
+	if s.detector.Empty() {
+		return &safety.CheckResult{Safe: true}
 	}
 
 	if content == "" {
-		return &aliyun.CheckResult{Safe: true}
+		return &safety.CheckResult{Safe: true}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	res, err := s.detector.Detect(ctx, typ, content)
+	if err != nil {
+		log.WithFields(log.Fields{"prompt": content, "type": typ}).Errorf("prompt detect failed: %v", err)
+		return nil
+	}
+
+	return res
+}
+
+// cachedDetector 缓存远程检测器的检测结果，相同的内容 24 小时内不再重复检测
+type cachedDetector struct {
+	rds      *redis.Client
+	detector safety.ContentDetector
+}
+
+func (c *cachedDetector) Name() string {
+	return c.detector.Name()
+}
+
+func (c *cachedDetector) Detect(ctx context.Context, typ safety.CheckType, content string) (*safety.CheckResult, error) {
 	cacheKey := fmt.Sprintf("detect:%s:%x", typ, md5.Sum([]byte(content)))
-	if cacheValue, err := s.rds.Get(ctx, cacheKey).Result(); err == nil {
-		var res aliyun.CheckResult
+	if cacheValue, err := c.rds.Get(ctx, cacheKey).Result(); err == nil {
+		var res safety.CheckResult
 		if err := json.Unmarshal([]byte(cacheValue), &res); err != nil {
 			log.WithFields(log.Fields{
 				"cache_key": cacheKey,
 			}).Errorf("unmarshal cache value failed: %s", err)
 		} else {
-			return &res
+			return &res, nil
 		}
 	}
 
-	res, err := s.aliClient.ContentDetect(typ, content)
+	res, err := c.detector.Detect(ctx, typ, content)
 	if err != nil {
-		log.WithFields(log.Fields{"prompt": content, "type": typ}).Errorf("prompt detect failed: %v", err)
-		return nil
+		return nil, err
 	}
 
 	cacheValue, err := json.Marshal(res)
@@ -75,12 +123,12 @@ func (s *SecurityService) contentDetect(typ aliyun.CheckType, content string) *a
 			"cache_key": cacheKey,
 		}).Errorf("marshal content detect result failed: %s", err)
 	} else {
-		if err := s.rds.Set(ctx, cacheKey, string(cacheValue), 24*time.Hour).Err(); err != nil {
+		if err := c.rds.Set(ctx, cacheKey, string(cacheValue), 24*time.Hour).Err(); err != nil {
 			log.WithFields(log.Fields{
 				"cache_key": cacheKey,
 			}).Errorf("cache content detect result failed: %s", err)
 		}
 	}
 
-	return res
+	return res, nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/go-utils/array"
 	"github.com/mylxsw/go-utils/must"
+	"github.com/mylxsw/go-utils/ternary"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -58,14 +59,7 @@ var (
 
 // FreeChatStatisticsForModel 用户免费聊天次数统计
 func (srv *UserService) FreeChatStatisticsForModel(ctx context.Context, userID int64, model string) (*FreeChatState, error) {
-	realModel := model
-	if srv.conf.VirtualModel.NanxianRel != "" && realModel == chat.ModelNanXian {
-		realModel = srv.conf.VirtualModel.NanxianRel
-	}
-
-	if srv.conf.VirtualModel.BeichouRel != "" && realModel == chat.ModelBeiChou {
-		realModel = srv.conf.VirtualModel.BeichouRel
-	}
+	realModel := srv.freeModelID(model)
 
 	freeModel := coins.GetFreeModel(realModel)
 	if freeModel == nil || freeModel.FreeCount <= 0 {
@@ -83,19 +77,26 @@ func (srv *UserService) FreeChatStatisticsForModel(ctx context.Context, userID i
 	}, nil
 }
 
+// freeModelID 返回免费次数统计使用的模型，虚拟模型本身不是免费模型时，使用驱动模型的免费次数
+func (srv *UserService) freeModelID(model string) string {
+	if coins.IsFreeModel(model) {
+		return model
+	}
+
+	if vm, ok := chat.LookupVirtualModel(srv.conf, model); ok {
+		return vm.Virtual.Model
+	}
+
+	return model
+}
+
 func (srv *UserService) freeChatCacheKey(userID int64, model string) string {
 	return fmt.Sprintf("free-chat:uid:%d:model:%s", userID, model)
 }
 
 // FreeChatRequestCounts 免费模型使用次数：每天免费 n 次
 func (srv *UserService) FreeChatRequestCounts(ctx context.Context, userID int64, model string) (leftCount int, maxCount int) {
-	if srv.conf.VirtualModel.NanxianRel != "" && model == chat.ModelNanXian {
-		model = srv.conf.VirtualModel.NanxianRel
-	}
-
-	if srv.conf.VirtualModel.BeichouRel != "" && model == chat.ModelBeiChou {
-		model = srv.conf.VirtualModel.BeichouRel
-	}
+	model = srv.freeModelID(model)
 
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
@@ -127,13 +128,7 @@ func (srv *UserService) FreeChatRequestCounts(ctx context.Context, userID int64,
 
 // UpdateFreeChatCount 更新免费聊天次数使用情况
 func (srv *UserService) UpdateFreeChatCount(ctx context.Context, userID int64, model string) error {
-	if srv.conf.VirtualModel.NanxianRel != "" && model == chat.ModelNanXian {
-		model = srv.conf.VirtualModel.NanxianRel
-	}
-
-	if srv.conf.VirtualModel.BeichouRel != "" && model == chat.ModelBeiChou {
-		model = srv.conf.VirtualModel.BeichouRel
-	}
+	model = srv.freeModelID(model)
 
 	if !coins.IsFreeModel(model) {
 		return nil
@@ -237,6 +232,26 @@ func (srv *UserService) UserQuota(ctx context.Context, userID int64) (*UserQuota
 	}, nil
 }
 
+// IsPaidUser 用户是否为付费用户（有未过期的已购买配额），带缓存（10分钟）
+func (srv *UserService) IsPaidUser(ctx context.Context, userID int64) (bool, error) {
+	key := fmt.Sprintf("user:%d:paid", userID)
+	if paid, err := srv.rds.Get(ctx, key).Result(); err == nil {
+		return paid == "1", nil
+	}
+
+	quotas, err := srv.quotaRepo.GetUserQuotaDetails(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("get user quota details failed: %w", err)
+	}
+
+	paid := array.Filter(quotas, func(item repo.Quota, _ int) bool { return !item.Expired && item.PaymentId != "" })
+	if err := srv.rds.SetNX(ctx, key, ternary.If(len(paid) > 0, "1", "0"), 10*time.Minute).Err(); err != nil {
+		log.F(log.M{"user_id": userID}).Errorf("缓存用户付费状态失败: %s", err)
+	}
+
+	return len(paid) > 0, nil
+}
+
 // FreezeUserQuota 冻结用户配额
 func (srv *UserService) FreezeUserQuota(ctx context.Context, userID int64, quota int64) error {
 	if quota <= 0 {
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/search"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+)
+
+var ErrWebSearchDisabled = errors.New("web search is disabled")
+
+// WebSearchService 联网搜索
+type WebSearchService struct {
+	conf *config.Config `autowire:"@"`
+
+	// searcher 未配置搜索服务时为 nil
+	searcher search.Searcher
+}
+
+func NewWebSearchService(resolver infra.Resolver) *WebSearchService {
+	svc := &WebSearchService{}
+	resolver.MustAutoWire(svc)
+
+	searcher, err := search.NewFromConfig(svc.conf)
+	if err != nil {
+		if !errors.Is(err, search.ErrNoProvider) {
+			log.Errorf("联网搜索服务初始化失败，联网搜索功能不可用: %v", err)
+		}
+
+		return svc
+	}
+
+	svc.searcher = searcher
+	return svc
+}
+
+// Enabled 联网搜索功能是否可用
+func (svc *WebSearchService) Enabled() bool {
+	return svc.searcher != nil
+}
+
+// Search 将用户的问题改写为搜索关键词后搜索，questions 为用户的历史问题，最后一个为当前问题，返回搜索关键词和搜索结果
+func (svc *WebSearchService) Search(ctx context.Context, questions []string) (string, []search.Result, error) {
+	if !svc.Enabled() {
+		return "", nil, ErrWebSearchDisabled
+	}
+
+	query := search.RewriteQuery(questions)
+	if query == "" {
+		return "", nil, nil
+	}
+
+	results, err := svc.searcher.Search(ctx, query, svc.conf.WebSearchResultCount)
+	if err != nil {
+		return query, nil, err
+	}
+
+	return query, results, nil
+}
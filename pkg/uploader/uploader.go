@@ -188,6 +188,29 @@ func (u *Uploader) UploadStream(ctx context.Context, uid int, expireAfterDays in
 }
 
 func (u *Uploader) uploadStream(ctx context.Context, uid int, expireAfterDays int, data []byte, ext string) (string, error) {
+	key, err := u.putStream(ctx, uid, expireAfterDays, data, ext)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", u.baseURL, key), nil
+}
+
+// UploadPrivateStream 上传文件流，返回在 ttl 时间内有效的私有访问 URL
+func (u *Uploader) UploadPrivateStream(ctx context.Context, uid int, expireAfterDays int, data []byte, ext string, ttl time.Duration) (string, error) {
+	key, err := u.putStream(ctx, uid, expireAfterDays, data, ext)
+	if err != nil {
+		time.Sleep(500 * time.Millisecond)
+		if key, err = u.putStream(ctx, uid, expireAfterDays, data, ext); err != nil {
+			return "", err
+		}
+	}
+
+	return u.MakePrivateURL(key, ttl), nil
+}
+
+// putStream 上传文件流，返回文件的 key
+func (u *Uploader) putStream(ctx context.Context, uid int, expireAfterDays int, data []byte, ext string) (string, error) {
 	putPolicy := storage.PutPolicy{
 		Scope:           u.conf.StorageBucket,
 		FsizeLimit:      1024 * 1024 * 20,
@@ -226,7 +249,7 @@ func (u *Uploader) uploadStream(ctx context.Context, uid int, expireAfterDays in
 		return "", fmt.Errorf("upload file failed: %w", err)
 	}
 
-	return fmt.Sprintf("%s/%s", u.baseURL, key), nil
+	return key, nil
 }
 
 // RemoveFile 删除文件
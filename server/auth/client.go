@@ -2,6 +2,7 @@ package auth
 
 import (
 	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 )
 
@@ -34,3 +35,13 @@ func (inf ClientInfo) isCNLocalMode(conf *config.Config) bool {
 
 	return inf.IsIOS() && misc.VersionNewer(inf.Version, "1.0.4")
 }
+
+// CatalogClient 返回用于判断模型是否可用的客户端信息，user 为 nil 表示匿名用户
+func (inf ClientInfo) CatalogClient(conf *config.Config, user *User) catalog.Client {
+	region := catalog.RegionGlobal
+	if inf.IsCNLocalMode(conf) && (user == nil || !user.ExtraPermissionUser()) {
+		region = catalog.RegionCN
+	}
+
+	return catalog.Client{Version: inf.Version, Platform: inf.Platform, Region: region}
+}
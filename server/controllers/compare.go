@@ -0,0 +1,547 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis_rate/v10"
+	"github.com/mylxsw/aidea-server/config"
+	"github.com/mylxsw/aidea-server/internal/coins"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
+	"github.com/mylxsw/aidea-server/pkg/ai/chat"
+	"github.com/mylxsw/aidea-server/pkg/ai/streamwriter"
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	"github.com/mylxsw/aidea-server/pkg/rate"
+	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/aidea-server/pkg/safety"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/aidea-server/pkg/youdao"
+	"github.com/mylxsw/aidea-server/server/auth"
+	"github.com/mylxsw/aidea-server/server/controllers/common"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/ternary"
+)
+
+// CompareController 多模型对比，同一个问题同时发送给多个模型，并排对比各模型的回复
+type CompareController struct {
+	conf        *config.Config           `autowire:"@"`
+	chat        chat.Chat                `autowire:"@"`
+	repo        *repo.Repository         `autowire:"@"`
+	securitySrv *service.SecurityService `autowire:"@"`
+	userSrv     *service.UserService     `autowire:"@"`
+	translater  youdao.Translater        `autowire:"@"`
+	limiter     *rate.RateLimiter        `autowire:"@"`
+}
+
+func NewCompareController(resolver infra.Resolver) web.Controller {
+	ctl := CompareController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *CompareController) Register(router web.Router) {
+	router.Group("/comparisons", func(router web.Router) {
+		router.Any("/stream", ctl.Compare)
+		router.Get("/", ctl.Comparisons)
+		router.Get("/{id}", ctl.Comparison)
+		router.Delete("/{id}", ctl.DeleteComparison)
+	})
+}
+
+// CompareRequest 多模型对比请求
+type CompareRequest struct {
+	// Models 参与对比的模型 ID 列表
+	Models    []string      `json:"models"`
+	Messages  chat.Messages `json:"messages"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+	chat.Sampling
+}
+
+func (req CompareRequest) Init() CompareRequest {
+	models := make([]string, 0, len(req.Models))
+	for _, m := range req.Models {
+		m = strings.TrimSpace(m)
+		if m != "" && !array.In(m, models) {
+			models = append(models, m)
+		}
+	}
+
+	req.Models = models
+	req.Messages = array.Filter(req.Messages, func(item chat.Message, _ int) bool {
+		return strings.TrimSpace(item.Content) != "" || len(item.MultipartContents) > 0
+	})
+
+	return req
+}
+
+const (
+	// CompareEventStart 对比开始，包含对比记录 ID
+	CompareEventStart = "start"
+	// CompareEventDelta 模型输出的内容
+	CompareEventDelta = "delta"
+	// CompareEventError 模型输出失败，或者输出内容违规被中断
+	CompareEventError = "error"
+	// CompareEventDone 模型输出结束
+	CompareEventDone = "done"
+	// CompareEventSummary 所有模型输出结束，包含各模型的用量和消耗
+	CompareEventSummary = "summary"
+)
+
+// CompareEvent 多模型对比的流式事件，各模型的输出通过 Index 和 Model 区分
+type CompareEvent struct {
+	Type         string          `json:"type"`
+	ComparisonID int64           `json:"comparison_id,omitempty"`
+	Index        int             `json:"index"`
+	Model        string          `json:"model,omitempty"`
+	Content      string          `json:"content,omitempty"`
+	FinishReason string          `json:"finish_reason,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Results      []CompareResult `json:"results,omitempty"`
+}
+
+// CompareResult 单个模型的对比结果
+type CompareResult struct {
+	Index         int    `json:"index"`
+	Model         string `json:"model"`
+	Error         string `json:"error,omitempty"`
+	InputTokens   int    `json:"input_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	QuotaConsumed int64  `json:"quota_consumed"`
+	ElapsedMs     int64  `json:"elapsed_ms"`
+}
+
+// compareAnswer 对比过程中单个模型的状态
+type compareAnswer struct {
+	model       string
+	req         *chat.Request
+	inputTokens int64
+	// free 本次请求是否使用免费额度
+	free bool
+	// moderator 输出内容审核，未启用时为 nil
+	moderator *safety.StreamModerator
+
+	text     string
+	usage    chat.Usage
+	err      string
+	violated bool
+	done     bool
+	elapsed  time.Duration
+	quota    int64
+}
+
+// Compare 多模型对比，将问题并发发送给多个模型，各模型的输出通过同一个 SSE/WS 连接返回，每个模型单独计费
+func (ctl *CompareController) Compare(ctx context.Context, webCtx web.Context, user *auth.User, w http.ResponseWriter) {
+	sw, req, err := streamwriter.New[CompareRequest](
+		webCtx.Input("ws") == "true", ctl.conf.EnableCORS, webCtx.Request().Raw(), w,
+	)
+	if err != nil {
+		log.F(log.M{"user": user.ID}).Errorf("create stream writer failed: %s", err)
+		return
+	}
+	defer sw.Close()
+
+	if len(req.Messages) == 0 {
+		misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest)), http.StatusBadRequest))
+		return
+	}
+
+	if len(req.Models) < 2 || len(req.Models) > ctl.conf.ChatCompareMaxModels {
+		misc.NoError(sw.WriteErrorStream(
+			fmt.Errorf(common.Text(webCtx, ctl.translater, "请选择 2-%d 个模型进行对比"), ctl.conf.ChatCompareMaxModels),
+			http.StatusBadRequest,
+		))
+		return
+	}
+
+	if err := req.Sampling.Validate(); err != nil {
+		misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+		return
+	}
+
+	// 流控，与聊天共用频率限制，每个模型计为一次聊天请求
+	if ctl.conf.EnableModelRateLimit {
+		if err := ctl.limiter.AllowN(ctx, fmt.Sprintf("chat-limit:u:%d:minute", user.ID), redis_rate.PerMinute(10), len(req.Models)); err != nil {
+			if errors.Is(err, rate.ErrRateLimitExceeded) {
+				misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, rate.ErrRateLimitExceeded.Error())), http.StatusTooManyRequests))
+				return
+			}
+
+			log.F(log.M{"user_id": user.ID}).Errorf("聊天请求频率过高： %s", err)
+		}
+	}
+
+	// 内容安全检测
+	prompt := req.Messages[len(req.Messages)-1].Content
+	if checkRes := ctl.securitySrv.ChatDetect(prompt); checkRes != nil && checkRes.IsReallyUnSafe() {
+		log.F(log.M{"user_id": user.ID, "details": checkRes.ReasonDetail(), "content": prompt}).Warningf("用户 %d 违规，违规内容：%s", user.ID, checkRes.Reason)
+		misc.NoError(sw.WriteErrorStream(errors.New(violateContentPolicyMessage), http.StatusBadRequest))
+		return
+	}
+
+	answers, err := ctl.buildCompareAnswers(ctx, webCtx, user, req)
+	if err != nil {
+		misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+		return
+	}
+
+	// 计算所有付费模型需要预扣的智慧果，余量不足时拒绝请求
+	var needCoins int64
+	for _, a := range answers {
+		calFeeModel := a.req.ResolveCalFeeModel(ctl.conf)
+		if a.free || coins.IsZeroPriceModel(calFeeModel) {
+			continue
+		}
+
+		// 假设每个模型将会消耗 3 个智慧果
+		needCoins += coins.GetOpenAITextCoins(calFeeModel, a.inputTokens) + 3
+	}
+
+	if needCoins > 0 {
+		quota, err := ctl.userSrv.UserQuota(ctx, user.ID)
+		if err != nil {
+			log.F(log.M{"user_id": user.ID}).Errorf("查询用户智慧果余量失败: %s", err)
+			misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, common.ErrInternalError)), http.StatusInternalServerError))
+			return
+		}
+
+		if quota.Rest-quota.Freezed < needCoins {
+			misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, common.ErrQuotaNotEnough)), http.StatusPaymentRequired))
+			return
+		}
+
+		if err := ctl.userSrv.FreezeUserQuota(ctx, user.ID, needCoins); err != nil {
+			log.F(log.M{"user_id": user.ID, "quota": needCoins}).Errorf("freeze user quota failed: %s", err)
+		} else {
+			defer func(ctx context.Context) {
+				if err := ctl.userSrv.UnfreezeUserQuota(ctx, user.ID, needCoins); err != nil {
+					log.F(log.M{"user_id": user.ID, "quota": needCoins}).Errorf("unfreeze user quota failed: %s", err)
+				}
+			}(ctx)
+		}
+	}
+
+	messages, _ := json.Marshal(req.Messages)
+	comparisonID, err := ctl.repo.Comparison.CreateComparison(ctx, user.ID, prompt, messages, req.Models)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("create comparison failed: %v", err)
+		misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, common.ErrInternalError)), http.StatusInternalServerError))
+		return
+	}
+
+	if err := sw.WriteStream(CompareEvent{Type: CompareEventStart, ComparisonID: comparisonID}); err != nil {
+		return
+	}
+
+	startTime := time.Now()
+	ctl.writeCompareResponse(ctx, user, sw, answers, startTime)
+
+	results := ctl.settleCompareAnswers(ctx, user, comparisonID, answers)
+	misc.NoError(sw.WriteStream(CompareEvent{Type: CompareEventSummary, ComparisonID: comparisonID, Results: results}))
+
+	log.F(log.M{"user_id": user.ID, "comparison_id": comparisonID, "elapse": time.Since(startTime).Seconds()}).
+		Infof("接收到多模型对比请求，模型 %s", strings.Join(req.Models, ", "))
+}
+
+// buildCompareAnswers 为每个模型构建聊天请求，检查模型是否可用，并查询模型的免费额度
+func (ctl *CompareController) buildCompareAnswers(ctx context.Context, webCtx web.Context, user *auth.User, req *CompareRequest) ([]*compareAnswer, error) {
+	available := array.ToMap(chat.Models(ctl.conf, false), func(item chat.Model, _ int) string {
+		return item.ID
+	})
+
+	answers := make([]*compareAnswer, 0, len(req.Models))
+	for _, m := range req.Models {
+		if _, ok := available[m]; !ok {
+			return nil, fmt.Errorf("%s: %s", common.Text(webCtx, ctl.translater, common.ErrInvalidModel), m)
+		}
+
+		chatReq := chat.Request{
+			Model:     m,
+			Messages:  req.Messages,
+			MaxTokens: req.MaxTokens,
+			Sampling:  req.Sampling,
+		}.Init()
+		chatReq.Model = catalog.Default().Resolve(chatReq.Model)
+
+		fixed, inputTokens, err := chatReq.Fix(ctl.chat, int64(len(chatReq.Messages)), 1000*200)
+		if err != nil {
+			return nil, err
+		}
+
+		leftCount, _ := ctl.userSrv.FreeChatRequestCounts(ctx, user.ID, fixed.Model)
+		answers = append(answers, &compareAnswer{
+			model:       m,
+			req:         fixed,
+			inputTokens: inputTokens,
+			free:        leftCount > 0,
+			moderator:   ctl.securitySrv.NewChatOutputModerator(),
+		})
+	}
+
+	return answers, nil
+}
+
+// writeCompareResponse 并发请求所有模型，将各模型的输出写入 SSE/WS 流
+// 所有的写入都在当前协程中完成，StreamWriter 不支持并发写入
+func (ctl *CompareController) writeCompareResponse(ctx context.Context, user *auth.User, sw *streamwriter.StreamWriter, answers []*compareAnswer, startTime time.Time) {
+	fanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 响应超时或者客户端断开连接时，未完成输出的模型标记为失败
+	defer func() {
+		for _, a := range answers {
+			if !a.done {
+				a.elapsed = time.Since(startTime)
+				if a.err == "" {
+					a.err = "响应超时或者已中断"
+				}
+			}
+		}
+	}()
+
+	reqs := array.Map(answers, func(a *compareAnswer, _ int) chat.Request { return *a.req })
+	events := chat.FanOut(fanCtx, ctl.chat, reqs)
+
+	write := func(evt CompareEvent) {
+		if err := sw.WriteStream(evt); err != nil {
+			log.F(log.M{"user_id": user.ID, "model": evt.Model}).Warningf("write compare response failed: %v", err)
+		}
+	}
+
+	// moderate 审核模型输出的内容，返回检测通过可以发送的内容，内容违规时中断该模型的输出
+	moderate := func(index int, a *compareAnswer, text string, flush bool) string {
+		if a.moderator == nil {
+			return text
+		}
+
+		passed, checkRes, err := a.moderator.Write(ctx, text)
+		if err == nil && checkRes == nil && flush {
+			var rest string
+			rest, checkRes, err = a.moderator.Flush(ctx)
+			passed += rest
+		}
+
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "model": a.model}).Errorf("多模型对比输出内容安全检测失败: %v", err)
+		}
+
+		if checkRes != nil {
+			log.F(log.M{"user_id": user.ID, "details": checkRes.ReasonDetail(), "detector": checkRes.Detector}).
+				Warningf("模型 %s 输出内容违规，已中断输出", a.model)
+
+			if passed != "" {
+				write(CompareEvent{Type: CompareEventDelta, Index: index, Model: a.model, Content: passed})
+			}
+
+			reason := violateOutputPolicyMessage
+			if detail := checkRes.ReasonDetail(); detail != "" {
+				reason += fmt.Sprintf("\n> \n> 原因：%s", detail)
+			}
+
+			a.violated = true
+			a.text = a.moderator.Passed()
+			a.err = "输出内容违规"
+			write(CompareEvent{Type: CompareEventError, Index: index, Model: a.model, Error: reason, FinishReason: "content_filter"})
+			return ""
+		}
+
+		return passed
+	}
+
+	timer := time.NewTimer(60 * time.Second)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			log.F(log.M{"user_id": user.ID}).Warningf("多模型对比响应超时，已中断输出")
+			return
+		case <-ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+
+			timer.Reset(30 * time.Second)
+
+			a := answers[evt.Index]
+			if evt.Done {
+				a.done = true
+				a.elapsed = time.Since(startTime)
+				if !a.violated && a.err == "" {
+					if text := moderate(evt.Index, a, "", true); text != "" {
+						write(CompareEvent{Type: CompareEventDelta, Index: evt.Index, Model: a.model, Content: text})
+					}
+				}
+
+				write(CompareEvent{Type: CompareEventDone, Index: evt.Index, Model: a.model})
+				continue
+			}
+
+			// 输出内容违规或者出错后，丢弃该模型后续的输出
+			if a.violated || a.err != "" {
+				continue
+			}
+
+			a.usage.Update(evt.Response)
+
+			if evt.ErrorCode != "" {
+				log.F(log.M{"user_id": user.ID, "model": a.model}).Errorf("多模型对比聊天响应失败: %v", evt.Response)
+
+				// 错误信息不需要审核，发送前先发送缓存中已经通过检测的内容
+				if text := moderate(evt.Index, a, "", true); text != "" {
+					write(CompareEvent{Type: CompareEventDelta, Index: evt.Index, Model: a.model, Content: text})
+				}
+
+				if !a.violated {
+					a.err = ternary.If(evt.Error != "", evt.Error, evt.ErrorCode)
+					write(CompareEvent{Type: CompareEventError, Index: evt.Index, Model: a.model, Error: a.err})
+				}
+
+				continue
+			}
+
+			a.text += evt.Text
+			text := moderate(evt.Index, a, evt.Text, evt.FinishReason != "")
+			if a.violated || (text == "" && evt.FinishReason == "") {
+				continue
+			}
+
+			write(CompareEvent{Type: CompareEventDelta, Index: evt.Index, Model: a.model, Content: text, FinishReason: evt.FinishReason})
+		}
+	}
+}
+
+// settleCompareAnswers 按照每个模型实际的用量分别扣除智慧果，并保存对比结果
+func (ctl *CompareController) settleCompareAnswers(ctx context.Context, user *auth.User, comparisonID int64, answers []*compareAnswer) []CompareResult {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	results := make([]CompareResult, 0, len(answers))
+	records := make([]model.ChatComparisonAnswer, 0, len(answers))
+	for i, a := range answers {
+		// 虚拟模型按照其实际使用的模型计算 Token 数量，输出内容违规被拦截时，不扣除智慧果
+		calFeeModel := a.req.ResolveCalFeeModel(ctl.conf)
+		a.usage = a.usage.Fill(a.req.Messages, chat.Message{Role: "assistant", Content: a.text}, calFeeModel)
+		if !a.free && !a.violated && a.text != "" {
+			a.quota = coins.GetTextCoins(calFeeModel, int64(a.usage.InputTokens), int64(a.usage.OutputTokens))
+		}
+
+		if a.text != "" && !a.violated {
+			if err := ctl.userSrv.UpdateFreeChatCount(ctx, user.ID, a.req.Model); err != nil {
+				log.F(log.M{"user_id": user.ID, "model": a.req.Model}).Errorf("update free chat count failed: %s", err)
+			}
+		}
+
+		if a.quota > 0 {
+			meta := repo.NewQuotaUsedMeta("chat-compare", a.req.Model).WithUsage(int64(a.usage.ReportedInputTokens), int64(a.usage.ReportedOutputTokens), int64(a.usage.EstimatedInputTokens), int64(a.usage.EstimatedOutputTokens))
+			if err := ctl.repo.Quota.QuotaConsume(ctx, user.ID, a.quota, meta); err != nil {
+				log.F(log.M{"user_id": user.ID, "model": a.req.Model}).Errorf("used quota add failed: %s", err)
+			}
+		}
+
+		status := repo.ComparisonAnswerStatusSucceed
+		if a.err != "" {
+			status = repo.ComparisonAnswerStatusFailed
+		}
+
+		records = append(records, model.ChatComparisonAnswer{
+			Seq:           int64(i),
+			Model:         a.model,
+			Answer:        a.text,
+			Status:        int64(status),
+			Error:         a.err,
+			InputTokens:   int64(a.usage.InputTokens),
+			OutputTokens:  int64(a.usage.OutputTokens),
+			QuotaConsumed: a.quota,
+			ElapsedMs:     a.elapsed.Milliseconds(),
+		})
+
+		results = append(results, CompareResult{
+			Index:         i,
+			Model:         a.model,
+			Error:         a.err,
+			InputTokens:   a.usage.InputTokens,
+			OutputTokens:  a.usage.OutputTokens,
+			QuotaConsumed: a.quota,
+			ElapsedMs:     a.elapsed.Milliseconds(),
+		})
+	}
+
+	if err := ctl.repo.Comparison.SaveAnswers(ctx, user.ID, comparisonID, records); err != nil {
+		log.F(log.M{"user_id": user.ID, "comparison_id": comparisonID}).Errorf("save comparison answers failed: %v", err)
+	}
+
+	return results
+}
+
+// Comparisons 对比记录列表
+func (ctl *CompareController) Comparisons(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	startID := webCtx.Int64Input("start_id", 0)
+	perPage := webCtx.Int64Input("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	items, lastID, err := ctl.repo.Comparison.Comparisons(ctx, user.ID, startID, perPage)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query comparisons failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"data":     items,
+		"start_id": startID,
+		"last_id":  lastID,
+		"per_page": perPage,
+	})
+}
+
+// Comparison 对比记录详情，包含请求上下文和各模型的回复
+func (ctl *CompareController) Comparison(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	item, err := ctl.repo.Comparison.Comparison(ctx, user.ID, int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("query comparison failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(item)
+}
+
+// DeleteComparison 删除对比记录
+func (ctl *CompareController) DeleteComparison(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Comparison.DeleteComparison(ctx, user.ID, int64(id)); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("delete comparison failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mylxsw/aidea-server/internal/queue"
+	"github.com/mylxsw/aidea-server/pkg/knowledge"
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/aidea-server/pkg/youdao"
+	"github.com/mylxsw/aidea-server/server/auth"
+	"github.com/mylxsw/aidea-server/server/controllers/common"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+)
+
+// KnowledgeController 知识库管理，知识库关联到数字人后，聊天时会自动检索知识库中的相关内容
+type KnowledgeController struct {
+	repo         *repo2.Repository         `autowire:"@"`
+	knowledgeSrv *service.KnowledgeService `autowire:"@"`
+	queue        *queue.Queue              `autowire:"@"`
+	translater   youdao.Translater         `autowire:"@"`
+}
+
+func NewKnowledgeController(resolver infra.Resolver) web.Controller {
+	ctl := KnowledgeController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *KnowledgeController) Register(router web.Router) {
+	router.Group("/knowledge-bases", func(router web.Router) {
+		router.Get("/", ctl.Bases)
+		router.Post("/", ctl.CreateBase)
+		router.Get("/{id}", ctl.Base)
+		router.Put("/{id}", ctl.UpdateBase)
+		router.Delete("/{id}", ctl.DeleteBase)
+		router.Post("/{id}/documents", ctl.AddDocument)
+		router.Delete("/{id}/documents/{document_id}", ctl.DeleteDocument)
+	})
+}
+
+// Bases 知识库列表
+func (ctl *KnowledgeController) Bases(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	bases, err := ctl.repo.Knowledge.Bases(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query knowledge bases failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": bases})
+}
+
+// parseBaseRequest 解析知识库名称和描述
+func (ctl *KnowledgeController) parseBaseRequest(webCtx web.Context) (string, string, error) {
+	name := strings.TrimSpace(webCtx.Input("name"))
+	if name == "" {
+		return "", "", errors.New("知识库名称不能为空")
+	}
+
+	if utf8.RuneCountInString(name) > 30 {
+		return "", "", errors.New("知识库名称不能超过 30 个字符")
+	}
+
+	description := strings.TrimSpace(webCtx.Input("description"))
+	if utf8.RuneCountInString(description) > 100 {
+		return "", "", errors.New("知识库描述不能超过 100 个字符")
+	}
+
+	return name, description, nil
+}
+
+// CreateBase 创建知识库
+func (ctl *KnowledgeController) CreateBase(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	if !ctl.knowledgeSrv.Enabled() {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, "知识库功能未启用"), http.StatusBadRequest)
+	}
+
+	name, description, err := ctl.parseBaseRequest(webCtx)
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, err.Error()), http.StatusBadRequest)
+	}
+
+	id, err := ctl.repo.Knowledge.CreateBase(ctx, user.ID, name, description)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("create knowledge base failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"id": id})
+}
+
+// Base 知识库详情，包含知识库中的文档列表
+func (ctl *KnowledgeController) Base(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	base, err := ctl.repo.Knowledge.Base(ctx, user.ID, int64(id))
+	if err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("query knowledge base failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	documents, err := ctl.repo.Knowledge.Documents(ctx, user.ID, base.Id)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("query knowledge documents failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"base": base, "documents": documents})
+}
+
+// UpdateBase 更新知识库信息
+func (ctl *KnowledgeController) UpdateBase(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	name, description, err := ctl.parseBaseRequest(webCtx)
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, err.Error()), http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Knowledge.UpdateBase(ctx, user.ID, int64(id), name, description); err != nil {
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("update knowledge base failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// DeleteBase 删除知识库，已关联该知识库的数字人会自动解除关联
+func (ctl *KnowledgeController) DeleteBase(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Knowledge.DeleteBase(ctx, user.ID, int64(id)); err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("delete knowledge base failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// AddDocument 向知识库中添加文档，支持上传文本、Markdown、PDF 文件（file），或者直接提交文本内容（name、content）
+// 文档添加后通过异步任务进行分块和向量化，处理完成前无法被检索
+func (ctl *KnowledgeController) AddDocument(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	if !ctl.knowledgeSrv.Enabled() {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, "知识库功能未启用"), http.StatusBadRequest)
+	}
+
+	id, err := strconv.Atoi(webCtx.PathVar("id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	base, err := ctl.repo.Knowledge.Base(ctx, user.ID, int64(id))
+	if err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("query knowledge base failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	filename, data, err := ctl.readDocument(webCtx)
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, err.Error()), http.StatusBadRequest)
+	}
+
+	docID, err := ctl.knowledgeSrv.AddDocument(ctx, user.ID, base.Id, filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrKnowledgeDocumentTooLarge):
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrFileTooLarge), http.StatusBadRequest)
+		case errors.Is(err, service.ErrKnowledgeTooManyDocuments):
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, "知识库中的文档数量已达上限"), http.StatusBadRequest)
+		case errors.Is(err, service.ErrKnowledgeDocumentNotParsed):
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, "无法解析文档内容"), http.StatusBadRequest)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("add knowledge document failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	taskID, err := ctl.queue.Enqueue(&queue.KnowledgeIndexPayload{
+		UserID:     user.ID,
+		DocumentID: docID,
+		CreatedAt:  time.Now(),
+	}, queue.NewKnowledgeIndexTask)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "document_id": docID}).Errorf("enqueue knowledge index task failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"id": docID, "task_id": taskID})
+}
+
+// readDocument 读取上传的文档或者提交的文本内容，返回文档名称和内容
+func (ctl *KnowledgeController) readDocument(webCtx web.Context) (string, []byte, error) {
+	if content := webCtx.Input("content"); content != "" {
+		name := strings.TrimSpace(webCtx.InputWithDefault("name", "文本"))
+		if !knowledge.IsSupported(name) {
+			name += ".txt"
+		}
+
+		return name, []byte(content), nil
+	}
+
+	uploadedFile, err := webCtx.File("file")
+	if err != nil {
+		return "", nil, errors.New(common.ErrInvalidRequest)
+	}
+
+	defer func() { misc.NoError(uploadedFile.Delete()) }()
+
+	if !knowledge.IsSupported(uploadedFile.Name()) {
+		return "", nil, errors.New("仅支持 txt、md、pdf 格式的文档")
+	}
+
+	if uploadedFile.Size() > service.KnowledgeMaxFileSize {
+		return "", nil, errors.New(common.ErrFileTooLarge)
+	}
+
+	data, err := os.ReadFile(uploadedFile.GetTempFilename())
+	if err != nil {
+		log.Errorf("read uploaded file failed: %v", err)
+		return "", nil, errors.New(common.ErrInvalidRequest)
+	}
+
+	return uploadedFile.Name(), data, nil
+}
+
+// DeleteDocument 删除知识库中的文档
+func (ctl *KnowledgeController) DeleteDocument(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	docID, err := strconv.Atoi(webCtx.PathVar("document_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Knowledge.DeleteDocument(ctx, user.ID, int64(docID)); err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "document_id": docID}).Errorf("delete knowledge document failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
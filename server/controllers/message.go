@@ -1,13 +1,33 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mylxsw/aidea-server/internal/queue"
+	"github.com/mylxsw/aidea-server/pkg/export"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/aidea-server/pkg/service"
+	"github.com/mylxsw/aidea-server/pkg/youdao"
+	"github.com/mylxsw/aidea-server/server/auth"
+	"github.com/mylxsw/aidea-server/server/controllers/common"
+	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
 )
 
+// MessageController 聊天历史记录，用于客户端在多个设备之间恢复和同步聊天记录
 type MessageController struct {
-	// repo *repo.Repository `autowire:"@"`
-	// conf *config.Config   `autowire:"@"`
+	messageRepo *repo2.MessageRepo     `autowire:"@"`
+	exportSrv   *service.ExportService `autowire:"@"`
+	queue       *queue.Queue           `autowire:"@"`
+	translater  youdao.Translater      `autowire:"@"`
 }
 
 func NewMessageController(resolver infra.Resolver) web.Controller {
@@ -18,6 +38,269 @@ func NewMessageController(resolver infra.Resolver) web.Controller {
 
 func (ctl *MessageController) Register(router web.Router) {
 	router.Group("/messages", func(router web.Router) {
+		router.Get("/sync", ctl.Sync)
+		router.Get("/rooms/{room_id}", ctl.RoomMessages)
+		router.Delete("/rooms/{room_id}", ctl.DeleteRoomMessages)
+		router.Post("/rooms/{room_id}/export", ctl.Export)
+		router.Post("/rooms/{room_id}/import", ctl.Import)
+		router.Get("/{message_id}/thread", ctl.Thread)
+		router.Get("/{message_id}/branch", ctl.Branch)
+		router.Delete("/{message_id}", ctl.DeleteMessage)
+	})
+}
+
+// Message 聊天消息
+type Message struct {
+	ID            int64    `json:"id"`
+	RoomID        int64    `json:"room_id"`
+	PID           int64    `json:"pid,omitempty"`
+	Role          int64    `json:"role"`
+	Message       string   `json:"message"`
+	Model         string   `json:"model,omitempty"`
+	TokenConsumed int64    `json:"token_consumed,omitempty"`
+	QuotaConsumed int64    `json:"quota_consumed,omitempty"`
+	Status        int64    `json:"status"`
+	Error         string   `json:"error,omitempty"`
+	Images        []string `json:"images,omitempty"`
+	CreatedAt     int64    `json:"created_at"`
+	UpdatedAt     int64    `json:"updated_at"`
+}
+
+func buildMessages(messages []model.ChatMessages) []Message {
+	return array.Map(messages, func(msg model.ChatMessages, _ int) Message {
+		return Message{
+			ID:            msg.Id,
+			RoomID:        msg.RoomId,
+			PID:           msg.Pid,
+			Role:          msg.Role,
+			Message:       msg.Message,
+			Model:         msg.Model,
+			TokenConsumed: msg.TokenConsumed,
+			QuotaConsumed: msg.QuotaConsumed,
+			Status:        msg.Status,
+			Error:         msg.Error,
+			Images:        repo2.MessageImages(msg),
+			CreatedAt:     msg.CreatedAt.Unix(),
+			UpdatedAt:     msg.UpdatedAt.Unix(),
+		}
+	})
+}
+
+// RoomMessages 获取数字人的聊天历史记录，按照消息 ID 倒序分页
+func (ctl *MessageController) RoomMessages(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	roomID, err := strconv.Atoi(webCtx.PathVar("room_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	startID := webCtx.Int64Input("start_id", 0)
+	perPage := webCtx.Int64Input("per_page", 100)
+	if perPage < 1 || perPage > 300 {
+		perPage = 100
+	}
+
+	messages, lastID, err := ctl.messageRepo.GetRoomMessages(ctx, user.ID, int64(roomID), startID, perPage)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("query room messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
 
+	return webCtx.JSON(web.M{
+		"data":     buildMessages(messages),
+		"start_id": startID,
+		"last_id":  lastID,
+		"per_page": perPage,
 	})
 }
+
+// Thread 获取消息所在的对话（提问以及它的所有回复）
+func (ctl *MessageController) Thread(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	messageID, err := strconv.Atoi(webCtx.PathVar("message_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	messages, err := ctl.messageRepo.GetThread(ctx, user.ID, int64(messageID))
+	if err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "message_id": messageID}).Errorf("query message thread failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": buildMessages(messages)})
+}
+
+// Branch 获取消息所在的对话分支（从对话开始到该消息），用于客户端展示当前选择的分支
+func (ctl *MessageController) Branch(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	messageID, err := strconv.Atoi(webCtx.PathVar("message_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	limit := webCtx.IntInput("limit", 100)
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	messages, err := ctl.messageRepo.GetBranch(ctx, user.ID, int64(messageID), limit)
+	if err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "message_id": messageID}).Errorf("query message branch failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": buildMessages(messages)})
+}
+
+// DeleteMessage 删除聊天消息，删除提问时同时删除它的所有回复
+func (ctl *MessageController) DeleteMessage(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	messageID, err := strconv.Atoi(webCtx.PathVar("message_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.messageRepo.DeleteMessage(ctx, user.ID, int64(messageID)); err != nil {
+		log.F(log.M{"user_id": user.ID, "message_id": messageID}).Errorf("delete message failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// DeleteRoomMessages 清空数字人的聊天历史记录
+func (ctl *MessageController) DeleteRoomMessages(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	roomID, err := strconv.Atoi(webCtx.PathVar("room_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.messageRepo.DeleteRoomMessages(ctx, user.ID, int64(roomID)); err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("delete room messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// Sync 多端同步聊天记录
+// 只指定 since_id 时，返回 ID 大于 since_id 的新消息；
+// 指定 updated_after（Unix 时间戳，秒）时，返回该时间之后有变更的消息，包括已删除的消息（status = 3），
+// 客户端使用返回的 last_id 和 last_updated_at 作为下一次同步的 since_id 和 updated_after，直到 has_more 为 false
+func (ctl *MessageController) Sync(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	limit := webCtx.Int64Input("limit", 100)
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	req := repo2.MessageSyncReq{
+		SinceID: webCtx.Int64Input("since_id", 0),
+		Limit:   limit,
+	}
+
+	if updatedAfter := webCtx.Int64Input("updated_after", 0); updatedAfter > 0 {
+		req.UpdatedAfter = time.Unix(updatedAfter, 0)
+	}
+
+	res, err := ctl.messageRepo.SyncMessages(ctx, user.ID, req)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("sync messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	var lastUpdatedAt int64
+	if !res.LastUpdatedAt.IsZero() {
+		lastUpdatedAt = res.LastUpdatedAt.Unix()
+	}
+
+	return webCtx.JSON(web.M{
+		"data":            buildMessages(res.Messages),
+		"last_id":         res.LastID,
+		"last_updated_at": lastUpdatedAt,
+		"has_more":        res.HasMore,
+	})
+}
+
+// Export 导出数字人的聊天记录，format 可选值为 markdown（默认）/html/json
+// 消息数量较少时直接返回文件的访问地址，否则创建异步任务，客户端通过任务状态查询接口获取文件地址
+func (ctl *MessageController) Export(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	roomID, err := strconv.Atoi(webCtx.PathVar("room_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	format := export.Format(webCtx.InputWithDefault("format", string(export.FormatMarkdown)))
+	if !format.Valid() {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	async, err := ctl.exportSrv.ShouldAsync(ctx, user.ID, int64(roomID))
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("count room messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	if async {
+		taskID, err := ctl.queue.Enqueue(&queue.ChatExportPayload{
+			UserID:    user.ID,
+			RoomID:    int64(roomID),
+			Format:    format,
+			CreatedAt: time.Now(),
+		}, queue.NewChatExportTask)
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("enqueue chat export task failed: %v", err)
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+		}
+
+		return webCtx.JSON(web.M{"task_id": taskID})
+	}
+
+	url, err := ctl.exportSrv.Export(ctx, user.ID, int64(roomID), format)
+	if err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("export chat messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"url":          url,
+		"valid_before": time.Now().Add(service.ExportURLTTL).Format(time.RFC3339),
+	})
+}
+
+// Import 将 JSON 格式导出的聊天记录导入到数字人中，请求体为导出的 JSON 文件内容
+func (ctl *MessageController) Import(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	roomID, err := strconv.Atoi(webCtx.PathVar("room_id"))
+	if err != nil {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	conv, err := export.Decode(bytes.NewReader(webCtx.Body()), service.ExportMaxMessages)
+	if err != nil {
+		if errors.Is(err, export.ErrTooManyMessages) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrFileTooLarge), http.StatusRequestEntityTooLarge)
+		}
+
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Warningf("parse imported chat messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	if err := ctl.exportSrv.Import(ctx, user.ID, int64(roomID), conv); err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrNotFound), http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "room_id": roomID}).Errorf("import chat messages failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"count": len(conv.Messages)})
+}
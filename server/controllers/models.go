@@ -28,27 +28,11 @@ func (ctl *ModelController) Register(router web.Router) {
 
 // Models 获取模型列表
 func (ctl *ModelController) Models(ctx web.Context, client *auth.ClientInfo, user *auth.UserOptional) web.Response {
+	catalogClient := client.CatalogClient(ctl.conf, user.User)
+
 	if client.Version == "" || misc.VersionNewer(client.Version, "1.0.6") {
 		models := array.Map(chat.Models(ctl.conf, true), func(item chat.Model, _ int) chat.Model {
-			if item.Disabled {
-				return item
-			}
-
-			if client.Version != "" && item.VersionMin != "" && misc.VersionOlder(client.Version, item.VersionMin) {
-				item.Disabled = true
-				return item
-			}
-
-			if client.Version != "" && item.VersionMax != "" && misc.VersionNewer(client.Version, item.VersionMax) {
-				item.Disabled = true
-				return item
-			}
-
-			if client.IsCNLocalMode(ctl.conf) && item.IsSensitiveModel() && (user.User == nil || !user.User.ExtraPermissionUser()) {
-				item.Disabled = true
-				return item
-			}
-
+			item.Disabled = !item.AvailableFor(catalogClient)
 			return item
 		})
 
@@ -56,15 +40,7 @@ func (ctl *ModelController) Models(ctx web.Context, client *auth.ClientInfo, use
 	}
 
 	models := array.Filter(chat.Models(ctl.conf, false), func(item chat.Model, _ int) bool {
-		if item.VersionMin != "" && misc.VersionOlder(client.Version, item.VersionMin) {
-			return false
-		}
-
-		if item.VersionMax != "" && misc.VersionNewer(client.Version, item.VersionMax) {
-			return false
-		}
-
-		return !(client.IsCNLocalMode(ctl.conf) && item.IsSensitiveModel() && (user.User == nil || !user.User.ExtraPermissionUser()))
+		return item.AvailableFor(catalogClient)
 	})
 
 	return ctx.JSON(models)
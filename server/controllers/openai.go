@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/mylxsw/aidea-server/pkg/ai/catalog"
 	"github.com/mylxsw/aidea-server/pkg/ai/chat"
 	"github.com/mylxsw/aidea-server/pkg/ai/control"
 	openaiHelper "github.com/mylxsw/aidea-server/pkg/ai/openai"
 	"github.com/mylxsw/aidea-server/pkg/ai/streamwriter"
+	"github.com/mylxsw/aidea-server/pkg/knowledge"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/rate"
 	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/aidea-server/pkg/safety"
+	"github.com/mylxsw/aidea-server/pkg/search"
 	"github.com/mylxsw/aidea-server/pkg/service"
 	"github.com/mylxsw/aidea-server/pkg/tencent"
 	"github.com/mylxsw/aidea-server/pkg/youdao"
@@ -50,7 +55,15 @@ type OpenAIController struct {
 	limiter     *rate.RateLimiter        `autowire:"@"`
 	repo        *repo.Repository         `autowire:"@"`
 
+	knowledgeSrv *service.KnowledgeService `autowire:"@"`
+	webSearchSrv *service.WebSearchService `autowire:"@"`
+	policy       *chat.Policy              `autowire:"@"`
+
 	upgrader websocket.Upgrader
+	// compressor 上下文压缩，未启用时为 nil
+	compressor *chat.Compressor
+	// suggester 自动生成标题和推荐问题，未启用时为 nil
+	suggester *chat.Suggester
 
 	apiMode bool // 是否为 OpenAI API 模式
 }
@@ -65,6 +78,21 @@ func NewOpenAIController(resolver infra.Resolver, conf *config.Config, apiMode b
 			return true
 		},
 	}
+
+	if conf.ChatContextCompression && !apiMode {
+		ctl.compressor = chat.NewCompressor(
+			ctl.chat,
+			ctl.repo.Cache,
+			conf.ChatContextCompressionModel,
+			conf.ChatContextCompressionMaxTokens,
+			conf.ChatContextCompressionTTL,
+		)
+	}
+
+	if (conf.ChatSuggestionTitle || conf.ChatSuggestionFollowUp) && !apiMode {
+		ctl.suggester = chat.NewSuggester(ctl.chat, conf.ChatSuggestionModel)
+	}
+
 	return ctl
 }
 
@@ -188,6 +216,12 @@ type FinalMessage struct {
 	AnswerID      int64  `json:"answer_id,omitempty"`
 	Info          string `json:"info,omitempty"`
 	Error         string `json:"error,omitempty"`
+	// Sources 联网模式下，本次回答参考的搜索结果
+	Sources []search.Result `json:"sources,omitempty"`
+	// SuggestedQuestions 推荐的追问问题
+	SuggestedQuestions []string `json:"suggested_questions,omitempty"`
+	// Route 请求被路由策略改写时，实际使用的模型和渠道
+	Route *chat.PolicyDecision `json:"route,omitempty"`
 }
 
 func (m FinalMessage) ToJSON() string {
@@ -237,12 +271,38 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 		req.Model = ctl.conf.FreeChatModel
 	}
 
+	// 采样参数校验
+	if err := req.Sampling.Validate(); err != nil {
+		misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+		return
+	}
+
 	// 请求参数预处理
 	var inputTokenCount, maxContextLen int64
+	// 上下文压缩生成的摘要
+	var contextSummary *chat.Summary
+	// 截断上下文时被丢弃的早期对话，开启上下文压缩时会被总结为摘要
+	var droppedMessages chat.Messages
+	// 分支对话中，新提问的父消息 ID，以及重新生成回复时的提问 ID
+	var branchParentID, branchQuestionID int64
+	// 联网模式下，加入到上下文中的搜索结果
+	var searchSources []search.Result
+	// 路由策略的执行结果，未命中时为 nil
+	var route *chat.PolicyDecision
+	// 数字人的聊天配置
+	var settings roomSettings
 
 	if ctl.apiMode {
 		// API 模式下，还原 n 参数原始值（不支持 room 上下文配置）
 		req.N = int(req.RoomID)
+		req.Model = catalog.Default().Resolve(req.Model)
+
+		// 路由策略，API 模式下同样生效，路由结果只改写请求的模型或者渠道（没有返回给客户端的控制消息）
+		if route = ctl.routeModel(ctx, user.User, req); route != nil {
+			applied := route.Apply(*req)
+			req = &applied
+		}
+
 		icnt, err := chat.MessageTokenCount(req.Messages, req.Model)
 		if err != nil {
 			misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
@@ -270,13 +330,42 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 			}
 		}
 
-		// 模型最大上下文长度限制
-		maxContextLen = ctl.loadRoomContextLen(ctx, req.RoomID, user.User.ID)
+		// 模型别名解析为模型名称
+		req.Model = catalog.Default().Resolve(req.Model)
+
+		// 模型最大上下文长度限制，请求中未指定的采样参数使用数字人的配置
+		settings = ctl.loadRoomSettings(ctx, req.RoomID, user.User.ID)
+		maxContextLen = settings.MaxContextLength
+		req.Sampling = settings.Sampling.Merge(req.Sampling)
+
+		// 分支对话，基于服务端保存的聊天记录构建上下文
+		if req.IsBranch() {
+			branchParentID, branchQuestionID, err = ctl.resolveBranch(ctx, webCtx, user.User, req, maxContextLen)
+			if err != nil {
+				misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+				return
+			}
+		}
+
+		// 内容安全检测，需要在知识库检索、联网搜索之前执行，避免将违规内容发送给第三方服务
+		if err := ctl.contentSafety(req, user.User, sw); err != nil {
+			return
+		}
+
+		// 路由策略，根据用户等级、上下文长度等改写请求的模型或者渠道，需要在上下文截断和配额检查之前执行
+		if route = ctl.routeModel(ctx, user.User, req); route != nil {
+			applied := route.Apply(*req)
+			req = &applied
+		}
+
+		originalMessages := req.Messages
 		req, inputTokenCount, err = req.Fix(ctl.chat, maxContextLen, ternary.If(user.User.ID > 0, 1000*200, 1000))
 		if err != nil {
 			misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
 			return
 		}
+
+		droppedMessages = chat.DroppedMessages(originalMessages, req.Messages)
 	}
 
 	// 检查请求参数
@@ -326,13 +415,46 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 		}
 	}
 
-	// 内容安全检测
-	if err := ctl.contentSafety(req, user.User, sw); err != nil {
-		return
+	// 知识库检索、联网搜索和上下文压缩需要调用付费的向量模型、搜索服务和聊天模型，
+	// 因此在配额检查之后执行，避免智慧果不足的用户触发付费请求
+	if !ctl.apiMode && ctl.extraCostAffordable(ctx, user.User, leftCount) {
+		// 数字人关联了知识库时，检索与问题相关的文档内容加入到上下文中
+		knowledgePrompt, knowledgeCount := ctl.injectKnowledge(ctx, user.User, req, settings.KnowledgeBaseID)
+
+		// 联网模式，请求或者数字人开启联网模式时，搜索与问题相关的内容加入到上下文中
+		// 搜索结果的引用编号排在知识库参考资料之后，避免两者的 [编号] 冲突
+		var searchPrompt string
+		if req.WebSearch || settings.WebSearch {
+			searchPrompt, searchSources = ctl.injectWebSearch(ctx, user.User, req, knowledgeCount)
+		}
+
+		// 上下文压缩，将被丢弃的早期对话总结为摘要
+		var summaryPrompt string
+		if len(droppedMessages) > 0 {
+			if contextSummary = ctl.compressContext(ctx, user.User, req, droppedMessages); contextSummary != nil {
+				summaryPrompt = contextSummary.Text
+			}
+		}
+
+		if injected := array.Filter([]string{knowledgePrompt, searchPrompt, summaryPrompt}, func(item string, _ int) bool { return item != "" }); len(injected) > 0 {
+			// 参考资料和摘要追加在系统提示中，重新截断上下文，避免超过模型的上下文长度限制
+			fixed, fixedTokenCount, err := req.Fix(ctl.chat, maxContextLen, ternary.If(user.User.ID > 0, 1000*200, 1000))
+			if err != nil {
+				misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+				return
+			}
+
+			req, inputTokenCount = fixed, fixedTokenCount
+			for _, prompt := range injected {
+				promptTokens, _ := chat.MessageTokenCount(chat.Messages{{Role: "system", Content: prompt}}, req.Model)
+				inputTokenCount += int64(promptTokens)
+			}
+		}
 	}
 
 	var realTokenConsumed int
 	var quotaConsumed int64
+	var usage chat.Usage
 
 	startTime := time.Now()
 	defer func() {
@@ -351,11 +473,14 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 			)
 	}()
 
-	// 写入用户消息
-	questionID := ctl.saveChatQuestion(ctx, user.User, req)
+	// 写入用户消息，重新生成回复时不产生新的提问
+	questionID := branchQuestionID
+	if questionID == 0 {
+		questionID = ctl.saveChatQuestion(ctx, user.User, req, branchParentID)
+	}
 
 	// 发起聊天请求并返回 SSE/WS 流
-	replyText, err := ctl.handleChat(ctx, req, user.User, sw, webCtx, questionID, 0)
+	replyText, toolCalls, usage, err := ctl.handleChat(ctx, req, user.User, sw, webCtx, questionID, 0)
 	if errors.Is(err, ErrChatResponseHasSent) {
 		return
 	}
@@ -368,7 +493,7 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 		if startTime.Add(60 * time.Second).After(time.Now()) {
 			log.F(log.M{"req": req, "user_id": user.User.ID}).Warningf("聊天响应为空，尝试再次请求，模型：%s", req.Model)
 
-			replyText, err = ctl.handleChat(ctx, req, user.User, sw, webCtx, questionID, 1)
+			replyText, toolCalls, usage, err = ctl.handleChat(ctx, req, user.User, sw, webCtx, questionID, 1)
 			if errors.Is(err, ErrChatResponseHasSent) {
 				return
 			}
@@ -382,21 +507,44 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 	}
 
 	// 返回自定义控制信息，告诉客户端当前消耗情况
-	realTokenConsumed, quotaConsumed = ctl.resolveConsumeQuota(req, replyText, leftCount > 0)
+	// 输出内容违规被拦截时，不扣除智慧果
+	usage, quotaConsumed = ctl.resolveConsumeQuota(req, replyText, toolCalls, usage, leftCount > 0 || errors.Is(err, ErrChatResponseViolation))
+	realTokenConsumed = usage.Total()
+
+	// 生成上下文摘要按照摘要请求本身的用量单独计费，使用免费次数的请求也需要支付摘要的费用（摘要来自缓存时没有消耗）
+	quotaModels := []string{req.Model}
+	var summaryQuota int64
+	if contextSummary != nil && !contextSummary.Cached {
+		realTokenConsumed += contextSummary.Tokens()
+		summaryQuota = coins.GetTextCoins(contextSummary.Model, int64(contextSummary.InputTokens), int64(contextSummary.OutputTokens))
+	}
+
+	// 回答成功后，生成推荐的追问问题，按照配置决定是否计入本次请求的消耗中
+	var followUps []string
+	if ctl.suggester != nil && ctl.conf.ChatSuggestionFollowUp && err == nil && replyText != "" {
+		if suggestion := ctl.suggestFollowUps(ctx, user.User, req, replyText); suggestion != nil {
+			followUps = suggestion.Questions
+			if ctl.conf.ChatSuggestionBilling && quotaConsumed > 0 {
+				realTokenConsumed += suggestion.Tokens()
+				quotaConsumed += coins.GetTextCoins(suggestion.Model, int64(suggestion.InputTokens), int64(suggestion.OutputTokens))
+				quotaModels = append(quotaModels, suggestion.Model)
+			}
+		}
+	}
 
 	func() {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 
 		// 写入用户消息
-		answerID := ctl.saveChatAnswer(ctx, user.User, replyText, quotaConsumed, realTokenConsumed, req, questionID, chatErrorMessage)
+		answerID := ctl.saveChatAnswer(ctx, user.User, replyText, quotaConsumed+summaryQuota, realTokenConsumed, req, questionID, err)
 
 		if errors.Is(ErrChatResponseEmpty, err) {
 			misc.NoError(sw.WriteErrorStream(err, http.StatusInternalServerError))
 		} else {
 			if !ctl.apiMode {
 				// final 消息为定制消息，用于告诉 AIdea 客户端当前的资源消耗情况以及服务端信息
-				finalWord := ctl.buildFinalSystemMessage(questionID, answerID, user.User, quotaConsumed, realTokenConsumed, req, maxContextLen, chatErrorMessage)
+				finalWord := ctl.buildFinalSystemMessage(questionID, answerID, user.User, quotaConsumed+summaryQuota, realTokenConsumed, req, maxContextLen, chatErrorMessage, contextSummary, searchSources, followUps, route)
 				misc.NoError(sw.WriteStream(finalWord))
 			}
 		}
@@ -423,11 +571,32 @@ func (ctl *OpenAIController) Chat(ctx context.Context, webCtx web.Context, user
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
-			if err := quotaRepo.QuotaConsume(ctx, user.User.ID, quotaConsumed, repo.NewQuotaUsedMeta("chat", req.Model)); err != nil {
+			// 记录聊天模型本身的 Token 用量，摘要、追问等附加消耗不计入
+			meta := repo.NewQuotaUsedMeta("chat", quotaModels...).WithUsage(int64(usage.ReportedInputTokens), int64(usage.ReportedOutputTokens), int64(usage.EstimatedInputTokens), int64(usage.EstimatedOutputTokens))
+			if err := quotaRepo.QuotaConsume(ctx, user.User.ID, quotaConsumed, meta); err != nil {
 				log.Errorf("used quota add failed: %s", err)
 			}
 		}()
 	}
+
+	// 扣除生成上下文摘要消耗的智慧果
+	if summaryQuota > 0 {
+		func() {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			meta := repo.NewQuotaUsedMeta("chat-summary", contextSummary.Model).WithUsage(int64(contextSummary.ReportedInputTokens), int64(contextSummary.ReportedOutputTokens), int64(contextSummary.EstimatedInputTokens), int64(contextSummary.EstimatedOutputTokens))
+			if err := quotaRepo.QuotaConsume(ctx, user.User.ID, summaryQuota, meta); err != nil {
+				log.Errorf("used quota add failed: %s", err)
+			}
+		}()
+	}
+
+	// 新数字人的首次对话完成后，异步生成数字人标题
+	// 使用免费次数的请求，标题生成的费用由平台承担，与聊天本身免费保持一致
+	if ctl.suggester != nil && ctl.conf.ChatSuggestionTitle && err == nil && replyText != "" && user.User.ID > 0 && req.RoomID > 1 {
+		go ctl.generateRoomTitle(user.User, req, replyText, ctl.conf.ChatSuggestionBilling && leftCount <= 0)
+	}
 }
 
 func (ctl *OpenAIController) handleChat(
@@ -438,7 +607,7 @@ func (ctl *OpenAIController) handleChat(
 	webCtx web.Context,
 	questionID int64,
 	retryTimes int,
-) (string, error) {
+) (string, []chat.ToolCall, chat.Usage, error) {
 	chatCtx, cancel := context.WithTimeout(ctx, 180*time.Second)
 	defer cancel()
 
@@ -455,90 +624,201 @@ func (ctl *OpenAIController) handleChat(
 		// 内容违反内容安全策略
 		if errors.Is(err, chat.ErrContentFilter) {
 			ctl.sendViolateContentPolicyResp(sw, "")
-			return "", ErrChatResponseHasSent
+			return "", nil, chat.Usage{}, ErrChatResponseHasSent
 		}
 
 		log.WithFields(log.Fields{"user_id": user.ID, "retry_times": retryTimes}).Errorf("聊天请求失败，模型 %s: %v", req.Model, err)
 
 		misc.NoError(sw.WriteErrorStream(errors.New(common.Text(webCtx, ctl.translater, common.ErrInternalError)), http.StatusInternalServerError))
-		return "", ErrChatResponseHasSent
+		return "", nil, chat.Usage{}, ErrChatResponseHasSent
 	}
 
-	replyText, err := ctl.writeChatResponse(ctx, req, stream, user, sw)
+	replyText, toolCalls, usage, err := ctl.writeChatResponse(ctx, req, stream, user, sw)
 	if err != nil {
-		return replyText, err
+		return replyText, toolCalls, usage, err
 	}
 
 	replyText = strings.TrimSpace(replyText)
 
-	if replyText == "" {
-		return replyText, ErrChatResponseEmpty
+	// 模型发起工具调用时，回复内容可以为空
+	if replyText == "" && len(toolCalls) == 0 {
+		return replyText, toolCalls, usage, ErrChatResponseEmpty
 	}
 
-	return replyText, nil
+	return replyText, toolCalls, usage, nil
 }
 
 var (
+	ErrChatResponseViolation  = errors.New("聊天响应内容违规，已被系统拦截")
 	ErrChatResponseEmpty      = errors.New("聊天响应为空")
 	ErrChatResponseHasSent    = errors.New("聊天响应已经发送")
 	ErrChatResponseGapTimeout = errors.New("两次响应之间等待时间过长，强制中断")
 )
 
-func (ctl *OpenAIController) writeChatResponse(ctx context.Context, req *chat.Request, stream <-chan chat.Response, user *auth.User, sw *streamwriter.StreamWriter) (string, error) {
+// writeChatResponse 将聊天响应写入到 SSE/WS 流中，返回回复内容、工具调用以及服务商上报的 Token 用量
+func (ctl *OpenAIController) writeChatResponse(ctx context.Context, req *chat.Request, stream <-chan chat.Response, user *auth.User, sw *streamwriter.StreamWriter) (string, []chat.ToolCall, chat.Usage, error) {
 	var replyText string
+	var toolCalls []chat.ToolCall
+	var usage chat.Usage
+
+	// 输出内容审核，未启用时为 nil
+	var moderator *safety.StreamModerator
+	if !ctl.apiMode {
+		moderator = ctl.securitySrv.NewChatOutputModerator()
+	}
+
+	id := 0
+	writeDelta := func(text string, calls []chat.ToolCall, finishReason string) error {
+		id++
+		resp := ChatCompletionStreamResponse{
+			ID:      strconv.Itoa(id),
+			Created: time.Now().Unix(),
+			Model:   req.Model,
+			Object:  "chat.completion",
+			Choices: []ChatCompletionStreamChoice{
+				{
+					Delta: ChatCompletionStreamChoiceDelta{
+						Role:      "assistant",
+						Content:   text,
+						ToolCalls: calls,
+					},
+				},
+			},
+		}
+
+		if finishReason != "" {
+			resp.Choices[0].FinishReason = &finishReason
+		}
+
+		if err := sw.WriteStream(resp); err != nil {
+			log.F(log.M{"req": req, "user_id": user.ID}).Warningf("write response failed: %v", err)
+			return err
+		}
+
+		return nil
+	}
+
+	// moderate 审核输出内容，返回检测通过可以发送的内容，内容违规时返回 ErrChatResponseViolation
+	moderate := func(text string, flush bool) (string, error) {
+		passed, checkRes, err := moderator.Write(ctx, text)
+		if err == nil && checkRes == nil && flush {
+			var rest string
+			rest, checkRes, err = moderator.Flush(ctx)
+			passed += rest
+		}
+
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("聊天输出内容安全检测失败: %v", err)
+		}
+
+		if checkRes != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": req.RoomID, "details": checkRes.ReasonDetail(), "detector": checkRes.Detector}).
+				Warningf("模型 %s 输出内容违规，已中断输出", req.Model)
+			ctl.sendViolateOutputPolicyResp(sw, checkRes.ReasonDetail())
+			return passed, ErrChatResponseViolation
+		}
+
+		return passed, nil
+	}
+
+	// interrupted 客户端断开连接时，缓存中尚未审核的内容需要检测通过后才能保存
+	interrupted := func() (string, []chat.ToolCall, chat.Usage, error) {
+		if moderator == nil {
+			return replyText, toolCalls, usage, nil
+		}
+
+		// 请求上下文已经取消，使用独立的上下文完成检测
+		flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, checkRes, err := moderator.Flush(flushCtx)
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("聊天输出内容安全检测失败: %v", err)
+		}
+
+		if checkRes != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": req.RoomID, "details": checkRes.ReasonDetail(), "detector": checkRes.Detector}).
+				Warningf("模型 %s 输出内容违规", req.Model)
+			return moderator.Passed(), toolCalls, usage, ErrChatResponseViolation
+		}
+
+		return moderator.Passed(), toolCalls, usage, nil
+	}
 
 	// 生成 SSE 流
 	timer := time.NewTimer(60 * time.Second)
 	defer timer.Stop()
 
-	id := 0
+	received := 0
 	for {
-		if id > 0 {
+		if received > 0 {
 			timer.Reset(30 * time.Second)
 		}
 
 		select {
 		case <-timer.C:
-			return replyText, ErrChatResponseGapTimeout
+			return replyText, toolCalls, usage, ErrChatResponseGapTimeout
 		case <-ctx.Done():
-			return replyText, nil
+			return interrupted()
 		case res, ok := <-stream:
 			if !ok {
-				return replyText, nil
+				// 输出结束，发送缓存中剩余的内容
+				if moderator != nil {
+					text, err := moderate("", true)
+					if err != nil {
+						return moderator.Passed(), toolCalls, usage, err
+					}
+
+					if text != "" {
+						_ = writeDelta(text, nil, "")
+					}
+				}
+
+				return replyText, toolCalls, usage, nil
 			}
 
-			id++
+			received++
+			usage.Update(res)
 
+			text := res.Text
 			if res.ErrorCode != "" {
 				log.WithFields(log.Fields{"req": req, "user_id": user.ID}).Errorf("聊天响应失败: %v", res)
 
-				if res.Error != "" {
-					res.Text = fmt.Sprintf("\n\n---\n抱歉，我们遇到了一些错误，以下是错误详情：\n%s\n", res.Error)
-				} else {
-					return replyText, nil
+				if res.Error == "" {
+					return replyText, toolCalls, usage, nil
+				}
+
+				text = fmt.Sprintf("\n\n---\n抱歉，我们遇到了一些错误，以下是错误详情：\n%s\n", res.Error)
+				if moderator != nil {
+					// 错误信息不需要审核，发送前先发送缓存中已经输出的内容
+					passed, err := moderate("", true)
+					if err != nil {
+						return moderator.Passed(), toolCalls, usage, err
+					}
+
+					text = passed + text
 				}
 			} else {
 				replyText += res.Text
-			}
+				toolCalls = chat.MergeToolCalls(toolCalls, res.ToolCalls)
 
-			resp := ChatCompletionStreamResponse{
-				ID:      strconv.Itoa(id),
-				Created: time.Now().Unix(),
-				Model:   req.Model,
-				Object:  "chat.completion",
-				Choices: []ChatCompletionStreamChoice{
-					{
-						Delta: ChatCompletionStreamChoiceDelta{
-							Role:    "assistant",
-							Content: res.Text,
-						},
-					},
-				},
+				if moderator != nil {
+					passed, err := moderate(res.Text, res.FinishReason != "" || len(res.ToolCalls) > 0)
+					if err != nil {
+						return moderator.Passed(), toolCalls, usage, err
+					}
+
+					// 内容还在缓存中等待检测
+					if passed == "" && len(res.ToolCalls) == 0 && res.FinishReason == "" {
+						continue
+					}
+
+					text = passed
+				}
 			}
 
-			if err := sw.WriteStream(resp); err != nil {
-				log.F(log.M{"req": req, "user_id": user.ID}).Warningf("write response failed: %v", err)
-				return replyText, nil
+			if err := writeDelta(text, res.ToolCalls, res.FinishReason); err != nil {
+				return interrupted()
 			}
 		}
 	}
@@ -562,6 +842,7 @@ type ChatCompletionStreamChoiceDelta struct {
 	Content      string               `json:"content"`
 	Role         string               `json:"role,omitempty"`
 	FunctionCall *openai.FunctionCall `json:"function_call,omitempty"`
+	ToolCalls    []chat.ToolCall      `json:"tool_calls,omitempty"`
 }
 
 // buildFinalSystemMessage 构建最后一条消息，该消息为系统消息，用于告诉 AIdea 客户端当前的资源消耗情况以及服务端信息
@@ -574,13 +855,19 @@ func (*OpenAIController) buildFinalSystemMessage(
 	req *chat.Request,
 	maxContextLen int64,
 	chatErrorMessage string,
+	contextSummary *chat.Summary,
+	searchSources []search.Result,
+	followUps []string,
+	route *chat.PolicyDecision,
 ) ChatCompletionStreamResponse {
 	finalMsg := FinalMessage{
-		Type:       "summary",
-		QuestionID: questionID,
-		AnswerID:   answerID,
-		Token:      int64(realTokenConsumed),
-		Error:      chatErrorMessage,
+		Type:               "summary",
+		QuestionID:         questionID,
+		AnswerID:           answerID,
+		Token:              int64(realTokenConsumed),
+		Error:              chatErrorMessage,
+		Sources:            searchSources,
+		SuggestedQuestions: followUps,
 	}
 
 	if len(req.Messages) >= int(maxContextLen*2)-1 {
@@ -591,6 +878,22 @@ func (*OpenAIController) buildFinalSystemMessage(
 		}
 	}
 
+	if contextSummary != nil {
+		info := "较早的对话内容已被总结为摘要，AI 仍然可以参考这些内容。"
+		if !contextSummary.Cached {
+			info = fmt.Sprintf("较早的对话内容已被总结为摘要，AI 仍然可以参考这些内容，生成摘要消耗了 %d 个 Token（已计入本次请求）。", contextSummary.Tokens())
+		}
+
+		finalMsg.Info = strings.TrimSpace(finalMsg.Info + "\n\n" + info)
+	}
+
+	if route != nil {
+		finalMsg.Route = route
+		if route.Notice != "" {
+			finalMsg.Info = strings.TrimSpace(finalMsg.Info + "\n\n" + route.Notice)
+		}
+	}
+
 	if user.InternalUser() {
 		finalMsg.QuotaConsumed = quotaConsumed
 	}
@@ -631,8 +934,14 @@ func (ctl *OpenAIController) queryChatQuota(
 		return nil, 0, err
 	}
 
+	// 价格为 0 的模型（如免费的本地模型）不需要预扣智慧果
+	calFeeModel := req.ResolveCalFeeModel(ctl.conf)
+	if coins.IsZeroPriceModel(calFeeModel) {
+		return quota, 0, nil
+	}
+
 	// 假设本次请求将会消耗 3 个智慧果
-	return quota, coins.GetOpenAITextCoins(req.ResolveCalFeeModel(ctl.conf), inputTokenCount) + 3, nil
+	return quota, coins.GetOpenAITextCoins(calFeeModel, inputTokenCount) + 3, nil
 }
 
 func (ctl *OpenAIController) rateLimitPass(ctx context.Context, client *auth.ClientInfo, user *auth.User) error {
@@ -672,8 +981,15 @@ func (ctl *OpenAIController) rateLimitPass(ctx context.Context, client *auth.Cli
 	return nil
 }
 
-func (ctl *OpenAIController) saveChatAnswer(ctx context.Context, user *auth.User, replyText string, quotaConsumed int64, realWordCount int, req *chat.Request, questionID int64, chatErrorMessage string) int64 {
+func (ctl *OpenAIController) saveChatAnswer(ctx context.Context, user *auth.User, replyText string, quotaConsumed int64, realWordCount int, req *chat.Request, questionID int64, chatErr error) int64 {
 	if ctl.conf.EnableRecordChat && !ctl.apiMode {
+		var status int64 = repo.MessageStatusSucceed
+		var chatErrorMessage string
+		if chatErr != nil {
+			chatErrorMessage = chatErr.Error()
+			status = ternary.If[int64](errors.Is(chatErr, ErrChatResponseViolation), repo.MessageStatusBlocked, repo.MessageStatusFailed)
+		}
+
 		answerID, err := ctl.messageRepo.Add(ctx, repo.MessageAddReq{
 			UserID:        user.ID,
 			Message:       replyText,
@@ -683,7 +999,7 @@ func (ctl *OpenAIController) saveChatAnswer(ctx context.Context, user *auth.User
 			RoomID:        req.RoomID,
 			Model:         req.Model,
 			PID:           questionID,
-			Status:        int64(ternary.If(chatErrorMessage != "", repo.MessageStatusFailed, repo.MessageStatusSucceed)),
+			Status:        status,
 			Error:         chatErrorMessage,
 		})
 		if err != nil {
@@ -695,21 +1011,20 @@ func (ctl *OpenAIController) saveChatAnswer(ctx context.Context, user *auth.User
 	return 0
 }
 
-func (ctl *OpenAIController) resolveConsumeQuota(req *chat.Request, replyText string, isFreeRequest bool) (int, int64) {
-	messages := append(req.Messages, chat.Message{
-		Role:    "assistant",
-		Content: replyText,
-	})
-
-	realTokenConsumed, _ := chat.MessageTokenCount(messages, req.Model)
-	quotaConsumed := coins.GetOpenAITextCoins(req.ResolveCalFeeModel(ctl.conf), int64(realTokenConsumed))
+// resolveConsumeQuota 计算本次请求消耗的智慧果，输入、输出分别计费
+// 优先使用服务商上报的 Token 用量，未上报时使用分词器估算
+func (ctl *OpenAIController) resolveConsumeQuota(req *chat.Request, replyText string, toolCalls []chat.ToolCall, usage chat.Usage, isFreeRequest bool) (chat.Usage, int64) {
+	// 虚拟模型按照其实际使用的模型计算 Token 数量
+	calFeeModel := req.ResolveCalFeeModel(ctl.conf)
+	usage = usage.Fill(req.Messages, chat.Message{Role: "assistant", Content: replyText, ToolCalls: toolCalls}, calFeeModel)
+	quotaConsumed := coins.GetTextCoins(calFeeModel, int64(usage.InputTokens), int64(usage.OutputTokens))
 
 	// 免费请求，不扣除智慧果
-	if isFreeRequest || replyText == "" {
+	if isFreeRequest || (replyText == "" && len(toolCalls) == 0) {
 		quotaConsumed = 0
 	}
 
-	return realTokenConsumed, quotaConsumed
+	return usage, quotaConsumed
 }
 
 // makeChatQuestionFailed 更新聊天问题为失败状态
@@ -727,16 +1042,28 @@ func (ctl *OpenAIController) makeChatQuestionFailed(ctx context.Context, questio
 	}
 }
 
-// saveChatQuestion 保存用户聊天问题
-func (ctl *OpenAIController) saveChatQuestion(ctx context.Context, user *auth.User, req *chat.Request) int64 {
+// saveChatQuestion 保存用户聊天问题，parentID 为分支对话中上一轮的回复 ID
+// 非分支对话（parentID 为 0）时，问题的父消息为数字人中最后一条回复，使对话历史保持完整的消息链
+func (ctl *OpenAIController) saveChatQuestion(ctx context.Context, user *auth.User, req *chat.Request, parentID int64) int64 {
 	if ctl.conf.EnableRecordChat && !ctl.apiMode {
+		if parentID <= 0 && req.RoomID > 0 {
+			lastAnswerID, err := ctl.messageRepo.LastRoomAnswerID(ctx, user.ID, req.RoomID)
+			if err != nil {
+				log.F(log.M{"room_id": req.RoomID, "user_id": user.ID}).Errorf("查询数字人最后一条回复失败: %s", err)
+			}
+
+			parentID = lastAnswerID
+		}
+
 		qid, err := ctl.messageRepo.Add(ctx, repo.MessageAddReq{
 			UserID:  user.ID,
 			Message: req.Messages[len(req.Messages)-1].Content,
 			Role:    repo.MessageRoleUser,
 			RoomID:  req.RoomID,
 			Model:   req.Model,
+			PID:     parentID,
 			Status:  repo.MessageStatusSucceed,
+			Images:  messageImageURLs(req.Messages[len(req.Messages)-1]),
 		})
 		if err != nil {
 			log.F(log.M{"req": req, "user_id": user.ID}).Errorf("保存用户聊天请求失败（问题部分）: %s", err)
@@ -748,8 +1075,174 @@ func (ctl *OpenAIController) saveChatQuestion(ctx context.Context, user *auth.Us
 	return 0
 }
 
-func (ctl *OpenAIController) loadRoomContextLen(ctx context.Context, roomID int64, userID int64) int64 {
-	var maxContextLength int64 = 3
+// messageImageURLs 消息中包含的图片地址，base64 编码的图片数据不做保存
+func messageImageURLs(msg chat.Message) []string {
+	images := make([]string, 0)
+	for _, part := range msg.MultipartContents {
+		if part.ImageURL != nil && (strings.HasPrefix(part.ImageURL.URL, "http://") || strings.HasPrefix(part.ImageURL.URL, "https://")) {
+			images = append(images, part.ImageURL.URL)
+		}
+	}
+
+	return images
+}
+
+// branchMessage 使用保存的聊天记录构建上下文消息，包含图片时还原为多模态消息
+func branchMessage(role, content string, images []string) chat.Message {
+	if len(images) == 0 {
+		return chat.Message{Role: role, Content: content}
+	}
+
+	parts := make([]*chat.MultipartContent, 0, len(images)+1)
+	if strings.TrimSpace(content) != "" {
+		parts = append(parts, &chat.MultipartContent{Type: "text", Text: content})
+	}
+
+	for _, image := range images {
+		parts = append(parts, &chat.MultipartContent{Type: "image_url", ImageURL: &chat.ImageURL{URL: image}})
+	}
+
+	return chat.Message{Role: role, Content: content, MultipartContents: parts}
+}
+
+// resolveBranch 分支对话，使用服务端保存的分支聊天记录替换客户端提交的上下文
+// 返回新提问的父消息 ID，重新生成回复时，返回需要重新回答的提问 ID（不产生新的提问）
+func (ctl *OpenAIController) resolveBranch(ctx context.Context, webCtx web.Context, user *auth.User, req *chat.Request, maxContextLen int64) (parentID int64, questionID int64, err error) {
+	if !ctl.conf.EnableRecordChat || user.ID <= 0 {
+		return 0, 0, errors.New(common.Text(webCtx, ctl.translater, "当前服务不支持分支对话"))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	loadMessage := func(messageID int64, role repo.MessageRole) (*model.ChatMessages, error) {
+		msg, err := ctl.messageRepo.GetMessage(ctx, user.ID, messageID)
+		if err != nil {
+			if !errors.Is(err, repo.ErrNotFound) {
+				log.F(log.M{"user_id": user.ID, "message_id": messageID}).Errorf("查询分支消息失败: %s", err)
+			}
+
+			return nil, errors.New(common.Text(webCtx, ctl.translater, "消息不存在"))
+		}
+
+		if msg.RoomId != req.RoomID || msg.Role != int64(role) {
+			return nil, errors.New(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest))
+		}
+
+		return msg, nil
+	}
+
+	var leafID int64
+	switch {
+	case req.EditID > 0:
+		// 编辑消息后重新发送，新的提问与被编辑的提问拥有相同的父消息
+		edited, err := loadMessage(req.EditID, repo.MessageRoleUser)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		req.Regenerate = false
+		parentID, leafID = edited.Pid, edited.Pid
+	case req.Regenerate:
+		// 重新生成回复，上下文以需要重新回答的提问结尾
+		if _, err := loadMessage(req.ParentID, repo.MessageRoleUser); err != nil {
+			return 0, 0, err
+		}
+
+		questionID, leafID = req.ParentID, req.ParentID
+	default:
+		if _, err := loadMessage(req.ParentID, repo.MessageRoleAssistant); err != nil {
+			return 0, 0, err
+		}
+
+		parentID, leafID = req.ParentID, req.ParentID
+	}
+
+	history := make(chat.Messages, 0)
+	if leafID > 0 {
+		branch, err := ctl.messageRepo.GetBranch(ctx, user.ID, leafID, int(maxContextLen)*2+1)
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "message_id": leafID}).Errorf("查询对话分支失败: %s", err)
+			return 0, 0, errors.New(common.Text(webCtx, ctl.translater, common.ErrInternalError))
+		}
+
+		for _, msg := range branch {
+			// 失败以及内容违规被拦截的回复不作为上下文
+			isAssistant := msg.Role == int64(repo.MessageRoleAssistant)
+			images := repo.MessageImages(msg)
+			if (isAssistant && (msg.Status == repo.MessageStatusFailed || msg.Status == repo.MessageStatusBlocked)) || (strings.TrimSpace(msg.Message) == "" && len(images) == 0) {
+				continue
+			}
+
+			history = append(history, branchMessage(ternary.If(isAssistant, "assistant", "user"), msg.Message, images))
+		}
+	}
+
+	*req = req.WithHistory(history)
+	if len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != "user" {
+		return 0, 0, errors.New(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest))
+	}
+
+	return parentID, questionID, nil
+}
+
+// compressContext 上下文压缩，将被丢弃的早期对话总结为摘要并注入到请求中，摘要生成失败时不影响正常聊天
+func (ctl *OpenAIController) compressContext(ctx context.Context, user *auth.User, req *chat.Request, dropped chat.Messages) *chat.Summary {
+	if ctl.compressor == nil || len(dropped) == 0 || user.ID <= 0 || req.RoomID <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	summary, err := ctl.compressor.Summarize(ctx, fmt.Sprintf("chat-context-summary:%d:%d", user.ID, req.RoomID), dropped)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("生成上下文摘要失败: %s", err)
+		return nil
+	}
+
+	if summary != nil {
+		*req = req.InjectSummary(summary.Text)
+	}
+
+	return summary
+}
+
+// extraCostAffordable 用户是否可以支付上下文摘要、联网搜索等附加请求的费用
+// 付费请求已经通过了配额检查，使用免费次数的请求需要用户有剩余的智慧果，否则跳过附加请求
+func (ctl *OpenAIController) extraCostAffordable(ctx context.Context, user *auth.User, leftCount int) bool {
+	if user.ID <= 0 {
+		return false
+	}
+
+	if leftCount <= 0 {
+		return true
+	}
+
+	quota, err := ctl.userSrv.UserQuota(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("查询用户智慧果余量失败: %s", err)
+		return false
+	}
+
+	return quota.Rest-quota.Freezed > 0
+}
+
+// roomSettings 数字人的聊天配置
+type roomSettings struct {
+	// MaxContextLength 最大上下文长度
+	MaxContextLength int64
+	// Sampling 采样参数
+	Sampling chat.Sampling
+	// KnowledgeBaseID 关联的知识库 ID，未关联时为 0
+	KnowledgeBaseID int64
+	// WebSearch 是否开启联网模式
+	WebSearch bool
+}
+
+// loadRoomSettings 加载数字人的最大上下文长度、采样参数和知识库配置
+func (ctl *OpenAIController) loadRoomSettings(ctx context.Context, roomID int64, userID int64) roomSettings {
+	settings := roomSettings{MaxContextLength: 3}
 	if roomID > 0 && userID > 0 {
 		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 		defer cancel()
@@ -760,11 +1253,203 @@ func (ctl *OpenAIController) loadRoomContextLen(ctx context.Context, roomID int6
 		}
 
 		if room != nil && room.MaxContext > 0 {
-			maxContextLength = room.MaxContext
+			settings.MaxContextLength = room.MaxContext
+		}
+
+		if room != nil && room.Sampling != "" {
+			if s, err := chat.ParseSampling(room.Sampling); err != nil {
+				log.F(log.M{"room_id": roomID, "user_id": userID}).Errorf("解析 ROOM 采样参数失败: %s", err)
+			} else {
+				settings.Sampling = s
+			}
+		}
+
+		if room != nil {
+			settings.KnowledgeBaseID = room.KnowledgeBaseId
+			settings.WebSearch = room.WebSearch == 1
+		}
+	}
+
+	return settings
+}
+
+// routeModel 执行模型路由策略，没有命中的规则时返回 nil
+func (ctl *OpenAIController) routeModel(ctx context.Context, user *auth.User, req *chat.Request) *chat.PolicyDecision {
+	tokens, err := chat.MessageTokenCount(req.Messages, req.Model)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "model": req.Model}).Warningf("route model: count tokens failed: %v", err)
+	}
+
+	input := chat.PolicyInput{Model: req.Model, Tokens: tokens}
+	if ctl.policy.UsesTier() {
+		input.Tier = ctl.userPolicyTier(ctx, user)
+	}
+
+	decision := ctl.policy.Evaluate(input)
+	if decision != nil {
+		log.F(log.M{"user_id": user.ID, "tier": input.Tier, "tokens": tokens, "decision": decision}).Debugf("chat request routed by policy")
+	}
+
+	return decision
+}
+
+// userPolicyTier 用户在路由策略中的等级
+func (ctl *OpenAIController) userPolicyTier(ctx context.Context, user *auth.User) string {
+	if user.ID == 0 {
+		return chat.PolicyTierAnonymous
+	}
+
+	if user.InternalUser() {
+		return chat.PolicyTierInternal
+	}
+
+	paid, err := ctl.userSrv.IsPaidUser(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query user paid status failed: %v", err)
+	}
+
+	return ternary.If(paid, chat.PolicyTierPaid, chat.PolicyTierFree)
+}
+
+// suggestFollowUps 生成推荐的追问问题，生成失败时返回 nil
+func (ctl *OpenAIController) suggestFollowUps(ctx context.Context, user *auth.User, req *chat.Request, answer string) *chat.Suggestion {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	suggestion, err := ctl.suggester.FollowUps(ctx, req.Messages, answer)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("生成推荐问题失败: %s", err)
+		return nil
+	}
+
+	return suggestion
+}
+
+// generateRoomTitle 用户创建的数字人完成首次对话后，生成对话标题作为数字人名称
+// 只处理名称为空或者仍为默认名称的数字人，用户设置过名称的数字人不会被覆盖
+func (ctl *OpenAIController) generateRoomTitle(user *auth.User, req *chat.Request, answer string, billing bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	room, err := ctl.chatSrv.Room(ctx, user.ID, req.RoomID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("查询 ROOM 信息失败: %s", err)
+		return
+	}
+
+	if room.RoomType != repo.RoomTypeCustom || (strings.TrimSpace(room.Name) != "" && room.Name != repo.RoomDefaultName) {
+		return
+	}
+
+	// 数字人中只有本次的提问和回答时，才是首次对话
+	count, err := ctl.messageRepo.CountRoomMessages(ctx, user.ID, req.RoomID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("查询数字人消息数量失败: %s", err)
+		return
+	}
+
+	if count > 2 {
+		return
+	}
+
+	var question string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			question = req.Messages[i].Content
+			break
+		}
+	}
+
+	suggestion, err := ctl.suggester.Title(ctx, question, answer)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("生成数字人标题失败: %s", err)
+		return
+	}
+
+	// 生成标题期间用户可能已经修改了名称，只在名称仍为默认名称时更新
+	updated, err := ctl.repo.Room.UpdateDefaultName(ctx, user.ID, req.RoomID, suggestion.Title)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("更新数字人名称失败: %s", err)
+		return
+	}
+
+	if !updated {
+		return
+	}
+
+	if err := ctl.chatSrv.ForgetRoom(ctx, user.ID, req.RoomID); err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("清除数字人缓存失败: %s", err)
+	}
+
+	if billing {
+		if err := ctl.repo.Quota.QuotaConsume(ctx, user.ID, coins.GetTextCoins(suggestion.Model, int64(suggestion.InputTokens), int64(suggestion.OutputTokens)), repo.NewQuotaUsedMeta("chat-title", suggestion.Model)); err != nil {
+			log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("used quota add failed: %s", err)
 		}
 	}
+}
+
+// injectKnowledge 从知识库中检索与用户问题相关的文档分块，注入到系统提示中，返回注入的提示内容以及参考资料的数量
+// 检索失败时不影响正常聊天
+func (ctl *OpenAIController) injectKnowledge(ctx context.Context, user *auth.User, req *chat.Request, knowledgeBaseID int64) (string, int) {
+	if knowledgeBaseID <= 0 || user.ID <= 0 || !ctl.knowledgeSrv.Enabled() || len(req.Messages) == 0 {
+		return "", 0
+	}
+
+	question := req.Messages[len(req.Messages)-1]
+	if question.Role != "user" {
+		return "", 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	chunks, err := ctl.knowledgeSrv.Retrieve(ctx, knowledgeBaseID, question.Content)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID, "knowledge_base_id": knowledgeBaseID}).Errorf("知识库检索失败: %s", err)
+		return "", 0
+	}
+
+	prompt := knowledge.Prompt(chunks)
+	if prompt == "" {
+		return "", 0
+	}
+
+	*req = req.AppendSystemPrompt(prompt)
+	return prompt, len(chunks)
+}
+
+// injectWebSearch 将用户的问题改写为搜索关键词，搜索后将结果注入到系统提示中，返回注入的提示内容以及搜索结果
+// 搜索结果的引用编号从 offset+1 开始，搜索失败时不影响正常聊天
+func (ctl *OpenAIController) injectWebSearch(ctx context.Context, user *auth.User, req *chat.Request, offset int) (string, []search.Result) {
+	if !ctl.webSearchSrv.Enabled() || len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != "user" {
+		return "", nil
+	}
+
+	questions := array.Map(
+		array.Filter(req.Messages, func(item chat.Message, _ int) bool { return item.Role == "user" }),
+		func(item chat.Message, _ int) string { return item.Content },
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query, results, err := ctl.webSearchSrv.Search(ctx, questions)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID, "query": query}).Errorf("联网搜索失败: %s", err)
+		return "", nil
+	}
+
+	prompt := search.Prompt(query, results, offset)
+	if prompt == "" {
+		return "", nil
+	}
+
+	for i := range results {
+		results[i].Index = offset + i + 1
+	}
 
-	return maxContextLength
+	*req = req.AppendSystemPrompt(prompt)
+	return prompt, results
 }
 
 // 内容安全检测
@@ -792,6 +1477,22 @@ func (ctl *OpenAIController) contentSafety(req *chat.Request, user *auth.User, s
 
 const violateContentPolicyMessage = "抱歉，您的请求因包含违规内容被系统拦截，如果您对此有任何疑问或想进一步了解详情，欢迎通过以下渠道与我们联系：\n\n服务邮箱：support@aicode.cc\n\n微博：@mylxsw\n\n客服微信：x-prometheus\n\n\n---\n\n> 本次请求不扣除智慧果。"
 
+const violateOutputPolicyMessage = "\n\n---\n\n抱歉，AI 生成的回复可能包含违规内容，已被系统拦截，请尝试调整您的问题后重新提问。\n\n> 本次请求不扣除智慧果。"
+
+// sendViolateOutputPolicyResp 模型输出内容违规时，中断输出并告知用户
+func (ctl *OpenAIController) sendViolateOutputPolicyResp(sw *streamwriter.StreamWriter, detail string) {
+	reason := violateOutputPolicyMessage
+	if detail != "" {
+		reason += fmt.Sprintf("\n> \n> 原因：%s", detail)
+	}
+
+	misc.NoError(sw.WriteStream(fmt.Sprintf(
+		`{"id":"chatxxx1","object":"chat.completion.chunk","created":%d,"model":"gpt-3.5-turbo-0613","choices":[{"index":0,"delta":{"role":"assistant","content":%s},"finish_reason":"content_filter"}]}`+"\n\n",
+		time.Now().Unix(),
+		strconv.Quote(reason),
+	)))
+}
+
 func (ctl *OpenAIController) sendViolateContentPolicyResp(sw *streamwriter.StreamWriter, detail string) {
 	reason := violateContentPolicyMessage
 	if detail != "" {
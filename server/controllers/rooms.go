@@ -3,10 +3,12 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/mylxsw/aidea-server/pkg/ai/chat"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
 	"github.com/mylxsw/aidea-server/pkg/repo/model"
+	"github.com/mylxsw/aidea-server/pkg/service"
 	"github.com/mylxsw/aidea-server/pkg/youdao"
 	"net/http"
 	"strconv"
@@ -21,13 +23,16 @@ import (
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/web"
 	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/ternary"
 )
 
 // RoomController 数字人
 type RoomController struct {
-	roomRepo   *repo2.RoomRepo   `autowire:"@"`
-	translater youdao.Translater `autowire:"@"`
-	conf       *config.Config    `autowire:"@"`
+	roomRepo      *repo2.RoomRepo      `autowire:"@"`
+	knowledgeRepo *repo2.KnowledgeRepo `autowire:"@"`
+	chatSrv       *service.ChatService `autowire:"@"`
+	translater    youdao.Translater    `autowire:"@"`
+	conf          *config.Config       `autowire:"@"`
 }
 
 func NewRoomController(resolver infra.Resolver) web.Controller {
@@ -283,6 +288,22 @@ func (ctl *RoomController) CreateRoom(ctx context.Context, webCtx web.Context, u
 		InitMessage:    req.InitMessage,
 	}
 
+	if req.Sampling != nil {
+		room.Sampling = *req.Sampling
+	}
+
+	if req.KnowledgeBaseID != nil {
+		if err := ctl.checkKnowledgeBase(ctx, user.ID, *req.KnowledgeBaseID); err != nil {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, err.Error()), http.StatusBadRequest)
+		}
+
+		room.KnowledgeBaseId = *req.KnowledgeBaseID
+	}
+
+	if req.WebSearch != nil {
+		room.WebSearch = ternary.If[int64](*req.WebSearch, 1, 0)
+	}
+
 	id, err := ctl.roomRepo.Create(ctx, user.ID, &room, true)
 	if err != nil {
 		if err == repo2.ErrRoomNameExists {
@@ -373,6 +394,12 @@ type RoomRequest struct {
 	SystemPrompt string `json:"system_prompt,omitempty"`
 	InitMessage  string `json:"init_message,omitempty"`
 	MaxContext   int64  `json:"max_context,omitempty"`
+	// Sampling 采样参数（JSON 格式），为 nil 时表示请求中未指定
+	Sampling *string `json:"sampling,omitempty"`
+	// KnowledgeBaseID 关联的知识库 ID，为 nil 时表示请求中未指定，为 0 时表示解除关联
+	KnowledgeBaseID *int64 `json:"knowledge_base_id,omitempty"`
+	// WebSearch 是否开启联网模式，为 nil 时表示请求中未指定
+	WebSearch *bool `json:"web_search,omitempty"`
 }
 
 func (ctl *RoomController) parseRoomRequest(webCtx web.Context, isUpdate bool) (*RoomRequest, error) {
@@ -400,7 +427,12 @@ func (ctl *RoomController) parseRoomRequest(webCtx web.Context, isUpdate bool) (
 
 	req.InitMessage = initMessage
 
-	name := webCtx.Input("name")
+	name := strings.TrimSpace(webCtx.Input("name"))
+	if name == "" && !isUpdate && ctl.conf.ChatSuggestionTitle {
+		// 开启自动标题时，创建时可以不指定名称，先使用默认名称，首次对话完成时会自动生成标题
+		name = repo2.RoomDefaultName
+	}
+
 	if name == "" {
 		return nil, errors.New("数字人名称不能为空")
 	}
@@ -433,6 +465,37 @@ func (ctl *RoomController) parseRoomRequest(webCtx web.Context, isUpdate bool) (
 
 	req.SystemPrompt = systemPrompt
 
+	// 采样参数，为空时表示不修改，为 {} 时表示清空
+	if samplingStr := webCtx.Input("sampling"); samplingStr != "" {
+		sampling, err := chat.ParseSampling(samplingStr)
+		if err != nil {
+			return nil, errors.New("采样参数格式错误")
+		}
+
+		if err := sampling.Validate(); err != nil {
+			return nil, fmt.Errorf("采样参数不合法：%s", err)
+		}
+
+		samplingJSON := sampling.JSON()
+		req.Sampling = &samplingJSON
+	}
+
+	// 知识库，为空时表示不修改，为 0 时表示解除关联
+	if webCtx.Input("knowledge_base_id") != "" {
+		knowledgeBaseID := webCtx.Int64Input("knowledge_base_id", 0)
+		if knowledgeBaseID < 0 {
+			return nil, errors.New("知识库不存在")
+		}
+
+		req.KnowledgeBaseID = &knowledgeBaseID
+	}
+
+	// 联网模式，为空时表示不修改
+	if webSearch := webCtx.Input("web_search"); webSearch != "" {
+		enabled := webSearch == "true" || webSearch == "1"
+		req.WebSearch = &enabled
+	}
+
 	avatarId := webCtx.Int64Input("avatar_id", 0)
 	avatarUrl := webCtx.Input("avatar_url")
 
@@ -445,6 +508,24 @@ func (ctl *RoomController) parseRoomRequest(webCtx web.Context, isUpdate bool) (
 	return &req, nil
 }
 
+// checkKnowledgeBase 检查数字人关联的知识库是否属于当前用户，knowledgeBaseID 为 0 时表示解除关联
+func (ctl *RoomController) checkKnowledgeBase(ctx context.Context, userID, knowledgeBaseID int64) error {
+	if knowledgeBaseID == 0 {
+		return nil
+	}
+
+	if _, err := ctl.knowledgeRepo.Base(ctx, userID, knowledgeBaseID); err != nil {
+		if errors.Is(err, repo2.ErrNotFound) {
+			return errors.New("知识库不存在")
+		}
+
+		log.F(log.M{"user_id": userID, "knowledge_base_id": knowledgeBaseID}).Errorf("查询知识库失败: %v", err)
+		return errors.New(common.ErrInternalError)
+	}
+
+	return nil
+}
+
 // UpdateRoom 更新数字人信息
 func (ctl *RoomController) UpdateRoom(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
 	req, err := ctl.parseRoomRequest(webCtx, true)
@@ -506,6 +587,27 @@ func (ctl *RoomController) UpdateRoom(ctx context.Context, webCtx web.Context, u
 		changed = true
 	}
 
+	if req.Sampling != nil && *req.Sampling != room.Sampling {
+		room.Sampling = *req.Sampling
+		changed = true
+	}
+
+	if req.KnowledgeBaseID != nil && *req.KnowledgeBaseID != room.KnowledgeBaseId {
+		if err := ctl.checkKnowledgeBase(ctx, user.ID, *req.KnowledgeBaseID); err != nil {
+			return webCtx.JSONError(common.Text(webCtx, ctl.translater, err.Error()), http.StatusBadRequest)
+		}
+
+		room.KnowledgeBaseId = *req.KnowledgeBaseID
+		changed = true
+	}
+
+	if req.WebSearch != nil {
+		if webSearch := ternary.If[int64](*req.WebSearch, 1, 0); webSearch != room.WebSearch {
+			room.WebSearch = webSearch
+			changed = true
+		}
+	}
+
 	if req.MaxContext != 0 && req.MaxContext != room.MaxContext {
 		if req.MaxContext < 0 || req.MaxContext > 30 {
 			return webCtx.JSONError(common.Text(webCtx, ctl.translater, "最大对话上下文必须为 1-30 之间"), http.StatusBadRequest)
@@ -525,6 +627,10 @@ func (ctl *RoomController) UpdateRoom(ctx context.Context, webCtx web.Context, u
 		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
 	}
 
+	if err := ctl.chatSrv.ForgetRoom(ctx, user.ID, req.RoomID); err != nil {
+		log.F(log.M{"user_id": user.ID, "room_id": req.RoomID}).Errorf("清除数字人缓存失败: %v", err)
+	}
+
 	return webCtx.JSON(room)
 }
 
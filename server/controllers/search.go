@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mylxsw/aidea-server/pkg/misc"
+	repo2 "github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/youdao"
+	"github.com/mylxsw/aidea-server/server/auth"
+	"github.com/mylxsw/aidea-server/server/controllers/common"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
+)
+
+// SearchController 搜索用户的聊天记录和创作岛历史记录
+type SearchController struct {
+	searchRepo *repo2.SearchRepo `autowire:"@"`
+	translater youdao.Translater `autowire:"@"`
+}
+
+func NewSearchController(resolver infra.Resolver) web.Controller {
+	ctl := SearchController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *SearchController) Register(router web.Router) {
+	router.Group("/search", func(router web.Router) {
+		router.Get("/", ctl.Search)
+	})
+}
+
+// searchKeywordMaxLength 搜索关键词的最大长度
+const searchKeywordMaxLength = 100
+
+// Search 关键词搜索，source 可选值为 chat（默认）/group_chat/creative
+// 支持按照 room_id（数字人或群组）、model、创建时间范围（start_time/end_time，Unix 时间戳，秒）过滤，结果按照 ID 倒序分页
+// 返回的 snippet 为包含关键词的片段，关键词使用 <em></em> 标记
+func (ctl *SearchController) Search(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	keyword := strings.TrimSpace(webCtx.Input("keyword"))
+	if keyword == "" || len([]rune(keyword)) > searchKeywordMaxLength {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	source := repo2.SearchSource(webCtx.InputWithDefault("source", string(repo2.SearchSourceChat)))
+	if !array.In(source, []repo2.SearchSource{repo2.SearchSourceChat, repo2.SearchSourceGroupChat, repo2.SearchSourceCreative}) {
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInvalidRequest), http.StatusBadRequest)
+	}
+
+	perPage := webCtx.Int64Input("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	req := repo2.SearchReq{
+		Keyword:       keyword,
+		Source:        source,
+		RoomID:        webCtx.Int64Input("room_id", 0),
+		Model:         webCtx.Input("model"),
+		StartID:       webCtx.Int64Input("start_id", 0),
+		PerPage:       perPage,
+		HighlightPre:  "<em>",
+		HighlightPost: "</em>",
+	}
+
+	if startTime := webCtx.Int64Input("start_time", 0); startTime > 0 {
+		req.StartTime = time.Unix(startTime, 0)
+	}
+
+	if endTime := webCtx.Int64Input("end_time", 0); endTime > 0 {
+		req.EndTime = time.Unix(endTime, 0)
+	}
+
+	results, lastID, err := ctl.searchRepo.Search(ctx, user.ID, req)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("search failed: %v", err)
+		return webCtx.JSONError(common.Text(webCtx, ctl.translater, common.ErrInternalError), http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"data":     results,
+		"keywords": misc.SplitKeywords(keyword),
+		"start_id": req.StartID,
+		"last_id":  lastID,
+		"per_page": perPage,
+	})
+}
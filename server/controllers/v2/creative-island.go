@@ -9,6 +9,7 @@ import (
 	"github.com/mylxsw/aidea-server/pkg/ai/xfyun"
 	"github.com/mylxsw/aidea-server/pkg/misc"
 	"github.com/mylxsw/aidea-server/pkg/repo"
+	"github.com/mylxsw/aidea-server/pkg/repo/model"
 	"github.com/mylxsw/aidea-server/pkg/service"
 	"github.com/mylxsw/aidea-server/pkg/uploader"
 	"github.com/mylxsw/aidea-server/pkg/youdao"
@@ -263,7 +264,23 @@ func (ctl *CreativeIslandController) loadAllModels(ctx context.Context) []repo.I
 		log.Errorf("get models failed: %v", err)
 	}
 
+	// 模拟的图片生成模型，不请求任何外部服务，用于开发和测试
+	if ctl.conf.EnableFake && !array.In("fake", array.Map(models, func(m repo.ImageModel, _ int) string { return m.Vendor })) {
+		models = append(models, repo.ImageModel{ImageModel: model.ImageModel{
+			ModelId:     "fake-image",
+			ModelName:   "Fake Image",
+			Vendor:      "fake",
+			RealModel:   "fake-image",
+			Description: "模拟的图片生成模型，返回占位图片",
+			Status:      1,
+		}})
+	}
+
 	return array.Filter(models, func(m repo.ImageModel, _ int) bool {
+		if m.Vendor == "fake" {
+			return ctl.conf.EnableFake
+		}
+
 		if m.Vendor == "leapai" {
 			return ctl.conf.EnableLeapAI
 		}
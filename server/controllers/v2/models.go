@@ -21,6 +21,7 @@ type ModelController struct {
 	conf    *config.Config       `autowire:"@"`
 	repo    *repo.Repository     `autowire:"@"`
 	userSrv *service.UserService `autowire:"@"`
+	chat    chat.Chat            `autowire:"@"`
 }
 
 // NewModelController 创建模型控制器
@@ -32,6 +33,8 @@ func NewModelController(resolver infra.Resolver) web.Controller {
 
 func (ctl *ModelController) Register(router web.Router) {
 	router.Group("/models", func(router web.Router) {
+		// 模型目录，包含模型的能力描述
+		router.Get("/catalog", ctl.Catalog)
 		// 获取模型支持的风格
 		router.Get("/styles", ctl.Styles)
 		// 自定义首页模型
@@ -67,6 +70,24 @@ func (ctl *ModelController) Styles(ctx context.Context, webCtx web.Context) web.
 	})
 }
 
+// Catalog 返回当前客户端可用的模型以及模型的能力描述
+func (ctl *ModelController) Catalog(ctx context.Context, webCtx web.Context, client *auth.ClientInfo, user *auth.UserOptional) web.Response {
+	catalogClient := client.CatalogClient(ctl.conf, user.User)
+	models := array.Map(
+		array.Filter(chat.Models(ctl.conf, false), func(item chat.Model, _ int) bool {
+			return item.AvailableFor(catalogClient)
+		}),
+		func(item chat.Model, _ int) chat.Model {
+			item.ContextWindow = chat.ContextWindow(ctl.chat, item)
+			return item
+		},
+	)
+
+	return webCtx.JSON(web.M{
+		"data": models,
+	})
+}
+
 // GetAllHomeModels 获取所有首页模型
 func (ctl *ModelController) GetAllHomeModels(ctx context.Context, webCtx web.Context, user *auth.UserOptional) web.Response {
 	homeModels := make([]service.HomeModel, 0)
@@ -97,6 +97,9 @@ func routes(resolver infra.Resolver, router web.Router, mw web.RequestMiddleware
 		"/v1/rooms",            // 数字人管理
 		"/v1/room-galleries",   // 数字人 Gallery
 		"/v1/voice",            // 语音合成
+		"/v1/messages",         // 聊天历史记录
+		"/v1/search",           // 聊天记录和创作岛历史记录搜索
+		"/v1/knowledge-bases",  // 知识库
 		"/v1/admin",            // 管理员接口
 
 		// v2 版本
@@ -293,6 +296,10 @@ func routes(resolver infra.Resolver, router web.Router, mw web.RequestMiddleware
 		controllers.NewTranslateController(resolver, conf),
 		controllers.NewOpenAIController(resolver, conf, false),
 		controllers.NewGroupChatController(resolver),
+		controllers.NewMessageController(resolver),
+		controllers.NewKnowledgeController(resolver),
+		controllers.NewCompareController(resolver),
+		controllers.NewSearchController(resolver),
 
 		controllers.NewAuthController(resolver, conf),
 		controllers.NewUserController(resolver),
@@ -336,6 +343,9 @@ func routes(resolver infra.Resolver, router web.Router, mw web.RequestMiddleware
 }
 
 func muxRoutes(resolver infra.Resolver, router *mux.Router) {
+	// 限制导入聊天记录的请求体大小，框架会在调用控制器之前读取完整的请求体，因此需要在这里限制
+	router.Use(importBodyLimit)
+
 	resolver.MustResolve(func(conf *config.Config) {
 		// 添加 prometheus metrics 支持
 		router.PathPrefix("/metrics").Handler(PrometheusHandler{token: conf.PrometheusToken})
@@ -355,6 +365,32 @@ func muxRoutes(resolver infra.Resolver, router *mux.Router) {
 	})
 }
 
+// importBodyLimit 限制导入聊天记录接口的请求体大小，超出限制的请求体会被截断，导致解析失败
+func importBodyLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if tpl, _ := route.GetPathTemplate(); !strings.HasSuffix(tpl, "/messages/rooms/{room_id}/import") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength > service.ImportMaxBodySize {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(`{"error": "文件太大"}`))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, service.ImportMaxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
 type PrometheusHandler struct {
 	token string
 }